@@ -1,14 +1,119 @@
 package app
 
-import "go-product-app/common/postgresql"
+import (
+	"go-product-app/common/localization"
+	"go-product-app/common/postgresql"
+	"time"
+)
 
 type ConfigurationManager struct {
-	PostgreSqlConfig postgresql.Config
+	PostgreSqlConfig     postgresql.Config
+	PriceSchedulerConfig PriceSchedulerConfig
+	MediaStorageConfig   MediaStorageConfig
+	LocalizationConfig   localization.Settings
+	ProductPurgeConfig   ProductPurgeConfig
+	FileExchangeConfig   FileExchangeConfig
+	JobWorkerConfig      JobWorkerConfig
+	OutboxRelayConfig    OutboxRelayConfig
+	OutboxPurgeConfig    OutboxPurgeConfig
+	WebhookConfig        WebhookConfig
+	ProductStreamConfig  ProductStreamConfig
+	ProductChangeConfig  ProductChangeConfig
+	ProductCacheConfig   ProductCacheConfig
+}
+
+type ProductPurgeConfig struct {
+	// Retention is how long a soft deleted product can still be restored
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+type PriceSchedulerConfig struct {
+	Interval time.Duration
+}
+
+// FileExchangeConfig is where import error reports and export files are stored and served from.
+// Uploaded import files wait for their job under UploadRoot, which is not served.
+type FileExchangeConfig struct {
+	Root       string
+	BaseUrl    string
+	UploadRoot string
+}
+
+type JobWorkerConfig struct {
+	Concurrency  int
+	PollInterval time.Duration
+	// Lease is how long a job stays claimed by a worker that stopped renewing it
+	Lease time.Duration
+}
+
+type OutboxRelayConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+type OutboxPurgeConfig struct {
+	// Retention is how long a published event can still be caught up with by the product stream
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+type WebhookConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	// Timeout is how long a receiver has to respond to a delivery
+	Timeout time.Duration
+	// Lease is how long a delivery being sent is held back from the other dispatchers
+	Lease       time.Duration
+	MaxAttempts int
+	// DisableAfterFailures deactivates a webhook whose deliveries failed this many times in a row
+	DisableAfterFailures int
+}
+
+// ProductStreamConfig sizes the live product stream. BacklogSize events are kept for reconnecting clients,
+// a client that has BufferSize events waiting to be sent is disconnected.
+type ProductStreamConfig struct {
+	BacklogSize int
+	BufferSize  int
+	// Heartbeat keeps idle connections from being closed by proxies
+	Heartbeat time.Duration
+}
+
+type ProductChangeConfig struct {
+	// ReconnectDelay is the first wait before the change listener reconnects, it doubles while reconnecting fails
+	ReconnectDelay time.Duration
+}
+
+// ProductCacheConfig sizes the cache of products by id and of products by store.
+// Without Enabled every read goes to the database.
+type ProductCacheConfig struct {
+	Enabled     bool
+	MaxProducts int
+	MaxStores   int
+	Ttl         time.Duration
+}
+
+type MediaStorageConfig struct {
+	Root          string
+	BaseUrl       string
+	MaxUploadSize int64
 }
 
 func NewConfigurationManager() *ConfigurationManager {
 	return &ConfigurationManager{
-		PostgreSqlConfig: ConfigPostgreSql(),
+		PostgreSqlConfig:     ConfigPostgreSql(),
+		PriceSchedulerConfig: ConfigPriceScheduler(),
+		MediaStorageConfig:   ConfigMediaStorage(),
+		LocalizationConfig:   ConfigLocalization(),
+		ProductPurgeConfig:   ConfigProductPurge(),
+		FileExchangeConfig:   ConfigFileExchange(),
+		JobWorkerConfig:      ConfigJobWorker(),
+		OutboxRelayConfig:    ConfigOutboxRelay(),
+		OutboxPurgeConfig:    ConfigOutboxPurge(),
+		WebhookConfig:        ConfigWebhook(),
+		ProductStreamConfig:  ConfigProductStream(),
+		ProductChangeConfig:  ConfigProductChange(),
+		ProductCacheConfig:   ConfigProductCache(),
 	}
 }
 
@@ -23,3 +128,97 @@ func ConfigPostgreSql() postgresql.Config {
 		MaxConnectionIdleTime: "30s",
 	}
 }
+
+func ConfigPriceScheduler() PriceSchedulerConfig {
+	return PriceSchedulerConfig{
+		Interval: 30 * time.Second,
+	}
+}
+
+func ConfigMediaStorage() MediaStorageConfig {
+	return MediaStorageConfig{
+		Root:          "./data/media",
+		BaseUrl:       "/media",
+		MaxUploadSize: 10 << 20,
+	}
+}
+
+func ConfigLocalization() localization.Settings {
+	return localization.Settings{
+		DefaultLocale: "en",
+		StoreDefaultLocales: map[string]string{
+			"ABC TECH": "tr",
+		},
+	}
+}
+
+func ConfigProductPurge() ProductPurgeConfig {
+	return ProductPurgeConfig{
+		Retention: 30 * 24 * time.Hour,
+		Interval:  time.Hour,
+	}
+}
+
+func ConfigFileExchange() FileExchangeConfig {
+	return FileExchangeConfig{
+		Root:       "./data/files",
+		BaseUrl:    "/files",
+		UploadRoot: "./data/uploads",
+	}
+}
+
+func ConfigJobWorker() JobWorkerConfig {
+	return JobWorkerConfig{
+		Concurrency:  2,
+		PollInterval: 2 * time.Second,
+		Lease:        time.Minute,
+	}
+}
+
+func ConfigOutboxRelay() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		Interval:  time.Second,
+		BatchSize: 100,
+	}
+}
+
+func ConfigOutboxPurge() OutboxPurgeConfig {
+	return OutboxPurgeConfig{
+		Retention: 7 * 24 * time.Hour,
+		Interval:  time.Hour,
+	}
+}
+
+func ConfigWebhook() WebhookConfig {
+	return WebhookConfig{
+		Interval:             2 * time.Second,
+		BatchSize:            50,
+		Timeout:              10 * time.Second,
+		Lease:                time.Minute,
+		MaxAttempts:          8,
+		DisableAfterFailures: 20,
+	}
+}
+
+func ConfigProductStream() ProductStreamConfig {
+	return ProductStreamConfig{
+		BacklogSize: 1000,
+		BufferSize:  64,
+		Heartbeat:   15 * time.Second,
+	}
+}
+
+func ConfigProductChange() ProductChangeConfig {
+	return ProductChangeConfig{
+		ReconnectDelay: time.Second,
+	}
+}
+
+func ConfigProductCache() ProductCacheConfig {
+	return ProductCacheConfig{
+		Enabled:     true,
+		MaxProducts: 10000,
+		MaxStores:   100,
+		Ttl:         5 * time.Minute,
+	}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import "sync"
+
+// Group collapses concurrent loads of the same key into one, the callers that arrive while a key
+// is loading wait for that load and share its result. The zero Group is ready to use.
+type Group[K comparable, V any] struct {
+	mutex sync.Mutex
+	calls map[K]*groupCall[V]
+}
+
+type groupCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Do runs load unless a load of key is already running and returns whether the result was shared.
+func (group *Group[K, V]) Do(key K, load func() (V, error)) (V, error, bool) {
+	group.mutex.Lock()
+	if group.calls == nil {
+		group.calls = make(map[K]*groupCall[V])
+	}
+	if call, running := group.calls[key]; running {
+		group.mutex.Unlock()
+		<-call.done
+		return call.value, call.err, true
+	}
+	call := &groupCall[V]{done: make(chan struct{})}
+	group.calls[key] = call
+	group.mutex.Unlock()
+
+	defer func() {
+		group.mutex.Lock()
+		if group.calls[key] == call {
+			delete(group.calls, key)
+		}
+		group.mutex.Unlock()
+		close(call.done)
+	}()
+	call.value, call.err = load()
+	return call.value, call.err, false
+}
+
+// Forget makes the next Do of key load again instead of waiting for the load already running,
+// which may have read what is being changed.
+func (group *Group[K, V]) Forget(key K) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	delete(group.calls, key)
+}
+
+func (group *Group[K, V]) ForgetAll() {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	group.calls = nil
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a map of at most maxEntries entries that expire ttl after they were set. When it is full the least
+// recently used entry is evicted. Every removal moves it to a new generation, which SetIfGeneration uses to
+// drop values loaded before the removal. It is safe for concurrent use.
+type LRU[K comparable, V any] struct {
+	mutex      sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    map[K]*list.Element
+	// order has the most recently used entry at the front
+	order      *list.List
+	generation uint64
+	hits       int64
+	misses     int64
+	evictions  int64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+func NewLRU[K comparable, V any](maxEntries int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[K]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (lru *LRU[K, V]) Get(key K) (V, bool) {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	element, found := lru.entries[key]
+	if found && time.Now().After(element.Value.(*lruEntry[K, V]).expiresAt) {
+		lru.removeElement(element)
+		found = false
+	}
+	if !found {
+		lru.misses++
+		var zero V
+		return zero, false
+	}
+	lru.hits++
+	lru.order.MoveToFront(element)
+	return element.Value.(*lruEntry[K, V]).value, true
+}
+
+func (lru *LRU[K, V]) Set(key K, value V) {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	lru.set(key, value)
+}
+
+// Generation is to be read before loading a value that is then stored with SetIfGeneration.
+func (lru *LRU[K, V]) Generation() uint64 {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	return lru.generation
+}
+
+// SetIfGeneration stores the value unless an entry was removed since generation was read,
+// the value may then be older than what was removed.
+func (lru *LRU[K, V]) SetIfGeneration(key K, value V, generation uint64) bool {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	if lru.generation != generation {
+		return false
+	}
+	lru.set(key, value)
+	return true
+}
+
+func (lru *LRU[K, V]) Remove(key K) {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	lru.generation++
+	if element, found := lru.entries[key]; found {
+		lru.removeElement(element)
+	}
+}
+
+func (lru *LRU[K, V]) Clear() {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	lru.generation++
+	lru.entries = make(map[K]*list.Element)
+	lru.order.Init()
+}
+
+func (lru *LRU[K, V]) Stats() Stats {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	return Stats{Hits: lru.hits, Misses: lru.misses, Evictions: lru.evictions, Entries: len(lru.entries)}
+}
+
+func (lru *LRU[K, V]) set(key K, value V) {
+	expiresAt := time.Now().Add(lru.ttl)
+	if element, found := lru.entries[key]; found {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value, entry.expiresAt = value, expiresAt
+		lru.order.MoveToFront(element)
+		return
+	}
+	if lru.maxEntries <= 0 {
+		return
+	}
+	for len(lru.entries) >= lru.maxEntries {
+		lru.removeElement(lru.order.Back())
+		lru.evictions++
+	}
+	lru.entries[key] = lru.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+}
+
+func (lru *LRU[K, V]) removeElement(element *list.Element) {
+	lru.order.Remove(element)
+	delete(lru.entries, element.Value.(*lruEntry[K, V]).key)
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type Stats struct {
+	Hits   int64
+	Misses int64
+	// SharedLoads counts the misses that waited for a load already running instead of loading again
+	SharedLoads int64
+	Evictions   int64
+	Entries     int
+}
+
+// ReadThrough loads missing values on Get and keeps them in an LRU, concurrent misses of a key share one load.
+// Errors are not cached. A value loaded while its key was invalidated is returned but not kept.
+type ReadThrough[K comparable, V any] struct {
+	lru         *LRU[K, V]
+	group       Group[K, V]
+	sharedLoads atomic.Int64
+}
+
+func NewReadThrough[K comparable, V any](maxEntries int, ttl time.Duration) *ReadThrough[K, V] {
+	return &ReadThrough[K, V]{lru: NewLRU[K, V](maxEntries, ttl)}
+}
+
+func (readThrough *ReadThrough[K, V]) Get(key K, load func() (V, error)) (V, error) {
+	if value, found := readThrough.lru.Get(key); found {
+		return value, nil
+	}
+
+	value, err, shared := readThrough.group.Do(key, func() (V, error) {
+		generation := readThrough.lru.Generation()
+		value, err := load()
+		if err == nil {
+			readThrough.lru.SetIfGeneration(key, value, generation)
+		}
+		return value, err
+	})
+	if shared {
+		readThrough.sharedLoads.Add(1)
+	}
+	return value, err
+}
+
+func (readThrough *ReadThrough[K, V]) Invalidate(key K) {
+	readThrough.group.Forget(key)
+	readThrough.lru.Remove(key)
+}
+
+func (readThrough *ReadThrough[K, V]) InvalidateAll() {
+	readThrough.group.ForgetAll()
+	readThrough.lru.Clear()
+}
+
+func (readThrough *ReadThrough[K, V]) Stats() Stats {
+	stats := readThrough.lru.Stats()
+	stats.SharedLoads = readThrough.sharedLoads.Load()
+	return stats
+}
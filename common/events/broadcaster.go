@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"go-product-app/domain"
+	"sync"
+)
+
+// Broadcaster fans the published events out to in-process subscribers, e.g. the clients of the product stream.
+// It keeps the latest events in a backlog so a subscriber that reconnects can catch up from the last event it saw.
+// Publishing never waits for a subscriber, one whose buffer is full is dropped and should subscribe again.
+type Broadcaster struct {
+	mutex       sync.Mutex
+	backlog     []domain.ProductEvent
+	backlogSize int
+	bufferSize  int
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives the events of its store, or of every store when Store is empty.
+// The channel of Events is closed when the subscription ends, Dropped tells whether it fell behind.
+type Subscription struct {
+	Store   string
+	events  chan domain.ProductEvent
+	dropped bool
+}
+
+func NewBroadcaster(backlogSize int, bufferSize int) *Broadcaster {
+	return &Broadcaster{
+		backlog:     make([]domain.ProductEvent, 0, backlogSize),
+		backlogSize: backlogSize,
+		bufferSize:  bufferSize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (broadcaster *Broadcaster) Publish(ctx context.Context, event domain.ProductEvent) error {
+	broadcaster.mutex.Lock()
+	defer broadcaster.mutex.Unlock()
+
+	if len(broadcaster.backlog) == broadcaster.backlogSize && broadcaster.backlogSize > 0 {
+		copy(broadcaster.backlog, broadcaster.backlog[1:])
+		broadcaster.backlog = broadcaster.backlog[:len(broadcaster.backlog)-1]
+	}
+	if broadcaster.backlogSize > 0 {
+		broadcaster.backlog = append(broadcaster.backlog, event)
+	}
+
+	for subscription := range broadcaster.subscribers {
+		if !subscription.matches(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.dropped = true
+			broadcaster.remove(subscription)
+		}
+	}
+	return nil
+}
+
+// Subscribe starts a subscription that receives the events published from now on. With a lastEventId it also
+// returns the backlog events published after that event. resumed is false when the event is no longer in
+// the backlog, the subscriber may then have missed events and should reload what it shows.
+func (broadcaster *Broadcaster) Subscribe(store string, lastEventId int64) (*Subscription, []domain.ProductEvent, bool) {
+	broadcaster.mutex.Lock()
+	defer broadcaster.mutex.Unlock()
+
+	subscription := &Subscription{Store: store, events: make(chan domain.ProductEvent, broadcaster.bufferSize)}
+	broadcaster.subscribers[subscription] = struct{}{}
+
+	missed := make([]domain.ProductEvent, 0)
+	if lastEventId == 0 {
+		return subscription, missed, true
+	}
+	// the backlog is in publish order, which is not always the order of the ids
+	position := -1
+	for i, event := range broadcaster.backlog {
+		if event.Id == lastEventId {
+			position = i
+			break
+		}
+	}
+	if position < 0 {
+		return subscription, missed, false
+	}
+	for _, event := range broadcaster.backlog[position+1:] {
+		if subscription.matches(event) {
+			missed = append(missed, event)
+		}
+	}
+	return subscription, missed, true
+}
+
+func (broadcaster *Broadcaster) Unsubscribe(subscription *Subscription) {
+	broadcaster.mutex.Lock()
+	defer broadcaster.mutex.Unlock()
+
+	broadcaster.remove(subscription)
+}
+
+func (broadcaster *Broadcaster) remove(subscription *Subscription) {
+	if _, subscribed := broadcaster.subscribers[subscription]; subscribed {
+		delete(broadcaster.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+func (subscription *Subscription) Events() <-chan domain.ProductEvent {
+	return subscription.events
+}
+
+// Dropped is only meaningful once Events is closed.
+func (subscription *Subscription) Dropped() bool {
+	return subscription.dropped
+}
+
+func (subscription *Subscription) matches(event domain.ProductEvent) bool {
+	return len(subscription.Store) == 0 || subscription.Store == event.Store
+}
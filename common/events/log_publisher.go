@@ -0,0 +1,19 @@
+package events
+
+import (
+	"context"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+)
+
+// LogPublisher only logs the events, it stands in until a message broker is configured.
+type LogPublisher struct{}
+
+func NewLogPublisher() Publisher {
+	return LogPublisher{}
+}
+
+func (logPublisher LogPublisher) Publish(ctx context.Context, event domain.ProductEvent) error {
+	log.Infof("Event %s %s of product %d: %s", event.EventId, event.Type, event.ProductId, event.Payload)
+	return nil
+}
@@ -0,0 +1,12 @@
+package events
+
+import (
+	"context"
+	"go-product-app/domain"
+)
+
+// Publisher delivers product events to other systems. Delivery is at least once, an event whose delivery
+// could not be recorded is published again, so consumers drop the duplicates by EventId.
+type Publisher interface {
+	Publish(ctx context.Context, event domain.ProductEvent) error
+}
@@ -0,0 +1,53 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+)
+
+// Thumbnail scales img down so that neither side exceeds maxSize, keeping the aspect ratio.
+// Every destination pixel is the average of the source pixels it covers, which keeps
+// downscaled photos smooth without an external imaging library. Smaller images are returned as is.
+func Thumbnail(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSize && height <= maxSize {
+		return img
+	}
+
+	thumbnailWidth, thumbnailHeight := maxSize, maxSize
+	if width > height {
+		thumbnailHeight = max(1, height*maxSize/width)
+	} else {
+		thumbnailWidth = max(1, width*maxSize/height)
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, thumbnailWidth, thumbnailHeight))
+	for y := 0; y < thumbnailHeight; y++ {
+		sourceTop := bounds.Min.Y + y*height/thumbnailHeight
+		sourceBottom := bounds.Min.Y + (y+1)*height/thumbnailHeight
+		for x := 0; x < thumbnailWidth; x++ {
+			sourceLeft := bounds.Min.X + x*width/thumbnailWidth
+			sourceRight := bounds.Min.X + (x+1)*width/thumbnailWidth
+
+			var red, green, blue, alpha, count uint64
+			for sourceY := sourceTop; sourceY < sourceBottom; sourceY++ {
+				for sourceX := sourceLeft; sourceX < sourceRight; sourceX++ {
+					r, g, b, a := img.At(sourceX, sourceY).RGBA()
+					red += uint64(r)
+					green += uint64(g)
+					blue += uint64(b)
+					alpha += uint64(a)
+					count++
+				}
+			}
+			thumbnail.Set(x, y, color.RGBA64{
+				R: uint16(red / count),
+				G: uint16(green / count),
+				B: uint16(blue / count),
+				A: uint16(alpha / count),
+			})
+		}
+	}
+	return thumbnail
+}
@@ -0,0 +1,21 @@
+package localization
+
+import "strings"
+
+// Settings holds the locale every store writes its catalog in.
+type Settings struct {
+	DefaultLocale       string
+	StoreDefaultLocales map[string]string
+}
+
+func (settings Settings) DefaultLocaleFor(store string) string {
+	if locale, found := settings.StoreDefaultLocales[store]; found {
+		return locale
+	}
+	return settings.DefaultLocale
+}
+
+// Normalize lower-cases a locale and uses dashes, so "tr_TR" and "tr-tr" are the same key.
+func Normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,274 @@
+package search
+
+import (
+	"fmt"
+	"go-product-app/domain"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// DefaultPriceBuckets are the upper bounds of the price facet buckets, the last bucket is open ended.
+var DefaultPriceBuckets = []float32{100, 500, 1000, 5000}
+
+const (
+	nameWeight        = 2.0
+	exactMatchBonus   = 1.0
+	descriptionWeight = 1.0
+)
+
+// MemoryIndex is an inverted index from word to the products that contain it. Words are also kept sorted,
+// so prefix lookups are a binary search followed by a scan over the matching range.
+type MemoryIndex struct {
+	mutex        sync.RWMutex
+	priceBuckets []float32
+	products     map[int64]domain.Product
+	productWords map[int64][]string
+	postings     map[string]map[int64]float64
+	words        []string
+}
+
+func NewMemoryIndex(priceBuckets []float32) SearchIndex {
+	return &MemoryIndex{
+		priceBuckets: priceBuckets,
+		products:     make(map[int64]domain.Product),
+		productWords: make(map[int64][]string),
+		postings:     make(map[string]map[int64]float64),
+	}
+}
+
+func (memoryIndex *MemoryIndex) Index(product domain.Product) {
+	memoryIndex.mutex.Lock()
+	defer memoryIndex.mutex.Unlock()
+
+	memoryIndex.remove(product.Id)
+	memoryIndex.add(product)
+}
+
+func (memoryIndex *MemoryIndex) Remove(productId int64) {
+	memoryIndex.mutex.Lock()
+	defer memoryIndex.mutex.Unlock()
+
+	memoryIndex.remove(productId)
+}
+
+func (memoryIndex *MemoryIndex) Rebuild(products []domain.Product) {
+	memoryIndex.mutex.Lock()
+	defer memoryIndex.mutex.Unlock()
+
+	memoryIndex.products = make(map[int64]domain.Product, len(products))
+	memoryIndex.productWords = make(map[int64][]string, len(products))
+	memoryIndex.postings = make(map[string]map[int64]float64)
+	for _, product := range products {
+		memoryIndex.addPostings(product)
+	}
+	// the words are sorted once, inserting each new word in order would cost a copy of the list per word
+	memoryIndex.words = make([]string, 0, len(memoryIndex.postings))
+	for word := range memoryIndex.postings {
+		memoryIndex.words = append(memoryIndex.words, word)
+	}
+	slices.Sort(memoryIndex.words)
+}
+
+func (memoryIndex *MemoryIndex) Search(query Query) Result {
+	memoryIndex.mutex.RLock()
+	defer memoryIndex.mutex.RUnlock()
+
+	scores := memoryIndex.match(Tokenize(query.Text))
+
+	storeCounts := make(map[string]int)
+	priceCounts := make(map[string]int)
+	hits := make([]Hit, 0)
+	for productId, score := range scores {
+		product := memoryIndex.products[productId]
+		if len(query.Statuses) > 0 && !slices.Contains(query.Statuses, product.Status) {
+			continue
+		}
+		storeMatches := len(query.Store) == 0 || product.Store == query.Store
+		priceBucket := memoryIndex.priceBucket(product.Price)
+		priceMatches := len(query.PriceBucket) == 0 || priceBucket == query.PriceBucket
+
+		if priceMatches {
+			storeCounts[product.Store]++
+		}
+		if storeMatches {
+			priceCounts[priceBucket]++
+		}
+		if storeMatches && priceMatches {
+			hits = append(hits, Hit{Product: product, Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Product.Id < hits[j].Product.Id
+	})
+
+	result := Result{Total: len(hits), StoreFacets: sortedFacets(storeCounts), PriceFacets: memoryIndex.priceFacets(priceCounts)}
+	start := min(query.Offset, len(hits))
+	end := len(hits)
+	if query.Limit > 0 {
+		end = min(start+query.Limit, len(hits))
+	}
+	result.Hits = hits[start:end]
+	return result
+}
+
+// match scores every product that has a word starting with each of the tokens, an empty token list matches everything.
+func (memoryIndex *MemoryIndex) match(tokens []string) map[int64]float64 {
+	scores := make(map[int64]float64)
+	if len(tokens) == 0 {
+		for productId := range memoryIndex.products {
+			scores[productId] = 0
+		}
+		return scores
+	}
+
+	for i, token := range tokens {
+		tokenScores := make(map[int64]float64)
+		start := sort.SearchStrings(memoryIndex.words, token)
+		for _, word := range memoryIndex.words[start:] {
+			if !strings.HasPrefix(word, token) {
+				break
+			}
+			for productId, weight := range memoryIndex.postings[word] {
+				if word == token {
+					weight += exactMatchBonus
+				}
+				tokenScores[productId] = max(tokenScores[productId], weight)
+			}
+		}
+
+		if i == 0 {
+			scores = tokenScores
+			continue
+		}
+		for productId, score := range scores {
+			tokenScore, found := tokenScores[productId]
+			if !found {
+				delete(scores, productId)
+				continue
+			}
+			scores[productId] = score + tokenScore
+		}
+	}
+	return scores
+}
+
+func (memoryIndex *MemoryIndex) add(product domain.Product) {
+	for _, word := range memoryIndex.addPostings(product) {
+		position := sort.SearchStrings(memoryIndex.words, word)
+		memoryIndex.words = slices.Insert(memoryIndex.words, position, word)
+	}
+}
+
+// addPostings indexes the words of the product and returns the words that were new to the index, which the caller
+// still has to add to the sorted word list.
+func (memoryIndex *MemoryIndex) addPostings(product domain.Product) []string {
+	memoryIndex.products[product.Id] = product
+
+	weights := make(map[string]float64)
+	addText := func(text string, weight float64) {
+		for _, token := range Tokenize(text) {
+			weights[token] = max(weights[token], weight)
+		}
+	}
+	addText(product.Name, nameWeight)
+	addText(product.Sku, nameWeight)
+	for _, translation := range product.Translations {
+		addText(translation.Name, nameWeight)
+		addText(translation.Description, descriptionWeight)
+	}
+	addText(product.Description, descriptionWeight)
+	addText(product.Category, descriptionWeight)
+
+	productWords := make([]string, 0, len(weights))
+	var newWords []string
+	for word, weight := range weights {
+		posting, found := memoryIndex.postings[word]
+		if !found {
+			posting = make(map[int64]float64)
+			memoryIndex.postings[word] = posting
+			newWords = append(newWords, word)
+		}
+		posting[product.Id] = weight
+		productWords = append(productWords, word)
+	}
+	memoryIndex.productWords[product.Id] = productWords
+	return newWords
+}
+
+func (memoryIndex *MemoryIndex) remove(productId int64) {
+	if _, found := memoryIndex.products[productId]; !found {
+		return
+	}
+
+	for _, word := range memoryIndex.productWords[productId] {
+		posting := memoryIndex.postings[word]
+		delete(posting, productId)
+		if len(posting) == 0 {
+			delete(memoryIndex.postings, word)
+			position := sort.SearchStrings(memoryIndex.words, word)
+			memoryIndex.words = slices.Delete(memoryIndex.words, position, position+1)
+		}
+	}
+	delete(memoryIndex.products, productId)
+	delete(memoryIndex.productWords, productId)
+}
+
+func (memoryIndex *MemoryIndex) priceBucket(price float32) string {
+	labels := memoryIndex.priceBucketLabels()
+	for i, upperBound := range memoryIndex.priceBuckets {
+		if price < upperBound {
+			return labels[i]
+		}
+	}
+	return labels[len(labels)-1]
+}
+
+// priceBucketLabels names the buckets like "100-500", the last one like "5000+".
+func (memoryIndex *MemoryIndex) priceBucketLabels() []string {
+	labels := make([]string, 0, len(memoryIndex.priceBuckets)+1)
+	lowerBound := float32(0)
+	for _, upperBound := range memoryIndex.priceBuckets {
+		labels = append(labels, fmt.Sprintf("%g-%g", lowerBound, upperBound))
+		lowerBound = upperBound
+	}
+	return append(labels, fmt.Sprintf("%g+", lowerBound))
+}
+
+// priceFacets lists the buckets in price order rather than by count.
+func (memoryIndex *MemoryIndex) priceFacets(counts map[string]int) []FacetCount {
+	facets := make([]FacetCount, 0, len(counts))
+	for _, label := range memoryIndex.priceBucketLabels() {
+		if count, found := counts[label]; found {
+			facets = append(facets, FacetCount{Value: label, Count: count})
+		}
+	}
+	return facets
+}
+
+func sortedFacets(counts map[string]int) []FacetCount {
+	facets := make([]FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	return facets
+}
+
+// Tokenize lower-cases text and splits it into words of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
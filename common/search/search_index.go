@@ -0,0 +1,43 @@
+package search
+
+import "go-product-app/domain"
+
+// SearchIndex answers product searches without touching the database. It has to be told about every product change.
+type SearchIndex interface {
+	// Index adds the product or replaces the version already indexed
+	Index(product domain.Product)
+	Remove(productId int64)
+	// Rebuild replaces the whole index with products
+	Rebuild(products []domain.Product)
+	Search(query Query) Result
+}
+
+// Query matches products that have a word starting with every word of Text, an empty Text matches every product.
+type Query struct {
+	Text     string
+	Store    string
+	Statuses []string
+	// PriceBucket is one of the bucket labels returned in Result.PriceFacets
+	PriceBucket string
+	Limit       int
+	Offset      int
+}
+
+type Hit struct {
+	Product domain.Product
+	Score   float64
+}
+
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// Result carries facet counts over all matches. A facet ignores its own filter, so the store facet still lists
+// every store when Query.Store is set.
+type Result struct {
+	Hits        []Hit
+	Total       int
+	StoreFacets []FacetCount
+	PriceFacets []FacetCount
+}
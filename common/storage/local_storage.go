@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps objects as files under a root directory and serves them from baseUrl.
+type LocalStorage struct {
+	root    string
+	baseUrl string
+}
+
+func NewLocalStorage(root string, baseUrl string) Storage {
+	return &LocalStorage{root: root, baseUrl: strings.TrimRight(baseUrl, "/")}
+}
+
+func (localStorage *LocalStorage) Save(key string, reader io.Reader) (int64, error) {
+	filePath, err := localStorage.filePath(key)
+	if err != nil {
+		return 0, err
+	}
+	err = os.MkdirAll(filepath.Dir(filePath), 0o755)
+	if err != nil {
+		return 0, err
+	}
+
+	// write to a temporary file first so readers never see a half written object
+	temporaryFile, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(temporaryFile.Name())
+
+	written, err := io.Copy(temporaryFile, reader)
+	closeErr := temporaryFile.Close()
+	if err != nil {
+		return 0, err
+	}
+	if closeErr != nil {
+		return 0, closeErr
+	}
+
+	return written, os.Rename(temporaryFile.Name(), filePath)
+}
+
+func (localStorage *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	filePath, err := localStorage.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filePath)
+}
+
+func (localStorage *LocalStorage) Delete(key string) error {
+	filePath, err := localStorage.filePath(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (localStorage *LocalStorage) URL(key string) string {
+	return localStorage.baseUrl + "/" + key
+}
+
+func (localStorage *LocalStorage) filePath(key string) (string, error) {
+	cleanKey := path.Clean("/" + key)[1:]
+	if len(cleanKey) == 0 || cleanKey != key {
+		return "", errors.New(fmt.Sprintf("Invalid storage key %s", key))
+	}
+	return filepath.Join(localStorage.root, filepath.FromSlash(cleanKey)), nil
+}
@@ -0,0 +1,11 @@
+package storage
+
+import "io"
+
+// Storage keeps binary objects under slash separated keys.
+type Storage interface {
+	Save(key string, reader io.Reader) (int64, error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	URL(key string) string
+}
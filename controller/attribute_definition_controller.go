@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/request"
+	"go-product-app/controller/response"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service"
+	"net/http"
+	"strconv"
+)
+
+type AttributeDefinitionController struct {
+	attributeDefinitionService service.IAttributeDefinitionService
+}
+
+func NewAttributeDefinitionController(attributeDefinitionService service.IAttributeDefinitionService) *AttributeDefinitionController {
+	return &AttributeDefinitionController{
+		attributeDefinitionService: attributeDefinitionService,
+	}
+}
+
+func (attributeDefinitionController *AttributeDefinitionController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/attribute-definitions", attributeDefinitionController.GetAll)
+	e.POST("/api/v1/attribute-definitions", attributeDefinitionController.Add)
+	e.DELETE("/api/v1/attribute-definitions/:id", attributeDefinitionController.DeleteById)
+}
+
+func (attributeDefinitionController *AttributeDefinitionController) GetAll(c echo.Context) error {
+	category := c.QueryParam("category")
+	var err error
+	var attributeDefinitions []domain.AttributeDefinition
+	if len(category) > 0 {
+		attributeDefinitions, err = attributeDefinitionController.attributeDefinitionService.GetByCategory(category)
+	} else {
+		attributeDefinitions, err = attributeDefinitionController.attributeDefinitionService.GetAll()
+	}
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToAttributeDefinitionResponseList(attributeDefinitions))
+}
+
+func (attributeDefinitionController *AttributeDefinitionController) Add(c echo.Context) error {
+	var addAttributeDefinitionRequest request.AddAttributeDefinitionRequest
+	err := c.Bind(&addAttributeDefinitionRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	err = attributeDefinitionController.attributeDefinitionService.Add(addAttributeDefinitionRequest.ToModel())
+	var conflictError persistence.ConflictError
+	if errors.As(err, &conflictError) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+func (attributeDefinitionController *AttributeDefinitionController) DeleteById(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	err = attributeDefinitionController.attributeDefinitionService.DeleteById(id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
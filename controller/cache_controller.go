@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/response"
+	"go-product-app/persistence"
+	"net/http"
+)
+
+type CacheController struct {
+	productCache *persistence.CachingProductRepository
+}
+
+// NewCacheController takes a nil productCache when the product cache is turned off.
+func NewCacheController(productCache *persistence.CachingProductRepository) *CacheController {
+	return &CacheController{
+		productCache: productCache,
+	}
+}
+
+func (cacheController *CacheController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/cache/stats", cacheController.GetStats)
+}
+
+// GetStats reports the hits and misses of every cache since the instance started.
+func (cacheController *CacheController) GetStats(c echo.Context) error {
+	if cacheController.productCache == nil {
+		return c.JSON(http.StatusOK, response.ToCacheStatsResponse(false, nil))
+	}
+	return c.JSON(http.StatusOK, response.ToCacheStatsResponse(true, cacheController.productCache.Stats()))
+}
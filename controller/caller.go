@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-product-app/domain"
+	"strings"
+)
+
+// callerFromRequest reads the identity the api gateway forwards in X-User-Id and X-User-Role.
+// Callers without a known role are treated as viewers.
+func callerFromRequest(c echo.Context) domain.Caller {
+	header := c.Request().Header
+	role := strings.ToLower(strings.TrimSpace(header.Get("X-User-Role")))
+	switch role {
+	case domain.CallerRoleAdmin, domain.CallerRoleEditor, domain.CallerRoleViewer:
+	default:
+		role = domain.CallerRoleViewer
+	}
+	return domain.Caller{Id: strings.TrimSpace(header.Get("X-User-Id")), Role: role}
+}
+
+// initialStatus is the status a product added by the caller starts in, it tells false when the caller may not use
+// the requested status. Only admins and editors can add products that are published right away, the products of
+// viewers start as drafts.
+func initialStatus(caller domain.Caller, status string) (string, bool) {
+	if caller.CanEdit() {
+		return status, true
+	}
+	return domain.ProductStatusDraft, len(status) == 0 || status == domain.ProductStatusDraft
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/response"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service"
+	"net/http"
+	"strconv"
+)
+
+type JobController struct {
+	jobService service.IJobService
+}
+
+func NewJobController(jobService service.IJobService) *JobController {
+	return &JobController{
+		jobService: jobService,
+	}
+}
+
+func (jobController *JobController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/jobs/:id", jobController.GetById)
+	e.POST("/api/v1/jobs/:id/cancel", jobController.Cancel)
+}
+
+func (jobController *JobController) GetById(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	job, err := jobController.jobService.GetById(id)
+	var notFoundError persistence.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToJobResponse(job))
+}
+
+func (jobController *JobController) Cancel(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	job, err := jobController.jobService.Cancel(id)
+	var notFoundError persistence.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	var conflictError persistence.ConflictError
+	if errors.As(err, &conflictError) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusAccepted, response.ToJobResponse(job))
+}
+
+// jobAccepted answers a request that started a job with 202 and where to poll it.
+func jobAccepted(c echo.Context, job domain.Job) error {
+	c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/api/v1/jobs/%d", job.Id))
+	return c.JSON(http.StatusAccepted, response.ToJobResponse(job))
+}
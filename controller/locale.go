@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-product-app/common/localization"
+	"go-product-app/domain"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// requestedLocales returns the locales asked for by the `locale` query parameter, or by the
+// Accept-Language header ordered by quality when the parameter is absent.
+func requestedLocales(c echo.Context) []string {
+	if locale := localization.Normalize(c.QueryParam("locale")); len(locale) > 0 {
+		return []string{locale}
+	}
+	return parseAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+}
+
+func parseAcceptLanguage(header string) []string {
+	type weightedLocale struct {
+		locale  string
+		quality float64
+	}
+
+	weightedLocales := make([]weightedLocale, 0)
+	for _, part := range strings.Split(header, ",") {
+		locale, parameters, _ := strings.Cut(part, ";")
+		locale = localization.Normalize(locale)
+		if len(locale) == 0 || locale == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(parameters), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed <= 0 {
+				continue
+			}
+			quality = parsed
+		}
+		weightedLocales = append(weightedLocales, weightedLocale{locale: locale, quality: quality})
+	}
+	sort.SliceStable(weightedLocales, func(i, j int) bool {
+		return weightedLocales[i].quality > weightedLocales[j].quality
+	})
+
+	locales := make([]string, 0, len(weightedLocales))
+	for _, weighted := range weightedLocales {
+		locales = append(locales, weighted.locale)
+	}
+	return locales
+}
+
+// localizeProduct falls back from the requested locales to the store default and the global default locale.
+// The returned locale is the one the text is in.
+func localizeProduct(settings localization.Settings, locales []string, product domain.Product) (domain.Product, string) {
+	storeDefaultLocale := settings.DefaultLocaleFor(product.Store)
+	localizedProduct, locale := product.Localize(locales, storeDefaultLocale, settings.DefaultLocale)
+	if len(locale) == 0 {
+		// untranslated products are written in the store default locale
+		locale = storeDefaultLocale
+	}
+	return localizedProduct, locale
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/request"
+	"go-product-app/controller/response"
+	"go-product-app/service"
+	"net/http"
+	"strconv"
+)
+
+type PriceScheduleController struct {
+	priceScheduleService service.IPriceScheduleService
+}
+
+func NewPriceScheduleController(priceScheduleService service.IPriceScheduleService) *PriceScheduleController {
+	return &PriceScheduleController{
+		priceScheduleService: priceScheduleService,
+	}
+}
+
+func (priceScheduleController *PriceScheduleController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/price-schedules", priceScheduleController.GetPending)
+	e.DELETE("/api/v1/price-schedules/:id", priceScheduleController.Cancel)
+	e.GET("/api/v1/products/:id/price-schedules", priceScheduleController.GetPendingByProductId)
+	e.POST("/api/v1/products/:id/price-schedules", priceScheduleController.Add)
+}
+
+func (priceScheduleController *PriceScheduleController) GetPending(c echo.Context) error {
+	priceSchedules, err := priceScheduleController.priceScheduleService.GetPending()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToPriceScheduleResponseList(priceSchedules))
+}
+
+func (priceScheduleController *PriceScheduleController) GetPendingByProductId(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	priceSchedules, err := priceScheduleController.priceScheduleService.GetPendingByProductId(productId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToPriceScheduleResponseList(priceSchedules))
+}
+
+func (priceScheduleController *PriceScheduleController) Add(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	var addPriceScheduleRequest request.AddPriceScheduleRequest
+	err = c.Bind(&addPriceScheduleRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	err = priceScheduleController.priceScheduleService.Add(addPriceScheduleRequest.ToModel(productId))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+func (priceScheduleController *PriceScheduleController) Cancel(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	err = priceScheduleController.priceScheduleService.Cancel(id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
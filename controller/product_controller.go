@@ -1,49 +1,275 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go-product-app/common/localization"
+	"go-product-app/common/search"
 	"go-product-app/controller/request"
 	"go-product-app/controller/response"
 	"go-product-app/domain"
+	"go-product-app/persistence"
 	"go-product-app/service"
 	"net/http"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type ProductController struct {
-	productService service.IProductService
+	productService        service.IProductService
+	productJobService     service.IProductJobService
+	productVariantService service.IProductVariantService
+	productMediaService   service.IProductMediaService
+	localizationSettings  localization.Settings
 }
 
-func NewProductController(productService service.IProductService) *ProductController {
+func NewProductController(productService service.IProductService,
+	productJobService service.IProductJobService,
+	productVariantService service.IProductVariantService,
+	productMediaService service.IProductMediaService,
+	localizationSettings localization.Settings) *ProductController {
 	return &ProductController{
-		productService: productService,
+		productService:        productService,
+		productJobService:     productJobService,
+		productVariantService: productVariantService,
+		productMediaService:   productMediaService,
+		localizationSettings:  localizationSettings,
 	}
 }
 
 func (productController *ProductController) RegisterRoutes(e *echo.Echo) {
 	e.GET("/api/v1/products", productController.GetAll)
 	e.GET("/api/v1/products/:id", productController.GetById)
+	e.GET("/api/v1/products/by-sku/:sku", productController.GetBySku)
+	e.GET("/api/v1/products/deleted", productController.GetAllDeleted)
+	e.GET("/api/v1/products/search", productController.Search)
+	e.GET("/api/v1/products/search/instant", productController.SearchIndex)
+	e.GET("/api/v1/products/stats", productController.GetStats)
+	e.GET("/api/v1/products/export", productController.Export)
+	e.POST("/api/v1/products/:id/restore", productController.RestoreById)
 	e.POST("/api/v1/products", productController.Add)
+	e.POST("/api/v1/products\\:batch", productController.AddBatch)
+	e.POST("/api/v1/products\\:batchUpdate", productController.UpdatePriceBatch)
+	e.POST("/api/v1/products\\:batchDelete", productController.DeleteBatch)
 	e.PUT("/api/v1/products/:id", productController.UpdatePrice)
+	e.PUT("/api/v1/products/:id/translations", productController.UpdateTranslations)
+	e.POST("/api/v1/products/:id/status", productController.ChangeStatus)
+	e.GET("/api/v1/products/:id/status-history", productController.GetStatusHistory)
+	e.GET("/api/v1/products/:id/price-history", productController.GetPriceHistory)
 	e.DELETE("/api/v1/products/:id", productController.DeleteById)
 }
 
+// GetAll lists the products matching the listing filters. With updated_since the products that were deleted or moved
+// out of the requested statuses since then are listed as well, marked as removed, so incremental pulls can drop them.
 func (productController *ProductController) GetAll(c echo.Context) error {
-	store := c.QueryParam("store")
-	var err error
-	var products []domain.Product
-	if len(store) > 0 {
-		products, err = productController.productService.GetAllByStore(store)
-	} else {
-		products, err = productController.productService.GetAll()
+	filter, err := productFilterFromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
 	}
 
+	filter.Statuses = visibleStatuses(callerFromRequest(c), filter.Statuses)
+	if len(filter.Statuses) == 0 {
+		return c.JSON(http.StatusOK, response.ToProductResponseList(nil))
+	}
+	filter.IncludeRemoved = filter.UpdatedSince != nil
+
+	products, err := productController.productService.GetAllByFilter(filter)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, response.ErrorResponse{
 			Description: err.Error(),
 		})
 	}
-	return c.JSON(http.StatusOK, response.ToProductResponseList(products))
+	var removedProducts []domain.Product
+	products = slices.DeleteFunc(products, func(product domain.Product) bool {
+		removed := product.DeletedAt != nil || !slices.Contains(filter.Statuses, product.Status)
+		if removed {
+			removedProducts = append(removedProducts, product)
+		}
+		return removed
+	})
+
+	productIds := make([]int64, 0, len(products))
+	for _, product := range products {
+		productIds = append(productIds, product.Id)
+	}
+	mediaByProductId, err := productController.productMediaService.GetByProductIds(productIds)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	locales := requestedLocales(c)
+	productLocales := make([]string, len(products))
+	for i, product := range products {
+		products[i], productLocales[i] = localizeProduct(productController.localizationSettings, locales, product)
+	}
+	productResponseList := response.ToProductResponseListWithMedia(products, mediaByProductId)
+	for i := range productResponseList {
+		productResponseList[i].Locale = productLocales[i]
+	}
+	for _, product := range removedProducts {
+		productResponseList = append(productResponseList, response.ToRemovedProductResponse(product))
+	}
+	return c.JSON(http.StatusOK, productResponseList)
+}
+
+// Search takes q=<text> with the listing filters, lang=<en|tr> and limit/offset pagination.
+func (productController *ProductController) Search(c echo.Context) error {
+	filter, err := productFilterFromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	limit, offset, err := paginationFromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	filter.Statuses = visibleStatuses(callerFromRequest(c), filter.Statuses)
+	if len(filter.Statuses) == 0 {
+		return c.JSON(http.StatusOK, response.ToProductSearchResponse(domain.ProductSearchResult{Limit: limit, Offset: offset}))
+	}
+
+	query := domain.ProductSearchQuery{Text: c.QueryParam("q"), Language: c.QueryParam("lang"), Filter: filter, Limit: limit, Offset: offset}
+	result, err := productController.productService.Search(query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToProductSearchResponse(result))
+}
+
+// SearchIndex serves search as you type from the in-process index: q=<word prefixes>, store, status, price=<bucket>
+// and limit/offset pagination. Facet counts for stores and price buckets come with every response.
+func (productController *ProductController) SearchIndex(c echo.Context) error {
+	filter, err := productFilterFromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	limit, offset, err := paginationFromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	filter.Statuses = visibleStatuses(callerFromRequest(c), filter.Statuses)
+	if len(filter.Statuses) == 0 {
+		return c.JSON(http.StatusOK, response.ToIndexSearchResponse(search.Result{}))
+	}
+
+	result := productController.productService.SearchIndex(search.Query{
+		Text:        c.QueryParam("q"),
+		Store:       filter.Store,
+		Statuses:    filter.Statuses,
+		PriceBucket: c.QueryParam("price"),
+		Limit:       limit,
+		Offset:      max(offset, 0),
+	})
+	return c.JSON(http.StatusOK, response.ToIndexSearchResponse(result))
+}
+
+// GetStats takes group_by=<store,category,discount_band> with the listing filters. Responses carry an ETag
+// and may be cached briefly, a matching If-None-Match is answered with 304.
+func (productController *ProductController) GetStats(c echo.Context) error {
+	filter, err := productFilterFromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	var groupBy []string
+	if groupByParam := c.QueryParam("group_by"); len(groupByParam) > 0 {
+		for _, dimension := range strings.Split(groupByParam, ",") {
+			groupBy = append(groupBy, strings.ToLower(strings.TrimSpace(dimension)))
+		}
+	}
+
+	filter.Statuses = visibleStatuses(callerFromRequest(c), filter.Statuses)
+	if len(filter.Statuses) == 0 {
+		return respondCacheable(c, response.ToProductStatsResponse(groupBy, nil), statsMaxAge)
+	}
+	groups, err := productController.productService.GetStats(domain.ProductStatsQuery{GroupBy: groupBy, Filter: filter})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return respondCacheable(c, response.ToProductStatsResponse(groupBy, groups), statsMaxAge)
+}
+
+// Export streams the products matching the listing filters as they are read, with chunked encoding.
+// It takes format=<csv|ndjson|xlsx> (csv by default) and fields=<comma separated fields>.
+// With async=true a job writes the file instead, the response is 202 with the job whose result has the file url.
+func (productController *ProductController) Export(c echo.Context) error {
+	filter, err := productFilterFromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	query := domain.ProductExportQuery{Format: strings.ToLower(c.QueryParam("format")), Filter: filter}
+	if len(query.Format) == 0 {
+		query.Format = domain.ExportFormatCsv
+	}
+	if fieldsParam := c.QueryParam("fields"); len(fieldsParam) > 0 {
+		for _, field := range strings.Split(fieldsParam, ",") {
+			query.Fields = append(query.Fields, strings.ToLower(strings.TrimSpace(field)))
+		}
+	}
+	if !domain.IsValidExportFormat(query.Format) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: fmt.Sprintf("Export format %s is not supported", query.Format),
+		})
+	}
+	if err = domain.ValidateExportFields(query.Fields); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	query.Filter.Statuses = visibleStatuses(callerFromRequest(c), query.Filter.Statuses)
+	if len(query.Filter.Statuses) == 0 {
+		// no status filter would export every status, so filter on one no product has and send just the header
+		query.Filter.Statuses = []string{""}
+	}
+
+	if c.QueryParam("async") == "true" {
+		job, err := productController.productJobService.StartExport(query, callerFromRequest(c).Id)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return jobAccepted(c, job)
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, exportContentTypes[query.Format])
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="products.%s"`, query.Format))
+	c.Response().WriteHeader(http.StatusOK)
+
+	err = productController.productService.Export(query, c.Response())
+	if err != nil {
+		// the status is already sent, the client sees a truncated file
+		log.Errorf("Error while exporting products: %v", err)
+	}
+	return nil
 }
 
 func (productController *ProductController) GetById(c echo.Context) error {
@@ -60,12 +286,60 @@ func (productController *ProductController) GetById(c echo.Context) error {
 		})
 	}
 	product, err := productController.productService.GetById(id)
+	if err == nil && !callerFromRequest(c).CanSeeStatus(product.Status) {
+		err = errors.New(fmt.Sprintf("Product with id %d not found", id))
+	}
 	if err != nil {
 		return c.JSON(http.StatusNotFound, response.ErrorResponse{
 			Description: err.Error(),
 		})
 	}
-	return c.JSON(http.StatusOK, response.ToProductResponse(product))
+	productMediaList, err := productController.productMediaService.GetByProductId(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	product, locale := localizeProduct(productController.localizationSettings, requestedLocales(c), product)
+	c.Response().Header().Set("Content-Language", locale)
+	productResponse := response.ToProductResponseWithMedia(product, productMediaList)
+	productResponse.Locale = locale
+	if len(product.Options) == 0 {
+		return c.JSON(http.StatusOK, productResponse)
+	}
+
+	productVariants, err := productController.productVariantService.GetByProductId(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	productResponse.Variants = response.ToProductVariantResponseList(product, productVariants)
+	return c.JSON(http.StatusOK, productResponse)
+}
+
+func (productController *ProductController) GetBySku(c echo.Context) error {
+	sku := c.Param("sku")
+	product, err := productController.productService.GetBySku(sku, c.QueryParam("store"))
+	if err == nil && !callerFromRequest(c).CanSeeStatus(product.Status) {
+		err = persistence.NotFoundError{Message: fmt.Sprintf("Product with sku %s not found", sku)}
+	}
+	var notFoundError persistence.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	product, locale := localizeProduct(productController.localizationSettings, requestedLocales(c), product)
+	c.Response().Header().Set("Content-Language", locale)
+	productResponse := response.ToProductResponse(product)
+	productResponse.Locale = locale
+	return c.JSON(http.StatusOK, productResponse)
 }
 
 func (productController *ProductController) Add(c echo.Context) error {
@@ -77,8 +351,22 @@ func (productController *ProductController) Add(c echo.Context) error {
 		})
 	}
 
-	product := addProductRequest.ToModel()
+	caller := callerFromRequest(c)
+	product := addProductRequest.ToModel(caller.Id)
+	status, allowed := initialStatus(caller, product.Status)
+	if !allowed {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can add products that are not drafts",
+		})
+	}
+	product.Status = status
 	err = productController.productService.Add(product)
+	var conflictError persistence.ConflictError
+	if errors.As(err, &conflictError) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
 			Description: err.Error(),
@@ -87,6 +375,69 @@ func (productController *ProductController) Add(c echo.Context) error {
 	return c.NoContent(http.StatusCreated)
 }
 
+// AddBatch takes {"mode": "atomic|best_effort", "items": [...]} and reports the outcome of every item.
+// The mode defaults to atomic, where a single failed item leaves every product unwritten.
+func (productController *ProductController) AddBatch(c echo.Context) error {
+	var batchAddProductRequest request.BatchAddProductRequest
+	err := c.Bind(&batchAddProductRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	caller := callerFromRequest(c)
+	products := batchAddProductRequest.ToModel(caller.Id)
+	for i := range products {
+		status, allowed := initialStatus(caller, products[i].Status)
+		if !allowed {
+			return c.JSON(http.StatusForbidden, response.ErrorResponse{
+				Description: "Only admins and editors can add products that are not drafts",
+			})
+		}
+		products[i].Status = status
+	}
+
+	mode := batchMode(batchAddProductRequest.Mode)
+	results, err := productController.productService.AddBatch(products, mode)
+	return batchResponse(c, mode, results, err)
+}
+
+// UpdatePriceBatch takes {"mode": "atomic|best_effort", "items": [{"id": 1, "price": 100}]}.
+func (productController *ProductController) UpdatePriceBatch(c echo.Context) error {
+	var batchUpdatePriceRequest request.BatchUpdatePriceRequest
+	err := c.Bind(&batchUpdatePriceRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	mode := batchMode(batchUpdatePriceRequest.Mode)
+	results, err := productController.productService.UpdatePriceBatch(batchUpdatePriceRequest.ToPriceUpdates(), mode, callerFromRequest(c).Id)
+	return batchResponse(c, mode, results, err)
+}
+
+// DeleteBatch takes {"mode": "atomic|best_effort", "ids": [1, 2]}.
+func (productController *ProductController) DeleteBatch(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can delete products",
+		})
+	}
+	var batchDeleteRequest request.BatchDeleteRequest
+	err := c.Bind(&batchDeleteRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	mode := batchMode(batchDeleteRequest.Mode)
+	results, err := productController.productService.DeleteBatch(batchDeleteRequest.Ids, mode, callerFromRequest(c).Id)
+	return batchResponse(c, mode, results, err)
+}
+
 func (productController *ProductController) UpdatePrice(c echo.Context) error {
 	id := c.Param("id")
 	price := c.QueryParam("price")
@@ -107,16 +458,162 @@ func (productController *ProductController) UpdatePrice(c echo.Context) error {
 			Description: err.Error(),
 		})
 	}
-	err = productController.productService.UpdatePrice(idInt, float32(priceFloat))
+	err = productController.productService.UpdatePrice(idInt, float32(priceFloat), callerFromRequest(c).Id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (productController *ProductController) UpdateTranslations(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	var updateTranslationsRequest request.UpdateTranslationsRequest
+	err = c.Bind(&updateTranslationsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	err = productController.productService.UpdateTranslations(id, request.ToLocalizedTexts(updateTranslationsRequest), callerFromRequest(c).Id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// ChangeStatus moves a product through its lifecycle, only admins and editors can publish or retire products.
+func (productController *ProductController) ChangeStatus(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can change the status of products",
+		})
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	var changeProductStatusRequest request.ChangeProductStatusRequest
+	err = c.Bind(&changeProductStatusRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	err = productController.productService.ChangeStatus(changeProductStatusRequest.ToModel(id, callerFromRequest(c).Id))
+	var conflictError persistence.ConflictError
+	if errors.As(err, &conflictError) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (productController *ProductController) GetStatusHistory(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	statusChanges, err := productController.productService.GetStatusHistory(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToProductStatusChangeResponseList(statusChanges))
+}
+
+func (productController *ProductController) GetPriceHistory(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	priceChanges, err := productController.productService.GetPriceHistory(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToPriceChangeResponseList(priceChanges))
+}
+
+// GetAllDeleted lists soft deleted products that are not purged yet, it is only available to admins.
+func (productController *ProductController) GetAllDeleted(c echo.Context) error {
+	if callerFromRequest(c).Role != domain.CallerRoleAdmin {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins can list deleted products",
+		})
+	}
+	products, err := productController.productService.GetAllDeleted()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToProductResponseList(products))
+}
+
+// RestoreById brings back a soft deleted product, like listing the deleted products it is only available to admins.
+func (productController *ProductController) RestoreById(c echo.Context) error {
+	if callerFromRequest(c).Role != domain.CallerRoleAdmin {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins can restore deleted products",
+		})
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
 			Description: err.Error(),
 		})
 	}
+	err = productController.productService.RestoreById(id, callerFromRequest(c).Id)
+	var notFoundError persistence.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	var conflictError persistence.ConflictError
+	if errors.As(err, &conflictError) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
 	return c.NoContent(http.StatusOK)
 }
 
 func (productController *ProductController) DeleteById(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can delete products",
+		})
+	}
 	id := c.Param("id")
 	if len(id) == 0 {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
@@ -129,7 +626,7 @@ func (productController *ProductController) DeleteById(c echo.Context) error {
 			Description: err.Error(),
 		})
 	}
-	err = productController.productService.DeleteById(idInt)
+	err = productController.productService.DeleteById(idInt, callerFromRequest(c).Id)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
 			Description: err.Error(),
@@ -138,3 +635,138 @@ func (productController *ProductController) DeleteById(c echo.Context) error {
 	return c.NoContent(http.StatusOK)
 
 }
+
+var attributeFilterPattern = regexp.MustCompile(`^attr\.([a-z][a-z0-9_]*)(?:\[([a-z]+)\])?$`)
+
+// productFilterFromQuery reads the listing filters: store=<name>, category=<name>, min_price=<price>, max_price=<price>,
+// status=<status>[,<status>] defaulting to active, updated_since=<RFC 3339 time, see domain.ProductFilter>, variant.<option>=<value> and attr.<name>[<operator>]=<value> where operator
+// is one of eq, gt, gte, lt, lte and defaults to eq. Attribute values are read as the type of their attribute definition.
+func productFilterFromQuery(c echo.Context) (domain.ProductFilter, error) {
+	filter := domain.ProductFilter{Store: c.QueryParam("store"), Category: c.QueryParam("category"), Statuses: []string{domain.ProductStatusActive}}
+	var err error
+	if filter.MinPrice, err = priceQueryParam(c, "min_price"); err != nil {
+		return domain.ProductFilter{}, err
+	}
+	if filter.MaxPrice, err = priceQueryParam(c, "max_price"); err != nil {
+		return domain.ProductFilter{}, err
+	}
+	if statusParam := c.QueryParam("status"); len(statusParam) > 0 {
+		filter.Statuses = strings.Split(statusParam, ",")
+		for _, status := range filter.Statuses {
+			if !domain.IsValidProductStatus(status) {
+				return domain.ProductFilter{}, errors.New(fmt.Sprintf("Status %s is not valid", status))
+			}
+		}
+	}
+	if updatedSinceParam := c.QueryParam("updated_since"); len(updatedSinceParam) > 0 {
+		updatedSince, err := time.Parse(time.RFC3339, updatedSinceParam)
+		if err != nil {
+			return domain.ProductFilter{}, errors.New(fmt.Sprintf("Updated since %s should be an RFC 3339 time", updatedSinceParam))
+		}
+		filter.UpdatedSince = &updatedSince
+	}
+	for name, values := range c.QueryParams() {
+		if len(values) == 0 {
+			continue
+		}
+		if option, found := strings.CutPrefix(name, "variant."); found && len(option) > 0 {
+			if filter.VariantAttributes == nil {
+				filter.VariantAttributes = make(map[string]string)
+			}
+			filter.VariantAttributes[option] = values[0]
+			continue
+		}
+		if strings.HasPrefix(name, "attr.") {
+			match := attributeFilterPattern.FindStringSubmatch(name)
+			if match == nil {
+				return domain.ProductFilter{}, errors.New(fmt.Sprintf("Invalid attribute filter %s", name))
+			}
+			operator := match[2]
+			if len(operator) == 0 {
+				operator = domain.FilterOperatorEq
+			}
+			switch operator {
+			case domain.FilterOperatorEq, domain.FilterOperatorGt, domain.FilterOperatorGte, domain.FilterOperatorLt, domain.FilterOperatorLte:
+			default:
+				return domain.ProductFilter{}, errors.New(fmt.Sprintf("Unknown attribute operator %s", operator))
+			}
+			filter.AttributeConditions = append(filter.AttributeConditions, domain.AttributeCondition{Name: match[1], Operator: operator, Value: values[0]})
+		}
+	}
+	return filter, nil
+}
+
+func priceQueryParam(c echo.Context, name string) (*float32, error) {
+	priceParam := c.QueryParam(name)
+	if len(priceParam) == 0 {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(priceParam, 32)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Price %s should be a number", priceParam))
+	}
+	value := float32(price)
+	return &value, nil
+}
+
+var exportContentTypes = map[string]string{
+	domain.ExportFormatCsv:    "text/csv; charset=utf-8",
+	domain.ExportFormatNdjson: "application/x-ndjson",
+	domain.ExportFormatXlsx:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+func batchMode(mode string) string {
+	if len(mode) == 0 {
+		return domain.BatchModeAtomic
+	}
+	return strings.ToLower(mode)
+}
+
+// batchResponse answers 200 when every item succeeded, 207 when a best effort batch was partly applied
+// and 422 when nothing was written.
+func batchResponse(c echo.Context, mode string, results []domain.BatchItemResult, err error) error {
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	batchResponse := response.ToBatchResponse(mode, results)
+	switch {
+	case batchResponse.Failed == 0:
+		return c.JSON(http.StatusOK, batchResponse)
+	case batchResponse.Succeeded > 0:
+		return c.JSON(http.StatusMultiStatus, batchResponse)
+	default:
+		return c.JSON(http.StatusUnprocessableEntity, batchResponse)
+	}
+}
+
+// visibleStatuses drops the statuses the caller is not allowed to see.
+func visibleStatuses(caller domain.Caller, statuses []string) []string {
+	visible := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		if caller.CanSeeStatus(status) {
+			visible = append(visible, status)
+		}
+	}
+	return visible
+}
+
+func paginationFromQuery(c echo.Context) (int, int, error) {
+	var limit, offset int
+	var err error
+	if limitParam := c.QueryParam("limit"); len(limitParam) > 0 {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			return 0, 0, errors.New(fmt.Sprintf("Limit %s should be a number", limitParam))
+		}
+	}
+	if offsetParam := c.QueryParam("offset"); len(offsetParam) > 0 {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil {
+			return 0, 0, errors.New(fmt.Sprintf("Offset %s should be a number", offsetParam))
+		}
+	}
+	return limit, offset, nil
+}
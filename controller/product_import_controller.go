@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/response"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const importColumnParamPrefix = "column."
+
+type ProductImportController struct {
+	productImporter   service.IProductImporter
+	productJobService service.IProductJobService
+}
+
+func NewProductImportController(productImporter service.IProductImporter, productJobService service.IProductJobService) *ProductImportController {
+	return &ProductImportController{
+		productImporter:   productImporter,
+		productJobService: productJobService,
+	}
+}
+
+func (productImportController *ProductImportController) RegisterRoutes(e *echo.Echo) {
+	e.POST("/api/v1/products/imports", productImportController.Import)
+}
+
+// Import reads the request body as the file itself, it is not buffered so files of any size can be sent.
+// It takes format=<csv|ndjson> (or the matching Content-Type), dry_run=<bool>, upsert_by=<sku|id>
+// and column.<field>=<column name> to map the columns of the file to product fields.
+// With async=true the file is kept and imported by a job, the response is 202 with the job to poll.
+// The products a viewer imports are created as drafts, rows with another status fail.
+func (productImportController *ProductImportController) Import(c echo.Context) error {
+	format := importFormat(c)
+	if len(format) == 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: "Format parameter should be csv or ndjson",
+		})
+	}
+	dryRun := false
+	if dryRunParam := c.QueryParam("dry_run"); len(dryRunParam) > 0 {
+		var err error
+		dryRun, err = strconv.ParseBool(dryRunParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+	}
+	upsertBy := c.QueryParam("upsert_by")
+	if len(upsertBy) == 0 {
+		upsertBy = domain.ImportUpsertBySku
+	}
+	mapping := make(map[string]string)
+	for key, values := range c.QueryParams() {
+		if field, found := strings.CutPrefix(key, importColumnParamPrefix); found && len(values) > 0 {
+			mapping[field] = values[0]
+		}
+	}
+
+	caller := callerFromRequest(c)
+	options := model.ImportProducts{
+		Format:     format,
+		DryRun:     dryRun,
+		UpsertBy:   upsertBy,
+		Mapping:    mapping,
+		DraftsOnly: !caller.CanEdit(),
+		Actor:      caller.Id,
+	}
+	if c.QueryParam("async") == "true" {
+		job, err := productImportController.productJobService.StartImport(c.Request().Body, options)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return jobAccepted(c, job)
+	}
+
+	report, err := productImportController.productImporter.Import(c.Request().Body, options)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToProductImportReportResponse(report))
+}
+
+func importFormat(c echo.Context) string {
+	format := strings.ToLower(c.QueryParam("format"))
+	if len(format) == 0 {
+		mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+		switch mediaType {
+		case "text/csv":
+			format = domain.ImportFormatCsv
+		case "application/x-ndjson", "application/ndjson":
+			format = domain.ImportFormatNdjson
+		}
+	}
+	if format != domain.ImportFormatCsv && format != domain.ImportFormatNdjson {
+		return ""
+	}
+	return format
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/request"
+	"go-product-app/controller/response"
+	"go-product-app/persistence"
+	"go-product-app/service"
+	"net/http"
+	"strconv"
+)
+
+type ProductMediaController struct {
+	productMediaService service.IProductMediaService
+}
+
+func NewProductMediaController(productMediaService service.IProductMediaService) *ProductMediaController {
+	return &ProductMediaController{
+		productMediaService: productMediaService,
+	}
+}
+
+func (productMediaController *ProductMediaController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/products/:id/media", productMediaController.GetByProductId)
+	e.POST("/api/v1/products/:id/media", productMediaController.Upload)
+	e.PUT("/api/v1/products/:id/media/order", productMediaController.Reorder)
+	e.PUT("/api/v1/products/:id/media/:mediaId/primary", productMediaController.SetPrimary)
+	e.DELETE("/api/v1/products/:id/media/:mediaId", productMediaController.Delete)
+}
+
+func (productMediaController *ProductMediaController) GetByProductId(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	productMediaList, err := productMediaController.productMediaService.GetByProductId(productId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToProductMediaResponseList(productMediaList))
+}
+
+func (productMediaController *ProductMediaController) Upload(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: "Multipart file field named file is required",
+		})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	defer file.Close()
+
+	productMedia, err := productMediaController.productMediaService.Upload(productId, file)
+	var conflictError persistence.ConflictError
+	if errors.As(err, &conflictError) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, response.ToProductMediaResponse(productMedia))
+}
+
+func (productMediaController *ProductMediaController) Reorder(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	var reorderProductMediaRequest request.ReorderProductMediaRequest
+	err = c.Bind(&reorderProductMediaRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	err = productMediaController.productMediaService.Reorder(productId, reorderProductMediaRequest.MediaIds)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (productMediaController *ProductMediaController) SetPrimary(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	mediaId, err := strconv.ParseInt(c.Param("mediaId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	err = productMediaController.productMediaService.SetPrimary(productId, mediaId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (productMediaController *ProductMediaController) Delete(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	mediaId, err := strconv.ParseInt(c.Param("mediaId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	err = productMediaController.productMediaService.Delete(productId, mediaId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go-product-app/common/events"
+	"go-product-app/controller/response"
+	"go-product-app/domain"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// streamRetry is how long browsers wait before reconnecting a dropped stream.
+const streamRetry = 3 * time.Second
+
+// ProductStreamController pushes product events to clients as server-sent events.
+type ProductStreamController struct {
+	broadcaster *events.Broadcaster
+	heartbeat   time.Duration
+}
+
+func NewProductStreamController(broadcaster *events.Broadcaster, heartbeat time.Duration) *ProductStreamController {
+	return &ProductStreamController{
+		broadcaster: broadcaster,
+		heartbeat:   heartbeat,
+	}
+}
+
+func (productStreamController *ProductStreamController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/products/stream", productStreamController.Stream)
+}
+
+// Stream sends product.created, product.updated, product.price_changed and product.deleted events, of one store with
+// store=<store>.
+// The id of every event can be sent back in Last-Event-ID, or last_event_id, to resume after the events already seen.
+// A reset event tells the client that events were missed and it should reload the products.
+// Clients that fall behind are disconnected and resume when they reconnect. Viewers do not get the events of drafts.
+func (productStreamController *ProductStreamController) Stream(c echo.Context) error {
+	lastEventId, err := lastEventIdFromRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	caller := callerFromRequest(c)
+	subscription, missed, resumed := productStreamController.broadcaster.Subscribe(c.QueryParam("store"), lastEventId)
+	defer productStreamController.broadcaster.Unsubscribe(subscription)
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	if err == nil && !resumed {
+		_, err = fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range missed {
+		if err != nil {
+			break
+		}
+		if caller.CanSeeStatus(event.Status) {
+			err = writeStreamEvent(w, event)
+		}
+	}
+	if err != nil {
+		return nil
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(productStreamController.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case event, open := <-subscription.Events():
+			if !open {
+				if subscription.Dropped() {
+					log.Infof("Product stream client %s fell behind and was disconnected", c.RealIP())
+				}
+				return nil
+			}
+			if !caller.CanSeeStatus(event.Status) {
+				continue
+			}
+			err = writeStreamEvent(w, event)
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		if err != nil {
+			return nil
+		}
+		w.Flush()
+	}
+}
+
+func writeStreamEvent(w *echo.Response, event domain.ProductEvent) error {
+	data, err := json.Marshal(response.ToProductEventResponse(event))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+	return err
+}
+
+func lastEventIdFromRequest(c echo.Context) (int64, error) {
+	lastEventId := c.Request().Header.Get("Last-Event-ID")
+	if len(lastEventId) == 0 {
+		lastEventId = c.QueryParam("last_event_id")
+	}
+	if len(lastEventId) == 0 {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(lastEventId, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New(fmt.Sprintf("Last event id %s should be a positive number", lastEventId))
+	}
+	return id, nil
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/request"
+	"go-product-app/controller/response"
+	"go-product-app/persistence"
+	"go-product-app/service"
+	"net/http"
+	"strconv"
+)
+
+type ProductVariantController struct {
+	productVariantService service.IProductVariantService
+	productService        service.IProductService
+}
+
+func NewProductVariantController(productVariantService service.IProductVariantService, productService service.IProductService) *ProductVariantController {
+	return &ProductVariantController{
+		productVariantService: productVariantService,
+		productService:        productService,
+	}
+}
+
+func (productVariantController *ProductVariantController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/products/:id/variants", productVariantController.GetByProductId)
+	e.POST("/api/v1/products/:id/variants", productVariantController.Add)
+}
+
+func (productVariantController *ProductVariantController) GetByProductId(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	product, err := productVariantController.productService.GetById(productId)
+	if err == nil && !callerFromRequest(c).CanSeeStatus(product.Status) {
+		err = errors.New(fmt.Sprintf("Product with id %d not found", productId))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	productVariants, err := productVariantController.productVariantService.GetByProductId(productId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToProductVariantResponseList(product, productVariants))
+}
+
+func (productVariantController *ProductVariantController) Add(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	var addProductVariantRequest request.AddProductVariantRequest
+	err = c.Bind(&addProductVariantRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	err = productVariantController.productVariantService.Add(addProductVariantRequest.ToModel(productId))
+	var conflictError persistence.ConflictError
+	if errors.As(err, &conflictError) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusCreated)
+}
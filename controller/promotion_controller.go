@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/request"
+	"go-product-app/controller/response"
+	"go-product-app/service"
+	"net/http"
+	"strconv"
+)
+
+type PromotionController struct {
+	promotionService service.IPromotionService
+}
+
+func NewPromotionController(promotionService service.IPromotionService) *PromotionController {
+	return &PromotionController{
+		promotionService: promotionService,
+	}
+}
+
+func (promotionController *PromotionController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/promotions", promotionController.GetAll)
+	e.POST("/api/v1/promotions", promotionController.Add)
+	e.DELETE("/api/v1/promotions/:id", promotionController.DeleteById)
+	e.POST("/api/v1/promotions/evaluate", promotionController.Evaluate)
+}
+
+func (promotionController *PromotionController) GetAll(c echo.Context) error {
+	promotions, err := promotionController.promotionService.GetAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToPromotionResponseList(promotions))
+}
+
+func (promotionController *PromotionController) Add(c echo.Context) error {
+	var addPromotionRequest request.AddPromotionRequest
+	err := c.Bind(&addPromotionRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	err = promotionController.promotionService.Add(addPromotionRequest.ToModel())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+func (promotionController *PromotionController) DeleteById(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	err = promotionController.promotionService.DeleteById(id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (promotionController *PromotionController) Evaluate(c echo.Context) error {
+	var evaluatePromotionsRequest request.EvaluatePromotionsRequest
+	err := c.Bind(&evaluatePromotionsRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	evaluation, err := promotionController.promotionService.Evaluate(evaluatePromotionsRequest.ToModel(), callerFromRequest(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToPromotionEvaluationResponse(evaluation))
+}
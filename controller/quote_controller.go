@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/request"
+	"go-product-app/controller/response"
+	"go-product-app/service"
+	"net/http"
+)
+
+type QuoteController struct {
+	quoteService service.IQuoteService
+}
+
+func NewQuoteController(quoteService service.IQuoteService) *QuoteController {
+	return &QuoteController{
+		quoteService: quoteService,
+	}
+}
+
+func (quoteController *QuoteController) RegisterRoutes(e *echo.Echo) {
+	e.POST("/api/v1/quotes", quoteController.Create)
+}
+
+func (quoteController *QuoteController) Create(c echo.Context) error {
+	var createQuoteRequest request.CreateQuoteRequest
+	err := c.Bind(&createQuoteRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+
+	quote, err := quoteController.quoteService.Quote(createQuoteRequest.ToModel(), callerFromRequest(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToQuoteResponse(quote))
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/request"
+	"go-product-app/controller/response"
+	"go-product-app/persistence"
+	"go-product-app/service"
+	"net/http"
+	"strconv"
+)
+
+type RepricingController struct {
+	productService service.IProductService
+}
+
+func NewRepricingController(productService service.IProductService) *RepricingController {
+	return &RepricingController{
+		productService: productService,
+	}
+}
+
+func (repricingController *RepricingController) RegisterRoutes(e *echo.Echo) {
+	e.POST("/api/v1/repricings/preview", repricingController.Preview)
+	e.POST("/api/v1/repricings", repricingController.Reprice)
+	e.GET("/api/v1/repricings/:id", repricingController.GetById)
+	e.POST("/api/v1/repricings/:id/undo", repricingController.Undo)
+}
+
+func (repricingController *RepricingController) Preview(c echo.Context) error {
+	var repriceProductsRequest request.RepriceProductsRequest
+	if err := c.Bind(&repriceProductsRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	preview, err := repricingController.productService.PreviewRepricing(repriceProductsRequest.ToModel(callerFromRequest(c).Id))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToRepricingPreviewResponse(preview))
+}
+
+// Reprice changes every matching product at once, either all of them are repriced or none is.
+func (repricingController *RepricingController) Reprice(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can reprice products",
+		})
+	}
+	var repriceProductsRequest request.RepriceProductsRequest
+	if err := c.Bind(&repriceProductsRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	repricing, err := repricingController.productService.Reprice(repriceProductsRequest.ToModel(callerFromRequest(c).Id))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, response.ToRepricingResponse(repricing))
+}
+
+func (repricingController *RepricingController) GetById(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	repricing, err := repricingController.productService.GetRepricingById(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToRepricingResponse(repricing))
+}
+
+// Undo restores the previous prices, products changed again since the repricing are skipped and counted.
+func (repricingController *RepricingController) Undo(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can undo repricings",
+		})
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	repricing, err := repricingController.productService.UndoRepricing(id, callerFromRequest(c).Id)
+	var notFoundError persistence.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	var conflictError persistence.ConflictError
+	if errors.As(err, &conflictError) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToRepricingResponse(repricing))
+}
@@ -1,19 +1,299 @@
 package request
 
-import "go-product-app/service/model"
+import (
+	"go-product-app/domain"
+	"go-product-app/service/model"
+	"time"
+)
 
 type AddProductRequest struct {
-	Name     string  `json:"name"`
-	Price    float32 `json:"price"`
-	Discount float32 `json:"discount"`
-	Store    string  `json:"store"`
+	Name         string                          `json:"name"`
+	Description  string                          `json:"description"`
+	Price        float32                         `json:"price"`
+	Discount     float32                         `json:"discount"`
+	Store        string                          `json:"store"`
+	Category     string                          `json:"category"`
+	Status       string                          `json:"status"`
+	Sku          string                          `json:"sku"`
+	Gtin         string                          `json:"gtin"`
+	Options      []string                        `json:"options"`
+	Attributes   map[string]interface{}          `json:"attributes"`
+	Translations map[string]LocalizedTextRequest `json:"translations"`
 }
 
-func (addProductRequest AddProductRequest) ToModel() model.CreateProduct {
+func (addProductRequest AddProductRequest) ToModel(actor string) model.CreateProduct {
 	return model.CreateProduct{
-		Name:     addProductRequest.Name,
-		Price:    addProductRequest.Price,
-		Discount: addProductRequest.Discount,
-		Store:    addProductRequest.Store,
+		Name:         addProductRequest.Name,
+		Description:  addProductRequest.Description,
+		Price:        addProductRequest.Price,
+		Discount:     addProductRequest.Discount,
+		Store:        addProductRequest.Store,
+		Category:     addProductRequest.Category,
+		Status:       addProductRequest.Status,
+		Sku:          addProductRequest.Sku,
+		Gtin:         addProductRequest.Gtin,
+		Options:      addProductRequest.Options,
+		Attributes:   addProductRequest.Attributes,
+		Translations: ToLocalizedTexts(addProductRequest.Translations),
+		Actor:        actor,
+	}
+}
+
+type BatchAddProductRequest struct {
+	Mode  string              `json:"mode"`
+	Items []AddProductRequest `json:"items"`
+}
+
+func (batchAddProductRequest BatchAddProductRequest) ToModel(actor string) []model.CreateProduct {
+	products := make([]model.CreateProduct, 0, len(batchAddProductRequest.Items))
+	for _, item := range batchAddProductRequest.Items {
+		products = append(products, item.ToModel(actor))
+	}
+	return products
+}
+
+type BatchUpdatePriceRequest struct {
+	Mode  string                   `json:"mode"`
+	Items []UpdatePriceItemRequest `json:"items"`
+}
+
+type UpdatePriceItemRequest struct {
+	Id    int64   `json:"id"`
+	Price float32 `json:"price"`
+}
+
+func (batchUpdatePriceRequest BatchUpdatePriceRequest) ToPriceUpdates() []domain.ProductPriceUpdate {
+	updates := make([]domain.ProductPriceUpdate, 0, len(batchUpdatePriceRequest.Items))
+	for _, item := range batchUpdatePriceRequest.Items {
+		updates = append(updates, domain.ProductPriceUpdate{Id: item.Id, Price: item.Price})
+	}
+	return updates
+}
+
+type BatchDeleteRequest struct {
+	Mode string  `json:"mode"`
+	Ids  []int64 `json:"ids"`
+}
+
+type ChangeProductStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func (changeProductStatusRequest ChangeProductStatusRequest) ToModel(productId int64, actor string) model.ChangeProductStatus {
+	return model.ChangeProductStatus{
+		ProductId: productId,
+		Status:    changeProductStatusRequest.Status,
+		Actor:     actor,
+		Reason:    changeProductStatusRequest.Reason,
+	}
+}
+
+type LocalizedTextRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UpdateTranslationsRequest is keyed by locale, e.g. {"tr": {"name": "..."}}
+type UpdateTranslationsRequest map[string]LocalizedTextRequest
+
+func ToLocalizedTexts(translations map[string]LocalizedTextRequest) map[string]domain.LocalizedText {
+	if translations == nil {
+		return nil
+	}
+	localizedTexts := make(map[string]domain.LocalizedText, len(translations))
+	for locale, translation := range translations {
+		localizedTexts[locale] = domain.LocalizedText{Name: translation.Name, Description: translation.Description}
+	}
+	return localizedTexts
+}
+
+type AddPriceScheduleRequest struct {
+	Price         *float32   `json:"price"`
+	Discount      *float32   `json:"discount"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+}
+
+func (addPriceScheduleRequest AddPriceScheduleRequest) ToModel(productId int64) model.CreatePriceSchedule {
+	return model.CreatePriceSchedule{
+		ProductId:     productId,
+		Price:         addPriceScheduleRequest.Price,
+		Discount:      addPriceScheduleRequest.Discount,
+		EffectiveFrom: addPriceScheduleRequest.EffectiveFrom,
+		EffectiveTo:   addPriceScheduleRequest.EffectiveTo,
+	}
+}
+
+type AddPromotionRequest struct {
+	Name             string     `json:"name"`
+	Type             string     `json:"type"`
+	Store            string     `json:"store"`
+	Category         string     `json:"category"`
+	MinPrice         *float32   `json:"min_price"`
+	MaxPrice         *float32   `json:"max_price"`
+	Percent          float32    `json:"percent"`
+	BuyQuantity      int        `json:"buy_quantity"`
+	FreeQuantity     int        `json:"free_quantity"`
+	BundleProductIds []int64    `json:"bundle_product_ids"`
+	Priority         int        `json:"priority"`
+	Stackable        bool       `json:"stackable"`
+	ValidFrom        time.Time  `json:"valid_from"`
+	ValidTo          *time.Time `json:"valid_to"`
+}
+
+func (addPromotionRequest AddPromotionRequest) ToModel() model.CreatePromotion {
+	return model.CreatePromotion{
+		Name:             addPromotionRequest.Name,
+		Type:             addPromotionRequest.Type,
+		Store:            addPromotionRequest.Store,
+		Category:         addPromotionRequest.Category,
+		MinPrice:         addPromotionRequest.MinPrice,
+		MaxPrice:         addPromotionRequest.MaxPrice,
+		Percent:          addPromotionRequest.Percent,
+		BuyQuantity:      addPromotionRequest.BuyQuantity,
+		FreeQuantity:     addPromotionRequest.FreeQuantity,
+		BundleProductIds: addPromotionRequest.BundleProductIds,
+		Priority:         addPromotionRequest.Priority,
+		Stackable:        addPromotionRequest.Stackable,
+		ValidFrom:        addPromotionRequest.ValidFrom,
+		ValidTo:          addPromotionRequest.ValidTo,
+	}
+}
+
+type BasketItemRequest struct {
+	ProductId int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+// EvaluatePromotionsRequest takes either items with quantities or plain product ids, which count as one unit each.
+type EvaluatePromotionsRequest struct {
+	Items      []BasketItemRequest `json:"items"`
+	ProductIds []int64             `json:"product_ids"`
+}
+
+func (evaluatePromotionsRequest EvaluatePromotionsRequest) ToModel() []model.BasketItem {
+	items := make([]model.BasketItem, 0, len(evaluatePromotionsRequest.Items)+len(evaluatePromotionsRequest.ProductIds))
+	for _, item := range evaluatePromotionsRequest.Items {
+		items = append(items, model.BasketItem{ProductId: item.ProductId, Quantity: item.Quantity})
+	}
+	for _, productId := range evaluatePromotionsRequest.ProductIds {
+		items = append(items, model.BasketItem{ProductId: productId, Quantity: 1})
+	}
+	return items
+}
+
+type CreateQuoteRequest struct {
+	Items []BasketItemRequest `json:"items"`
+}
+
+func (createQuoteRequest CreateQuoteRequest) ToModel() []model.BasketItem {
+	items := make([]model.BasketItem, 0, len(createQuoteRequest.Items))
+	for _, item := range createQuoteRequest.Items {
+		items = append(items, model.BasketItem{ProductId: item.ProductId, Quantity: item.Quantity})
+	}
+	return items
+}
+
+type AddProductVariantRequest struct {
+	Sku           string            `json:"sku"`
+	PriceOverride *float32          `json:"price_override"`
+	Stock         int               `json:"stock"`
+	Attributes    map[string]string `json:"attributes"`
+}
+
+func (addProductVariantRequest AddProductVariantRequest) ToModel(productId int64) model.CreateProductVariant {
+	return model.CreateProductVariant{
+		ProductId:     productId,
+		Sku:           addProductVariantRequest.Sku,
+		PriceOverride: addProductVariantRequest.PriceOverride,
+		Stock:         addProductVariantRequest.Stock,
+		Attributes:    addProductVariantRequest.Attributes,
+	}
+}
+
+type AddAttributeDefinitionRequest struct {
+	Category   string   `json:"category"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Required   bool     `json:"required"`
+	EnumValues []string `json:"enum_values"`
+	Unit       string   `json:"unit"`
+}
+
+func (addAttributeDefinitionRequest AddAttributeDefinitionRequest) ToModel() model.CreateAttributeDefinition {
+	return model.CreateAttributeDefinition{
+		Category:   addAttributeDefinitionRequest.Category,
+		Name:       addAttributeDefinitionRequest.Name,
+		Type:       addAttributeDefinitionRequest.Type,
+		Required:   addAttributeDefinitionRequest.Required,
+		EnumValues: addAttributeDefinitionRequest.EnumValues,
+		Unit:       addAttributeDefinitionRequest.Unit,
+	}
+}
+
+type ReorderProductMediaRequest struct {
+	MediaIds []int64 `json:"media_ids"`
+}
+
+type RepriceProductsRequest struct {
+	Filter    RepricingFilterRequest `json:"filter"`
+	Operation string                 `json:"operation"`
+	Value     float32                `json:"value"`
+}
+
+type RepricingFilterRequest struct {
+	Store    string   `json:"store"`
+	Category string   `json:"category"`
+	MinPrice *float32 `json:"min_price"`
+	MaxPrice *float32 `json:"max_price"`
+}
+
+func (repriceProductsRequest RepriceProductsRequest) ToModel(actor string) model.RepriceProducts {
+	return model.RepriceProducts{
+		Store:     repriceProductsRequest.Filter.Store,
+		Category:  repriceProductsRequest.Filter.Category,
+		MinPrice:  repriceProductsRequest.Filter.MinPrice,
+		MaxPrice:  repriceProductsRequest.Filter.MaxPrice,
+		Operation: repriceProductsRequest.Operation,
+		Value:     repriceProductsRequest.Value,
+		Actor:     actor,
+	}
+}
+
+type AddWebhookRequest struct {
+	Url        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Store      string   `json:"store"`
+	Secret     string   `json:"secret"`
+}
+
+func (addWebhookRequest AddWebhookRequest) ToModel(actor string) model.CreateWebhook {
+	return model.CreateWebhook{
+		Url:        addWebhookRequest.Url,
+		EventTypes: addWebhookRequest.EventTypes,
+		Store:      addWebhookRequest.Store,
+		Secret:     addWebhookRequest.Secret,
+		Actor:      actor,
+	}
+}
+
+// UpdateWebhookRequest replaces the subscription, an empty secret keeps the current one.
+type UpdateWebhookRequest struct {
+	Url        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Store      string   `json:"store"`
+	Secret     string   `json:"secret"`
+	Active     bool     `json:"active"`
+}
+
+func (updateWebhookRequest UpdateWebhookRequest) ToModel(id int64) model.UpdateWebhook {
+	return model.UpdateWebhook{
+		Id:         id,
+		Url:        updateWebhookRequest.Url,
+		EventTypes: updateWebhookRequest.EventTypes,
+		Store:      updateWebhookRequest.Store,
+		Secret:     updateWebhookRequest.Secret,
+		Active:     updateWebhookRequest.Active,
 	}
 }
@@ -1,24 +1,101 @@
 package response
 
-import "go-product-app/domain"
+import (
+	"encoding/json"
+	"go-product-app/common/cache"
+	"go-product-app/common/search"
+	"go-product-app/domain"
+	"go-product-app/domain/pricing"
+	"go-product-app/domain/promotion"
+	"go-product-app/service/model"
+	"time"
+)
 
 type ErrorResponse struct {
 	Description string `json:"description"`
 }
 
 type ProductResponse struct {
-	Name     string  `json:"name"`
-	Price    float32 `json:"price"`
-	Discount float32 `json:"discount"`
-	Store    string  `json:"store"`
+	Id              int64                    `json:"id"`
+	Name            string                   `json:"name"`
+	Description     string                   `json:"description,omitempty"`
+	Locale          string                   `json:"locale,omitempty"`
+	Price           float32                  `json:"price"`
+	Discount        float32                  `json:"discount"`
+	Store           string                   `json:"store"`
+	Category        string                   `json:"category"`
+	Status          string                   `json:"status"`
+	Sku             string                   `json:"sku,omitempty"`
+	Gtin            string                   `json:"gtin,omitempty"`
+	ListPrice       float64                  `json:"list_price"`
+	DiscountPercent float64                  `json:"discount_percent"`
+	DiscountAmount  float64                  `json:"discount_amount"`
+	FinalPrice      float64                  `json:"final_price"`
+	Options         []string                 `json:"options,omitempty"`
+	Attributes      map[string]interface{}   `json:"attributes,omitempty"`
+	Variants        []ProductVariantResponse `json:"variants,omitempty"`
+	Media           []ProductMediaResponse   `json:"media"`
+	// Removed marks a product of an incremental pull that was deleted or is no longer listed with the given statuses,
+	// only its id, store and times are set.
+	Removed   bool       `json:"removed,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
 }
 
 func ToProductResponse(product domain.Product) ProductResponse {
+	priceBreakdown := product.PriceBreakdown()
 	return ProductResponse{
-		Name:     product.Name,
-		Price:    product.Price,
-		Discount: product.Discount,
-		Store:    product.Store,
+		Id:              product.Id,
+		Name:            product.Name,
+		Description:     product.Description,
+		Price:           product.Price,
+		Discount:        product.Discount,
+		Store:           product.Store,
+		Category:        product.Category,
+		Status:          product.Status,
+		Sku:             product.Sku,
+		Gtin:            product.Gtin,
+		ListPrice:       priceBreakdown.ListPrice,
+		DiscountPercent: priceBreakdown.DiscountPercent,
+		DiscountAmount:  priceBreakdown.DiscountAmount,
+		FinalPrice:      priceBreakdown.FinalPrice,
+		Options:         product.Options,
+		Attributes:      product.Attributes,
+		Media:           make([]ProductMediaResponse, 0),
+		DeletedAt:       product.DeletedAt,
+		CreatedAt:       product.CreatedAt,
+		UpdatedAt:       product.UpdatedAt,
+		CreatedBy:       product.CreatedBy,
+		UpdatedBy:       product.UpdatedBy,
+	}
+}
+
+func ToProductResponseWithMedia(product domain.Product, productMediaList []domain.ProductMedia) ProductResponse {
+	productResponse := ToProductResponse(product)
+	productResponse.Media = ToProductMediaResponseList(productMediaList)
+	return productResponse
+}
+
+func ToProductResponseListWithMedia(products []domain.Product, mediaByProductId map[int64][]domain.ProductMedia) []ProductResponse {
+	productResponseList := make([]ProductResponse, 0)
+	for _, product := range products {
+		productResponseList = append(productResponseList, ToProductResponseWithMedia(product, mediaByProductId[product.Id]))
+	}
+	return productResponseList
+}
+
+func ToRemovedProductResponse(product domain.Product) ProductResponse {
+	return ProductResponse{
+		Id:        product.Id,
+		Store:     product.Store,
+		Media:     make([]ProductMediaResponse, 0),
+		Removed:   true,
+		DeletedAt: product.DeletedAt,
+		CreatedAt: product.CreatedAt,
+		UpdatedAt: product.UpdatedAt,
 	}
 }
 
@@ -29,3 +106,753 @@ func ToProductResponseList(products []domain.Product) []ProductResponse {
 	}
 	return productResponseList
 }
+
+type ProductSearchResponse struct {
+	Total  int64                      `json:"total"`
+	Limit  int                        `json:"limit"`
+	Offset int                        `json:"offset"`
+	Hits   []ProductSearchHitResponse `json:"hits"`
+}
+
+type ProductSearchHitResponse struct {
+	Product ProductResponse `json:"product"`
+	Rank    float64         `json:"rank"`
+	Snippet string          `json:"snippet"`
+}
+
+func ToProductSearchResponse(result domain.ProductSearchResult) ProductSearchResponse {
+	hitResponseList := make([]ProductSearchHitResponse, 0)
+	for _, hit := range result.Hits {
+		hitResponseList = append(hitResponseList, ProductSearchHitResponse{
+			Product: ToProductResponse(hit.Product),
+			Rank:    hit.Rank,
+			Snippet: hit.Snippet,
+		})
+	}
+	return ProductSearchResponse{Total: result.Total, Limit: result.Limit, Offset: result.Offset, Hits: hitResponseList}
+}
+
+type IndexSearchResponse struct {
+	Total  int                       `json:"total"`
+	Hits   []ProductResponse         `json:"hits"`
+	Facets IndexSearchFacetsResponse `json:"facets"`
+}
+
+type IndexSearchFacetsResponse struct {
+	Stores []FacetCountResponse `json:"stores"`
+	Prices []FacetCountResponse `json:"prices"`
+}
+
+type FacetCountResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+func ToIndexSearchResponse(result search.Result) IndexSearchResponse {
+	products := make([]domain.Product, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		products = append(products, hit.Product)
+	}
+	return IndexSearchResponse{
+		Total: result.Total,
+		Hits:  ToProductResponseList(products),
+		Facets: IndexSearchFacetsResponse{
+			Stores: toFacetCountResponseList(result.StoreFacets),
+			Prices: toFacetCountResponseList(result.PriceFacets),
+		},
+	}
+}
+
+func toFacetCountResponseList(facets []search.FacetCount) []FacetCountResponse {
+	facetResponseList := make([]FacetCountResponse, 0)
+	for _, facet := range facets {
+		facetResponseList = append(facetResponseList, FacetCountResponse{Value: facet.Value, Count: facet.Count})
+	}
+	return facetResponseList
+}
+
+type BatchResponse struct {
+	Mode      string                    `json:"mode"`
+	Succeeded int                       `json:"succeeded"`
+	Failed    int                       `json:"failed"`
+	Results   []BatchItemResultResponse `json:"results"`
+}
+
+type BatchItemResultResponse struct {
+	Index  int    `json:"index"`
+	Id     int64  `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func ToBatchResponse(mode string, results []domain.BatchItemResult) BatchResponse {
+	batchResponse := BatchResponse{Mode: mode, Results: make([]BatchItemResultResponse, 0)}
+	for _, result := range results {
+		switch result.Status {
+		case domain.BatchItemSucceeded:
+			batchResponse.Succeeded++
+		case domain.BatchItemFailed:
+			batchResponse.Failed++
+		}
+		batchResponse.Results = append(batchResponse.Results, BatchItemResultResponse{
+			Index:  result.Index,
+			Id:     result.Id,
+			Status: result.Status,
+			Error:  result.Error,
+		})
+	}
+	return batchResponse
+}
+
+type ProductStatsResponse struct {
+	GroupBy []string                    `json:"group_by"`
+	Groups  []ProductStatsGroupResponse `json:"groups"`
+}
+
+type ProductStatsGroupResponse struct {
+	Keys            map[string]string `json:"keys"`
+	Count           int64             `json:"count"`
+	AveragePrice    float64           `json:"average_price"`
+	MinPrice        float64           `json:"min_price"`
+	MaxPrice        float64           `json:"max_price"`
+	AverageDiscount float64           `json:"average_discount"`
+}
+
+func ToProductStatsResponse(groupBy []string, groups []domain.ProductStatsGroup) ProductStatsResponse {
+	groupResponseList := make([]ProductStatsGroupResponse, 0)
+	for _, group := range groups {
+		groupResponseList = append(groupResponseList, ProductStatsGroupResponse{
+			Keys:            group.Keys,
+			Count:           group.Count,
+			AveragePrice:    group.AveragePrice,
+			MinPrice:        group.MinPrice,
+			MaxPrice:        group.MaxPrice,
+			AverageDiscount: group.AverageDiscount,
+		})
+	}
+	if groupBy == nil {
+		groupBy = make([]string, 0)
+	}
+	return ProductStatsResponse{GroupBy: groupBy, Groups: groupResponseList}
+}
+
+type ProductStatusChangeResponse struct {
+	Id         int64     `json:"id"`
+	ProductId  int64     `json:"product_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"`
+	Reason     string    `json:"reason,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+func ToProductStatusChangeResponse(statusChange domain.ProductStatusChange) ProductStatusChangeResponse {
+	return ProductStatusChangeResponse{
+		Id:         statusChange.Id,
+		ProductId:  statusChange.ProductId,
+		FromStatus: statusChange.FromStatus,
+		ToStatus:   statusChange.ToStatus,
+		Actor:      statusChange.Actor,
+		Reason:     statusChange.Reason,
+		ChangedAt:  statusChange.ChangedAt,
+	}
+}
+
+func ToProductStatusChangeResponseList(statusChanges []domain.ProductStatusChange) []ProductStatusChangeResponse {
+	statusChangeResponseList := make([]ProductStatusChangeResponse, 0)
+	for _, statusChange := range statusChanges {
+		statusChangeResponseList = append(statusChangeResponseList, ToProductStatusChangeResponse(statusChange))
+	}
+	return statusChangeResponseList
+}
+
+type PriceScheduleResponse struct {
+	Id            int64      `json:"id"`
+	ProductId     int64      `json:"product_id"`
+	Price         *float32   `json:"price,omitempty"`
+	Discount      *float32   `json:"discount,omitempty"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+	Status        string     `json:"status"`
+}
+
+func ToPriceScheduleResponse(priceSchedule domain.PriceSchedule) PriceScheduleResponse {
+	return PriceScheduleResponse{
+		Id:            priceSchedule.Id,
+		ProductId:     priceSchedule.ProductId,
+		Price:         priceSchedule.Price,
+		Discount:      priceSchedule.Discount,
+		EffectiveFrom: priceSchedule.EffectiveFrom,
+		EffectiveTo:   priceSchedule.EffectiveTo,
+		Status:        priceSchedule.Status,
+	}
+}
+
+func ToPriceScheduleResponseList(priceSchedules []domain.PriceSchedule) []PriceScheduleResponse {
+	priceScheduleResponseList := make([]PriceScheduleResponse, 0)
+	for _, priceSchedule := range priceSchedules {
+		priceScheduleResponseList = append(priceScheduleResponseList, ToPriceScheduleResponse(priceSchedule))
+	}
+	return priceScheduleResponseList
+}
+
+type PromotionResponse struct {
+	Id               int64      `json:"id"`
+	Name             string     `json:"name"`
+	Type             string     `json:"type"`
+	Store            string     `json:"store,omitempty"`
+	Category         string     `json:"category,omitempty"`
+	MinPrice         *float32   `json:"min_price,omitempty"`
+	MaxPrice         *float32   `json:"max_price,omitempty"`
+	Percent          float32    `json:"percent,omitempty"`
+	BuyQuantity      int        `json:"buy_quantity,omitempty"`
+	FreeQuantity     int        `json:"free_quantity,omitempty"`
+	BundleProductIds []int64    `json:"bundle_product_ids,omitempty"`
+	Priority         int        `json:"priority"`
+	Stackable        bool       `json:"stackable"`
+	ValidFrom        time.Time  `json:"valid_from"`
+	ValidTo          *time.Time `json:"valid_to,omitempty"`
+}
+
+func ToPromotionResponse(promotion domain.Promotion) PromotionResponse {
+	return PromotionResponse{
+		Id:               promotion.Id,
+		Name:             promotion.Name,
+		Type:             promotion.Type,
+		Store:            promotion.Store,
+		Category:         promotion.Category,
+		MinPrice:         promotion.MinPrice,
+		MaxPrice:         promotion.MaxPrice,
+		Percent:          promotion.Percent,
+		BuyQuantity:      promotion.BuyQuantity,
+		FreeQuantity:     promotion.FreeQuantity,
+		BundleProductIds: promotion.BundleProductIds,
+		Priority:         promotion.Priority,
+		Stackable:        promotion.Stackable,
+		ValidFrom:        promotion.ValidFrom,
+		ValidTo:          promotion.ValidTo,
+	}
+}
+
+func ToPromotionResponseList(promotions []domain.Promotion) []PromotionResponse {
+	promotionResponseList := make([]PromotionResponse, 0)
+	for _, promotion := range promotions {
+		promotionResponseList = append(promotionResponseList, ToPromotionResponse(promotion))
+	}
+	return promotionResponseList
+}
+
+type RuleResultResponse struct {
+	PromotionId int64   `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Applied     bool    `json:"applied"`
+	Discount    float64 `json:"discount"`
+	Reason      string  `json:"reason"`
+}
+
+type PromotionLineResponse struct {
+	ProductId         int64                `json:"product_id"`
+	Name              string               `json:"name"`
+	Quantity          int                  `json:"quantity"`
+	UnitPrice         float64              `json:"unit_price"`
+	LineTotal         float64              `json:"line_total"`
+	PromotionDiscount float64              `json:"promotion_discount"`
+	FinalTotal        float64              `json:"final_total"`
+	Rules             []RuleResultResponse `json:"rules"`
+}
+
+type PromotionEvaluationResponse struct {
+	Lines             []PromotionLineResponse `json:"lines"`
+	Subtotal          float64                 `json:"subtotal"`
+	PromotionDiscount float64                 `json:"promotion_discount"`
+	Total             float64                 `json:"total"`
+}
+
+func ToRuleResultResponseList(ruleResults []promotion.RuleResult) []RuleResultResponse {
+	ruleResultResponseList := make([]RuleResultResponse, 0, len(ruleResults))
+	for _, rule := range ruleResults {
+		ruleResultResponseList = append(ruleResultResponseList, RuleResultResponse{
+			PromotionId: rule.PromotionId,
+			Name:        rule.Name,
+			Applied:     rule.Applied,
+			Discount:    rule.Discount,
+			Reason:      rule.Reason,
+		})
+	}
+	return ruleResultResponseList
+}
+
+func ToPromotionLineResponse(lineEvaluation promotion.LineEvaluation) PromotionLineResponse {
+	return PromotionLineResponse{
+		ProductId:         lineEvaluation.Product.Id,
+		Name:              lineEvaluation.Product.Name,
+		Quantity:          lineEvaluation.Quantity,
+		UnitPrice:         lineEvaluation.UnitPrice.FinalPrice,
+		LineTotal:         lineEvaluation.LineTotal,
+		PromotionDiscount: lineEvaluation.PromotionDiscount,
+		FinalTotal:        lineEvaluation.FinalTotal,
+		Rules:             ToRuleResultResponseList(lineEvaluation.Rules),
+	}
+}
+
+func ToPromotionEvaluationResponse(evaluation promotion.Evaluation) PromotionEvaluationResponse {
+	lines := make([]PromotionLineResponse, 0, len(evaluation.Lines))
+	for _, lineEvaluation := range evaluation.Lines {
+		lines = append(lines, ToPromotionLineResponse(lineEvaluation))
+	}
+	return PromotionEvaluationResponse{
+		Lines:             lines,
+		Subtotal:          evaluation.Subtotal,
+		PromotionDiscount: evaluation.PromotionDiscount,
+		Total:             evaluation.Total,
+	}
+}
+
+type QuoteLineResponse struct {
+	ProductId         int64                `json:"product_id"`
+	Quantity          int                  `json:"quantity"`
+	Status            string               `json:"status"`
+	Name              string               `json:"name,omitempty"`
+	UnitPrice         float64              `json:"unit_price"`
+	ListTotal         float64              `json:"list_total"`
+	ProductDiscount   float64              `json:"product_discount"`
+	PromotionDiscount float64              `json:"promotion_discount"`
+	LineTotal         float64              `json:"line_total"`
+	Promotions        []RuleResultResponse `json:"promotions"`
+}
+
+type QuoteResponse struct {
+	Lines             []QuoteLineResponse `json:"lines"`
+	Subtotal          float64             `json:"subtotal"`
+	ProductDiscount   float64             `json:"product_discount"`
+	PromotionDiscount float64             `json:"promotion_discount"`
+	GrandTotal        float64             `json:"grand_total"`
+}
+
+func ToQuoteResponse(quote model.Quote) QuoteResponse {
+	lines := make([]QuoteLineResponse, 0, len(quote.Lines))
+	for _, quoteLine := range quote.Lines {
+		lineResponse := QuoteLineResponse{
+			ProductId:  quoteLine.ProductId,
+			Quantity:   quoteLine.Quantity,
+			Status:     quoteLine.Status,
+			Promotions: make([]RuleResultResponse, 0),
+		}
+		if quoteLine.Status == model.QuoteLineStatusPriced {
+			lineResponse.Name = quoteLine.Evaluation.Product.Name
+			lineResponse.UnitPrice = quoteLine.Evaluation.UnitPrice.FinalPrice
+			lineResponse.ListTotal = quoteLine.ListTotal
+			lineResponse.ProductDiscount = quoteLine.ProductDiscount
+			lineResponse.PromotionDiscount = quoteLine.Evaluation.PromotionDiscount
+			lineResponse.LineTotal = quoteLine.Evaluation.FinalTotal
+			lineResponse.Promotions = ToRuleResultResponseList(quoteLine.Evaluation.Rules)
+		}
+		lines = append(lines, lineResponse)
+	}
+	return QuoteResponse{
+		Lines:             lines,
+		Subtotal:          quote.Subtotal,
+		ProductDiscount:   quote.ProductDiscount,
+		PromotionDiscount: quote.PromotionDiscount,
+		GrandTotal:        quote.GrandTotal,
+	}
+}
+
+type ProductVariantResponse struct {
+	Id            int64             `json:"id"`
+	Sku           string            `json:"sku,omitempty"`
+	Price         float32           `json:"price"`
+	PriceOverride *float32          `json:"price_override,omitempty"`
+	FinalPrice    float64           `json:"final_price"`
+	Stock         int               `json:"stock"`
+	Attributes    map[string]string `json:"attributes"`
+}
+
+func ToProductVariantResponse(parent domain.Product, productVariant domain.ProductVariant) ProductVariantResponse {
+	price := productVariant.EffectivePrice(parent)
+	return ProductVariantResponse{
+		Id:            productVariant.Id,
+		Sku:           productVariant.Sku,
+		Price:         price,
+		PriceOverride: productVariant.PriceOverride,
+		FinalPrice:    pricing.DefaultCalculator.Calculate(price, parent.Discount).FinalPrice,
+		Stock:         productVariant.Stock,
+		Attributes:    productVariant.Attributes,
+	}
+}
+
+func ToProductVariantResponseList(parent domain.Product, productVariants []domain.ProductVariant) []ProductVariantResponse {
+	productVariantResponseList := make([]ProductVariantResponse, 0)
+	for _, productVariant := range productVariants {
+		productVariantResponseList = append(productVariantResponseList, ToProductVariantResponse(parent, productVariant))
+	}
+	return productVariantResponseList
+}
+
+type AttributeDefinitionResponse struct {
+	Id         int64    `json:"id"`
+	Category   string   `json:"category"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Required   bool     `json:"required"`
+	EnumValues []string `json:"enum_values,omitempty"`
+	Unit       string   `json:"unit,omitempty"`
+}
+
+func ToAttributeDefinitionResponseList(attributeDefinitions []domain.AttributeDefinition) []AttributeDefinitionResponse {
+	attributeDefinitionResponseList := make([]AttributeDefinitionResponse, 0)
+	for _, attributeDefinition := range attributeDefinitions {
+		attributeDefinitionResponseList = append(attributeDefinitionResponseList, AttributeDefinitionResponse{
+			Id:         attributeDefinition.Id,
+			Category:   attributeDefinition.Category,
+			Name:       attributeDefinition.Name,
+			Type:       attributeDefinition.Type,
+			Required:   attributeDefinition.Required,
+			EnumValues: attributeDefinition.EnumValues,
+			Unit:       attributeDefinition.Unit,
+		})
+	}
+	return attributeDefinitionResponseList
+}
+
+type JobResponse struct {
+	Id          int64               `json:"id"`
+	Type        string              `json:"type"`
+	Status      string              `json:"status"`
+	Progress    JobProgressResponse `json:"progress"`
+	Result      json.RawMessage     `json:"result,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	Attempts    int                 `json:"attempts"`
+	MaxAttempts int                 `json:"max_attempts"`
+	CreatedBy   string              `json:"created_by"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	StartedAt   *time.Time          `json:"started_at,omitempty"`
+	FinishedAt  *time.Time          `json:"finished_at,omitempty"`
+}
+
+type JobProgressResponse struct {
+	Done  int64 `json:"done"`
+	Total int64 `json:"total,omitempty"`
+}
+
+func ToJobResponse(job domain.Job) JobResponse {
+	return JobResponse{
+		Id:          job.Id,
+		Type:        job.Type,
+		Status:      job.Status,
+		Progress:    JobProgressResponse{Done: job.ProgressDone, Total: job.ProgressTotal},
+		Result:      job.Result,
+		Error:       job.Error,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		CreatedBy:   job.CreatedBy,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		StartedAt:   job.StartedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+}
+
+type ProductImportReportResponse struct {
+	DryRun         bool   `json:"dry_run"`
+	Rows           int    `json:"rows"`
+	Created        int    `json:"created"`
+	Updated        int    `json:"updated"`
+	Failed         int    `json:"failed"`
+	Truncated      bool   `json:"truncated"`
+	ErrorReportUrl string `json:"error_report_url,omitempty"`
+}
+
+func ToProductImportReportResponse(report domain.ProductImportReport) ProductImportReportResponse {
+	return ProductImportReportResponse{
+		DryRun:         report.DryRun,
+		Rows:           report.Rows,
+		Created:        report.Created,
+		Updated:        report.Updated,
+		Failed:         report.Failed,
+		Truncated:      report.Truncated,
+		ErrorReportUrl: report.ErrorReportUrl,
+	}
+}
+
+type ProductMediaResponse struct {
+	Id           int64  `json:"id"`
+	Url          string `json:"url"`
+	ThumbnailUrl string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Checksum     string `json:"checksum"`
+	Position     int    `json:"position"`
+	IsPrimary    bool   `json:"is_primary"`
+}
+
+func ToProductMediaResponse(productMedia domain.ProductMedia) ProductMediaResponse {
+	return ProductMediaResponse{
+		Id:           productMedia.Id,
+		Url:          productMedia.Url,
+		ThumbnailUrl: productMedia.ThumbnailUrl,
+		ContentType:  productMedia.ContentType,
+		Size:         productMedia.Size,
+		Width:        productMedia.Width,
+		Height:       productMedia.Height,
+		Checksum:     productMedia.Checksum,
+		Position:     productMedia.Position,
+		IsPrimary:    productMedia.IsPrimary,
+	}
+}
+
+func ToProductMediaResponseList(productMediaList []domain.ProductMedia) []ProductMediaResponse {
+	productMediaResponseList := make([]ProductMediaResponse, 0)
+	for _, productMedia := range productMediaList {
+		productMediaResponseList = append(productMediaResponseList, ToProductMediaResponse(productMedia))
+	}
+	return productMediaResponseList
+}
+
+type RepricingResponse struct {
+	Id        int64                   `json:"id"`
+	Status    string                  `json:"status"`
+	Filter    RepricingFilterResponse `json:"filter"`
+	Operation string                  `json:"operation"`
+	Value     float32                 `json:"value"`
+	Affected  int                     `json:"affected"`
+	Reverted  int                     `json:"reverted"`
+	Skipped   int                     `json:"skipped"`
+	CreatedBy string                  `json:"created_by"`
+	CreatedAt time.Time               `json:"created_at"`
+	UndoneBy  string                  `json:"undone_by,omitempty"`
+	UndoneAt  *time.Time              `json:"undone_at,omitempty"`
+}
+
+type RepricingFilterResponse struct {
+	Store    string   `json:"store,omitempty"`
+	Category string   `json:"category,omitempty"`
+	MinPrice *float32 `json:"min_price,omitempty"`
+	MaxPrice *float32 `json:"max_price,omitempty"`
+}
+
+func ToRepricingResponse(repricing domain.Repricing) RepricingResponse {
+	return RepricingResponse{
+		Id:     repricing.Id,
+		Status: repricing.Status,
+		Filter: RepricingFilterResponse{
+			Store:    repricing.Filter.Store,
+			Category: repricing.Filter.Category,
+			MinPrice: repricing.Filter.MinPrice,
+			MaxPrice: repricing.Filter.MaxPrice,
+		},
+		Operation: repricing.Operation.Type,
+		Value:     repricing.Operation.Value,
+		Affected:  repricing.Affected,
+		Reverted:  repricing.Reverted,
+		Skipped:   repricing.Skipped,
+		CreatedBy: repricing.CreatedBy,
+		CreatedAt: repricing.CreatedAt,
+		UndoneBy:  repricing.UndoneBy,
+		UndoneAt:  repricing.UndoneAt,
+	}
+}
+
+type RepricingPreviewResponse struct {
+	Matched int                   `json:"matched"`
+	Changed int                   `json:"changed"`
+	Changes []PriceChangeResponse `json:"changes"`
+}
+
+func ToRepricingPreviewResponse(preview domain.RepricingPreview) RepricingPreviewResponse {
+	return RepricingPreviewResponse{
+		Matched: preview.Matched,
+		Changed: len(preview.Changes),
+		Changes: ToPriceChangeResponseList(preview.Changes),
+	}
+}
+
+// PriceChangeResponse is an entry of the price history, or a planned change in a preview
+// in which case only the product and the prices are set.
+type PriceChangeResponse struct {
+	Id          int64      `json:"id,omitempty"`
+	ProductId   int64      `json:"product_id"`
+	ProductName string     `json:"product_name"`
+	RepricingId int64      `json:"repricing_id,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+	OldPrice    float32    `json:"old_price"`
+	NewPrice    float32    `json:"new_price"`
+	OldDiscount float32    `json:"old_discount"`
+	NewDiscount float32    `json:"new_discount"`
+	Actor       string     `json:"actor,omitempty"`
+	ChangedAt   *time.Time `json:"changed_at,omitempty"`
+}
+
+func ToPriceChangeResponse(change domain.PriceChange) PriceChangeResponse {
+	priceChangeResponse := PriceChangeResponse{
+		Id:          change.Id,
+		ProductId:   change.ProductId,
+		ProductName: change.ProductName,
+		RepricingId: change.RepricingId,
+		Reason:      change.Reason,
+		OldPrice:    change.OldPrice,
+		NewPrice:    change.NewPrice,
+		OldDiscount: change.OldDiscount,
+		NewDiscount: change.NewDiscount,
+		Actor:       change.Actor,
+	}
+	if !change.ChangedAt.IsZero() {
+		priceChangeResponse.ChangedAt = &change.ChangedAt
+	}
+	return priceChangeResponse
+}
+
+func ToPriceChangeResponseList(changes []domain.PriceChange) []PriceChangeResponse {
+	priceChangeResponseList := make([]PriceChangeResponse, 0)
+	for _, change := range changes {
+		priceChangeResponseList = append(priceChangeResponseList, ToPriceChangeResponse(change))
+	}
+	return priceChangeResponseList
+}
+
+// WebhookResponse never shows the secret except right after the webhook is created.
+type WebhookResponse struct {
+	Id                  int64      `json:"id"`
+	Url                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	Store               string     `json:"store,omitempty"`
+	Secret              string     `json:"secret,omitempty"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedBy           string     `json:"created_by"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func ToWebhookResponse(webhook domain.Webhook) WebhookResponse {
+	return WebhookResponse{
+		Id:                  webhook.Id,
+		Url:                 webhook.Url,
+		EventTypes:          webhook.EventTypes,
+		Store:               webhook.Store,
+		Active:              webhook.Active,
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+		DisabledAt:          webhook.DisabledAt,
+		CreatedBy:           webhook.CreatedBy,
+		CreatedAt:           webhook.CreatedAt,
+		UpdatedAt:           webhook.UpdatedAt,
+	}
+}
+
+func ToCreatedWebhookResponse(webhook domain.Webhook) WebhookResponse {
+	webhookResponse := ToWebhookResponse(webhook)
+	webhookResponse.Secret = webhook.Secret
+	return webhookResponse
+}
+
+func ToWebhookResponseList(webhooks []domain.Webhook) []WebhookResponse {
+	webhookResponseList := make([]WebhookResponse, 0)
+	for _, webhook := range webhooks {
+		webhookResponseList = append(webhookResponseList, ToWebhookResponse(webhook))
+	}
+	return webhookResponseList
+}
+
+type WebhookDeliveryResponse struct {
+	Id             int64      `json:"id"`
+	WebhookId      int64      `json:"webhook_id"`
+	EventId        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	ProductId      int64      `json:"product_id"`
+	Body           string     `json:"body"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+func ToWebhookDeliveryResponse(delivery domain.WebhookDelivery) WebhookDeliveryResponse {
+	webhookDeliveryResponse := WebhookDeliveryResponse{
+		Id:             delivery.Id,
+		WebhookId:      delivery.WebhookId,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		ProductId:      delivery.ProductId,
+		Body:           delivery.Body,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	if delivery.Status == domain.WebhookDeliveryPending {
+		nextAttempt := delivery.NextAttempt
+		webhookDeliveryResponse.NextAttemptAt = &nextAttempt
+	}
+	return webhookDeliveryResponse
+}
+
+func ToWebhookDeliveryResponseList(deliveries []domain.WebhookDelivery) []WebhookDeliveryResponse {
+	webhookDeliveryResponseList := make([]WebhookDeliveryResponse, 0)
+	for _, delivery := range deliveries {
+		webhookDeliveryResponseList = append(webhookDeliveryResponseList, ToWebhookDeliveryResponse(delivery))
+	}
+	return webhookDeliveryResponseList
+}
+
+// ProductEventResponse is the data of an event of the product stream, data is the payload of the event.
+type ProductEventResponse struct {
+	EventId    string          `json:"event_id"`
+	Type       string          `json:"type"`
+	ProductId  int64           `json:"product_id"`
+	Store      string          `json:"store"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func ToProductEventResponse(event domain.ProductEvent) ProductEventResponse {
+	return ProductEventResponse{
+		EventId:    event.EventId,
+		Type:       event.Type,
+		ProductId:  event.ProductId,
+		Store:      event.Store,
+		OccurredAt: event.OccurredAt,
+		Data:       event.Payload,
+	}
+}
+
+type CacheStatsResponse struct {
+	Enabled bool                               `json:"enabled"`
+	Caches  map[string]CacheStatsResponseEntry `json:"caches"`
+}
+
+type CacheStatsResponseEntry struct {
+	Hits        int64   `json:"hits"`
+	Misses      int64   `json:"misses"`
+	HitRatio    float64 `json:"hit_ratio"`
+	SharedLoads int64   `json:"shared_loads"`
+	Evictions   int64   `json:"evictions"`
+	Entries     int     `json:"entries"`
+}
+
+func ToCacheStatsResponse(enabled bool, caches map[string]cache.Stats) CacheStatsResponse {
+	cacheStatsResponse := CacheStatsResponse{Enabled: enabled, Caches: make(map[string]CacheStatsResponseEntry)}
+	for name, stats := range caches {
+		var hitRatio float64
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			hitRatio = float64(stats.Hits) / float64(lookups)
+		}
+		cacheStatsResponse.Caches[name] = CacheStatsResponseEntry{
+			Hits:        stats.Hits,
+			Misses:      stats.Misses,
+			HitRatio:    hitRatio,
+			SharedLoads: stats.SharedLoads,
+			Evictions:   stats.Evictions,
+			Entries:     stats.Entries,
+		}
+	}
+	return cacheStatsResponse
+}
@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go-product-app/controller/request"
+	"go-product-app/controller/response"
+	"go-product-app/persistence"
+	"go-product-app/service"
+	"net/http"
+	"strconv"
+)
+
+type WebhookController struct {
+	webhookService service.IWebhookService
+}
+
+func NewWebhookController(webhookService service.IWebhookService) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+	}
+}
+
+func (webhookController *WebhookController) RegisterRoutes(e *echo.Echo) {
+	e.POST("/api/v1/webhooks", webhookController.Add)
+	e.GET("/api/v1/webhooks", webhookController.GetAll)
+	e.GET("/api/v1/webhooks/:id", webhookController.GetById)
+	e.PUT("/api/v1/webhooks/:id", webhookController.Update)
+	e.DELETE("/api/v1/webhooks/:id", webhookController.DeleteById)
+	e.GET("/api/v1/webhooks/:id/deliveries", webhookController.GetDeliveries)
+	e.POST("/api/v1/webhooks/:id/deliveries/:deliveryId/redeliver", webhookController.Redeliver)
+}
+
+// Add responds with the secret deliveries are signed with, it is not shown again.
+func (webhookController *WebhookController) Add(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can register webhooks",
+		})
+	}
+	var addWebhookRequest request.AddWebhookRequest
+	if err := c.Bind(&addWebhookRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	webhook, err := webhookController.webhookService.Add(addWebhookRequest.ToModel(callerFromRequest(c).Id))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, response.ToCreatedWebhookResponse(webhook))
+}
+
+func (webhookController *WebhookController) GetAll(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can list webhooks",
+		})
+	}
+	webhooks, err := webhookController.webhookService.GetAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToWebhookResponseList(webhooks))
+}
+
+func (webhookController *WebhookController) GetById(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can view webhooks",
+		})
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	webhook, err := webhookController.webhookService.GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToWebhookResponse(webhook))
+}
+
+// Update replaces the subscription, setting active to true resumes a webhook that was deactivated after failing.
+func (webhookController *WebhookController) Update(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can change webhooks",
+		})
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	var updateWebhookRequest request.UpdateWebhookRequest
+	if err = c.Bind(&updateWebhookRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	webhook, err := webhookController.webhookService.Update(updateWebhookRequest.ToModel(id))
+	var notFoundError persistence.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToWebhookResponse(webhook))
+}
+
+func (webhookController *WebhookController) DeleteById(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can delete webhooks",
+		})
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	err = webhookController.webhookService.DeleteById(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// GetDeliveries is the delivery log of a webhook, newest first, optionally filtered with status=<pending|succeeded|failed>.
+// The log shows the events of drafts, so like the webhooks themselves it is only available to admins and editors.
+func (webhookController *WebhookController) GetDeliveries(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can view webhook deliveries",
+		})
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	var limit int
+	if limitParam := c.QueryParam("limit"); len(limitParam) > 0 {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				Description: fmt.Sprintf("Limit %s should be a number", limitParam),
+			})
+		}
+	}
+	deliveries, err := webhookController.webhookService.GetDeliveries(id, c.QueryParam("status"), limit)
+	var notFoundError persistence.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToWebhookDeliveryResponseList(deliveries))
+}
+
+// Redeliver queues the delivery to be sent again, the dispatcher picks it up with its next batch.
+func (webhookController *WebhookController) Redeliver(c echo.Context) error {
+	if !callerFromRequest(c).CanEdit() {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Description: "Only admins and editors can redeliver webhooks",
+		})
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	deliveryId, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	delivery, err := webhookController.webhookService.Redeliver(id, deliveryId)
+	var notFoundError persistence.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusAccepted, response.ToWebhookDeliveryResponse(delivery))
+}
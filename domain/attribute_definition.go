@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeEnum    = "enum"
+)
+
+// AttributeDefinition describes a custom attribute products of a category may carry.
+type AttributeDefinition struct {
+	Id         int64
+	Category   string
+	Name       string
+	Type       string
+	Required   bool
+	EnumValues []string
+	Unit       string
+}
+
+// Validate checks a decoded JSON value against the definition.
+func (attributeDefinition AttributeDefinition) Validate(value interface{}) error {
+	switch attributeDefinition.Type {
+	case AttributeTypeString:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+	case AttributeTypeNumber:
+		switch value.(type) {
+		case float64, float32, int, int64:
+			return nil
+		}
+	case AttributeTypeBoolean:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+	case AttributeTypeEnum:
+		text, ok := value.(string)
+		if !ok {
+			break
+		}
+		for _, enumValue := range attributeDefinition.EnumValues {
+			if enumValue == text {
+				return nil
+			}
+		}
+		return errors.New(fmt.Sprintf("Attribute %s should be one of %s", attributeDefinition.Name, strings.Join(attributeDefinition.EnumValues, ", ")))
+	}
+	return errors.New(fmt.Sprintf("Attribute %s should be a %s", attributeDefinition.Name, attributeDefinition.Type))
+}
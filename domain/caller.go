@@ -0,0 +1,23 @@
+package domain
+
+const (
+	CallerRoleAdmin  = "admin"
+	CallerRoleEditor = "editor"
+	CallerRoleViewer = "viewer"
+)
+
+// Caller is whoever made the request, as identified by the api gateway.
+type Caller struct {
+	Id   string
+	Role string
+}
+
+// CanSeeStatus tells whether products in the given status are visible to the caller, viewers never see drafts.
+func (caller Caller) CanSeeStatus(status string) bool {
+	return status != ProductStatusDraft || caller.Role != CallerRoleViewer
+}
+
+// CanEdit tells whether the caller may change products and the settings around them, viewers can only read.
+func (caller Caller) CanEdit() bool {
+	return caller.Role == CallerRoleAdmin || caller.Role == CallerRoleEditor
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidateGtin checks a GTIN-8, GTIN-12 (UPC-A), GTIN-13 (EAN-13) or GTIN-14 code and its check digit.
+func ValidateGtin(gtin string) error {
+	switch len(gtin) {
+	case 8, 12, 13, 14:
+	default:
+		return errors.New("Gtin should be 8, 12, 13 or 14 digits long")
+	}
+
+	sum := 0
+	for i := len(gtin) - 2; i >= 0; i-- {
+		digit := gtin[i]
+		if digit < '0' || digit > '9' {
+			return errors.New("Gtin should contain only digits")
+		}
+		// weights alternate 3,1,3,... starting next to the check digit
+		weight := 1
+		if (len(gtin)-2-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(digit-'0') * weight
+	}
+
+	checkDigit := gtin[len(gtin)-1]
+	if checkDigit < '0' || checkDigit > '9' {
+		return errors.New("Gtin should contain only digits")
+	}
+	expected := (10 - sum%10) % 10
+	if int(checkDigit-'0') != expected {
+		return errors.New(fmt.Sprintf("Gtin check digit should be %d", expected))
+	}
+	return nil
+}
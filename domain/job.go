@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+const (
+	jobRetryBaseDelay = 10 * time.Second
+	jobRetryMaxDelay  = 10 * time.Minute
+)
+
+// Job is a long running operation run by a background worker. Payload and Result are json documents
+// whose shape depends on Type. ProgressTotal is zero while the amount of work is unknown.
+type Job struct {
+	Id              int64
+	Type            string
+	Status          string
+	Payload         json.RawMessage
+	Result          json.RawMessage
+	Error           string
+	ProgressDone    int64
+	ProgressTotal   int64
+	Attempts        int
+	MaxAttempts     int
+	RunAfter        time.Time
+	CancelRequested bool
+	CreatedBy       string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+}
+
+func (job Job) IsFinished() bool {
+	return job.Status == JobStatusSucceeded || job.Status == JobStatusFailed || job.Status == JobStatusCancelled
+}
+
+func (job Job) CanRetry() bool {
+	return job.Attempts < job.MaxAttempts
+}
+
+// JobRetryDelay is the exponential backoff before the next attempt of a job that failed attempts times.
+func JobRetryDelay(attempts int) time.Duration {
+	delay := jobRetryBaseDelay
+	for i := 1; i < attempts && delay < jobRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, jobRetryMaxDelay)
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "strings"
+
+type LocalizedText struct {
+	Name        string
+	Description string
+}
+
+// Localize returns the product with Name and Description taken from the first translation found
+// in preferredLocales followed by fallbackLocales. A regional locale like "tr-tr" also tries "tr".
+// Without any match the product keeps its own Name and Description.
+func (product Product) Localize(preferredLocales []string, fallbackLocales ...string) (Product, string) {
+	for _, locale := range localeFallbackChain(append(append([]string{}, preferredLocales...), fallbackLocales...)) {
+		if text, found := product.Translations[locale]; found {
+			product.Name = text.Name
+			product.Description = text.Description
+			return product, locale
+		}
+	}
+	return product, ""
+}
+
+func localeFallbackChain(locales []string) []string {
+	chain := make([]string, 0, len(locales)*2)
+	seen := make(map[string]bool, len(locales)*2)
+	add := func(locale string) {
+		if len(locale) > 0 && !seen[locale] {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	for _, locale := range locales {
+		add(locale)
+		if language, _, regional := strings.Cut(locale, "-"); regional {
+			add(language)
+		}
+	}
+	return chain
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+const (
+	PriceScheduleStatusPending   = "pending"
+	PriceScheduleStatusApplied   = "applied"
+	PriceScheduleStatusExpired   = "expired"
+	PriceScheduleStatusCancelled = "cancelled"
+)
+
+// PriceSchedule is a planned price and/or discount change for a product.
+// A schedule without EffectiveTo is permanent and gets written to the product
+// once it is due; a schedule with EffectiveTo is only overlaid while its window is open.
+//
+// Permanent schedules change the base price, so an open time-boxed schedule is always
+// laid over them, however their windows started. Among schedules of the same kind the
+// one that became effective last wins, field by field, ties are broken by id.
+type PriceSchedule struct {
+	Id            int64
+	ProductId     int64
+	Price         *float32
+	Discount      *float32
+	EffectiveFrom time.Time
+	EffectiveTo   *time.Time
+	Status        string
+}
+
+func (priceSchedule PriceSchedule) IsActiveAt(now time.Time) bool {
+	if priceSchedule.Status != PriceScheduleStatusPending {
+		return false
+	}
+	if now.Before(priceSchedule.EffectiveFrom) {
+		return false
+	}
+	return priceSchedule.EffectiveTo == nil || now.Before(*priceSchedule.EffectiveTo)
+}
+
+// ApplyTo returns the product with the price and discount of the schedule applied.
+func (priceSchedule PriceSchedule) ApplyTo(product Product) Product {
+	if priceSchedule.Price != nil {
+		product.Price = *priceSchedule.Price
+	}
+	if priceSchedule.Discount != nil {
+		product.Discount = *priceSchedule.Discount
+	}
+	return product
+}
+
+// ApplyPriceSchedules returns the product with the schedules active at now applied in their order of precedence.
+// Due permanent schedules are applied before the scheduler writes them, so the effective price does not change
+// when it does.
+func ApplyPriceSchedules(product Product, priceSchedules []PriceSchedule, now time.Time) Product {
+	active := make([]PriceSchedule, 0, len(priceSchedules))
+	for _, priceSchedule := range priceSchedules {
+		if priceSchedule.ProductId == product.Id && priceSchedule.IsActiveAt(now) {
+			active = append(active, priceSchedule)
+		}
+	}
+	slices.SortStableFunc(active, comparePriceSchedulePrecedence)
+	for _, priceSchedule := range active {
+		product = priceSchedule.ApplyTo(product)
+	}
+	return product
+}
+
+func comparePriceSchedulePrecedence(a PriceSchedule, b PriceSchedule) int {
+	if (a.EffectiveTo == nil) != (b.EffectiveTo == nil) {
+		if a.EffectiveTo == nil {
+			return -1
+		}
+		return 1
+	}
+	if c := a.EffectiveFrom.Compare(b.EffectiveFrom); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Id, b.Id)
+}
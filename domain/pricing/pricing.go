@@ -0,0 +1,67 @@
+package pricing
+
+import (
+	"math"
+	"strconv"
+)
+
+// Breakdown is the canonical price of a product. Amounts are rounded to cents, half away from zero.
+type Breakdown struct {
+	ListPrice       float64
+	DiscountPercent float64
+	DiscountAmount  float64
+	FinalPrice      float64
+}
+
+// Adjustment is applied on top of the product discount, in the order it was registered.
+// Taxes and promotions are meant to be added as adjustments.
+type Adjustment interface {
+	Apply(breakdown Breakdown) Breakdown
+}
+
+type Calculator struct {
+	adjustments []Adjustment
+}
+
+var DefaultCalculator = NewCalculator()
+
+func NewCalculator(adjustments ...Adjustment) *Calculator {
+	return &Calculator{adjustments: adjustments}
+}
+
+func (calculator *Calculator) Calculate(listPrice float32, discountPercent float32) Breakdown {
+	listPriceCents := toCents(toFloat64(listPrice))
+	discount := toFloat64(discountPercent)
+	discountCents := int64(math.Round(float64(listPriceCents) * discount / 100))
+
+	breakdown := Breakdown{
+		ListPrice:       fromCents(listPriceCents),
+		DiscountPercent: discount,
+		DiscountAmount:  fromCents(discountCents),
+		FinalPrice:      fromCents(listPriceCents - discountCents),
+	}
+
+	for _, adjustment := range calculator.adjustments {
+		breakdown = adjustment.Apply(breakdown)
+	}
+	return breakdown
+}
+
+// Round rounds an amount to cents, half away from zero.
+func Round(amount float64) float64 {
+	return fromCents(toCents(amount))
+}
+
+// toFloat64 widens a float32 using its shortest decimal form, so 19.99 stays 19.99 instead of 19.9899997711.
+func toFloat64(value float32) float64 {
+	widened, _ := strconv.ParseFloat(strconv.FormatFloat(float64(value), 'f', -1, 32), 64)
+	return widened
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -1,9 +1,32 @@
 package domain
 
+import (
+	"go-product-app/domain/pricing"
+	"time"
+)
+
 type Product struct {
-	Id       int64
-	Name     string
-	Price    float32
-	Discount float32
-	Store    string
+	Id           int64
+	Name         string
+	Description  string
+	Price        float32
+	Discount     float32
+	Store        string
+	Category     string
+	Status       string
+	Sku          string
+	Gtin         string
+	Options      []string
+	Attributes   map[string]interface{}
+	Translations map[string]LocalizedText
+	// DeletedAt is set while the product is soft deleted
+	DeletedAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy string
+	UpdatedBy string
+}
+
+func (product Product) PriceBreakdown() pricing.Breakdown {
+	return pricing.DefaultCalculator.Calculate(product.Price, product.Discount)
 }
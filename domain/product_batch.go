@@ -0,0 +1,32 @@
+package domain
+
+const (
+	// BatchModeAtomic applies every item of a batch or none of them.
+	BatchModeAtomic = "atomic"
+	// BatchModeBestEffort applies the items that succeed and reports the others.
+	BatchModeBestEffort = "best_effort"
+)
+
+const (
+	BatchItemSucceeded  = "succeeded"
+	BatchItemFailed     = "failed"
+	BatchItemNotApplied = "not_applied"
+)
+
+func IsValidBatchMode(mode string) bool {
+	return mode == BatchModeAtomic || mode == BatchModeBestEffort
+}
+
+// BatchItemResult reports the outcome of the item at Index of a batch request.
+// Items of an atomic batch that were valid but discarded because another item failed are NotApplied.
+type BatchItemResult struct {
+	Index  int
+	Id     int64
+	Status string
+	Error  string
+}
+
+type ProductPriceUpdate struct {
+	Id    int64
+	Price float32
+}
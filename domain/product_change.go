@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	ProductChangeInsert = "insert"
+	ProductChangeUpdate = "update"
+	// ProductChangeDelete is sent for soft deletes as well as for purges
+	ProductChangeDelete = "delete"
+	// ProductChangeResync is sent after the listener reconnected, changes made meanwhile were not received
+	ProductChangeResync = "resync"
+)
+
+// ProductChange is the notification the database sends after every write to a product, whichever instance made it.
+// It only tells which product changed, subscribers read the product again when they need its data.
+type ProductChange struct {
+	Operation string `json:"op"`
+	ProductId int64  `json:"id"`
+	Store     string `json:"store"`
+}
+
+func ParseProductChange(payload string) (ProductChange, error) {
+	var change ProductChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		return ProductChange{}, errors.New(fmt.Sprintf("Product change %s can not be read: %v", payload, err))
+	}
+	switch change.Operation {
+	case ProductChangeInsert, ProductChangeUpdate, ProductChangeDelete:
+	default:
+		return ProductChange{}, errors.New(fmt.Sprintf("Product change operation %s is not valid", change.Operation))
+	}
+	if change.ProductId <= 0 {
+		return ProductChange{}, errors.New(fmt.Sprintf("Product change %s has no product id", payload))
+	}
+	return change, nil
+}
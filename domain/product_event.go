@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ProductEventCreated      = "product.created"
+	ProductEventUpdated      = "product.updated"
+	ProductEventPriceChanged = "product.price_changed"
+	ProductEventDeleted      = "product.deleted"
+)
+
+const (
+	outboxRetryBaseDelay = time.Second
+	outboxRetryMaxDelay  = 5 * time.Minute
+)
+
+// ProductEvent is a product change recorded in the outbox together with the change itself.
+// Id orders the events, EventId stays the same when an event is published again so consumers can drop duplicates.
+// Store and Status are read from the payload so subscribers can filter without decoding it.
+type ProductEvent struct {
+	Id         int64
+	EventId    string
+	Type       string
+	ProductId  int64
+	Store      string
+	Status     string
+	Payload    json.RawMessage
+	OccurredAt time.Time
+	Attempts   int
+}
+
+// The payloads are built by the statements that change the products, with the keys of these json tags.
+
+type ProductCreatedPayload struct {
+	ProductId int64   `json:"product_id"`
+	Name      string  `json:"name"`
+	Price     float32 `json:"price"`
+	Discount  float32 `json:"discount"`
+	Store     string  `json:"store"`
+	Category  string  `json:"category"`
+	Status    string  `json:"status"`
+	Sku       string  `json:"sku"`
+	Actor     string  `json:"actor"`
+}
+
+// ProductUpdatedPayload is the product after an update of its fields or status, a price change of the update
+// is announced by its own product.price_changed event as well.
+type ProductUpdatedPayload struct {
+	ProductId int64   `json:"product_id"`
+	Name      string  `json:"name"`
+	Price     float32 `json:"price"`
+	Discount  float32 `json:"discount"`
+	Store     string  `json:"store"`
+	Category  string  `json:"category"`
+	Status    string  `json:"status"`
+	Sku       string  `json:"sku"`
+	Actor     string  `json:"actor"`
+}
+
+type PriceChangedPayload struct {
+	ProductId   int64   `json:"product_id"`
+	OldPrice    float32 `json:"old_price"`
+	NewPrice    float32 `json:"new_price"`
+	OldDiscount float32 `json:"old_discount"`
+	NewDiscount float32 `json:"new_discount"`
+	Store       string  `json:"store"`
+	Status      string  `json:"status"`
+	Actor       string  `json:"actor"`
+}
+
+type ProductDeletedPayload struct {
+	ProductId int64  `json:"product_id"`
+	Store     string `json:"store"`
+	Status    string `json:"status"`
+	Actor     string `json:"actor"`
+}
+
+// OutboxRetryDelay is how long the relay waits before publishing an event again after the given number of
+// failed attempts, doubling from a second up to five minutes.
+func OutboxRetryDelay(attempts int) time.Duration {
+	delay := outboxRetryBaseDelay
+	for i := 1; i < attempts && delay < outboxRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxRetryMaxDelay)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	ExportFormatCsv    = "csv"
+	ExportFormatNdjson = "ndjson"
+	ExportFormatXlsx   = "xlsx"
+)
+
+// ExportFields are the columns an export can select, in their default order. They share their names
+// with ImportFields so an exported file can be imported back.
+var ExportFields = []string{"id", "name", "description", "price", "discount", "store", "category", "status", "sku", "gtin", "options",
+	"created_at", "updated_at", "created_by", "updated_by"}
+
+func IsValidExportFormat(format string) bool {
+	return format == ExportFormatCsv || format == ExportFormatNdjson || format == ExportFormatXlsx
+}
+
+func ValidateExportFields(fields []string) error {
+	for i, field := range fields {
+		if !slices.Contains(ExportFields, field) {
+			return errors.New(fmt.Sprintf("Field %s can not be exported, exportable fields are %s", field, strings.Join(ExportFields, ", ")))
+		}
+		if slices.Contains(fields[:i], field) {
+			return errors.New(fmt.Sprintf("Field %s is selected more than once", field))
+		}
+	}
+	return nil
+}
+
+// ProductExportQuery selects the products of an export with Filter, every field is exported when Fields is empty.
+type ProductExportQuery struct {
+	Format string
+	Fields []string
+	Filter ProductFilter
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ProductFilter narrows product listings. Zero values do not filter.
+type ProductFilter struct {
+	Store    string
+	Category string
+	// MinPrice and MaxPrice keep products priced within the range, both ends included.
+	MinPrice *float32
+	MaxPrice *float32
+	// Statuses keeps products in any of these lifecycle statuses.
+	Statuses []string
+	// UpdatedSince keeps products modified at or after this time, for incremental pulls. Writes to the variants and
+	// media of a product count as modifications, changes to attribute definitions do not change any product.
+	// The time of a modification is the start of its transaction, so a change committed just after a pull can be
+	// older than the newest product of that pull. Pulls should overlap, e.g. by a minute, and drop what they have seen.
+	UpdatedSince *time.Time
+	// IncludeRemoved, together with UpdatedSince, also keeps the modified products that are soft deleted or not in
+	// Statuses any more, so an incremental pull learns about them. Purged products are gone for good.
+	IncludeRemoved bool
+	// VariantAttributes keeps products that have at least one variant with all of these attribute values.
+	VariantAttributes map[string]string
+	// AttributeConditions keeps products whose custom attributes satisfy every condition.
+	AttributeConditions []AttributeCondition
+}
+
+const (
+	FilterOperatorEq  = "eq"
+	FilterOperatorGt  = "gt"
+	FilterOperatorGte = "gte"
+	FilterOperatorLt  = "lt"
+	FilterOperatorLte = "lte"
+)
+
+type AttributeCondition struct {
+	Name     string
+	Operator string
+	Value    string
+	// Type is the declared type of the attribute, resolved from the attribute definitions before the filter runs.
+	Type string
+}
+
+// TypedValue reads the value as the JSON type the attribute is declared with, enum values are strings.
+func (attributeCondition AttributeCondition) TypedValue() (interface{}, error) {
+	switch attributeCondition.Type {
+	case AttributeTypeNumber:
+		number, err := strconv.ParseFloat(attributeCondition.Value, 64)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Attribute %s should be compared with a number", attributeCondition.Name))
+		}
+		return number, nil
+	case AttributeTypeBoolean:
+		boolean, err := strconv.ParseBool(attributeCondition.Value)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Attribute %s should be compared with true or false", attributeCondition.Name))
+		}
+		return boolean, nil
+	case AttributeTypeString, AttributeTypeEnum:
+		return attributeCondition.Value, nil
+	}
+	return nil, errors.New(fmt.Sprintf("Type of attribute %s is not known", attributeCondition.Name))
+}
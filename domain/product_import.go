@@ -0,0 +1,27 @@
+package domain
+
+const (
+	ImportFormatCsv    = "csv"
+	ImportFormatNdjson = "ndjson"
+)
+
+const (
+	ImportUpsertBySku = "sku"
+	ImportUpsertById  = "id"
+)
+
+// ImportFields are the product fields a column of an import file can be mapped to.
+var ImportFields = []string{"id", "name", "description", "price", "discount", "store", "category", "status", "sku", "gtin", "options"}
+
+// ProductImportReport sums up an import. Created and Updated count the rows that would be written in a dry run.
+// ErrorReportUrl points to a csv listing every failed row, it is empty when no row failed. Truncated tells that
+// reading stopped at a malformed ndjson line, the lines after it are neither imported nor counted.
+type ProductImportReport struct {
+	DryRun         bool
+	Rows           int
+	Created        int
+	Updated        int
+	Failed         int
+	Truncated      bool
+	ErrorReportUrl string
+}
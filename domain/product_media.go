@@ -0,0 +1,19 @@
+package domain
+
+// ProductMedia is an image attached to a product. Url and ThumbnailUrl are resolved
+// from the storage keys when the media is read and are not persisted.
+type ProductMedia struct {
+	Id           int64
+	ProductId    int64
+	Key          string
+	ThumbnailKey string
+	ContentType  string
+	Size         int64
+	Width        int
+	Height       int
+	Checksum     string
+	Position     int
+	IsPrimary    bool
+	Url          string
+	ThumbnailUrl string
+}
@@ -0,0 +1,35 @@
+package domain
+
+const (
+	SearchLanguageEnglish = "en"
+	SearchLanguageTurkish = "tr"
+)
+
+func IsSupportedSearchLanguage(language string) bool {
+	return language == SearchLanguageEnglish || language == SearchLanguageTurkish
+}
+
+// ProductSearchQuery is a free text search narrowed by the usual listing filter.
+type ProductSearchQuery struct {
+	Text string
+	// Language picks the stemming rules, see the SearchLanguage constants
+	Language string
+	Filter   ProductFilter
+	Limit    int
+	Offset   int
+}
+
+type ProductSearchHit struct {
+	Product Product
+	Rank    float64
+	// Snippet is the matching text escaped for html with the matched words wrapped in <b></b>
+	Snippet string
+}
+
+type ProductSearchResult struct {
+	Hits []ProductSearchHit
+	// Total is the number of matches regardless of Limit and Offset
+	Total  int64
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "fmt"
+
+const (
+	StatsDimensionStore        = "store"
+	StatsDimensionCategory     = "category"
+	StatsDimensionDiscountBand = "discount_band"
+)
+
+const NoDiscountBand = "none"
+
+// DiscountBandBounds are the upper bounds of the discount bands, the last band is open ended.
+var DiscountBandBounds = []float32{10, 20, 30, 50}
+
+func IsValidStatsDimension(dimension string) bool {
+	return dimension == StatsDimensionStore || dimension == StatsDimensionCategory || dimension == StatsDimensionDiscountBand
+}
+
+// DiscountBands lists the band labels in order, like "none", "0-10", ..., "50+".
+func DiscountBands() []string {
+	bands := []string{NoDiscountBand}
+	lowerBound := float32(0)
+	for _, upperBound := range DiscountBandBounds {
+		bands = append(bands, fmt.Sprintf("%g-%g", lowerBound, upperBound))
+		lowerBound = upperBound
+	}
+	return append(bands, fmt.Sprintf("%g+", lowerBound))
+}
+
+func DiscountBandOf(discount float32) string {
+	if discount == 0 {
+		return NoDiscountBand
+	}
+	bands := DiscountBands()
+	for i, upperBound := range DiscountBandBounds {
+		if discount < upperBound {
+			return bands[i+1]
+		}
+	}
+	return bands[len(bands)-1]
+}
+
+type ProductStatsQuery struct {
+	GroupBy []string
+	Filter  ProductFilter
+}
+
+// ProductStatsGroup holds the metrics of the products sharing the same Keys, keyed by dimension.
+// Without any dimension there is a single group covering every product.
+type ProductStatsGroup struct {
+	Keys            map[string]string
+	Count           int64
+	AveragePrice    float64
+	MinPrice        float64
+	MaxPrice        float64
+	AverageDiscount float64
+}
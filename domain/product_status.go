@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+const (
+	ProductStatusDraft        = "draft"
+	ProductStatusActive       = "active"
+	ProductStatusDiscontinued = "discontinued"
+	ProductStatusArchived     = "archived"
+)
+
+// productStatusTransitions lists the statuses a product may move to from each status. Archived is final.
+var productStatusTransitions = map[string][]string{
+	ProductStatusDraft:        {ProductStatusActive, ProductStatusArchived},
+	ProductStatusActive:       {ProductStatusDiscontinued, ProductStatusArchived},
+	ProductStatusDiscontinued: {ProductStatusActive, ProductStatusArchived},
+	ProductStatusArchived:     {},
+}
+
+func IsValidProductStatus(status string) bool {
+	_, found := productStatusTransitions[status]
+	return found
+}
+
+func CanTransitionProductStatus(from string, to string) bool {
+	for _, allowed := range productStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+type ProductStatusChange struct {
+	Id         int64
+	ProductId  int64
+	FromStatus string
+	ToStatus   string
+	Actor      string
+	Reason     string
+	ChangedAt  time.Time
+}
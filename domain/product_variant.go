@@ -0,0 +1,20 @@
+package domain
+
+// ProductVariant is a purchasable option combination of a parent product, e.g. size M in red.
+// Attributes has one value for every option axis of the parent.
+type ProductVariant struct {
+	Id            int64
+	ProductId     int64
+	Sku           string
+	PriceOverride *float32
+	Stock         int
+	Attributes    map[string]string
+}
+
+// EffectivePrice is the variant price, falling back to the parent price when there is no override.
+func (productVariant ProductVariant) EffectivePrice(parent Product) float32 {
+	if productVariant.PriceOverride != nil {
+		return *productVariant.PriceOverride
+	}
+	return parent.Price
+}
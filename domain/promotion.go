@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	PromotionTypePercentOff = "percent_off"
+	PromotionTypeBuyXGetY   = "buy_x_get_y"
+	PromotionTypeBundle     = "bundle"
+)
+
+// Promotion is a discount rule. Empty Store and Category and nil price bounds match every product.
+// A bundle only matches its BundleProductIds and takes Percent off the units bought together.
+type Promotion struct {
+	Id               int64
+	Name             string
+	Type             string
+	Store            string
+	Category         string
+	MinPrice         *float32
+	MaxPrice         *float32
+	Percent          float32
+	BuyQuantity      int
+	FreeQuantity     int
+	BundleProductIds []int64
+	Priority         int
+	Stackable        bool
+	ValidFrom        time.Time
+	ValidTo          *time.Time
+}
+
+func (promotion Promotion) IsValidAt(now time.Time) bool {
+	if now.Before(promotion.ValidFrom) {
+		return false
+	}
+	return promotion.ValidTo == nil || now.Before(*promotion.ValidTo)
+}
+
+func (promotion Promotion) Matches(product Product) bool {
+	if len(promotion.BundleProductIds) > 0 && !slices.Contains(promotion.BundleProductIds, product.Id) {
+		return false
+	}
+	if len(promotion.Store) > 0 && promotion.Store != product.Store {
+		return false
+	}
+	if len(promotion.Category) > 0 && promotion.Category != product.Category {
+		return false
+	}
+	if promotion.MinPrice != nil && product.Price < *promotion.MinPrice {
+		return false
+	}
+	if promotion.MaxPrice != nil && product.Price > *promotion.MaxPrice {
+		return false
+	}
+	return true
+}
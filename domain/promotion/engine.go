@@ -0,0 +1,195 @@
+package promotion
+
+import (
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/domain/pricing"
+	"sort"
+	"time"
+)
+
+type Line struct {
+	Product  domain.Product
+	Quantity int
+}
+
+// RuleResult explains what a matching promotion did to a line.
+type RuleResult struct {
+	PromotionId int64
+	Name        string
+	Applied     bool
+	Discount    float64
+	Reason      string
+}
+
+type LineEvaluation struct {
+	Product           domain.Product
+	Quantity          int
+	UnitPrice         pricing.Breakdown
+	LineTotal         float64
+	PromotionDiscount float64
+	FinalTotal        float64
+	Rules             []RuleResult
+}
+
+type Evaluation struct {
+	Lines             []LineEvaluation
+	Subtotal          float64
+	PromotionDiscount float64
+	Total             float64
+}
+
+// Evaluate applies the promotions valid at now to every line. Rules are tried by descending
+// priority; stackable rules compound on what is left of the line total, a non-stackable rule
+// only applies when nothing else has and then stops further rules on that line.
+func Evaluate(lines []Line, promotions []domain.Promotion, now time.Time) Evaluation {
+	orderedPromotions := make([]domain.Promotion, 0, len(promotions))
+	for _, candidate := range promotions {
+		if candidate.IsValidAt(now) {
+			orderedPromotions = append(orderedPromotions, candidate)
+		}
+	}
+	sort.SliceStable(orderedPromotions, func(i, j int) bool {
+		if orderedPromotions[i].Priority != orderedPromotions[j].Priority {
+			return orderedPromotions[i].Priority > orderedPromotions[j].Priority
+		}
+		return orderedPromotions[i].Id < orderedPromotions[j].Id
+	})
+
+	unitsLeft := bundleUnits(lines, orderedPromotions)
+	evaluation := Evaluation{Lines: make([]LineEvaluation, 0, len(lines))}
+	for _, line := range lines {
+		lineEvaluation := evaluateLine(line, orderedPromotions, unitsLeft)
+		evaluation.Lines = append(evaluation.Lines, lineEvaluation)
+		evaluation.Subtotal += lineEvaluation.LineTotal
+		evaluation.PromotionDiscount += lineEvaluation.PromotionDiscount
+	}
+	evaluation.Subtotal = pricing.Round(evaluation.Subtotal)
+	evaluation.PromotionDiscount = pricing.Round(evaluation.PromotionDiscount)
+	evaluation.Total = pricing.Round(evaluation.Subtotal - evaluation.PromotionDiscount)
+	return evaluation
+}
+
+// bundleUnits counts the complete bundles in the basket per bundle promotion, every product of a bundle has that many
+// units left to discount. Lines of the same product share the units, so a bundle is never discounted twice.
+func bundleUnits(lines []Line, orderedPromotions []domain.Promotion) map[int64]map[int64]int {
+	unitsLeft := make(map[int64]map[int64]int)
+	for _, candidate := range orderedPromotions {
+		if candidate.Type != domain.PromotionTypeBundle || len(candidate.BundleProductIds) == 0 {
+			continue
+		}
+
+		quantities := make(map[int64]int)
+		for _, line := range lines {
+			if candidate.Matches(line.Product) {
+				quantities[line.Product.Id] += line.Quantity
+			}
+		}
+		bundles := quantities[candidate.BundleProductIds[0]]
+		for _, productId := range candidate.BundleProductIds[1:] {
+			bundles = min(bundles, quantities[productId])
+		}
+
+		unitsLeft[candidate.Id] = make(map[int64]int, len(candidate.BundleProductIds))
+		for _, productId := range candidate.BundleProductIds {
+			unitsLeft[candidate.Id][productId] = bundles
+		}
+	}
+	return unitsLeft
+}
+
+func evaluateLine(line Line, orderedPromotions []domain.Promotion, unitsLeft map[int64]map[int64]int) LineEvaluation {
+	unitPrice := line.Product.PriceBreakdown()
+	lineTotal := pricing.Round(unitPrice.FinalPrice * float64(line.Quantity))
+
+	lineEvaluation := LineEvaluation{
+		Product:   line.Product,
+		Quantity:  line.Quantity,
+		UnitPrice: unitPrice,
+		LineTotal: lineTotal,
+		Rules:     make([]RuleResult, 0),
+	}
+
+	remaining := lineTotal
+	var blockedBy *domain.Promotion
+	for i, candidate := range orderedPromotions {
+		if !candidate.Matches(line.Product) {
+			continue
+		}
+
+		result := RuleResult{PromotionId: candidate.Id, Name: candidate.Name}
+		switch {
+		case blockedBy != nil:
+			result.Reason = fmt.Sprintf("not combinable with %q", blockedBy.Name)
+		case !candidate.Stackable && anyApplied(lineEvaluation.Rules):
+			result.Reason = "not stackable with promotions already applied"
+		default:
+			quantity := line.Quantity
+			if candidate.Type == domain.PromotionTypeBundle {
+				quantity = min(quantity, unitsLeft[candidate.Id][line.Product.Id])
+			}
+			discount := ruleDiscount(candidate, unitPrice.FinalPrice, quantity, remaining)
+			if discount <= 0 {
+				result.Reason = "conditions met but no discount for this quantity"
+				break
+			}
+			result.Applied = true
+			result.Discount = discount
+			result.Reason = describe(candidate)
+			remaining = pricing.Round(remaining - discount)
+			if candidate.Type == domain.PromotionTypeBundle {
+				unitsLeft[candidate.Id][line.Product.Id] -= quantity
+			}
+			if !candidate.Stackable {
+				blockedBy = &orderedPromotions[i]
+			}
+		}
+		lineEvaluation.Rules = append(lineEvaluation.Rules, result)
+	}
+
+	lineEvaluation.PromotionDiscount = pricing.Round(lineTotal - remaining)
+	lineEvaluation.FinalTotal = remaining
+	return lineEvaluation
+}
+
+func ruleDiscount(candidate domain.Promotion, unitPrice float64, quantity int, remaining float64) float64 {
+	var discount float64
+	switch candidate.Type {
+	case domain.PromotionTypePercentOff:
+		discount = pricing.Round(remaining * float64(candidate.Percent) / 100)
+	case domain.PromotionTypeBuyXGetY:
+		groupSize := candidate.BuyQuantity + candidate.FreeQuantity
+		if groupSize <= 0 {
+			return 0
+		}
+		freeUnits := quantity / groupSize * candidate.FreeQuantity
+		discount = pricing.Round(unitPrice * float64(freeUnits))
+	case domain.PromotionTypeBundle:
+		discount = pricing.Round(unitPrice * float64(quantity) * float64(candidate.Percent) / 100)
+	}
+	if discount > remaining {
+		return remaining
+	}
+	return discount
+}
+
+func describe(candidate domain.Promotion) string {
+	switch candidate.Type {
+	case domain.PromotionTypePercentOff:
+		return fmt.Sprintf("%g%% off", candidate.Percent)
+	case domain.PromotionTypeBuyXGetY:
+		return fmt.Sprintf("buy %d get %d free", candidate.BuyQuantity, candidate.FreeQuantity)
+	case domain.PromotionTypeBundle:
+		return fmt.Sprintf("%g%% off when bought with the rest of the bundle", candidate.Percent)
+	}
+	return candidate.Type
+}
+
+func anyApplied(rules []RuleResult) bool {
+	for _, rule := range rules {
+		if rule.Applied {
+			return true
+		}
+	}
+	return false
+}
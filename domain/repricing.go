@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// RepricingPercentChange raises or lowers the price by Value percent.
+	RepricingPercentChange = "percent_change"
+	// RepricingAbsoluteChange adds Value, which may be negative, to the price.
+	RepricingAbsoluteChange = "absolute_change"
+	RepricingSetPrice       = "set_price"
+	RepricingSetDiscount    = "set_discount"
+	// RepricingRoundTo99 moves the price to the nearest price ending in .99, Value is not used.
+	RepricingRoundTo99 = "round_to_99"
+)
+
+const (
+	RepricingStatusApplied = "applied"
+	RepricingStatusUndone  = "undone"
+)
+
+// The reasons of the price history, every write that changes the stored price or discount of a product records one.
+const (
+	PriceChangeReasonUpdate    = "update"
+	PriceChangeReasonSchedule  = "price_schedule"
+	PriceChangeReasonRepricing = "repricing"
+	PriceChangeReasonUndo      = "repricing_undo"
+)
+
+func IsValidRepricingOperation(operationType string) bool {
+	switch operationType {
+	case RepricingPercentChange, RepricingAbsoluteChange, RepricingSetPrice, RepricingSetDiscount, RepricingRoundTo99:
+		return true
+	}
+	return false
+}
+
+type RepricingOperation struct {
+	Type  string
+	Value float32
+}
+
+// Apply returns the price and discount after the operation, computed prices are rounded to cents.
+func (operation RepricingOperation) Apply(price float32, discount float32) (float32, float32) {
+	switch operation.Type {
+	case RepricingPercentChange:
+		return roundToCents(float64(price) * (1 + float64(operation.Value)/100)), discount
+	case RepricingAbsoluteChange:
+		return roundToCents(float64(price) + float64(operation.Value)), discount
+	case RepricingSetPrice:
+		return operation.Value, discount
+	case RepricingSetDiscount:
+		return price, operation.Value
+	case RepricingRoundTo99:
+		return roundToCents(math.Round(float64(price)+0.01) - 0.01), discount
+	}
+	return price, discount
+}
+
+func roundToCents(value float64) float32 {
+	return float32(math.Round(value*100) / 100)
+}
+
+// PlanRepricing returns the changes the operation makes to the products, products it leaves as they are
+// are not part of the plan. It fails when any product would end up without a positive price.
+func PlanRepricing(products []Product, operation RepricingOperation) ([]PriceChange, error) {
+	changes := make([]PriceChange, 0, len(products))
+	for _, product := range products {
+		price, discount := operation.Apply(product.Price, product.Discount)
+		if price <= 0 {
+			return nil, errors.New(fmt.Sprintf("Repricing would set the price of product %d to %.2f", product.Id, price))
+		}
+		if price == product.Price && discount == product.Discount {
+			continue
+		}
+		changes = append(changes, PriceChange{
+			ProductId:   product.Id,
+			ProductName: product.Name,
+			OldPrice:    product.Price,
+			NewPrice:    price,
+			OldDiscount: product.Discount,
+			NewDiscount: discount,
+		})
+	}
+	return changes, nil
+}
+
+// Repricing is an executed repricing command. Undoing it restores the products that were not changed since.
+type Repricing struct {
+	Id        int64
+	Filter    ProductFilter
+	Operation RepricingOperation
+	Status    string
+	// Affected counts the products whose price or discount was changed
+	Affected int
+	// Reverted and Skipped count the products an undo restored and the ones it left alone
+	Reverted  int
+	Skipped   int
+	CreatedBy string
+	CreatedAt time.Time
+	UndoneBy  string
+	UndoneAt  *time.Time
+}
+
+type RepricingPreview struct {
+	// Matched counts the products selected by the filter, including the ones the operation leaves unchanged
+	Matched int
+	Changes []PriceChange
+}
+
+type PriceChange struct {
+	Id          int64
+	ProductId   int64
+	ProductName string
+	RepricingId int64
+	Reason      string
+	OldPrice    float32
+	NewPrice    float32
+	OldDiscount float32
+	NewDiscount float32
+	Actor       string
+	ChangedAt   time.Time
+}
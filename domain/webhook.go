@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+const (
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = time.Hour
+)
+
+var ProductEventTypes = []string{ProductEventCreated, ProductEventUpdated, ProductEventPriceChanged, ProductEventDeleted}
+
+func IsValidProductEventType(eventType string) bool {
+	return slices.Contains(ProductEventTypes, eventType)
+}
+
+// Webhook is a partner subscription to product events. An empty Store receives the events of every store.
+// A webhook is deactivated once ConsecutiveFailures reaches the configured limit and its deliveries wait until it is activated again.
+type Webhook struct {
+	Id                  int64
+	Url                 string
+	EventTypes          []string
+	Store               string
+	Secret              string
+	Active              bool
+	ConsecutiveFailures int
+	DisabledAt          *time.Time
+	CreatedBy           string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+type WebhookDelivery struct {
+	Id          int64
+	WebhookId   int64
+	EventId     string
+	EventType   string
+	ProductId   int64
+	Body        string
+	Status      string
+	Attempts    int
+	NextAttempt time.Time
+	// LastStatusCode is 0 when the receiver could not be reached
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+	// Url and Secret are only loaded for the deliveries being sent
+	Url    string
+	Secret string
+}
+
+// WebhookRetryDelay is the exponential backoff before the next attempt of a delivery that failed attempts times.
+func WebhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempts && delay < webhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookRetryMaxDelay)
+}
+
+// SignWebhookBody returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>" with the secret of the webhook.
+// Signing the timestamp along with the body lets receivers reject replayed deliveries.
+func SignWebhookBody(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
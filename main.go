@@ -4,7 +4,10 @@ import (
 	"context"
 	"github.com/labstack/echo/v4"
 	"go-product-app/common/app"
+	"go-product-app/common/events"
 	"go-product-app/common/postgresql"
+	"go-product-app/common/search"
+	"go-product-app/common/storage"
 	"go-product-app/controller"
 	"go-product-app/persistence"
 	"go-product-app/service"
@@ -18,13 +21,113 @@ func main() {
 
 	//db - repo - service - controller
 	dbPool := postgresql.GetConnectionPool(ctx, configurationManager.PostgreSqlConfig)
-	productRepository := persistence.NewProductRepository(dbPool)
-	productService := service.NewProductService(productRepository)
-	productController := controller.NewProductController(productService)
+	var productRepository persistence.IProductRepository = persistence.NewProductRepository(dbPool)
+	var productCache *persistence.CachingProductRepository
+	if productCacheConfig := configurationManager.ProductCacheConfig; productCacheConfig.Enabled {
+		productCache = persistence.NewCachingProductRepository(productRepository, productCacheConfig.MaxProducts, productCacheConfig.MaxStores, productCacheConfig.Ttl)
+		productRepository = productCache
+	}
+	priceScheduleRepository := persistence.NewPriceScheduleRepository(dbPool)
+	promotionRepository := persistence.NewPromotionRepository(dbPool)
+	productVariantRepository := persistence.NewProductVariantRepository(dbPool)
+	attributeDefinitionRepository := persistence.NewAttributeDefinitionRepository(dbPool)
+	productMediaRepository := persistence.NewProductMediaRepository(dbPool)
+	jobRepository := persistence.NewJobRepository(dbPool)
+	outboxRepository := persistence.NewOutboxRepository(dbPool)
+	webhookRepository := persistence.NewWebhookRepository(dbPool)
+
+	mediaStorageConfig := configurationManager.MediaStorageConfig
+	mediaStorage := storage.NewLocalStorage(mediaStorageConfig.Root, mediaStorageConfig.BaseUrl)
+	fileExchangeConfig := configurationManager.FileExchangeConfig
+	fileStorage := storage.NewLocalStorage(fileExchangeConfig.Root, fileExchangeConfig.BaseUrl)
+	uploadStorage := storage.NewLocalStorage(fileExchangeConfig.UploadRoot, "")
+
+	searchIndex := search.NewMemoryIndex(search.DefaultPriceBuckets)
+	productStreamConfig := configurationManager.ProductStreamConfig
+	productEventBroadcaster := events.NewBroadcaster(productStreamConfig.BacklogSize, productStreamConfig.BufferSize)
+	productService := service.NewProductService(productRepository, priceScheduleRepository, attributeDefinitionRepository, configurationManager.LocalizationConfig, searchIndex)
+	priceScheduleService := service.NewPriceScheduleService(priceScheduleRepository, productService)
+	promotionService := service.NewPromotionService(promotionRepository, productService)
+	quoteService := service.NewQuoteService(productService, promotionRepository)
+	productVariantService := service.NewProductVariantService(productVariantRepository, productRepository)
+	attributeDefinitionService := service.NewAttributeDefinitionService(attributeDefinitionRepository)
+	productMediaService := service.NewProductMediaService(productMediaRepository, productRepository, mediaStorage, mediaStorageConfig.MaxUploadSize)
+	productImporter := service.NewProductImporter(productService, productRepository, fileStorage, configurationManager.LocalizationConfig)
+	jobService := service.NewJobService(jobRepository)
+	productJobService := service.NewProductJobService(jobService, productService, productImporter, uploadStorage, fileStorage)
+	webhookService := service.NewWebhookService(webhookRepository)
+
+	productController := controller.NewProductController(productService, productJobService, productVariantService, productMediaService, configurationManager.LocalizationConfig)
+	priceScheduleController := controller.NewPriceScheduleController(priceScheduleService)
+	promotionController := controller.NewPromotionController(promotionService)
+	quoteController := controller.NewQuoteController(quoteService)
+	productVariantController := controller.NewProductVariantController(productVariantService, productService)
+	attributeDefinitionController := controller.NewAttributeDefinitionController(attributeDefinitionService)
+	productMediaController := controller.NewProductMediaController(productMediaService)
+	productImportController := controller.NewProductImportController(productImporter, productJobService)
+	jobController := controller.NewJobController(jobService)
+	repricingController := controller.NewRepricingController(productService)
+	webhookController := controller.NewWebhookController(webhookService)
+	productStreamController := controller.NewProductStreamController(productEventBroadcaster, productStreamConfig.Heartbeat)
+	cacheController := controller.NewCacheController(productCache)
 
 	productController.RegisterRoutes(e)
+	priceScheduleController.RegisterRoutes(e)
+	promotionController.RegisterRoutes(e)
+	quoteController.RegisterRoutes(e)
+	productVariantController.RegisterRoutes(e)
+	attributeDefinitionController.RegisterRoutes(e)
+	productMediaController.RegisterRoutes(e)
+	productImportController.RegisterRoutes(e)
+	jobController.RegisterRoutes(e)
+	repricingController.RegisterRoutes(e)
+	webhookController.RegisterRoutes(e)
+	productStreamController.RegisterRoutes(e)
+	cacheController.RegisterRoutes(e)
+	e.Static(mediaStorageConfig.BaseUrl, mediaStorageConfig.Root)
+	e.Static(fileExchangeConfig.BaseUrl, fileExchangeConfig.Root)
+
+	//background jobs
+	productChangeListener := persistence.NewProductChangeListener(dbPool, configurationManager.ProductChangeConfig.ReconnectDelay)
+	if productCache != nil {
+		// the cache is invalidated first so that the search index reads the changed product
+		productChangeListener.Subscribe(productCache.Invalidate)
+	}
+	searchIndexRefresher := service.NewSearchIndexRefresher(productService)
+	productChangeListener.Subscribe(searchIndexRefresher.HandleChange)
+	// the relay runs on one instance at a time, every instance streams the events it published
+	productEventFeed := service.NewProductEventFeed(outboxRepository, productEventBroadcaster, productStreamConfig.BacklogSize)
+	productChangeListener.Subscribe(productEventFeed.HandleChange)
+	productChangeListener.SubscribePublishedEvents(productEventFeed.Notify)
+	productEventFeed.Start(ctx)
+	productChangeListener.Start(ctx)
+	// the index is rebuilt once the listener listens, the changes committed meanwhile are applied after the rebuild
+	<-productChangeListener.Listening()
+	err := productService.RebuildSearchIndex()
+	if err != nil {
+		panic(err)
+	}
+	searchIndexRefresher.Start(ctx)
+	service.NewPriceScheduler(priceScheduleRepository, configurationManager.PriceSchedulerConfig.Interval).Start(ctx)
+	productPurgeConfig := configurationManager.ProductPurgeConfig
+	service.NewProductPurger(productRepository, mediaStorage, productPurgeConfig.Retention, productPurgeConfig.Interval).Start(ctx)
+	jobWorkerConfig := configurationManager.JobWorkerConfig
+	jobWorker := service.NewJobWorker(jobRepository, jobWorkerConfig.Concurrency, jobWorkerConfig.PollInterval, jobWorkerConfig.Lease)
+	jobWorker.Handle(service.JobTypeProductImport, productJobService.HandleImport)
+	jobWorker.Handle(service.JobTypeProductExport, productJobService.HandleExport)
+	jobWorker.HandleFinished(service.JobTypeProductImport, productJobService.FinishImport)
+	jobService.HandleFinished(service.JobTypeProductImport, productJobService.FinishImport)
+	jobWorker.Start(ctx)
+	outboxRelayConfig := configurationManager.OutboxRelayConfig
+	service.NewOutboxRelay(outboxRepository, service.NewWebhookPublisher(webhookRepository), outboxRelayConfig.Interval, outboxRelayConfig.BatchSize).Start(ctx)
+	outboxPurgeConfig := configurationManager.OutboxPurgeConfig
+	service.NewOutboxPurger(outboxRepository, outboxPurgeConfig.Retention, outboxPurgeConfig.Interval).Start(ctx)
+	webhookConfig := configurationManager.WebhookConfig
+	webhookClient := service.NewWebhookClient(webhookConfig.Timeout)
+	service.NewWebhookDispatcher(webhookRepository, webhookClient, webhookConfig.Interval, webhookConfig.BatchSize,
+		webhookConfig.Lease, webhookConfig.MaxAttempts, webhookConfig.DisableAfterFailures).Start(ctx)
 
-	err := e.Start("localhost:8080")
+	err = e.Start("localhost:8080")
 	if err != nil {
 		panic(err)
 	}
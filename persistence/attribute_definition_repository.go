@@ -0,0 +1,116 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+)
+
+const attributeDefinitionColumns = `id, category, name, type, required, enum_values, unit`
+
+type IAttributeDefinitionRepository interface {
+	Add(attributeDefinition domain.AttributeDefinition) error
+	GetAll() ([]domain.AttributeDefinition, error)
+	GetByCategory(category string) ([]domain.AttributeDefinition, error)
+	DeleteById(id int64) error
+}
+
+type AttributeDefinitionRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewAttributeDefinitionRepository(dbPool *pgxpool.Pool) IAttributeDefinitionRepository {
+	return &AttributeDefinitionRepository{dbPool: dbPool}
+}
+
+func (attributeDefinitionRepository *AttributeDefinitionRepository) Add(attributeDefinition domain.AttributeDefinition) error {
+	ctx := context.Background()
+
+	sqlCommand := `INSERT INTO attribute_definitions(category, name, type, required, enum_values, unit) VALUES($1, $2, $3, $4, $5, $6)`
+
+	_, err := attributeDefinitionRepository.dbPool.Exec(ctx, sqlCommand,
+		attributeDefinition.Category,
+		attributeDefinition.Name,
+		attributeDefinition.Type,
+		attributeDefinition.Required,
+		attributeDefinition.EnumValues,
+		attributeDefinition.Unit)
+	if _, duplicate := isUniqueViolation(err); duplicate {
+		return ConflictError{Message: fmt.Sprintf("Attribute %s is already defined for category %s", attributeDefinition.Name, attributeDefinition.Category)}
+	}
+	if err != nil {
+		log.Errorf("Error while inserting attribute definition: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (attributeDefinitionRepository *AttributeDefinitionRepository) GetAll() ([]domain.AttributeDefinition, error) {
+	ctx := context.Background()
+
+	rows, err := attributeDefinitionRepository.dbPool.Query(ctx, `SELECT `+attributeDefinitionColumns+` FROM attribute_definitions ORDER BY category, name`)
+	if err != nil {
+		log.Errorf("Error while fetching attribute definitions: %v", err)
+		return []domain.AttributeDefinition{}, err
+	}
+
+	return extractAttributeDefinitionsFromRows(rows)
+}
+
+func (attributeDefinitionRepository *AttributeDefinitionRepository) GetByCategory(category string) ([]domain.AttributeDefinition, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + attributeDefinitionColumns + ` FROM attribute_definitions WHERE category = $1 ORDER BY name`
+
+	rows, err := attributeDefinitionRepository.dbPool.Query(ctx, query, category)
+	if err != nil {
+		log.Errorf("Error while fetching attribute definitions of category %s: %v", category, err)
+		return []domain.AttributeDefinition{}, err
+	}
+
+	return extractAttributeDefinitionsFromRows(rows)
+}
+
+func (attributeDefinitionRepository *AttributeDefinitionRepository) DeleteById(id int64) error {
+	ctx := context.Background()
+
+	exec, err := attributeDefinitionRepository.dbPool.Exec(ctx, `DELETE FROM attribute_definitions WHERE id = $1`, id)
+	if err != nil {
+		log.Errorf("Error while deleting attribute definition with id:%d %v", id, err)
+		return errors.New(fmt.Sprintf("Error while deleting attribute definition with id %d", id))
+	}
+	if exec.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Attribute definition with id %d not found", id))
+	}
+
+	return nil
+}
+
+func extractAttributeDefinitionsFromRows(rows pgx.Rows) ([]domain.AttributeDefinition, error) {
+	defer rows.Close()
+
+	var attributeDefinitions []domain.AttributeDefinition
+	for rows.Next() {
+		var attributeDefinition domain.AttributeDefinition
+		err := rows.Scan(&attributeDefinition.Id,
+			&attributeDefinition.Category,
+			&attributeDefinition.Name,
+			&attributeDefinition.Type,
+			&attributeDefinition.Required,
+			&attributeDefinition.EnumValues,
+			&attributeDefinition.Unit)
+		if err != nil {
+			log.Errorf("Error while scanning attribute definition rows: %v", err)
+			return []domain.AttributeDefinition{}, err
+		}
+
+		attributeDefinitions = append(attributeDefinitions, attributeDefinition)
+	}
+
+	return attributeDefinitions, rows.Err()
+}
@@ -0,0 +1,205 @@
+package persistence
+
+import (
+	"go-product-app/common/cache"
+	"go-product-app/domain"
+	"maps"
+	"slices"
+	"time"
+)
+
+// CachingProductRepository serves GetById and GetAllByStore from memory and passes everything else through.
+// Writes made through it invalidate the cache at once. Writes made elsewhere, by the price scheduler or other
+// instances, reach it through Invalidate when it is subscribed to the ProductChangeListener, and at the latest
+// when the entries expire.
+type CachingProductRepository struct {
+	productRepository IProductRepository
+	byId              *cache.ReadThrough[int64, domain.Product]
+	byStore           *cache.ReadThrough[string, []domain.Product]
+}
+
+func NewCachingProductRepository(productRepository IProductRepository, maxProducts int, maxStores int, ttl time.Duration) *CachingProductRepository {
+	return &CachingProductRepository{
+		productRepository: productRepository,
+		byId:              cache.NewReadThrough[int64, domain.Product](maxProducts, ttl),
+		byStore:           cache.NewReadThrough[string, []domain.Product](maxStores, ttl),
+	}
+}
+
+// Invalidate drops what a product change made stale. Any change can move a product between stores,
+// so the cached stores are all dropped.
+func (cachingProductRepository *CachingProductRepository) Invalidate(change domain.ProductChange) {
+	if change.Operation == domain.ProductChangeResync {
+		cachingProductRepository.invalidateAll()
+		return
+	}
+	cachingProductRepository.invalidateProducts(change.ProductId)
+}
+
+func (cachingProductRepository *CachingProductRepository) Stats() map[string]cache.Stats {
+	return map[string]cache.Stats{
+		"products_by_id":    cachingProductRepository.byId.Stats(),
+		"products_by_store": cachingProductRepository.byStore.Stats(),
+	}
+}
+
+func (cachingProductRepository *CachingProductRepository) GetById(id int64) (domain.Product, error) {
+	product, err := cachingProductRepository.byId.Get(id, func() (domain.Product, error) {
+		return cachingProductRepository.productRepository.GetById(id)
+	})
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return cloneProduct(product), nil
+}
+
+func (cachingProductRepository *CachingProductRepository) GetAllByStore(store string) ([]domain.Product, error) {
+	products, err := cachingProductRepository.byStore.Get(store, func() ([]domain.Product, error) {
+		return cachingProductRepository.productRepository.GetAllByStore(store)
+	})
+	if err != nil {
+		return []domain.Product{}, err
+	}
+	clones := make([]domain.Product, 0, len(products))
+	for _, product := range products {
+		clones = append(clones, cloneProduct(product))
+	}
+	return clones, nil
+}
+
+func (cachingProductRepository *CachingProductRepository) Add(product domain.Product) (int64, error) {
+	defer cachingProductRepository.byStore.Invalidate(product.Store)
+	return cachingProductRepository.productRepository.Add(product)
+}
+
+func (cachingProductRepository *CachingProductRepository) DeleteById(id int64, actor string) error {
+	defer cachingProductRepository.invalidateProducts(id)
+	return cachingProductRepository.productRepository.DeleteById(id, actor)
+}
+
+func (cachingProductRepository *CachingProductRepository) UpdateProductPrice(id int64, price float32, actor string) error {
+	defer cachingProductRepository.invalidateProducts(id)
+	return cachingProductRepository.productRepository.UpdateProductPrice(id, price, actor)
+}
+
+func (cachingProductRepository *CachingProductRepository) Update(product domain.Product) error {
+	defer cachingProductRepository.invalidateProducts(product.Id)
+	return cachingProductRepository.productRepository.Update(product)
+}
+
+func (cachingProductRepository *CachingProductRepository) UpdateTranslations(id int64, translations map[string]domain.LocalizedText, actor string) error {
+	defer cachingProductRepository.invalidateProducts(id)
+	return cachingProductRepository.productRepository.UpdateTranslations(id, translations, actor)
+}
+
+func (cachingProductRepository *CachingProductRepository) UpdateStatus(statusChange domain.ProductStatusChange) error {
+	defer cachingProductRepository.invalidateProducts(statusChange.ProductId)
+	return cachingProductRepository.productRepository.UpdateStatus(statusChange)
+}
+
+func (cachingProductRepository *CachingProductRepository) RestoreById(id int64, actor string) error {
+	defer cachingProductRepository.invalidateProducts(id)
+	return cachingProductRepository.productRepository.RestoreById(id, actor)
+}
+
+func (cachingProductRepository *CachingProductRepository) PurgeDeleted(deletedBefore time.Time) (int64, []string, error) {
+	defer cachingProductRepository.invalidateAll()
+	return cachingProductRepository.productRepository.PurgeDeleted(deletedBefore)
+}
+
+func (cachingProductRepository *CachingProductRepository) AddBatch(products []domain.Product, atomic bool) ([]domain.BatchItemResult, error) {
+	defer cachingProductRepository.byStore.InvalidateAll()
+	return cachingProductRepository.productRepository.AddBatch(products, atomic)
+}
+
+func (cachingProductRepository *CachingProductRepository) UpdatePricesBatch(updates []domain.ProductPriceUpdate, actor string, atomic bool) ([]domain.BatchItemResult, error) {
+	ids := make([]int64, 0, len(updates))
+	for _, update := range updates {
+		ids = append(ids, update.Id)
+	}
+	defer cachingProductRepository.invalidateProducts(ids...)
+	return cachingProductRepository.productRepository.UpdatePricesBatch(updates, actor, atomic)
+}
+
+func (cachingProductRepository *CachingProductRepository) DeleteBatch(ids []int64, actor string, atomic bool) ([]domain.BatchItemResult, error) {
+	defer cachingProductRepository.invalidateProducts(ids...)
+	return cachingProductRepository.productRepository.DeleteBatch(ids, actor, atomic)
+}
+
+func (cachingProductRepository *CachingProductRepository) Reprice(repricing domain.Repricing) (domain.Repricing, []domain.PriceChange, error) {
+	defer cachingProductRepository.invalidateAll()
+	return cachingProductRepository.productRepository.Reprice(repricing)
+}
+
+func (cachingProductRepository *CachingProductRepository) UndoRepricing(id int64, actor string) (domain.Repricing, []domain.PriceChange, error) {
+	defer cachingProductRepository.invalidateAll()
+	return cachingProductRepository.productRepository.UndoRepricing(id, actor)
+}
+
+// The other reads are not cached.
+
+func (cachingProductRepository *CachingProductRepository) GetAll() ([]domain.Product, error) {
+	return cachingProductRepository.productRepository.GetAll()
+}
+
+func (cachingProductRepository *CachingProductRepository) GetAllByFilter(filter domain.ProductFilter) ([]domain.Product, error) {
+	return cachingProductRepository.productRepository.GetAllByFilter(filter)
+}
+
+func (cachingProductRepository *CachingProductRepository) StreamByFilter(filter domain.ProductFilter, chunkSize int, handle func(products []domain.Product) error) error {
+	return cachingProductRepository.productRepository.StreamByFilter(filter, chunkSize, handle)
+}
+
+func (cachingProductRepository *CachingProductRepository) GetBySku(sku string, store string) ([]domain.Product, error) {
+	return cachingProductRepository.productRepository.GetBySku(sku, store)
+}
+
+func (cachingProductRepository *CachingProductRepository) GetByIds(ids []int64) ([]domain.Product, error) {
+	return cachingProductRepository.productRepository.GetByIds(ids)
+}
+
+func (cachingProductRepository *CachingProductRepository) GetStatusHistory(productId int64) ([]domain.ProductStatusChange, error) {
+	return cachingProductRepository.productRepository.GetStatusHistory(productId)
+}
+
+func (cachingProductRepository *CachingProductRepository) GetAllDeleted() ([]domain.Product, error) {
+	return cachingProductRepository.productRepository.GetAllDeleted()
+}
+
+func (cachingProductRepository *CachingProductRepository) Search(query domain.ProductSearchQuery) (domain.ProductSearchResult, error) {
+	return cachingProductRepository.productRepository.Search(query)
+}
+
+func (cachingProductRepository *CachingProductRepository) GetStats(query domain.ProductStatsQuery) ([]domain.ProductStatsGroup, error) {
+	return cachingProductRepository.productRepository.GetStats(query)
+}
+
+func (cachingProductRepository *CachingProductRepository) GetRepricingById(id int64) (domain.Repricing, error) {
+	return cachingProductRepository.productRepository.GetRepricingById(id)
+}
+
+func (cachingProductRepository *CachingProductRepository) GetPriceHistory(productId int64) ([]domain.PriceChange, error) {
+	return cachingProductRepository.productRepository.GetPriceHistory(productId)
+}
+
+// invalidateProducts drops the products and every cached store, the stores of the products are not known here.
+func (cachingProductRepository *CachingProductRepository) invalidateProducts(ids ...int64) {
+	for _, id := range ids {
+		cachingProductRepository.byId.Invalidate(id)
+	}
+	cachingProductRepository.byStore.InvalidateAll()
+}
+
+func (cachingProductRepository *CachingProductRepository) invalidateAll() {
+	cachingProductRepository.byId.InvalidateAll()
+	cachingProductRepository.byStore.InvalidateAll()
+}
+
+// cloneProduct copies the slices and maps of a cached product so callers can not change the cache.
+// Attribute values are scalars, so a shallow copy of the attributes is enough.
+func cloneProduct(product domain.Product) domain.Product {
+	product.Options = slices.Clone(product.Options)
+	product.Attributes = maps.Clone(product.Attributes)
+	product.Translations = maps.Clone(product.Translations)
+	return product
+}
@@ -0,0 +1,37 @@
+package persistence
+
+import (
+	"errors"
+	"github.com/jackc/pgconn"
+)
+
+const uniqueViolationCode = "23505"
+
+// storeSkuConstraint is reported by the triggers that keep a sku unique in a store across products and variants.
+const storeSkuConstraint = "store_sku_key"
+
+// ConflictError is returned when a write violates a unique constraint.
+type ConflictError struct {
+	Message string
+}
+
+func (conflictError ConflictError) Error() string {
+	return conflictError.Message
+}
+
+// NotFoundError is returned when the requested row does not exist.
+type NotFoundError struct {
+	Message string
+}
+
+func (notFoundError NotFoundError) Error() string {
+	return notFoundError.Message
+}
+
+func isUniqueViolation(err error) (*pgconn.PgError, bool) {
+	var pgError *pgconn.PgError
+	if errors.As(err, &pgError) && pgError.Code == uniqueViolationCode {
+		return pgError, true
+	}
+	return nil, false
+}
@@ -0,0 +1,225 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/persistence/errorMessages"
+	"time"
+)
+
+const jobColumns = `id, type, status, payload, result, error, progress_done, progress_total, attempts, max_attempts, run_after,
+	cancel_requested, created_by, created_at, updated_at, started_at, finished_at`
+
+type IJobRepository interface {
+	Add(job domain.Job) (domain.Job, error)
+	GetById(id int64) (domain.Job, error)
+	FailAbandoned() ([]domain.Job, error)
+	ClaimNext(lease time.Duration) (domain.Job, bool, error)
+	UpdateProgress(id int64, attempt int, done int64, total int64, lease time.Duration) (bool, error)
+	Complete(id int64, attempt int, result json.RawMessage) error
+	Fail(id int64, attempt int, message string, retryAt *time.Time) error
+	MarkCancelled(id int64, attempt int) error
+	RequestCancel(id int64) (domain.Job, error)
+}
+
+type JobRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewJobRepository(dbPool *pgxpool.Pool) IJobRepository {
+	return &JobRepository{dbPool: dbPool}
+}
+
+func (jobRepository *JobRepository) Add(job domain.Job) (domain.Job, error) {
+	ctx := context.Background()
+
+	sqlCommand := `INSERT INTO jobs(type, payload, max_attempts, created_by) VALUES($1, COALESCE($2::jsonb, '{}'), $3, $4) RETURNING ` + jobColumns
+
+	addedJob, err := scanJob(jobRepository.dbPool.QueryRow(ctx, sqlCommand, job.Type, []byte(job.Payload), job.MaxAttempts, job.CreatedBy))
+	if err != nil {
+		log.Errorf("Error while inserting %s job: %v", job.Type, err)
+		return domain.Job{}, err
+	}
+
+	log.Infof("Job %d of type %s queued", addedJob.Id, addedJob.Type)
+	return addedJob, nil
+}
+
+func (jobRepository *JobRepository) GetById(id int64) (domain.Job, error) {
+	ctx := context.Background()
+
+	job, err := scanJob(jobRepository.dbPool.QueryRow(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id))
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return domain.Job{}, NotFoundError{Message: fmt.Sprintf("Job with id %d not found", id)}
+	}
+	if err != nil {
+		log.Errorf("Error while fetching job with id %d: %v", id, err)
+		return domain.Job{}, errors.New(fmt.Sprintf("Error while fetching job by id %d", id))
+	}
+
+	return job, nil
+}
+
+// ClaimNext takes the oldest due job for the calling worker, or a running job whose worker let its lease expire.
+// SKIP LOCKED lets any number of workers claim concurrently without waiting on each other. An expired job that
+// used up its attempts is failed instead, so a job that crashes its worker is not run forever.
+//
+// The claimed job carries its attempt, the updates that follow only apply while the job is still on that attempt.
+// A worker that lost its lease and was overtaken by another one can not overwrite the newer attempt.
+// FailAbandoned fails the running jobs whose worker stopped renewing the lease on their last attempt.
+func (jobRepository *JobRepository) FailAbandoned() ([]domain.Job, error) {
+	ctx := context.Background()
+
+	rows, err := jobRepository.dbPool.Query(ctx, `UPDATE jobs SET status = 'failed', error = 'Worker stopped renewing its lease on the last attempt',
+			lease_expires_at = NULL, finished_at = now(), updated_at = now()
+		WHERE status = 'running' AND lease_expires_at < now() AND attempts >= max_attempts
+		RETURNING `+jobColumns)
+	if err != nil {
+		log.Errorf("Error while failing abandoned jobs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []domain.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			log.Errorf("Error while scanning abandoned job rows: %v", err)
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if len(jobs) > 0 {
+		log.Infof("%d abandoned jobs failed after their last attempt", len(jobs))
+	}
+	return jobs, rows.Err()
+}
+
+func (jobRepository *JobRepository) ClaimNext(lease time.Duration) (domain.Job, bool, error) {
+	ctx := context.Background()
+
+	sqlCommand := `UPDATE jobs SET status = 'running', attempts = attempts + 1, lease_expires_at = now() + $1::interval,
+			started_at = COALESCE(started_at, now()), updated_at = now()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = 'queued' AND run_after <= now()) OR (status = 'running' AND lease_expires_at < now() AND attempts < max_attempts)
+			ORDER BY run_after, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(jobRepository.dbPool.QueryRow(ctx, sqlCommand, lease))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Job{}, false, nil
+	}
+	if err != nil {
+		log.Errorf("Error while claiming a job: %v", err)
+		return domain.Job{}, false, err
+	}
+
+	return job, true, nil
+}
+
+// UpdateProgress records the progress of a running job and extends its lease, it tells whether cancellation was requested.
+// A job that is no longer running on the given attempt counts as cancelled, its worker should stop.
+func (jobRepository *JobRepository) UpdateProgress(id int64, attempt int, done int64, total int64, lease time.Duration) (bool, error) {
+	ctx := context.Background()
+
+	sqlCommand := `UPDATE jobs SET progress_done = GREATEST($2, 0), progress_total = GREATEST($3, 0), lease_expires_at = now() + $4::interval, updated_at = now()
+		WHERE id = $1 AND status = 'running' AND attempts = $5
+		RETURNING cancel_requested`
+
+	var cancelRequested bool
+	err := jobRepository.dbPool.QueryRow(ctx, sqlCommand, id, done, total, lease, attempt).Scan(&cancelRequested)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// the job is not running any more, e.g. it was cancelled while queued for a retry or another worker took it over
+		return true, nil
+	}
+	if err != nil {
+		log.Errorf("Error while updating progress of job %d: %v", id, err)
+		return false, err
+	}
+
+	return cancelRequested, nil
+}
+
+func (jobRepository *JobRepository) Complete(id int64, attempt int, result json.RawMessage) error {
+	return jobRepository.finish(id, attempt, `status = 'succeeded', result = $2::jsonb, progress_done = GREATEST(progress_done, progress_total)`, []byte(result))
+}
+
+// Fail queues the job again for retryAt, or marks it failed for good when retryAt is nil.
+func (jobRepository *JobRepository) Fail(id int64, attempt int, message string, retryAt *time.Time) error {
+	if retryAt != nil {
+		ctx := context.Background()
+
+		_, err := jobRepository.dbPool.Exec(ctx, `UPDATE jobs SET status = 'queued', error = $2, run_after = $3, lease_expires_at = NULL, updated_at = now()
+			WHERE id = $1 AND status = 'running' AND attempts = $4`, id, message, *retryAt, attempt)
+		if err != nil {
+			log.Errorf("Error while queueing job %d for a retry: %v", id, err)
+		}
+		return err
+	}
+	return jobRepository.finish(id, attempt, `status = 'failed', error = $2`, message)
+}
+
+func (jobRepository *JobRepository) MarkCancelled(id int64, attempt int) error {
+	return jobRepository.finish(id, attempt, `status = 'cancelled', error = $2`, "Cancelled")
+}
+
+func (jobRepository *JobRepository) finish(id int64, attempt int, assignments string, argument interface{}) error {
+	ctx := context.Background()
+
+	_, err := jobRepository.dbPool.Exec(ctx, `UPDATE jobs SET `+assignments+`, lease_expires_at = NULL, finished_at = now(), updated_at = now()
+		WHERE id = $1 AND status = 'running' AND attempts = $3`, id, argument, attempt)
+	if err != nil {
+		log.Errorf("Error while finishing job %d: %v", id, err)
+	}
+	return err
+}
+
+// RequestCancel cancels a queued job right away and flags a running one, its worker stops it on its next check.
+func (jobRepository *JobRepository) RequestCancel(id int64) (domain.Job, error) {
+	ctx := context.Background()
+
+	sqlCommand := `UPDATE jobs SET
+			status = CASE WHEN status = 'queued' THEN 'cancelled' ELSE status END,
+			finished_at = CASE WHEN status = 'queued' THEN now() ELSE finished_at END,
+			error = CASE WHEN status = 'queued' THEN 'Cancelled' ELSE error END,
+			cancel_requested = true, updated_at = now()
+		WHERE id = $1 AND status IN ('queued', 'running')
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(jobRepository.dbPool.QueryRow(ctx, sqlCommand, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		existing, getErr := jobRepository.GetById(id)
+		if getErr != nil {
+			return domain.Job{}, getErr
+		}
+		return domain.Job{}, ConflictError{Message: fmt.Sprintf("Job with id %d is already %s", id, existing.Status)}
+	}
+	if err != nil {
+		log.Errorf("Error while cancelling job %d: %v", id, err)
+		return domain.Job{}, err
+	}
+
+	return job, nil
+}
+
+func scanJob(row pgx.Row) (domain.Job, error) {
+	var job domain.Job
+	var payload, result []byte
+	err := row.Scan(&job.Id, &job.Type, &job.Status, &payload, &result, &job.Error, &job.ProgressDone, &job.ProgressTotal, &job.Attempts,
+		&job.MaxAttempts, &job.RunAfter, &job.CancelRequested, &job.CreatedBy, &job.CreatedAt, &job.UpdatedAt, &job.StartedAt, &job.FinishedAt)
+	if err != nil {
+		return domain.Job{}, err
+	}
+	job.Payload, job.Result = payload, result
+	return job, nil
+}
@@ -0,0 +1,220 @@
+package persistence
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"time"
+)
+
+// outboxRelayLockKey is the advisory lock key that makes only one instance relay events at a time,
+// which keeps the events of a product in order.
+const outboxRelayLockKey = 726002
+
+// The statements that change products write their events to the outbox in the same statement, so an event
+// exists exactly when its change was committed. Each statement names its data modifying CTE changed, returns
+// the columns the payload needs from it and ends with one of these tails, which return the ids of the changed rows.
+
+// returningPriceChange is the RETURNING clause of an UPDATE of products joined with previous, the locked rows as they were.
+const returningPriceChange = ` RETURNING products.id, previous.price AS old_price, products.price AS new_price,
+	previous.discount AS old_discount, products.discount AS new_discount, products.store, products.status, products.updated_by AS actor`
+
+// priceChangedEvents only records the products whose price or discount really changed, as an event and in the price
+// history with the given reason. repricingId is the SQL expression of the repricing behind the change, NULL for none.
+func priceChangedEvents(reason string, repricingId string) string {
+	return `, price_changes AS (
+		SELECT * FROM changed WHERE (old_price, old_discount) IS DISTINCT FROM (new_price, new_discount)
+	), events AS (
+		INSERT INTO outbox(aggregate_id, event_type, payload)
+		SELECT id, '` + domain.ProductEventPriceChanged + `', jsonb_build_object('product_id', id, 'old_price', old_price, 'new_price', new_price,
+			'old_discount', COALESCE(old_discount, 0), 'new_discount', COALESCE(new_discount, 0), 'store', store, 'status', status, 'actor', actor)
+		FROM price_changes
+	), history AS (
+		INSERT INTO price_history(product_id, repricing_id, reason, old_price, new_price, old_discount, new_discount, actor)
+		SELECT id, ` + repricingId + `, '` + reason + `', old_price, new_price, COALESCE(old_discount, 0), COALESCE(new_discount, 0), COALESCE(actor, '')
+		FROM price_changes
+	)
+	SELECT id FROM changed`
+}
+
+const returningCreatedProduct = ` RETURNING id, name, price, discount, store, category, status, sku, created_by AS actor`
+
+const productCreatedEvents = `, events AS (
+		INSERT INTO outbox(aggregate_id, event_type, payload)
+		SELECT id, '` + domain.ProductEventCreated + `', jsonb_build_object('product_id', id, 'name', name, 'price', price, 'discount', COALESCE(discount, 0),
+			'store', store, 'category', category, 'status', status, 'sku', COALESCE(sku, ''), 'actor', actor)
+		FROM changed
+	)
+	SELECT id FROM changed`
+
+// returningUpdatedProduct is the RETURNING clause of an UPDATE of products that ends with productUpdatedEvents.
+const returningUpdatedProduct = ` RETURNING id, name, price, discount, store, category, status, sku, updated_by AS actor`
+
+// productUpdatedEvent records the changed products as they are after the change. It is a CTE so a statement can
+// follow it with another tail, the statement needs the columns of returningUpdatedProduct.
+const productUpdatedEvent = `, updated_events AS (
+		INSERT INTO outbox(aggregate_id, event_type, payload)
+		SELECT id, '` + domain.ProductEventUpdated + `', jsonb_build_object('product_id', id, 'name', name, 'price', price, 'discount', COALESCE(discount, 0),
+			'store', store, 'category', category, 'status', status, 'sku', COALESCE(sku, ''), 'actor', actor)
+		FROM changed
+	)`
+
+const productUpdatedEvents = productUpdatedEvent + `
+	SELECT id FROM changed`
+
+const productDeletedEvents = `, events AS (
+		INSERT INTO outbox(aggregate_id, event_type, payload)
+		SELECT id, '` + domain.ProductEventDeleted + `', jsonb_build_object('product_id', id, 'store', store, 'status', status, 'actor', actor)
+		FROM changed
+	)
+	SELECT id FROM changed`
+
+const outboxEventColumns = `id, event_id::text, event_type, aggregate_id, COALESCE(payload->>'store', ''), COALESCE(payload->>'status', ''),
+	payload, occurred_at, attempts`
+
+type IOutboxRepository interface {
+	RelayPending(limit int, publish func(event domain.ProductEvent) error) (int, int, error)
+	GetPublished(ids []int64) ([]domain.ProductEvent, error)
+	GetPublishedAfter(afterId int64, limit int) ([]domain.ProductEvent, error)
+	PurgePublished(publishedBefore time.Time) (int64, error)
+}
+
+type OutboxRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewOutboxRepository(dbPool *pgxpool.Pool) IOutboxRepository {
+	return &OutboxRepository{dbPool: dbPool}
+}
+
+// RelayPending hands up to limit due events to publish and returns how many were published and how many failed.
+// Only the oldest unpublished event of each product is due, so a failing event holds back the later events of its
+// product but not the others. An event is marked published after publish returns, a crash in between publishes it again.
+func (outboxRepository *OutboxRepository) RelayPending(limit int, publish func(event domain.ProductEvent) error) (int, int, error) {
+	ctx := context.Background()
+
+	tx, err := outboxRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting outbox relay transaction: %v", err)
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxRelayLockKey).Scan(&locked)
+	if err != nil {
+		log.Errorf("Error while acquiring outbox relay lock: %v", err)
+		return 0, 0, err
+	}
+	if !locked {
+		return 0, 0, nil
+	}
+
+	events, err := queryDueEvents(ctx, tx, limit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	published, failed := 0, 0
+	for _, event := range events {
+		publishErr := publish(event)
+		if publishErr == nil {
+			_, err = tx.Exec(ctx, `UPDATE outbox SET attempts = attempts + 1, last_error = '', published_at = now() WHERE id = $1`, event.Id)
+			published++
+		} else {
+			retryAt := time.Now().Add(domain.OutboxRetryDelay(event.Attempts + 1))
+			_, err = tx.Exec(ctx, `UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1`,
+				event.Id, publishErr.Error(), retryAt)
+			failed++
+			log.Errorf("Error while publishing event %s of product %d, retrying at %s: %v", event.EventId, event.ProductId, retryAt.Format(time.RFC3339), publishErr)
+		}
+		if err != nil {
+			log.Errorf("Error while recording the delivery of event %s: %v", event.EventId, err)
+			return 0, 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Errorf("Error while committing outbox relay: %v", err)
+		return 0, 0, err
+	}
+	return published, failed, nil
+}
+
+// GetPublished returns the published events with the given ids, in the order of the ids.
+func (outboxRepository *OutboxRepository) GetPublished(ids []int64) ([]domain.ProductEvent, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + outboxEventColumns + ` FROM outbox
+		WHERE id = ANY($1) AND published_at IS NOT NULL
+		ORDER BY array_position($1, id)`
+
+	rows, err := outboxRepository.dbPool.Query(ctx, query, ids)
+	if err != nil {
+		log.Errorf("Error while fetching published outbox events: %v", err)
+		return nil, err
+	}
+	return scanProductEvents(rows)
+}
+
+// GetPublishedAfter returns up to limit published events with an id above afterId, in the order of the ids.
+func (outboxRepository *OutboxRepository) GetPublishedAfter(afterId int64, limit int) ([]domain.ProductEvent, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + outboxEventColumns + ` FROM outbox
+		WHERE id > $1 AND published_at IS NOT NULL
+		ORDER BY id LIMIT $2`
+
+	rows, err := outboxRepository.dbPool.Query(ctx, query, afterId, limit)
+	if err != nil {
+		log.Errorf("Error while fetching published outbox events after %d: %v", afterId, err)
+		return nil, err
+	}
+	return scanProductEvents(rows)
+}
+
+// PurgePublished deletes the events published before publishedBefore, unpublished events are kept however old they are.
+func (outboxRepository *OutboxRepository) PurgePublished(publishedBefore time.Time) (int64, error) {
+	ctx := context.Background()
+
+	commandTag, err := outboxRepository.dbPool.Exec(ctx, `DELETE FROM outbox WHERE published_at < $1`, publishedBefore)
+	if err != nil {
+		log.Errorf("Error while purging published outbox events: %v", err)
+		return 0, err
+	}
+	return commandTag.RowsAffected(), nil
+}
+
+func queryDueEvents(ctx context.Context, tx pgx.Tx, limit int) ([]domain.ProductEvent, error) {
+	query := `SELECT ` + outboxEventColumns + ` FROM outbox
+		WHERE published_at IS NULL AND next_attempt_at <= now()
+		AND NOT EXISTS (SELECT 1 FROM outbox earlier WHERE earlier.aggregate_id = outbox.aggregate_id AND earlier.published_at IS NULL AND earlier.id < outbox.id)
+		ORDER BY id LIMIT $1`
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		log.Errorf("Error while fetching due outbox events: %v", err)
+		return nil, err
+	}
+	return scanProductEvents(rows)
+}
+
+func scanProductEvents(rows pgx.Rows) ([]domain.ProductEvent, error) {
+	defer rows.Close()
+
+	events := make([]domain.ProductEvent, 0)
+	for rows.Next() {
+		var event domain.ProductEvent
+		var payload []byte
+		err := rows.Scan(&event.Id, &event.EventId, &event.Type, &event.ProductId, &event.Store, &event.Status, &payload, &event.OccurredAt, &event.Attempts)
+		if err != nil {
+			log.Errorf("Error while scanning outbox rows: %v", err)
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,271 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/persistence/errorMessages"
+	"time"
+)
+
+// priceSchedulerLockKey is the advisory lock key that makes only one instance apply due schedules at a time.
+const priceSchedulerLockKey = 726001
+
+const priceScheduleColumns = `id, product_id, price, discount, effective_from, effective_to, status`
+
+// touchScheduledProducts ends a statement whose CTE scheduled returns the product_id of the schedules it changed.
+// The price a product is read with depends on its schedules, so the products are touched and their change is
+// notified to the search index and the cache of every instance like any other product change.
+const touchScheduledProducts = `
+	UPDATE products SET updated_at = now() WHERE id IN (SELECT product_id FROM scheduled)`
+
+type IPriceScheduleRepository interface {
+	Add(priceSchedule domain.PriceSchedule) error
+	GetById(id int64) (domain.PriceSchedule, error)
+	GetPending() ([]domain.PriceSchedule, error)
+	GetPendingByProductId(productId int64) ([]domain.PriceSchedule, error)
+	GetActiveByProductIds(productIds []int64, now time.Time) ([]domain.PriceSchedule, error)
+	Cancel(id int64) error
+	ApplyDue(now time.Time) (int64, error)
+}
+
+type PriceScheduleRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewPriceScheduleRepository(dbPool *pgxpool.Pool) IPriceScheduleRepository {
+	return &PriceScheduleRepository{dbPool: dbPool}
+}
+
+func (priceScheduleRepository *PriceScheduleRepository) Add(priceSchedule domain.PriceSchedule) error {
+	ctx := context.Background()
+
+	sqlCommand := `WITH scheduled AS (
+			INSERT INTO price_schedules(product_id, price, discount, effective_from, effective_to, status) VALUES($1, $2, $3, $4, $5, $6)
+			RETURNING product_id
+		)` + touchScheduledProducts
+
+	_, err := priceScheduleRepository.dbPool.Exec(ctx, sqlCommand,
+		priceSchedule.ProductId,
+		priceSchedule.Price,
+		priceSchedule.Discount,
+		priceSchedule.EffectiveFrom,
+		priceSchedule.EffectiveTo,
+		domain.PriceScheduleStatusPending)
+	if err != nil {
+		log.Errorf("Error while inserting price schedule: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (priceScheduleRepository *PriceScheduleRepository) GetById(id int64) (domain.PriceSchedule, error) {
+	ctx := context.Background()
+
+	sqlCommand := `SELECT ` + priceScheduleColumns + ` FROM price_schedules WHERE id = $1`
+
+	priceSchedule, err := scanPriceSchedule(priceScheduleRepository.dbPool.QueryRow(ctx, sqlCommand, id))
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return domain.PriceSchedule{}, errors.New(fmt.Sprintf("Price schedule with id %d not found", id))
+	}
+
+	if err != nil {
+		log.Errorf("Error while fetching price schedule with id: %d %v", id, err)
+		return domain.PriceSchedule{}, errors.New(fmt.Sprintf("Error while fetching price schedule by id %d", id))
+	}
+
+	return priceSchedule, nil
+}
+
+func (priceScheduleRepository *PriceScheduleRepository) GetPending() ([]domain.PriceSchedule, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + priceScheduleColumns + ` FROM price_schedules WHERE status = $1 ORDER BY effective_from, id`
+
+	rows, err := priceScheduleRepository.dbPool.Query(ctx, query, domain.PriceScheduleStatusPending)
+	if err != nil {
+		log.Errorf("Error while fetching price schedules: %v", err)
+		return []domain.PriceSchedule{}, err
+	}
+
+	return extractPriceSchedulesFromRows(rows)
+}
+
+func (priceScheduleRepository *PriceScheduleRepository) GetPendingByProductId(productId int64) ([]domain.PriceSchedule, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + priceScheduleColumns + ` FROM price_schedules WHERE status = $1 AND product_id = $2 ORDER BY effective_from, id`
+
+	rows, err := priceScheduleRepository.dbPool.Query(ctx, query, domain.PriceScheduleStatusPending, productId)
+	if err != nil {
+		log.Errorf("Error while fetching price schedules of product %d: %v", productId, err)
+		return []domain.PriceSchedule{}, err
+	}
+
+	return extractPriceSchedulesFromRows(rows)
+}
+
+func (priceScheduleRepository *PriceScheduleRepository) GetActiveByProductIds(productIds []int64, now time.Time) ([]domain.PriceSchedule, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + priceScheduleColumns + ` FROM price_schedules
+		WHERE status = $1 AND product_id = ANY($3) AND effective_from <= $2 AND (effective_to IS NULL OR effective_to > $2)
+		ORDER BY effective_from, id`
+
+	rows, err := priceScheduleRepository.dbPool.Query(ctx, query, domain.PriceScheduleStatusPending, now, productIds)
+	if err != nil {
+		log.Errorf("Error while fetching active price schedules: %v", err)
+		return []domain.PriceSchedule{}, err
+	}
+
+	return extractPriceSchedulesFromRows(rows)
+}
+
+// Cancel cancels a pending schedule in one statement, so it can not cancel a schedule the scheduler applied
+// or expired in the meantime: whichever of them updates the row first wins and the other one skips it.
+func (priceScheduleRepository *PriceScheduleRepository) Cancel(id int64) error {
+	ctx := context.Background()
+
+	sqlCommand := `WITH scheduled AS (
+			UPDATE price_schedules SET status = $1 WHERE id = $2 AND status = $3 RETURNING product_id
+		), touched AS (` + touchScheduledProducts + `
+		)
+		SELECT count(*) FROM scheduled`
+
+	var cancelled int64
+	err := priceScheduleRepository.dbPool.QueryRow(ctx, sqlCommand, domain.PriceScheduleStatusCancelled, id, domain.PriceScheduleStatusPending).Scan(&cancelled)
+	if err != nil {
+		log.Errorf("Error while cancelling price schedule with id:%d %v", id, err)
+		return errors.New(fmt.Sprintf("Error while cancelling price schedule with id %d", id))
+	}
+	if cancelled > 0 {
+		return nil
+	}
+
+	priceSchedule, err := priceScheduleRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+	return errors.New(fmt.Sprintf("Price schedule with id %d is already %s", id, priceSchedule.Status))
+}
+
+// ApplyDue writes due permanent schedules to their products, expires finished time-boxed ones and touches the
+// products of the time-boxed windows that opened or closed.
+// Several due schedules of a product are folded like domain.ApplyPriceSchedules does: the price and
+// the discount each come from the latest schedule that sets them.
+// It runs in one transaction guarded by an advisory lock, so when several instances run the
+// scheduler only one of them does the work per tick; the others return 0 without waiting.
+func (priceScheduleRepository *PriceScheduleRepository) ApplyDue(now time.Time) (int64, error) {
+	ctx := context.Background()
+
+	tx, err := priceScheduleRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting price schedule transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, priceSchedulerLockKey).Scan(&locked)
+	if err != nil {
+		log.Errorf("Error while acquiring price scheduler lock: %v", err)
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	applyCommand := `WITH due AS (
+			UPDATE price_schedules SET status = $1
+			WHERE status = $2 AND effective_to IS NULL AND effective_from <= $3
+			RETURNING product_id, price, discount, effective_from, id
+		), latest AS (
+			SELECT product_id,
+				(array_agg(price ORDER BY effective_from DESC, id DESC) FILTER (WHERE price IS NOT NULL))[1] AS price,
+				(array_agg(discount ORDER BY effective_from DESC, id DESC) FILTER (WHERE discount IS NOT NULL))[1] AS discount
+			FROM due GROUP BY product_id
+		), previous AS (
+			SELECT products.id, products.price, products.discount FROM products JOIN latest ON products.id = latest.product_id FOR UPDATE OF products
+		), changed AS (
+			UPDATE products SET price = COALESCE(latest.price, products.price), discount = COALESCE(latest.discount, products.discount),
+				updated_at = now(), updated_by = 'price-scheduler'
+			FROM latest, previous WHERE products.id = latest.product_id AND products.id = previous.id` + returningPriceChange + `
+		)` + priceChangedEvents(domain.PriceChangeReasonSchedule, "NULL")
+
+	applied, err := tx.Exec(ctx, applyCommand, domain.PriceScheduleStatusApplied, domain.PriceScheduleStatusPending, now)
+	if err != nil {
+		log.Errorf("Error while applying due price schedules: %v", err)
+		return 0, err
+	}
+
+	// a time-boxed schedule changes the price without writing it, its products are touched when the window closes
+	expireCommand := `WITH scheduled AS (
+			UPDATE price_schedules SET status = $1 WHERE status = $2 AND effective_to IS NOT NULL AND effective_to <= $3 RETURNING product_id
+		), touched AS (` + touchScheduledProducts + `
+		)
+		SELECT count(*) FROM scheduled`
+
+	var expired int64
+	err = tx.QueryRow(ctx, expireCommand, domain.PriceScheduleStatusExpired, domain.PriceScheduleStatusPending, now).Scan(&expired)
+	if err != nil {
+		log.Errorf("Error while expiring price schedules: %v", err)
+		return 0, err
+	}
+
+	// and when it opens, opened_at keeps a window from being announced twice
+	openCommand := `WITH scheduled AS (
+			UPDATE price_schedules SET opened_at = $2
+			WHERE status = $1 AND effective_to IS NOT NULL AND effective_from <= $2 AND opened_at IS NULL RETURNING product_id
+		), touched AS (` + touchScheduledProducts + `
+		)
+		SELECT count(*) FROM scheduled`
+
+	var opened int64
+	err = tx.QueryRow(ctx, openCommand, domain.PriceScheduleStatusPending, now).Scan(&opened)
+	if err != nil {
+		log.Errorf("Error while opening price schedules: %v", err)
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		log.Errorf("Error while committing price schedule transaction: %v", err)
+		return 0, err
+	}
+
+	return applied.RowsAffected() + expired + opened, nil
+}
+
+func scanPriceSchedule(row pgx.Row) (domain.PriceSchedule, error) {
+	var priceSchedule domain.PriceSchedule
+	err := row.Scan(&priceSchedule.Id,
+		&priceSchedule.ProductId,
+		&priceSchedule.Price,
+		&priceSchedule.Discount,
+		&priceSchedule.EffectiveFrom,
+		&priceSchedule.EffectiveTo,
+		&priceSchedule.Status)
+	return priceSchedule, err
+}
+
+func extractPriceSchedulesFromRows(rows pgx.Rows) ([]domain.PriceSchedule, error) {
+	defer rows.Close()
+
+	var priceSchedules []domain.PriceSchedule
+	for rows.Next() {
+		priceSchedule, err := scanPriceSchedule(rows)
+		if err != nil {
+			log.Errorf("Error while scanning price schedule rows: %v", err)
+			return []domain.PriceSchedule{}, err
+		}
+
+		priceSchedules = append(priceSchedules, priceSchedule)
+	}
+
+	return priceSchedules, rows.Err()
+}
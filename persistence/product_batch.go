@@ -0,0 +1,213 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+)
+
+// AddBatch inserts the products with a single round trip. Products clashing with an existing sku or gtin
+// are reported as failed instead of aborting the batch, when atomic is set any failure rolls everything back.
+func (productRepository *ProductRepository) AddBatch(products []domain.Product, atomic bool) ([]domain.BatchItemResult, error) {
+	ctx := context.Background()
+
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting product batch insert transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// ON CONFLICT without a target covers the partial unique indexes on sku and gtin
+	sqlCommand := `WITH changed AS (
+			INSERT INTO products(name, description, price, discount, store, category, status, sku, gtin, options, attributes, created_by, updated_by)
+			VALUES($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), 'active'), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10::text[], '{}'), COALESCE(NULLIF($11::jsonb, 'null'), '{}'), $12, $12)
+			ON CONFLICT DO NOTHING` + returningCreatedProduct + `
+		), translations AS (
+			INSERT INTO product_translations(product_id, locale, name, description)
+			SELECT changed.id, translation.key, translation.value->>'Name', translation.value->>'Description'
+			FROM changed, jsonb_each(COALESCE(NULLIF($13::jsonb, 'null'), '{}')) AS translation
+		)` + productCreatedEvents
+
+	// the sku check of the trigger would abort the whole batch, so the skus of variants are skipped up front
+	variantSkus, err := variantSkusInStores(ctx, tx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.BatchItemResult, len(products))
+	batch := &pgx.Batch{}
+	for i, product := range products {
+		if variantSkus[product.Store+"/"+product.Sku] {
+			results[i] = domain.BatchItemResult{Index: i, Status: domain.BatchItemFailed,
+				Error: fmt.Sprintf("Sku %s is used by a variant in store %s", product.Sku, product.Store)}
+			continue
+		}
+		batch.Queue(sqlCommand, product.Name, product.Description, product.Price, product.Discount, product.Store, product.Category, product.Status,
+			product.Sku, product.Gtin, product.Options, product.Attributes, product.CreatedBy, product.Translations)
+	}
+
+	batchResults := tx.SendBatch(ctx, batch)
+	for i := range products {
+		if results[i].Status == domain.BatchItemFailed {
+			continue
+		}
+		results[i] = domain.BatchItemResult{Index: i, Status: domain.BatchItemSucceeded}
+		err = batchResults.QueryRow().Scan(&results[i].Id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			results[i] = domain.BatchItemResult{Index: i, Status: domain.BatchItemFailed}
+			continue
+		}
+		if err != nil {
+			batchResults.Close()
+			log.Errorf("Error while inserting product batch item %d: %v", i, err)
+			return nil, err
+		}
+	}
+	if err = batchResults.Close(); err != nil {
+		log.Errorf("Error while closing product batch insert: %v", err)
+		return nil, err
+	}
+
+	// the conflicting row decides whether the sku or the gtin is reported
+	for i, product := range products {
+		if results[i].Status == domain.BatchItemFailed && len(results[i].Error) == 0 {
+			results[i].Error = productRepository.batchConflictMessage(ctx, tx, product)
+		}
+	}
+
+	results, err = commitBatch(ctx, tx, results, atomic, "insert")
+	for i := range results {
+		if results[i].Status == domain.BatchItemNotApplied {
+			// the ids were handed out by the rolled back inserts
+			results[i].Id = 0
+		}
+	}
+	return results, err
+}
+
+// variantSkusInStores returns the store/sku pairs of the products that are taken by variants.
+func variantSkusInStores(ctx context.Context, tx pgx.Tx, products []domain.Product) (map[string]bool, error) {
+	stores := make([]string, 0, len(products))
+	skus := make([]string, 0, len(products))
+	for _, product := range products {
+		stores = append(stores, product.Store)
+		skus = append(skus, product.Sku)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT products.store, product_variants.sku FROM product_variants
+		JOIN products ON products.id = product_variants.product_id AND products.deleted_at IS NULL
+		JOIN unnest($1::text[], $2::text[]) AS batch(store, sku) ON batch.store = products.store AND batch.sku = product_variants.sku`, stores, skus)
+	if err != nil {
+		log.Errorf("Error while looking up the variant skus of a product batch: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	variantSkus := make(map[string]bool)
+	for rows.Next() {
+		var store, sku string
+		if err = rows.Scan(&store, &sku); err != nil {
+			log.Errorf("Error while scanning variant skus: %v", err)
+			return nil, err
+		}
+		variantSkus[store+"/"+sku] = true
+	}
+	return variantSkus, rows.Err()
+}
+
+func (productRepository *ProductRepository) batchConflictMessage(ctx context.Context, tx pgx.Tx, product domain.Product) string {
+	var skuTaken bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM products WHERE store = $1 AND sku = NULLIF($2, '') AND deleted_at IS NULL)`,
+		product.Store, product.Sku).Scan(&skuTaken)
+	if err != nil {
+		log.Errorf("Error while looking up the conflict of product %s: %v", product.Name, err)
+	}
+	if skuTaken || len(product.Gtin) == 0 {
+		return fmt.Sprintf("Product with sku %s already exists in store %s", product.Sku, product.Store)
+	}
+	return fmt.Sprintf("Product with gtin %s already exists in store %s", product.Gtin, product.Store)
+}
+
+func (productRepository *ProductRepository) UpdatePricesBatch(updates []domain.ProductPriceUpdate, actor string, atomic bool) ([]domain.BatchItemResult, error) {
+	ctx := context.Background()
+
+	batch := &pgx.Batch{}
+	ids := make([]int64, 0, len(updates))
+	for _, update := range updates {
+		batch.Queue(updatePriceCommand, update.Price, update.Id, actor)
+		ids = append(ids, update.Id)
+	}
+	return productRepository.execBatch(ctx, batch, ids, atomic, "price update")
+}
+
+// DeleteBatch soft deletes the products like DeleteById does.
+func (productRepository *ProductRepository) DeleteBatch(ids []int64, actor string, atomic bool) ([]domain.BatchItemResult, error) {
+	ctx := context.Background()
+
+	batch := &pgx.Batch{}
+	for _, id := range ids {
+		batch.Queue(deleteProductCommand, id, actor)
+	}
+	return productRepository.execBatch(ctx, batch, ids, atomic, "delete")
+}
+
+// execBatch runs a batch of single row updates, an item whose product does not exist is reported as failed.
+func (productRepository *ProductRepository) execBatch(ctx context.Context, batch *pgx.Batch, ids []int64, atomic bool, operation string) ([]domain.BatchItemResult, error) {
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting product batch %s transaction: %v", operation, err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	results := make([]domain.BatchItemResult, len(ids))
+	batchResults := tx.SendBatch(ctx, batch)
+	for i, id := range ids {
+		commandTag, err := batchResults.Exec()
+		if err != nil {
+			batchResults.Close()
+			log.Errorf("Error during product batch %s of id %d: %v", operation, id, err)
+			return nil, err
+		}
+		results[i] = domain.BatchItemResult{Index: i, Id: id, Status: domain.BatchItemSucceeded}
+		if commandTag.RowsAffected() == 0 {
+			results[i].Status = domain.BatchItemFailed
+			results[i].Error = fmt.Sprintf("Product with id %d not found", id)
+		}
+	}
+	if err = batchResults.Close(); err != nil {
+		log.Errorf("Error while closing product batch %s: %v", operation, err)
+		return nil, err
+	}
+
+	return commitBatch(ctx, tx, results, atomic, operation)
+}
+
+// commitBatch commits the transaction unless atomic is set and an item failed,
+// in which case the succeeded items are rolled back and marked as not applied.
+func commitBatch(ctx context.Context, tx pgx.Tx, results []domain.BatchItemResult, atomic bool, operation string) ([]domain.BatchItemResult, error) {
+	if atomic {
+		for _, result := range results {
+			if result.Status != domain.BatchItemFailed {
+				continue
+			}
+			for i := range results {
+				if results[i].Status == domain.BatchItemSucceeded {
+					results[i].Status = domain.BatchItemNotApplied
+				}
+			}
+			return results, nil
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Errorf("Error while committing product batch %s: %v", operation, err)
+		return nil, err
+	}
+	log.Infof("Product batch %s of %d items committed", operation, len(results))
+	return results, nil
+}
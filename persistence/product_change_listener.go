@@ -0,0 +1,132 @@
+package persistence
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ProductChangesChannel is notified by the products_notify_change trigger after every insert, update and delete of a product.
+const ProductChangesChannel = "product_changes"
+
+// ProductEventsChannel is notified by the outbox_notify_published trigger with the outbox id of every event the relay published.
+const ProductEventsChannel = "product_events"
+
+const maxListenerReconnectDelay = time.Minute
+
+// ProductChangeHandler is called for every change in the order the changes were committed.
+// Handlers run one after another on the listener, so they should not block.
+type ProductChangeHandler func(change domain.ProductChange)
+
+// PublishedEventHandler is called with the outbox id of every published product event, in the order they were published.
+// It runs on the listener like a ProductChangeHandler and should not block either.
+type PublishedEventHandler func(eventId int64)
+
+// ProductChangeListener receives the product changes and published product events of every instance on a connection
+// of its own and hands them to its handlers. A lost connection is reopened with a growing delay, the product change
+// handlers then get a ProductChangeResync change since the changes and events sent meanwhile were missed.
+type ProductChangeListener struct {
+	dbPool         *pgxpool.Pool
+	handlers       []ProductChangeHandler
+	eventHandlers  []PublishedEventHandler
+	reconnectDelay time.Duration
+	listening      chan struct{}
+	listeningOnce  sync.Once
+}
+
+func NewProductChangeListener(dbPool *pgxpool.Pool, reconnectDelay time.Duration) *ProductChangeListener {
+	return &ProductChangeListener{dbPool: dbPool, reconnectDelay: reconnectDelay, listening: make(chan struct{})}
+}
+
+// Listening is closed once the listener listens for the first time, the changes committed after that reach the handlers.
+func (productChangeListener *ProductChangeListener) Listening() <-chan struct{} {
+	return productChangeListener.listening
+}
+
+// Subscribe registers a handler, it should be called before Start.
+func (productChangeListener *ProductChangeListener) Subscribe(handler ProductChangeHandler) {
+	productChangeListener.handlers = append(productChangeListener.handlers, handler)
+}
+
+// SubscribePublishedEvents registers a handler for the published events, it should be called before Start.
+func (productChangeListener *ProductChangeListener) SubscribePublishedEvents(handler PublishedEventHandler) {
+	productChangeListener.eventHandlers = append(productChangeListener.eventHandlers, handler)
+}
+
+func (productChangeListener *ProductChangeListener) Start(ctx context.Context) {
+	go func() {
+		delay := productChangeListener.reconnectDelay
+		reconnecting := false
+		for {
+			listening, err := productChangeListener.listen(ctx, reconnecting)
+			if ctx.Err() != nil {
+				return
+			}
+			if listening {
+				delay = productChangeListener.reconnectDelay
+			}
+			log.Errorf("Product change listener disconnected, reconnecting in %s: %v", delay, err)
+			reconnecting = true
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxListenerReconnectDelay)
+		}
+	}()
+}
+
+// listen takes a connection out of the pool for as long as it listens and returns whether it got to listen.
+func (productChangeListener *ProductChangeListener) listen(ctx context.Context, reconnecting bool) (bool, error) {
+	pooledConn, err := productChangeListener.dbPool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	conn := pooledConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, `LISTEN `+ProductChangesChannel+`; LISTEN `+ProductEventsChannel); err != nil {
+		return false, err
+	}
+	productChangeListener.listeningOnce.Do(func() { close(productChangeListener.listening) })
+	if reconnecting {
+		log.Infof("Product change listener reconnected")
+		productChangeListener.dispatch(domain.ProductChange{Operation: domain.ProductChangeResync})
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		if notification.Channel == ProductEventsChannel {
+			eventId, err := strconv.ParseInt(notification.Payload, 10, 64)
+			if err != nil {
+				log.Errorf("Error while reading published event notification %s: %v", notification.Payload, err)
+				continue
+			}
+			for _, handler := range productChangeListener.eventHandlers {
+				handler(eventId)
+			}
+			continue
+		}
+		change, err := domain.ParseProductChange(notification.Payload)
+		if err != nil {
+			log.Errorf("Error while reading product change notification: %v", err)
+			continue
+		}
+		productChangeListener.dispatch(change)
+	}
+}
+
+func (productChangeListener *ProductChangeListener) dispatch(change domain.ProductChange) {
+	for _, handler := range productChangeListener.handlers {
+		handler(change)
+	}
+}
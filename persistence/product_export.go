@@ -0,0 +1,54 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+)
+
+// StreamByFilter reads the products matching the filter through a server side cursor and hands them to handle
+// chunkSize at a time, so the whole result is never held in memory. An error from handle stops the stream.
+func (productRepository *ProductRepository) StreamByFilter(filter domain.ProductFilter, chunkSize int, handle func(products []domain.Product) error) error {
+	ctx := context.Background()
+
+	where, args, err := buildProductFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	tx, err := productRepository.dbPool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		log.Errorf("Error while starting product stream transaction: %v", err)
+		return err
+	}
+	// the cursor is closed with the transaction
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DECLARE product_stream NO SCROLL CURSOR FOR SELECT `+productColumns+` FROM products`+where+` ORDER BY id`, args...)
+	if err != nil {
+		log.Errorf("Error while declaring product stream cursor: %v", err)
+		return err
+	}
+
+	for {
+		// FETCH takes no bind parameters
+		rows, err := tx.Query(ctx, fmt.Sprintf(`FETCH FORWARD %d FROM product_stream`, chunkSize))
+		if err != nil {
+			log.Errorf("Error while fetching from product stream cursor: %v", err)
+			return err
+		}
+		products, err := extractProductsFromRows(rows, err)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			return nil
+		}
+		if err = handle(products); err != nil {
+			return err
+		}
+	}
+}
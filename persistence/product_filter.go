@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"strings"
+)
+
+var attributeOperators = map[string]string{
+	domain.FilterOperatorGt:  ">",
+	domain.FilterOperatorGte: ">=",
+	domain.FilterOperatorLt:  "<",
+	domain.FilterOperatorLte: "<=",
+}
+
+// buildProductFilter turns a filter into a WHERE clause over the products table and its positional arguments.
+func buildProductFilter(filter domain.ProductFilter) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	includeRemoved := filter.IncludeRemoved && filter.UpdatedSince != nil
+	if !includeRemoved {
+		conditions = append(conditions, `products.deleted_at IS NULL`)
+	}
+	if len(filter.Store) > 0 {
+		addCondition(`products.store = $%d`, filter.Store)
+	}
+	if len(filter.Category) > 0 {
+		addCondition(`products.category = $%d`, filter.Category)
+	}
+	if filter.MinPrice != nil {
+		addCondition(`products.price >= $%d`, *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		addCondition(`products.price <= $%d`, *filter.MaxPrice)
+	}
+	if len(filter.Statuses) > 0 && !includeRemoved {
+		addCondition(`products.status = ANY($%d)`, filter.Statuses)
+	}
+	if filter.UpdatedSince != nil {
+		addCondition(`products.updated_at >= $%d`, *filter.UpdatedSince)
+	}
+	if len(filter.VariantAttributes) > 0 {
+		attributes, err := json.Marshal(filter.VariantAttributes)
+		if err != nil {
+			return "", nil, err
+		}
+		addCondition(`EXISTS (SELECT 1 FROM product_variants WHERE product_variants.product_id = products.id AND product_variants.attributes @> $%d::jsonb)`, string(attributes))
+	}
+
+	for _, attributeCondition := range filter.AttributeConditions {
+		if attributeCondition.Operator == domain.FilterOperatorEq {
+			// containment keeps the condition on the GIN index
+			value, err := attributeCondition.TypedValue()
+			if err != nil {
+				return "", nil, err
+			}
+			containment, err := json.Marshal(map[string]interface{}{attributeCondition.Name: value})
+			if err != nil {
+				return "", nil, err
+			}
+			addCondition(`products.attributes @> $%d::jsonb`, string(containment))
+			continue
+		}
+
+		sqlOperator, found := attributeOperators[attributeCondition.Operator]
+		if !found {
+			return "", nil, errors.New(fmt.Sprintf("Unknown attribute operator %s", attributeCondition.Operator))
+		}
+		if attributeCondition.Type != domain.AttributeTypeNumber {
+			return "", nil, errors.New(fmt.Sprintf("Attribute %s is not a number, it can only be compared with %s", attributeCondition.Name, domain.FilterOperatorEq))
+		}
+		value, err := attributeCondition.TypedValue()
+		if err != nil {
+			return "", nil, err
+		}
+		addCondition(`products.attributes ? $%d`, attributeCondition.Name)
+		nameIndex := len(args)
+		args = append(args, value)
+		// the order of AND operands is up to the planner, the CASE keeps values of other types from reaching the cast
+		conditions = append(conditions, fmt.Sprintf(`CASE WHEN jsonb_typeof(products.attributes -> $%d) = 'number' THEN (products.attributes ->> $%d)::numeric END %s $%d`,
+			nameIndex, nameIndex, sqlOperator, len(args)))
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
@@ -0,0 +1,197 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/persistence/errorMessages"
+)
+
+const productMediaColumns = `id, product_id, key, thumbnail_key, content_type, size, width, height, checksum, position, is_primary`
+
+type IProductMediaRepository interface {
+	Add(productMedia domain.ProductMedia) (int64, error)
+	GetById(id int64) (domain.ProductMedia, error)
+	GetByProductId(productId int64) ([]domain.ProductMedia, error)
+	GetByProductIds(productIds []int64) ([]domain.ProductMedia, error)
+	SetPrimary(productId int64, id int64) error
+	Reorder(productId int64, orderedIds []int64) error
+	DeleteById(id int64) error
+}
+
+type ProductMediaRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewProductMediaRepository(dbPool *pgxpool.Pool) IProductMediaRepository {
+	return &ProductMediaRepository{dbPool: dbPool}
+}
+
+// Add appends the media after the existing ones; the first media of a product becomes its primary image.
+func (productMediaRepository *ProductMediaRepository) Add(productMedia domain.ProductMedia) (int64, error) {
+	ctx := context.Background()
+
+	sqlCommand := `INSERT INTO product_media(product_id, key, thumbnail_key, content_type, size, width, height, checksum, position, is_primary)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, COALESCE(MAX(position) + 1, 0), COUNT(*) = 0
+		FROM product_media WHERE product_id = $1
+		RETURNING id`
+
+	var id int64
+	err := productMediaRepository.dbPool.QueryRow(ctx, sqlCommand,
+		productMedia.ProductId,
+		productMedia.Key,
+		productMedia.ThumbnailKey,
+		productMedia.ContentType,
+		productMedia.Size,
+		productMedia.Width,
+		productMedia.Height,
+		productMedia.Checksum).Scan(&id)
+	if _, duplicate := isUniqueViolation(err); duplicate {
+		return 0, ConflictError{Message: fmt.Sprintf("The same file is already attached to product %d", productMedia.ProductId)}
+	}
+	if err != nil {
+		log.Errorf("Error while inserting product media: %v", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (productMediaRepository *ProductMediaRepository) GetById(id int64) (domain.ProductMedia, error) {
+	ctx := context.Background()
+
+	sqlCommand := `SELECT ` + productMediaColumns + ` FROM product_media WHERE id = $1`
+
+	productMedia, err := scanProductMedia(productMediaRepository.dbPool.QueryRow(ctx, sqlCommand, id))
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return domain.ProductMedia{}, errors.New(fmt.Sprintf("Media with id %d not found", id))
+	}
+
+	if err != nil {
+		log.Errorf("Error while fetching media with id: %d %v", id, err)
+		return domain.ProductMedia{}, errors.New(fmt.Sprintf("Error while fetching media by id %d", id))
+	}
+
+	return productMedia, nil
+}
+
+func (productMediaRepository *ProductMediaRepository) GetByProductId(productId int64) ([]domain.ProductMedia, error) {
+	return productMediaRepository.GetByProductIds([]int64{productId})
+}
+
+func (productMediaRepository *ProductMediaRepository) GetByProductIds(productIds []int64) ([]domain.ProductMedia, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + productMediaColumns + ` FROM product_media WHERE product_id = ANY($1) ORDER BY product_id, position, id`
+
+	rows, err := productMediaRepository.dbPool.Query(ctx, query, productIds)
+	if err != nil {
+		log.Errorf("Error while fetching product media: %v", err)
+		return []domain.ProductMedia{}, err
+	}
+	defer rows.Close()
+
+	var productMediaList []domain.ProductMedia
+	for rows.Next() {
+		productMedia, err := scanProductMedia(rows)
+		if err != nil {
+			log.Errorf("Error while scanning product media rows: %v", err)
+			return []domain.ProductMedia{}, err
+		}
+		productMediaList = append(productMediaList, productMedia)
+	}
+
+	return productMediaList, rows.Err()
+}
+
+func (productMediaRepository *ProductMediaRepository) SetPrimary(productId int64, id int64) error {
+	ctx := context.Background()
+
+	// a single statement flips the old and the new primary together
+	sqlCommand := `UPDATE product_media SET is_primary = (id = $2) WHERE product_id = $1 AND EXISTS (SELECT 1 FROM product_media WHERE id = $2 AND product_id = $1)`
+
+	exec, err := productMediaRepository.dbPool.Exec(ctx, sqlCommand, productId, id)
+	if err != nil {
+		log.Errorf("Error while setting primary media %d of product %d: %v", id, productId, err)
+		return errors.New(fmt.Sprintf("Error while setting primary media of product %d", productId))
+	}
+	if exec.RowsAffected() == 0 {
+		return errors.New(fmt.Sprintf("Media with id %d not found for product %d", id, productId))
+	}
+
+	return nil
+}
+
+func (productMediaRepository *ProductMediaRepository) Reorder(productId int64, orderedIds []int64) error {
+	ctx := context.Background()
+
+	sqlCommand := `UPDATE product_media SET position = ordered.position - 1
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS ordered(id, position)
+		WHERE product_media.id = ordered.id AND product_media.product_id = $1`
+
+	exec, err := productMediaRepository.dbPool.Exec(ctx, sqlCommand, productId, orderedIds)
+	if err != nil {
+		log.Errorf("Error while reordering media of product %d: %v", productId, err)
+		return errors.New(fmt.Sprintf("Error while reordering media of product %d", productId))
+	}
+	if exec.RowsAffected() != int64(len(orderedIds)) {
+		return errors.New(fmt.Sprintf("Media ids should all belong to product %d", productId))
+	}
+
+	return nil
+}
+
+// DeleteById removes the media and promotes the next one in order when the primary image was removed.
+func (productMediaRepository *ProductMediaRepository) DeleteById(id int64) error {
+	ctx := context.Background()
+
+	tx, err := productMediaRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting media transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var productId int64
+	var wasPrimary bool
+	err = tx.QueryRow(ctx, `DELETE FROM product_media WHERE id = $1 RETURNING product_id, is_primary`, id).Scan(&productId, &wasPrimary)
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return errors.New(fmt.Sprintf("Media with id %d not found", id))
+	}
+	if err != nil {
+		log.Errorf("Error while deleting media with id:%d %v", id, err)
+		return errors.New(fmt.Sprintf("Error while deleting media with id %d", id))
+	}
+
+	if wasPrimary {
+		promoteCommand := `UPDATE product_media SET is_primary = true
+			WHERE id = (SELECT id FROM product_media WHERE product_id = $1 ORDER BY position, id LIMIT 1)`
+		_, err = tx.Exec(ctx, promoteCommand, productId)
+		if err != nil {
+			log.Errorf("Error while promoting primary media of product %d: %v", productId, err)
+			return errors.New(fmt.Sprintf("Error while deleting media with id %d", id))
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanProductMedia(row pgx.Row) (domain.ProductMedia, error) {
+	var productMedia domain.ProductMedia
+	err := row.Scan(&productMedia.Id,
+		&productMedia.ProductId,
+		&productMedia.Key,
+		&productMedia.ThumbnailKey,
+		&productMedia.ContentType,
+		&productMedia.Size,
+		&productMedia.Width,
+		&productMedia.Height,
+		&productMedia.Checksum,
+		&productMedia.Position,
+		&productMedia.IsPrimary)
+	return productMedia, err
+}
@@ -4,22 +4,60 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/labstack/gommon/log"
 	"go-product-app/domain"
 	"go-product-app/persistence/errorMessages"
+	"time"
 )
 
+const productColumns = `id, name, description, price, discount, store, category, status, COALESCE(sku, ''), COALESCE(gtin, ''), options, NULLIF(attributes, '{}'::jsonb),
+	(SELECT jsonb_object_agg(locale, jsonb_build_object('name', t.name, 'description', t.description)) FROM product_translations t WHERE t.product_id = products.id),
+	deleted_at, created_at, updated_at, created_by, updated_by`
+
 type IProductRepository interface {
 	GetAll() ([]domain.Product, error)
 	GetAllByStore(store string) ([]domain.Product, error)
-	Add(product domain.Product) error
+	GetAllByFilter(filter domain.ProductFilter) ([]domain.Product, error)
+	StreamByFilter(filter domain.ProductFilter, chunkSize int, handle func(products []domain.Product) error) error
+	Add(product domain.Product) (int64, error)
 	GetById(id int64) (domain.Product, error)
-	DeleteById(id int64) error
-	UpdateProductPrice(id int64, price float32) error
+	GetBySku(sku string, store string) ([]domain.Product, error)
+	GetByIds(ids []int64) ([]domain.Product, error)
+	DeleteById(id int64, actor string) error
+	UpdateProductPrice(id int64, price float32, actor string) error
+	Update(product domain.Product) error
+	UpdateTranslations(id int64, translations map[string]domain.LocalizedText, actor string) error
+	UpdateStatus(statusChange domain.ProductStatusChange) error
+	GetStatusHistory(productId int64) ([]domain.ProductStatusChange, error)
+	GetAllDeleted() ([]domain.Product, error)
+	RestoreById(id int64, actor string) error
+	PurgeDeleted(deletedBefore time.Time) (int64, []string, error)
+	Search(query domain.ProductSearchQuery) (domain.ProductSearchResult, error)
+	GetStats(query domain.ProductStatsQuery) ([]domain.ProductStatsGroup, error)
+	AddBatch(products []domain.Product, atomic bool) ([]domain.BatchItemResult, error)
+	UpdatePricesBatch(updates []domain.ProductPriceUpdate, actor string, atomic bool) ([]domain.BatchItemResult, error)
+	DeleteBatch(ids []int64, actor string, atomic bool) ([]domain.BatchItemResult, error)
+	Reprice(repricing domain.Repricing) (domain.Repricing, []domain.PriceChange, error)
+	GetRepricingById(id int64) (domain.Repricing, error)
+	UndoRepricing(id int64, actor string) (domain.Repricing, []domain.PriceChange, error)
+	GetPriceHistory(productId int64) ([]domain.PriceChange, error)
 }
 
+// updatePriceCommand sets the price of a live product, $1 is the price, $2 the id and $3 the actor.
+var updatePriceCommand = `WITH previous AS (
+		SELECT id, price, discount FROM products WHERE id = $2 AND deleted_at IS NULL FOR UPDATE
+	), changed AS (
+		UPDATE products SET price = $1, updated_at = now(), updated_by = $3 FROM previous WHERE products.id = previous.id` + returningPriceChange + `
+	)` + priceChangedEvents(domain.PriceChangeReasonUpdate, "NULL")
+
+// deleteProductCommand soft deletes a live product, $1 is the id and $2 the actor.
+const deleteProductCommand = `WITH changed AS (
+		UPDATE products SET deleted_at = now(), updated_at = now(), updated_by = $2 WHERE id = $1 AND deleted_at IS NULL RETURNING id, store, status, updated_by AS actor
+	)` + productDeletedEvents
+
 type ProductRepository struct {
 	dbPool *pgxpool.Pool
 }
@@ -28,28 +66,209 @@ func NewProductRepository(dbPool *pgxpool.Pool) IProductRepository {
 	return &ProductRepository{dbPool: dbPool}
 }
 
-func (productRepository *ProductRepository) Add(product domain.Product) error {
+func (productRepository *ProductRepository) Add(product domain.Product) (int64, error) {
+	ctx := context.Background()
+
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting product insert transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	sqlCommand := `WITH changed AS (
+			INSERT INTO products(name, description, price, discount, store, category, status, sku, gtin, options, attributes, created_by, updated_by)
+			VALUES($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), 'active'), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10::text[], '{}'), COALESCE(NULLIF($11::jsonb, 'null'), '{}'), $12, $12)` +
+		returningCreatedProduct + `)` + productCreatedEvents
+
+	var id int64
+	err = tx.QueryRow(ctx, sqlCommand, product.Name, product.Description, product.Price, product.Discount, product.Store, product.Category, product.Status, product.Sku, product.Gtin, product.Options, product.Attributes, product.CreatedBy).Scan(&id)
+	if pgError, duplicate := isUniqueViolation(err); duplicate {
+		return 0, productConflictError(pgError, product)
+	}
+	if err != nil {
+		log.Errorf("Error while inserting product: %v", err)
+		return 0, err
+	}
+
+	if err = upsertTranslations(ctx, tx, id, product.Translations); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Errorf("Error while committing product insert: %v", err)
+		return 0, err
+	}
+
+	log.Infof("Product added successfully with id %d", id)
+	return id, nil
+}
+
+// Update replaces the editable fields of the product and upserts its translations.
+// Store and status are kept, a product changes status through UpdateStatus only.
+func (productRepository *ProductRepository) Update(product domain.Product) error {
+	ctx := context.Background()
+
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting product update transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	sqlCommand := `WITH previous AS (
+			SELECT id, price, discount FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+		), changed AS (
+			UPDATE products SET name = $2, description = $3, price = $4, discount = $5, category = $6, sku = NULLIF($7, ''), gtin = NULLIF($8, ''),
+			options = NULLIF($9::text[], '{}'), attributes = COALESCE(NULLIF($10::jsonb, 'null'), '{}'), updated_at = now(), updated_by = $11
+			FROM previous WHERE products.id = previous.id` + returningPriceChange + `,
+			products.name, products.price, products.discount, products.category, products.sku)` + productUpdatedEvent +
+		priceChangedEvents(domain.PriceChangeReasonUpdate, "NULL")
+
+	commandTag, err := tx.Exec(ctx, sqlCommand, product.Id, product.Name, product.Description, product.Price, product.Discount, product.Category,
+		product.Sku, product.Gtin, product.Options, product.Attributes, product.UpdatedBy)
+	if pgError, duplicate := isUniqueViolation(err); duplicate {
+		return productConflictError(pgError, product)
+	}
+	if err != nil {
+		log.Errorf("Error while updating product with id %d: %v", product.Id, err)
+		return errors.New(fmt.Sprintf("Error while updating product with id %d", product.Id))
+	}
+	if commandTag.RowsAffected() == 0 {
+		return NotFoundError{Message: fmt.Sprintf("Product with id %d not found", product.Id)}
+	}
+
+	if err = upsertTranslations(ctx, tx, product.Id, product.Translations); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		log.Errorf("Error while committing product update: %v", err)
+		return err
+	}
+
+	log.Infof("Product with id %d updated", product.Id)
+	return nil
+}
+
+func (productRepository *ProductRepository) UpdateTranslations(id int64, translations map[string]domain.LocalizedText, actor string) error {
 	ctx := context.Background()
 
-	sqlCommand := `INSERT INTO products(name, price, discount, store) VALUES($1, $2, $3, $4)`
+	_, err := productRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting translation update transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = upsertTranslations(ctx, tx, id, translations); err != nil {
+		return errors.New(fmt.Sprintf("Error while updating translations of product with id %d", id))
+	}
+	_, err = tx.Exec(ctx, `UPDATE products SET updated_at = now(), updated_by = $1 WHERE id = $2`, actor, id)
+	if err != nil {
+		log.Errorf("Error while touching product %d after translation update: %v", id, err)
+		return errors.New(fmt.Sprintf("Error while updating translations of product with id %d", id))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Errorf("Error while committing translation update: %v", err)
+		return errors.New(fmt.Sprintf("Error while updating translations of product with id %d", id))
+	}
+	return nil
+}
+
+// UpdateStatus moves the product from FromStatus to ToStatus and records the change. The update only
+// matches while the product is still in FromStatus, so a concurrent transition is reported as a conflict.
+func (productRepository *ProductRepository) UpdateStatus(statusChange domain.ProductStatusChange) error {
+	ctx := context.Background()
 
-	exec, err := productRepository.dbPool.Exec(ctx, sqlCommand, product.Name, product.Price, product.Discount, product.Store)
+	tx, err := productRepository.dbPool.Begin(ctx)
 	if err != nil {
-		log.Error("Error while inserting product: %v\n", err)
+		log.Errorf("Error while starting status update transaction: %v", err)
 		return err
 	}
+	defer tx.Rollback(ctx)
+
+	sqlCommand := `WITH changed AS (
+			UPDATE products SET status = $1, updated_at = now(), updated_by = $4 WHERE id = $2 AND status = $3 AND deleted_at IS NULL` +
+		returningUpdatedProduct + `)` + productUpdatedEvents
+
+	commandTag, err := tx.Exec(ctx, sqlCommand, statusChange.ToStatus, statusChange.ProductId, statusChange.FromStatus, statusChange.Actor)
+	if err != nil {
+		log.Errorf("Error while updating status of product %d: %v", statusChange.ProductId, err)
+		return errors.New(fmt.Sprintf("Error while updating status of product with id %d", statusChange.ProductId))
+	}
+	if commandTag.RowsAffected() == 0 {
+		return ConflictError{Message: fmt.Sprintf("Product with id %d is no longer %s", statusChange.ProductId, statusChange.FromStatus)}
+	}
 
-	log.Info("Product added successfully: %v\n", exec)
+	_, err = tx.Exec(ctx, `INSERT INTO product_status_history(product_id, from_status, to_status, actor, reason, changed_at)
+		VALUES($1, $2, $3, $4, $5, $6)`,
+		statusChange.ProductId, statusChange.FromStatus, statusChange.ToStatus, statusChange.Actor, statusChange.Reason, statusChange.ChangedAt)
+	if err != nil {
+		log.Errorf("Error while recording status change of product %d: %v", statusChange.ProductId, err)
+		return errors.New(fmt.Sprintf("Error while updating status of product with id %d", statusChange.ProductId))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Errorf("Error while committing status update: %v", err)
+		return errors.New(fmt.Sprintf("Error while updating status of product with id %d", statusChange.ProductId))
+	}
+	return nil
+}
+
+func (productRepository *ProductRepository) GetStatusHistory(productId int64) ([]domain.ProductStatusChange, error) {
+	ctx := context.Background()
+
+	query := `SELECT id, product_id, from_status, to_status, actor, reason, changed_at
+		FROM product_status_history WHERE product_id = $1 ORDER BY changed_at, id`
+
+	rows, err := productRepository.dbPool.Query(ctx, query, productId)
+	if err != nil {
+		log.Errorf("Error while fetching status history of product %d: %v", productId, err)
+		return []domain.ProductStatusChange{}, err
+	}
+	defer rows.Close()
+
+	statusChanges := make([]domain.ProductStatusChange, 0)
+	for rows.Next() {
+		var statusChange domain.ProductStatusChange
+		err = rows.Scan(&statusChange.Id, &statusChange.ProductId, &statusChange.FromStatus, &statusChange.ToStatus,
+			&statusChange.Actor, &statusChange.Reason, &statusChange.ChangedAt)
+		if err != nil {
+			log.Errorf("Error while scanning status history rows: %v", err)
+			return []domain.ProductStatusChange{}, err
+		}
+		statusChanges = append(statusChanges, statusChange)
+	}
+	return statusChanges, rows.Err()
+}
+
+func upsertTranslations(ctx context.Context, tx pgx.Tx, productId int64, translations map[string]domain.LocalizedText) error {
+	sqlCommand := `INSERT INTO product_translations(product_id, locale, name, description) VALUES($1, $2, $3, $4)
+		ON CONFLICT (product_id, locale) DO UPDATE SET name = EXCLUDED.name, description = EXCLUDED.description`
+
+	for locale, text := range translations {
+		_, err := tx.Exec(ctx, sqlCommand, productId, locale, text.Name, text.Description)
+		if err != nil {
+			log.Errorf("Error while saving %s translation of product %d: %v", locale, productId, err)
+			return err
+		}
+	}
 	return nil
 }
 
 func (productRepository *ProductRepository) GetById(id int64) (domain.Product, error) {
 	ctx := context.Background()
 
-	sqlCommand := `SELECT * FROM products WHERE id = $1`
+	sqlCommand := `SELECT ` + productColumns + ` FROM products WHERE id = $1 AND deleted_at IS NULL`
 
 	var product domain.Product
-	err := productRepository.dbPool.QueryRow(ctx, sqlCommand, id).Scan(&product.Id, &product.Name, &product.Price, &product.Discount, &product.Store)
+	err := productRepository.dbPool.QueryRow(ctx, sqlCommand, id).Scan(productScanTargets(&product)...)
 	if err != nil && err.Error() == errorMessages.NOT_FOUND {
 		return domain.Product{}, errors.New(fmt.Sprintf("Product with id %d not found", id))
 	}
@@ -64,7 +283,7 @@ func (productRepository *ProductRepository) GetById(id int64) (domain.Product, e
 
 func (productRepository *ProductRepository) GetAll() ([]domain.Product, error) {
 	ctx := context.Background()
-	productRows, err := productRepository.dbPool.Query(ctx, "SELECT * FROM products")
+	productRows, err := productRepository.dbPool.Query(ctx, "SELECT "+productColumns+" FROM products WHERE deleted_at IS NULL")
 	if err != nil {
 		log.Error("Error while fetching products: %v\n", err)
 		return []domain.Product{}, err
@@ -76,7 +295,7 @@ func (productRepository *ProductRepository) GetAll() ([]domain.Product, error) {
 func (productRepository *ProductRepository) GetAllByStore(store string) ([]domain.Product, error) {
 	context := context.Background()
 
-	query := `SELECT *FROM products WHERE store= $1`
+	query := `SELECT ` + productColumns + ` FROM products WHERE store= $1 AND deleted_at IS NULL`
 
 	rows, err := productRepository.dbPool.Query(context, query, store)
 	if err != nil {
@@ -87,13 +306,83 @@ func (productRepository *ProductRepository) GetAllByStore(store string) ([]domai
 	return extractProductsFromRows(rows, err)
 }
 
+func (productRepository *ProductRepository) GetAllByFilter(filter domain.ProductFilter) ([]domain.Product, error) {
+	ctx := context.Background()
+
+	where, args, err := buildProductFilter(filter)
+	if err != nil {
+		return []domain.Product{}, err
+	}
+	query := `SELECT ` + productColumns + ` FROM products` + where + ` ORDER BY id`
+
+	rows, err := productRepository.dbPool.Query(ctx, query, args...)
+	if err != nil {
+		log.Errorf("Error while fetching products by filter: %v", err)
+		return []domain.Product{}, err
+	}
+
+	return extractProductsFromRows(rows, err)
+}
+
+func (productRepository *ProductRepository) GetBySku(sku string, store string) ([]domain.Product, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE sku = $1 AND ($2 = '' OR store = $2) AND deleted_at IS NULL ORDER BY id`
+
+	rows, err := productRepository.dbPool.Query(ctx, query, sku, store)
+	if err != nil {
+		log.Errorf("Error while fetching products by sku %s: %v", sku, err)
+		return []domain.Product{}, err
+	}
+
+	products, err := extractProductsFromRows(rows, err)
+	if err != nil {
+		return []domain.Product{}, err
+	}
+	if len(products) == 0 {
+		return []domain.Product{}, NotFoundError{Message: fmt.Sprintf("Product with sku %s not found", sku)}
+	}
+	return products, nil
+}
+
+func (productRepository *ProductRepository) GetByIds(ids []int64) ([]domain.Product, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id`
+
+	rows, err := productRepository.dbPool.Query(ctx, query, ids)
+	if err != nil {
+		log.Errorf("Error while fetching products by ids: %v", err)
+		return []domain.Product{}, err
+	}
+
+	return extractProductsFromRows(rows, err)
+}
+
+func productConflictError(pgError *pgconn.PgError, product domain.Product) error {
+	if pgError.ConstraintName == "products_store_gtin_key" {
+		return ConflictError{Message: fmt.Sprintf("Product with gtin %s already exists in store %s", product.Gtin, product.Store)}
+	}
+	if pgError.ConstraintName == storeSkuConstraint {
+		return ConflictError{Message: fmt.Sprintf("Sku %s is used by a variant in store %s", product.Sku, product.Store)}
+	}
+	return ConflictError{Message: fmt.Sprintf("Product with sku %s already exists in store %s", product.Sku, product.Store)}
+}
+
+// productScanTargets returns the fields of product in the order of productColumns.
+func productScanTargets(product *domain.Product) []interface{} {
+	return []interface{}{&product.Id, &product.Name, &product.Description, &product.Price, &product.Discount, &product.Store,
+		&product.Category, &product.Status, &product.Sku, &product.Gtin, &product.Options, &product.Attributes, &product.Translations,
+		&product.DeletedAt, &product.CreatedAt, &product.UpdatedAt, &product.CreatedBy, &product.UpdatedBy}
+}
+
 func extractProductsFromRows(productRows pgx.Rows, err error) ([]domain.Product, error) {
 	var products []domain.Product
 
 	for productRows.Next() {
 
 		var product domain.Product
-		err = productRows.Scan(&product.Id, &product.Name, &product.Price, &product.Discount, &product.Store)
+		err = productRows.Scan(productScanTargets(&product)...)
 		if err != nil {
 			log.Error("Error while scanning product rows: %v\n", err)
 			return []domain.Product{}, err
@@ -105,7 +394,7 @@ func extractProductsFromRows(productRows pgx.Rows, err error) ([]domain.Product,
 	return products, nil
 }
 
-func (productRepository *ProductRepository) DeleteById(id int64) error {
+func (productRepository *ProductRepository) DeleteById(id int64, actor string) error {
 	ctx := context.Background()
 
 	_, err := productRepository.GetById(id)
@@ -114,9 +403,7 @@ func (productRepository *ProductRepository) DeleteById(id int64) error {
 		return errors.New(fmt.Sprintf("Product with id %d not found", id))
 	}
 
-	sqlCommand := `DELETE FROM products WHERE id = $1`
-
-	exec, err := productRepository.dbPool.Exec(ctx, sqlCommand, id)
+	exec, err := productRepository.dbPool.Exec(ctx, deleteProductCommand, id, actor)
 	if err != nil {
 		log.Error("Error while deleting product with id:%d %v\n", id, err)
 		return errors.New(fmt.Sprintf("Error while deleting product with id %d", id))
@@ -126,7 +413,89 @@ func (productRepository *ProductRepository) DeleteById(id int64) error {
 	return nil
 }
 
-func (productRepository *ProductRepository) UpdateProductPrice(id int64, price float32) error {
+func (productRepository *ProductRepository) GetAllDeleted() ([]domain.Product, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id`
+
+	rows, err := productRepository.dbPool.Query(ctx, query)
+	if err != nil {
+		log.Errorf("Error while fetching deleted products: %v", err)
+		return []domain.Product{}, err
+	}
+
+	return extractProductsFromRows(rows, err)
+}
+
+// RestoreById brings back a soft deleted product and announces it with a product.updated event. A live product
+// may have taken its sku or gtin since, which is reported as a conflict.
+func (productRepository *ProductRepository) RestoreById(id int64, actor string) error {
+	ctx := context.Background()
+
+	sqlCommand := `WITH changed AS (
+			UPDATE products SET deleted_at = NULL, updated_at = now(), updated_by = $2 WHERE id = $1 AND deleted_at IS NOT NULL` +
+		returningUpdatedProduct + `)` + productUpdatedEvents
+
+	commandTag, err := productRepository.dbPool.Exec(ctx, sqlCommand, id, actor)
+	if pgError, duplicate := isUniqueViolation(err); duplicate {
+		if pgError.ConstraintName == "products_store_gtin_key" {
+			return ConflictError{Message: fmt.Sprintf("Product with id %d can not be restored, its gtin is in use", id)}
+		}
+		return ConflictError{Message: fmt.Sprintf("Product with id %d can not be restored, its sku is in use", id)}
+	}
+	if err != nil {
+		log.Errorf("Error while restoring product with id %d: %v", id, err)
+		return errors.New(fmt.Sprintf("Error while restoring product with id %d", id))
+	}
+	if commandTag.RowsAffected() == 0 {
+		return NotFoundError{Message: fmt.Sprintf("Deleted product with id %d not found", id)}
+	}
+
+	log.Infof("Product with id %d restored", id)
+	return nil
+}
+
+// PurgeDeleted hard deletes the products soft deleted before deletedBefore, their dependent rows go with them.
+// It returns the storage keys of the media files of the purged products, which are left to the caller to delete.
+func (productRepository *ProductRepository) PurgeDeleted(deletedBefore time.Time) (int64, []string, error) {
+	ctx := context.Background()
+
+	// the select reads product_media as it was before the delete cascaded to it
+	sqlCommand := `WITH purged AS (
+			DELETE FROM products WHERE deleted_at < $1 RETURNING id
+		)
+		SELECT purged.id, product_media.key, product_media.thumbnail_key
+		FROM purged LEFT JOIN product_media ON product_media.product_id = purged.id`
+
+	rows, err := productRepository.dbPool.Query(ctx, sqlCommand, deletedBefore)
+	if err != nil {
+		log.Errorf("Error while purging deleted products: %v", err)
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	purged := make(map[int64]bool)
+	mediaKeys := make([]string, 0)
+	for rows.Next() {
+		var id int64
+		var key, thumbnailKey *string
+		if err = rows.Scan(&id, &key, &thumbnailKey); err != nil {
+			log.Errorf("Error while scanning purged products: %v", err)
+			return 0, nil, err
+		}
+		purged[id] = true
+		if key != nil {
+			mediaKeys = append(mediaKeys, *key, *thumbnailKey)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error while purging deleted products: %v", err)
+		return 0, nil, err
+	}
+	return int64(len(purged)), mediaKeys, nil
+}
+
+func (productRepository *ProductRepository) UpdateProductPrice(id int64, price float32, actor string) error {
 	ctx := context.Background()
 
 	_, err := productRepository.GetById(id)
@@ -135,9 +504,7 @@ func (productRepository *ProductRepository) UpdateProductPrice(id int64, price f
 		return err
 	}
 
-	sqlCommand := `UPDATE products SET price = $1 WHERE id = $2`
-
-	exec, err := productRepository.dbPool.Exec(ctx, sqlCommand, price, id)
+	exec, err := productRepository.dbPool.Exec(ctx, updatePriceCommand, price, id, actor)
 
 	if err != nil {
 		log.Error("Error while updating product price with id:%d %v\n", id, err)
@@ -0,0 +1,252 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/persistence/errorMessages"
+)
+
+const repricingColumns = `id, filter, operation, status, affected, reverted, skipped, created_by, created_at, undone_by, undone_at`
+
+// Reprice applies the operation to every product matching the filter in one transaction. The products are
+// locked while the changes are planned, so the recorded history matches what was written.
+func (productRepository *ProductRepository) Reprice(repricing domain.Repricing) (domain.Repricing, []domain.PriceChange, error) {
+	ctx := context.Background()
+
+	where, args, err := buildProductFilter(repricing.Filter)
+	if err != nil {
+		return domain.Repricing{}, nil, err
+	}
+
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting repricing transaction: %v", err)
+		return domain.Repricing{}, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT products.id, products.name, products.price, products.discount FROM products`+where+` ORDER BY products.id FOR UPDATE`, args...)
+	if err != nil {
+		log.Errorf("Error while locking products to reprice: %v", err)
+		return domain.Repricing{}, nil, err
+	}
+	var products []domain.Product
+	for rows.Next() {
+		var product domain.Product
+		if err = rows.Scan(&product.Id, &product.Name, &product.Price, &product.Discount); err != nil {
+			rows.Close()
+			log.Errorf("Error while scanning products to reprice: %v", err)
+			return domain.Repricing{}, nil, err
+		}
+		products = append(products, product)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return domain.Repricing{}, nil, err
+	}
+
+	changes, err := domain.PlanRepricing(products, repricing.Operation)
+	if err != nil {
+		return domain.Repricing{}, nil, err
+	}
+
+	filter, err := json.Marshal(repricing.Filter)
+	if err != nil {
+		return domain.Repricing{}, nil, err
+	}
+	operation, err := json.Marshal(repricing.Operation)
+	if err != nil {
+		return domain.Repricing{}, nil, err
+	}
+	addedRepricing, err := scanRepricing(tx.QueryRow(ctx, `INSERT INTO repricings(filter, operation, status, affected, created_by)
+		VALUES($1, $2, $3, $4, $5) RETURNING `+repricingColumns,
+		filter, operation, domain.RepricingStatusApplied, len(changes), repricing.CreatedBy))
+	if err != nil {
+		log.Errorf("Error while inserting repricing: %v", err)
+		return domain.Repricing{}, nil, err
+	}
+
+	batch := &pgx.Batch{}
+	for i := range changes {
+		changes[i].RepricingId, changes[i].Reason, changes[i].Actor = addedRepricing.Id, domain.PriceChangeReasonRepricing, repricing.CreatedBy
+		queuePriceChange(batch, changes[i])
+	}
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		log.Errorf("Error while writing repricing %d: %v", addedRepricing.Id, err)
+		return domain.Repricing{}, nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Errorf("Error while committing repricing %d: %v", addedRepricing.Id, err)
+		return domain.Repricing{}, nil, err
+	}
+	log.Infof("Repricing %d changed %d of %d matched products", addedRepricing.Id, len(changes), len(products))
+	return addedRepricing, changes, nil
+}
+
+func (productRepository *ProductRepository) GetRepricingById(id int64) (domain.Repricing, error) {
+	ctx := context.Background()
+
+	repricing, err := scanRepricing(productRepository.dbPool.QueryRow(ctx, `SELECT `+repricingColumns+` FROM repricings WHERE id = $1`, id))
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return domain.Repricing{}, NotFoundError{Message: fmt.Sprintf("Repricing with id %d not found", id)}
+	}
+	if err != nil {
+		log.Errorf("Error while fetching repricing with id %d: %v", id, err)
+		return domain.Repricing{}, err
+	}
+	return repricing, nil
+}
+
+// UndoRepricing restores the price and discount each product had before the repricing. A product that
+// was changed again since, or deleted, is skipped so that later edits are never overwritten.
+func (productRepository *ProductRepository) UndoRepricing(id int64, actor string) (domain.Repricing, []domain.PriceChange, error) {
+	ctx := context.Background()
+
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting repricing undo transaction: %v", err)
+		return domain.Repricing{}, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	repricing, err := scanRepricing(tx.QueryRow(ctx, `SELECT `+repricingColumns+` FROM repricings WHERE id = $1 FOR UPDATE`, id))
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return domain.Repricing{}, nil, NotFoundError{Message: fmt.Sprintf("Repricing with id %d not found", id)}
+	}
+	if err != nil {
+		log.Errorf("Error while locking repricing with id %d: %v", id, err)
+		return domain.Repricing{}, nil, err
+	}
+	if repricing.Status == domain.RepricingStatusUndone {
+		return domain.Repricing{}, nil, ConflictError{Message: fmt.Sprintf("Repricing with id %d is already undone", id)}
+	}
+
+	changes, err := queryPriceChanges(ctx, tx, `price_history.repricing_id = $1 AND price_history.reason = $2`, id, domain.PriceChangeReasonRepricing)
+	if err != nil {
+		return domain.Repricing{}, nil, err
+	}
+
+	batch := &pgx.Batch{}
+	for _, change := range changes {
+		batch.Queue(`WITH previous AS (
+				SELECT id, price, discount FROM products WHERE id = $1 AND price = $4 AND discount = $5 AND deleted_at IS NULL FOR UPDATE
+			), changed AS (
+				UPDATE products SET price = $2, discount = $3, updated_at = now(), updated_by = $6 FROM previous WHERE products.id = previous.id`+returningPriceChange+`
+			)`+priceChangedEvents(domain.PriceChangeReasonUndo, "$7::bigint"),
+			change.ProductId, change.OldPrice, change.OldDiscount, change.NewPrice, change.NewDiscount, actor, id)
+	}
+	batchResults := tx.SendBatch(ctx, batch)
+	reverted := make([]domain.PriceChange, 0, len(changes))
+	for _, change := range changes {
+		commandTag, err := batchResults.Exec()
+		if err != nil {
+			batchResults.Close()
+			log.Errorf("Error while undoing repricing %d of product %d: %v", id, change.ProductId, err)
+			return domain.Repricing{}, nil, err
+		}
+		if commandTag.RowsAffected() > 0 {
+			reverted = append(reverted, domain.PriceChange{
+				ProductId:   change.ProductId,
+				ProductName: change.ProductName,
+				RepricingId: id,
+				Reason:      domain.PriceChangeReasonUndo,
+				OldPrice:    change.NewPrice,
+				NewPrice:    change.OldPrice,
+				OldDiscount: change.NewDiscount,
+				NewDiscount: change.OldDiscount,
+				Actor:       actor,
+			})
+		}
+	}
+	if err = batchResults.Close(); err != nil {
+		log.Errorf("Error while closing repricing %d undo: %v", id, err)
+		return domain.Repricing{}, nil, err
+	}
+
+	repricing, err = scanRepricing(tx.QueryRow(ctx, `UPDATE repricings SET status = $2, reverted = $3, skipped = $4, undone_by = $5, undone_at = now()
+		WHERE id = $1 RETURNING `+repricingColumns,
+		id, domain.RepricingStatusUndone, len(reverted), len(changes)-len(reverted), actor))
+	if err != nil {
+		log.Errorf("Error while marking repricing %d as undone: %v", id, err)
+		return domain.Repricing{}, nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Errorf("Error while committing repricing %d undo: %v", id, err)
+		return domain.Repricing{}, nil, err
+	}
+	log.Infof("Repricing %d undone, %d products reverted and %d skipped", id, repricing.Reverted, repricing.Skipped)
+	return repricing, reverted, nil
+}
+
+// GetPriceHistory lists every change of the stored price or discount of the product. Time-boxed price schedules are
+// overlaid when the product is read and do not change the stored price, so they are not part of the history.
+func (productRepository *ProductRepository) GetPriceHistory(productId int64) ([]domain.PriceChange, error) {
+	ctx := context.Background()
+
+	return queryPriceChanges(ctx, productRepository.dbPool, `price_history.product_id = $1`, productId)
+}
+
+// queuePriceChange writes the new price and discount of a product, the history entry is recorded with the event.
+func queuePriceChange(batch *pgx.Batch, change domain.PriceChange) {
+	batch.Queue(`WITH previous AS (
+			SELECT id, price, discount FROM products WHERE id = $1 FOR UPDATE
+		), changed AS (
+			UPDATE products SET price = $2, discount = $3, updated_at = now(), updated_by = $4 FROM previous WHERE products.id = previous.id`+returningPriceChange+`
+		)`+priceChangedEvents(change.Reason, "$5::bigint"),
+		change.ProductId, change.NewPrice, change.NewDiscount, change.Actor, change.RepricingId)
+}
+
+// queryPriceChanges runs on the pool or inside a transaction, whichever the caller holds.
+func queryPriceChanges(ctx context.Context, querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}, condition string, args ...interface{}) ([]domain.PriceChange, error) {
+	query := `SELECT price_history.id, price_history.product_id, products.name, COALESCE(price_history.repricing_id, 0), price_history.reason,
+			price_history.old_price, price_history.new_price, price_history.old_discount, price_history.new_discount,
+			price_history.actor, price_history.changed_at
+		FROM price_history JOIN products ON products.id = price_history.product_id
+		WHERE ` + condition + ` ORDER BY price_history.changed_at, price_history.id`
+
+	rows, err := querier.Query(ctx, query, args...)
+	if err != nil {
+		log.Errorf("Error while fetching price history: %v", err)
+		return []domain.PriceChange{}, err
+	}
+	defer rows.Close()
+
+	changes := make([]domain.PriceChange, 0)
+	for rows.Next() {
+		var change domain.PriceChange
+		err = rows.Scan(&change.Id, &change.ProductId, &change.ProductName, &change.RepricingId, &change.Reason,
+			&change.OldPrice, &change.NewPrice, &change.OldDiscount, &change.NewDiscount, &change.Actor, &change.ChangedAt)
+		if err != nil {
+			log.Errorf("Error while scanning price history rows: %v", err)
+			return []domain.PriceChange{}, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+func scanRepricing(row pgx.Row) (domain.Repricing, error) {
+	var repricing domain.Repricing
+	var filter, operation []byte
+	err := row.Scan(&repricing.Id, &filter, &operation, &repricing.Status, &repricing.Affected, &repricing.Reverted, &repricing.Skipped,
+		&repricing.CreatedBy, &repricing.CreatedAt, &repricing.UndoneBy, &repricing.UndoneAt)
+	if err != nil {
+		return domain.Repricing{}, err
+	}
+	if err = json.Unmarshal(filter, &repricing.Filter); err != nil {
+		return domain.Repricing{}, errors.New(fmt.Sprintf("Filter of repricing %d can not be read: %v", repricing.Id, err))
+	}
+	if err = json.Unmarshal(operation, &repricing.Operation); err != nil {
+		return domain.Repricing{}, errors.New(fmt.Sprintf("Operation of repricing %d can not be read: %v", repricing.Id, err))
+	}
+	return repricing, nil
+}
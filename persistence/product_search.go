@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+)
+
+// searchVectorColumns maps a search language to its text search configuration and the generated tsvector column built with it.
+var searchVectorColumns = map[string][2]string{
+	domain.SearchLanguageEnglish: {"english", "search_vector_english"},
+	domain.SearchLanguageTurkish: {"turkish", "search_vector_turkish"},
+}
+
+// escapedHtml escapes the text of the SQL expression for html, so the snippet only carries the tags of the highlight.
+// The text search parser reads the entities as entities, ts_headline neither matches nor cuts them.
+func escapedHtml(expression string) string {
+	return `replace(replace(replace(replace(replace(` + expression + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+}
+
+// Search ranks products by full text relevance, name matches weigh more than description matches. The names and
+// descriptions of the translations are searched with the same weights as the row text, so a product can be found in
+// any of its locales. Trigram word similarity on the names lets queries with typos still match.
+func (productRepository *ProductRepository) Search(query domain.ProductSearchQuery) (domain.ProductSearchResult, error) {
+	ctx := context.Background()
+
+	searchVector, found := searchVectorColumns[query.Language]
+	if !found {
+		return domain.ProductSearchResult{}, errors.New(fmt.Sprintf("Search language %s is not supported", query.Language))
+	}
+	configuration, vectorColumn := searchVector[0], searchVector[1]
+
+	where, args, err := buildProductFilter(query.Filter)
+	if err != nil {
+		return domain.ProductSearchResult{}, err
+	}
+	args = append(args, query.Text)
+	textIndex := len(args)
+	args = append(args, query.Limit, query.Offset)
+
+	sqlQuery := fmt.Sprintf(`SELECT `+productColumns+`,
+			ts_rank_cd(products.%[1]s || translated.vector, search.query)
+				+ greatest(word_similarity($%[3]d, products.name), word_similarity($%[3]d, translated.names)) AS rank,
+			ts_headline('%[2]s', `+escapedHtml(`concat_ws(' ', products.name, products.description, translated.names, translated.descriptions)`)+`, search.query,
+				'StartSel=<b>, StopSel=</b>, MaxFragments=2, MinWords=5, MaxWords=20') AS snippet,
+			count(*) OVER () AS total
+		FROM products
+			CROSS JOIN (SELECT websearch_to_tsquery('%[2]s', $%[3]d) AS query) search
+			CROSS JOIN LATERAL (SELECT coalesce(string_agg(t.name, ' '), '') AS names, coalesce(string_agg(t.description, ' '), '') AS descriptions,
+					setweight(to_tsvector('%[2]s', coalesce(string_agg(t.name, ' '), '')), 'A')
+						|| setweight(to_tsvector('%[2]s', coalesce(string_agg(t.description, ' '), '')), 'B') AS vector
+				FROM product_translations t WHERE t.product_id = products.id) translated
+		%[4]s AND (products.%[1]s @@ search.query OR translated.vector @@ search.query
+			OR $%[3]d <%% products.name OR $%[3]d <%% translated.names)
+		ORDER BY rank DESC, products.id
+		LIMIT $%[5]d OFFSET $%[6]d`, vectorColumn, configuration, textIndex, where, textIndex+1, textIndex+2)
+
+	rows, err := productRepository.dbPool.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		log.Errorf("Error while searching products: %v", err)
+		return domain.ProductSearchResult{}, err
+	}
+	defer rows.Close()
+
+	result := domain.ProductSearchResult{Hits: make([]domain.ProductSearchHit, 0)}
+	for rows.Next() {
+		var hit domain.ProductSearchHit
+		err = rows.Scan(append(productScanTargets(&hit.Product), &hit.Rank, &hit.Snippet, &result.Total)...)
+		if err != nil {
+			log.Errorf("Error while scanning product search rows: %v", err)
+			return domain.ProductSearchResult{}, err
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, rows.Err()
+}
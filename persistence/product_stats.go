@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"strings"
+)
+
+// statsDimensionExpression returns the SQL expression a stats dimension groups by.
+func statsDimensionExpression(dimension string) (string, error) {
+	switch dimension {
+	case domain.StatsDimensionStore:
+		return `COALESCE(products.store, '')`, nil
+	case domain.StatsDimensionCategory:
+		return `COALESCE(products.category, '')`, nil
+	case domain.StatsDimensionDiscountBand:
+		// mirrors domain.DiscountBandOf
+		bands := domain.DiscountBands()
+		expression := fmt.Sprintf(`CASE WHEN COALESCE(products.discount, 0) = 0 THEN '%s'`, bands[0])
+		for i, upperBound := range domain.DiscountBandBounds {
+			expression += fmt.Sprintf(` WHEN products.discount < %g THEN '%s'`, upperBound, bands[i+1])
+		}
+		return expression + fmt.Sprintf(` ELSE '%s' END`, bands[len(bands)-1]), nil
+	}
+	return "", errors.New(fmt.Sprintf("Unknown stats dimension %s", dimension))
+}
+
+// GetStats aggregates the products matching the filter per combination of the group by dimensions.
+func (productRepository *ProductRepository) GetStats(query domain.ProductStatsQuery) ([]domain.ProductStatsGroup, error) {
+	ctx := context.Background()
+
+	where, args, err := buildProductFilter(query.Filter)
+	if err != nil {
+		return []domain.ProductStatsGroup{}, err
+	}
+
+	dimensionExpressions := make([]string, 0, len(query.GroupBy))
+	for _, dimension := range query.GroupBy {
+		expression, err := statsDimensionExpression(dimension)
+		if err != nil {
+			return []domain.ProductStatsGroup{}, err
+		}
+		dimensionExpressions = append(dimensionExpressions, expression)
+	}
+
+	selectList := `count(*), COALESCE(round(avg(products.price)::numeric, 2), 0)::float8, COALESCE(min(products.price), 0), COALESCE(max(products.price), 0),
+		COALESCE(round(avg(COALESCE(products.discount, 0))::numeric, 2), 0)::float8`
+	groupBy := ""
+	if len(dimensionExpressions) > 0 {
+		selectList = strings.Join(dimensionExpressions, ", ") + ", " + selectList
+		positions := make([]string, 0, len(dimensionExpressions))
+		for i := range dimensionExpressions {
+			positions = append(positions, fmt.Sprint(i+1))
+		}
+		groupBy = ` GROUP BY ` + strings.Join(positions, ", ") + ` ORDER BY ` + strings.Join(positions, ", ")
+	}
+
+	rows, err := productRepository.dbPool.Query(ctx, `SELECT `+selectList+` FROM products`+where+groupBy, args...)
+	if err != nil {
+		log.Errorf("Error while computing product stats: %v", err)
+		return []domain.ProductStatsGroup{}, err
+	}
+	defer rows.Close()
+
+	groups := make([]domain.ProductStatsGroup, 0)
+	for rows.Next() {
+		keyValues := make([]string, len(query.GroupBy))
+		var group domain.ProductStatsGroup
+		targets := make([]interface{}, 0, len(keyValues)+5)
+		for i := range keyValues {
+			targets = append(targets, &keyValues[i])
+		}
+		targets = append(targets, &group.Count, &group.AveragePrice, &group.MinPrice, &group.MaxPrice, &group.AverageDiscount)
+		err = rows.Scan(targets...)
+		if err != nil {
+			log.Errorf("Error while scanning product stats rows: %v", err)
+			return []domain.ProductStatsGroup{}, err
+		}
+
+		group.Keys = make(map[string]string, len(query.GroupBy))
+		for i, dimension := range query.GroupBy {
+			group.Keys[dimension] = keyValues[i]
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
@@ -0,0 +1,87 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+)
+
+const productVariantColumns = `id, product_id, COALESCE(sku, ''), price_override, stock, attributes`
+
+type IProductVariantRepository interface {
+	Add(productVariant domain.ProductVariant) error
+	GetByProductId(productId int64) ([]domain.ProductVariant, error)
+}
+
+type ProductVariantRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewProductVariantRepository(dbPool *pgxpool.Pool) IProductVariantRepository {
+	return &ProductVariantRepository{dbPool: dbPool}
+}
+
+func (productVariantRepository *ProductVariantRepository) Add(productVariant domain.ProductVariant) error {
+	ctx := context.Background()
+
+	sqlCommand := `INSERT INTO product_variants(product_id, sku, price_override, stock, attributes) VALUES($1, NULLIF($2, ''), $3, $4, $5)`
+
+	_, err := productVariantRepository.dbPool.Exec(ctx, sqlCommand,
+		productVariant.ProductId,
+		productVariant.Sku,
+		productVariant.PriceOverride,
+		productVariant.Stock,
+		productVariant.Attributes)
+	if pgError, duplicate := isUniqueViolation(err); duplicate {
+		if pgError.ConstraintName == storeSkuConstraint {
+			return ConflictError{Message: fmt.Sprintf("Sku %s already exists in the store of product %d", productVariant.Sku, productVariant.ProductId)}
+		}
+		return ConflictError{Message: fmt.Sprintf("Variant with the same attributes already exists for product %d", productVariant.ProductId)}
+	}
+	if err != nil {
+		log.Errorf("Error while inserting product variant: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (productVariantRepository *ProductVariantRepository) GetByProductId(productId int64) ([]domain.ProductVariant, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + productVariantColumns + ` FROM product_variants WHERE product_id = $1 ORDER BY id`
+
+	rows, err := productVariantRepository.dbPool.Query(ctx, query, productId)
+	if err != nil {
+		log.Errorf("Error while fetching variants of product %d: %v", productId, err)
+		return []domain.ProductVariant{}, err
+	}
+
+	return extractProductVariantsFromRows(rows)
+}
+
+func extractProductVariantsFromRows(rows pgx.Rows) ([]domain.ProductVariant, error) {
+	defer rows.Close()
+
+	var productVariants []domain.ProductVariant
+	for rows.Next() {
+		var productVariant domain.ProductVariant
+		err := rows.Scan(&productVariant.Id,
+			&productVariant.ProductId,
+			&productVariant.Sku,
+			&productVariant.PriceOverride,
+			&productVariant.Stock,
+			&productVariant.Attributes)
+		if err != nil {
+			log.Errorf("Error while scanning product variant rows: %v", err)
+			return []domain.ProductVariant{}, err
+		}
+
+		productVariants = append(productVariants, productVariant)
+	}
+
+	return productVariants, rows.Err()
+}
@@ -0,0 +1,160 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/persistence/errorMessages"
+	"time"
+)
+
+const promotionColumns = `id, name, type, store, category, min_price, max_price, percent, buy_quantity, free_quantity, bundle_product_ids, priority, stackable, valid_from, valid_to`
+
+type IPromotionRepository interface {
+	Add(promotion domain.Promotion) error
+	GetById(id int64) (domain.Promotion, error)
+	GetAll() ([]domain.Promotion, error)
+	GetValid(now time.Time) ([]domain.Promotion, error)
+	DeleteById(id int64) error
+}
+
+type PromotionRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewPromotionRepository(dbPool *pgxpool.Pool) IPromotionRepository {
+	return &PromotionRepository{dbPool: dbPool}
+}
+
+func (promotionRepository *PromotionRepository) Add(promotion domain.Promotion) error {
+	ctx := context.Background()
+
+	sqlCommand := `INSERT INTO promotions(name, type, store, category, min_price, max_price, percent, buy_quantity, free_quantity, bundle_product_ids, priority, stackable, valid_from, valid_to)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce($10::bigint[], '{}'), $11, $12, $13, $14)`
+
+	_, err := promotionRepository.dbPool.Exec(ctx, sqlCommand,
+		promotion.Name,
+		promotion.Type,
+		promotion.Store,
+		promotion.Category,
+		promotion.MinPrice,
+		promotion.MaxPrice,
+		promotion.Percent,
+		promotion.BuyQuantity,
+		promotion.FreeQuantity,
+		promotion.BundleProductIds,
+		promotion.Priority,
+		promotion.Stackable,
+		promotion.ValidFrom,
+		promotion.ValidTo)
+	if err != nil {
+		log.Errorf("Error while inserting promotion: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (promotionRepository *PromotionRepository) GetById(id int64) (domain.Promotion, error) {
+	ctx := context.Background()
+
+	sqlCommand := `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1`
+
+	promotion, err := scanPromotion(promotionRepository.dbPool.QueryRow(ctx, sqlCommand, id))
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return domain.Promotion{}, errors.New(fmt.Sprintf("Promotion with id %d not found", id))
+	}
+
+	if err != nil {
+		log.Errorf("Error while fetching promotion with id: %d %v", id, err)
+		return domain.Promotion{}, errors.New(fmt.Sprintf("Error while fetching promotion by id %d", id))
+	}
+
+	return promotion, nil
+}
+
+func (promotionRepository *PromotionRepository) GetAll() ([]domain.Promotion, error) {
+	ctx := context.Background()
+
+	rows, err := promotionRepository.dbPool.Query(ctx, `SELECT `+promotionColumns+` FROM promotions ORDER BY priority DESC, id`)
+	if err != nil {
+		log.Errorf("Error while fetching promotions: %v", err)
+		return []domain.Promotion{}, err
+	}
+
+	return extractPromotionsFromRows(rows)
+}
+
+func (promotionRepository *PromotionRepository) GetValid(now time.Time) ([]domain.Promotion, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + promotionColumns + ` FROM promotions
+		WHERE valid_from <= $1 AND (valid_to IS NULL OR valid_to > $1)
+		ORDER BY priority DESC, id`
+
+	rows, err := promotionRepository.dbPool.Query(ctx, query, now)
+	if err != nil {
+		log.Errorf("Error while fetching valid promotions: %v", err)
+		return []domain.Promotion{}, err
+	}
+
+	return extractPromotionsFromRows(rows)
+}
+
+func (promotionRepository *PromotionRepository) DeleteById(id int64) error {
+	ctx := context.Background()
+
+	_, err := promotionRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = promotionRepository.dbPool.Exec(ctx, `DELETE FROM promotions WHERE id = $1`, id)
+	if err != nil {
+		log.Errorf("Error while deleting promotion with id:%d %v", id, err)
+		return errors.New(fmt.Sprintf("Error while deleting promotion with id %d", id))
+	}
+
+	return nil
+}
+
+func scanPromotion(row pgx.Row) (domain.Promotion, error) {
+	var promotion domain.Promotion
+	err := row.Scan(&promotion.Id,
+		&promotion.Name,
+		&promotion.Type,
+		&promotion.Store,
+		&promotion.Category,
+		&promotion.MinPrice,
+		&promotion.MaxPrice,
+		&promotion.Percent,
+		&promotion.BuyQuantity,
+		&promotion.FreeQuantity,
+		&promotion.BundleProductIds,
+		&promotion.Priority,
+		&promotion.Stackable,
+		&promotion.ValidFrom,
+		&promotion.ValidTo)
+	return promotion, err
+}
+
+func extractPromotionsFromRows(rows pgx.Rows) ([]domain.Promotion, error) {
+	defer rows.Close()
+
+	var promotions []domain.Promotion
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			log.Errorf("Error while scanning promotion rows: %v", err)
+			return []domain.Promotion{}, err
+		}
+
+		promotions = append(promotions, promotion)
+	}
+
+	return promotions, rows.Err()
+}
@@ -0,0 +1,273 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/persistence/errorMessages"
+	"time"
+)
+
+const webhookColumns = `id, url, event_types, store, secret, active, consecutive_failures, disabled_at, created_by, created_at, updated_at`
+
+const webhookDeliveryColumns = `webhook_deliveries.id, webhook_deliveries.webhook_id, webhook_deliveries.event_id::text, webhook_deliveries.event_type,
+	webhook_deliveries.product_id, webhook_deliveries.body, webhook_deliveries.status, webhook_deliveries.attempts, webhook_deliveries.next_attempt_at,
+	webhook_deliveries.last_status_code, webhook_deliveries.last_error, webhook_deliveries.created_at, webhook_deliveries.delivered_at`
+
+type IWebhookRepository interface {
+	Add(webhook domain.Webhook) (domain.Webhook, error)
+	GetAll() ([]domain.Webhook, error)
+	GetById(id int64) (domain.Webhook, error)
+	Update(webhook domain.Webhook) (domain.Webhook, error)
+	DeleteById(id int64) error
+	EnqueueDeliveries(event domain.ProductEvent, store string, body string) (int64, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	RecordAttempt(delivery domain.WebhookDelivery, disableAfter int) error
+	GetDeliveries(webhookId int64, status string, limit int) ([]domain.WebhookDelivery, error)
+	Redeliver(webhookId int64, deliveryId int64) (domain.WebhookDelivery, error)
+}
+
+type WebhookRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewWebhookRepository(dbPool *pgxpool.Pool) IWebhookRepository {
+	return &WebhookRepository{dbPool: dbPool}
+}
+
+func (webhookRepository *WebhookRepository) Add(webhook domain.Webhook) (domain.Webhook, error) {
+	ctx := context.Background()
+
+	sqlCommand := `INSERT INTO webhooks(url, event_types, store, secret, created_by) VALUES($1, $2, $3, $4, $5) RETURNING ` + webhookColumns
+
+	addedWebhook, err := scanWebhook(webhookRepository.dbPool.QueryRow(ctx, sqlCommand, webhook.Url, webhook.EventTypes, webhook.Store, webhook.Secret, webhook.CreatedBy))
+	if err != nil {
+		log.Errorf("Error while inserting webhook for %s: %v", webhook.Url, err)
+		return domain.Webhook{}, err
+	}
+	return addedWebhook, nil
+}
+
+func (webhookRepository *WebhookRepository) GetAll() ([]domain.Webhook, error) {
+	ctx := context.Background()
+
+	rows, err := webhookRepository.dbPool.Query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		log.Errorf("Error while fetching webhooks: %v", err)
+		return []domain.Webhook{}, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]domain.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			log.Errorf("Error while scanning webhook rows: %v", err)
+			return []domain.Webhook{}, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (webhookRepository *WebhookRepository) GetById(id int64) (domain.Webhook, error) {
+	ctx := context.Background()
+
+	webhook, err := scanWebhook(webhookRepository.dbPool.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return domain.Webhook{}, NotFoundError{Message: fmt.Sprintf("Webhook with id %d not found", id)}
+	}
+	if err != nil {
+		log.Errorf("Error while fetching webhook with id %d: %v", id, err)
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// Update replaces the subscription, the secret is only replaced when a new one is given.
+// Activating a webhook clears its failures so that its waiting deliveries are sent again.
+func (webhookRepository *WebhookRepository) Update(webhook domain.Webhook) (domain.Webhook, error) {
+	ctx := context.Background()
+
+	sqlCommand := `UPDATE webhooks SET url = $2, event_types = $3, store = $4, secret = COALESCE(NULLIF($5, ''), secret), active = $6,
+			consecutive_failures = CASE WHEN $6 THEN 0 ELSE consecutive_failures END,
+			disabled_at = CASE WHEN $6 THEN NULL ELSE COALESCE(disabled_at, now()) END, updated_at = now()
+		WHERE id = $1 RETURNING ` + webhookColumns
+
+	updatedWebhook, err := scanWebhook(webhookRepository.dbPool.QueryRow(ctx, sqlCommand,
+		webhook.Id, webhook.Url, webhook.EventTypes, webhook.Store, webhook.Secret, webhook.Active))
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return domain.Webhook{}, NotFoundError{Message: fmt.Sprintf("Webhook with id %d not found", webhook.Id)}
+	}
+	if err != nil {
+		log.Errorf("Error while updating webhook with id %d: %v", webhook.Id, err)
+		return domain.Webhook{}, err
+	}
+	return updatedWebhook, nil
+}
+
+func (webhookRepository *WebhookRepository) DeleteById(id int64) error {
+	ctx := context.Background()
+
+	commandTag, err := webhookRepository.dbPool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		log.Errorf("Error while deleting webhook with id %d: %v", id, err)
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return NotFoundError{Message: fmt.Sprintf("Webhook with id %d not found", id)}
+	}
+	return nil
+}
+
+// EnqueueDeliveries queues the event for every active webhook subscribed to its type and store.
+// An event published again is not queued twice for the same webhook.
+func (webhookRepository *WebhookRepository) EnqueueDeliveries(event domain.ProductEvent, store string, body string) (int64, error) {
+	ctx := context.Background()
+
+	sqlCommand := `INSERT INTO webhook_deliveries(webhook_id, event_id, event_type, product_id, body)
+		SELECT id, $1::uuid, $2, $3, $4 FROM webhooks WHERE active AND $2 = ANY(event_types) AND (store = '' OR store = $5)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING`
+
+	commandTag, err := webhookRepository.dbPool.Exec(ctx, sqlCommand, event.EventId, event.Type, event.ProductId, body, store)
+	if err != nil {
+		log.Errorf("Error while queueing webhook deliveries of event %s: %v", event.EventId, err)
+		return 0, err
+	}
+	return commandTag.RowsAffected(), nil
+}
+
+// ClaimDueDeliveries hands out due deliveries of active webhooks. A claimed delivery is due again after the lease,
+// so a delivery whose dispatcher stopped is sent by another one.
+func (webhookRepository *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	ctx := context.Background()
+
+	sqlCommand := `WITH due AS (
+			SELECT webhook_deliveries.id FROM webhook_deliveries JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+			WHERE webhook_deliveries.status = $2 AND webhook_deliveries.next_attempt_at <= now() AND webhooks.active
+			ORDER BY webhook_deliveries.id LIMIT $1
+			FOR UPDATE OF webhook_deliveries SKIP LOCKED
+		)
+		UPDATE webhook_deliveries SET next_attempt_at = $3 FROM due, webhooks
+		WHERE webhook_deliveries.id = due.id AND webhooks.id = webhook_deliveries.webhook_id
+		RETURNING ` + webhookDeliveryColumns + `, webhooks.url, webhooks.secret`
+
+	rows, err := webhookRepository.dbPool.Query(ctx, sqlCommand, limit, domain.WebhookDeliveryPending, time.Now().Add(lease))
+	if err != nil {
+		log.Errorf("Error while claiming webhook deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		err = rows.Scan(append(webhookDeliveryScanTargets(&delivery), &delivery.Url, &delivery.Secret)...)
+		if err != nil {
+			log.Errorf("Error while scanning claimed webhook deliveries: %v", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt stores the outcome of sending the delivery. Every failed attempt counts against its webhook,
+// which is deactivated when disableAfter attempts in a row have failed, a successful one clears the count.
+func (webhookRepository *WebhookRepository) RecordAttempt(delivery domain.WebhookDelivery, disableAfter int) error {
+	ctx := context.Background()
+
+	tx, err := webhookRepository.dbPool.Begin(ctx)
+	if err != nil {
+		log.Errorf("Error while starting webhook delivery transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	succeeded := delivery.Status == domain.WebhookDeliverySucceeded
+	_, err = tx.Exec(ctx, `UPDATE webhook_deliveries SET status = $2, attempts = attempts + 1, next_attempt_at = $3, last_status_code = $4, last_error = $5,
+			delivered_at = CASE WHEN $6 THEN now() END
+		WHERE id = $1`,
+		delivery.Id, delivery.Status, delivery.NextAttempt, delivery.LastStatusCode, delivery.LastError, succeeded)
+	if err != nil {
+		log.Errorf("Error while recording attempt of webhook delivery %d: %v", delivery.Id, err)
+		return err
+	}
+
+	if succeeded {
+		_, err = tx.Exec(ctx, `UPDATE webhooks SET consecutive_failures = 0 WHERE id = $1 AND consecutive_failures > 0`, delivery.WebhookId)
+	} else {
+		var active bool
+		err = tx.QueryRow(ctx, `UPDATE webhooks SET consecutive_failures = consecutive_failures + 1, active = active AND consecutive_failures + 1 < $2,
+				disabled_at = CASE WHEN active AND consecutive_failures + 1 >= $2 THEN now() ELSE disabled_at END
+			WHERE id = $1 RETURNING active`, delivery.WebhookId, disableAfter).Scan(&active)
+		if err == nil && !active {
+			log.Infof("Webhook %d deactivated after %d failed deliveries in a row", delivery.WebhookId, disableAfter)
+		}
+	}
+	if err != nil {
+		log.Errorf("Error while updating failures of webhook %d: %v", delivery.WebhookId, err)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (webhookRepository *WebhookRepository) GetDeliveries(webhookId int64, status string, limit int) ([]domain.WebhookDelivery, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2 = '' OR status = $2) ORDER BY id DESC LIMIT $3`
+
+	rows, err := webhookRepository.dbPool.Query(ctx, query, webhookId, status, limit)
+	if err != nil {
+		log.Errorf("Error while fetching deliveries of webhook %d: %v", webhookId, err)
+		return []domain.WebhookDelivery{}, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		if err = rows.Scan(webhookDeliveryScanTargets(&delivery)...); err != nil {
+			log.Errorf("Error while scanning webhook delivery rows: %v", err)
+			return []domain.WebhookDelivery{}, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// Redeliver queues the delivery to be sent right away with a fresh set of attempts, whatever its status.
+func (webhookRepository *WebhookRepository) Redeliver(webhookId int64, deliveryId int64) (domain.WebhookDelivery, error) {
+	ctx := context.Background()
+
+	sqlCommand := `UPDATE webhook_deliveries SET status = $3, attempts = 0, next_attempt_at = now()
+		WHERE id = $2 AND webhook_id = $1 RETURNING ` + webhookDeliveryColumns
+
+	var delivery domain.WebhookDelivery
+	err := webhookRepository.dbPool.QueryRow(ctx, sqlCommand, webhookId, deliveryId, domain.WebhookDeliveryPending).Scan(webhookDeliveryScanTargets(&delivery)...)
+	if err != nil && err.Error() == errorMessages.NOT_FOUND {
+		return domain.WebhookDelivery{}, NotFoundError{Message: fmt.Sprintf("Delivery with id %d of webhook %d not found", deliveryId, webhookId)}
+	}
+	if err != nil {
+		log.Errorf("Error while queueing redelivery %d of webhook %d: %v", deliveryId, webhookId, err)
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func scanWebhook(row pgx.Row) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := row.Scan(&webhook.Id, &webhook.Url, &webhook.EventTypes, &webhook.Store, &webhook.Secret, &webhook.Active,
+		&webhook.ConsecutiveFailures, &webhook.DisabledAt, &webhook.CreatedBy, &webhook.CreatedAt, &webhook.UpdatedAt)
+	return webhook, err
+}
+
+func webhookDeliveryScanTargets(delivery *domain.WebhookDelivery) []interface{} {
+	return []interface{}{&delivery.Id, &delivery.WebhookId, &delivery.EventId, &delivery.EventType, &delivery.ProductId, &delivery.Body,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttempt, &delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt}
+}
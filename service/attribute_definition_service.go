@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service/model"
+	"regexp"
+)
+
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+type IAttributeDefinitionService interface {
+	Add(attributeDefinition model.CreateAttributeDefinition) error
+	GetAll() ([]domain.AttributeDefinition, error)
+	GetByCategory(category string) ([]domain.AttributeDefinition, error)
+	DeleteById(id int64) error
+}
+
+type AttributeDefinitionService struct {
+	attributeDefinitionRepository persistence.IAttributeDefinitionRepository
+}
+
+func NewAttributeDefinitionService(attributeDefinitionRepository persistence.IAttributeDefinitionRepository) IAttributeDefinitionService {
+	return &AttributeDefinitionService{attributeDefinitionRepository: attributeDefinitionRepository}
+}
+
+func (attributeDefinitionService *AttributeDefinitionService) Add(attributeDefinition model.CreateAttributeDefinition) error {
+	validationErr := validateAttributeDefinition(attributeDefinition)
+	if validationErr != nil {
+		return validationErr
+	}
+
+	return attributeDefinitionService.attributeDefinitionRepository.Add(domain.AttributeDefinition{
+		Category:   attributeDefinition.Category,
+		Name:       attributeDefinition.Name,
+		Type:       attributeDefinition.Type,
+		Required:   attributeDefinition.Required,
+		EnumValues: attributeDefinition.EnumValues,
+		Unit:       attributeDefinition.Unit,
+	})
+}
+
+func (attributeDefinitionService *AttributeDefinitionService) GetAll() ([]domain.AttributeDefinition, error) {
+	return attributeDefinitionService.attributeDefinitionRepository.GetAll()
+}
+
+func (attributeDefinitionService *AttributeDefinitionService) GetByCategory(category string) ([]domain.AttributeDefinition, error) {
+	return attributeDefinitionService.attributeDefinitionRepository.GetByCategory(category)
+}
+
+func (attributeDefinitionService *AttributeDefinitionService) DeleteById(id int64) error {
+	return attributeDefinitionService.attributeDefinitionRepository.DeleteById(id)
+}
+
+func validateAttributeDefinition(attributeDefinition model.CreateAttributeDefinition) error {
+	if len(attributeDefinition.Category) == 0 {
+		return errors.New("Category is required")
+	}
+	if !attributeNamePattern.MatchString(attributeDefinition.Name) {
+		return errors.New("Attribute name should be lowercase letters, digits or underscores")
+	}
+	switch attributeDefinition.Type {
+	case domain.AttributeTypeString, domain.AttributeTypeNumber, domain.AttributeTypeBoolean:
+		if len(attributeDefinition.EnumValues) > 0 {
+			return errors.New("Enum values are only allowed for enum attributes")
+		}
+	case domain.AttributeTypeEnum:
+		if len(attributeDefinition.EnumValues) == 0 {
+			return errors.New("Enum attributes need at least one enum value")
+		}
+	default:
+		return errors.New(fmt.Sprintf("Attribute type should be one of %s, %s, %s, %s",
+			domain.AttributeTypeString, domain.AttributeTypeNumber, domain.AttributeTypeBoolean, domain.AttributeTypeEnum))
+	}
+	return nil
+}
+
+// validateProductAttributes checks custom attributes against the definitions of the product category.
+func validateProductAttributes(attributes map[string]interface{}, category string, attributeDefinitions []domain.AttributeDefinition) error {
+	definitionsByName := make(map[string]domain.AttributeDefinition, len(attributeDefinitions))
+	for _, attributeDefinition := range attributeDefinitions {
+		definitionsByName[attributeDefinition.Name] = attributeDefinition
+		if _, found := attributes[attributeDefinition.Name]; attributeDefinition.Required && !found {
+			return errors.New(fmt.Sprintf("Attribute %s is required for category %s", attributeDefinition.Name, category))
+		}
+	}
+
+	for name, value := range attributes {
+		attributeDefinition, found := definitionsByName[name]
+		if !found {
+			return errors.New(fmt.Sprintf("Attribute %s is not defined for category %s", name, category))
+		}
+		err := attributeDefinition.Validate(value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+)
+
+const defaultJobMaxAttempts = 3
+
+type IJobService interface {
+	Enqueue(jobType string, payload interface{}, actor string) (domain.Job, error)
+	GetById(id int64) (domain.Job, error)
+	Cancel(id int64) (domain.Job, error)
+	HandleFinished(jobType string, finisher JobFinisher)
+}
+
+type JobService struct {
+	jobRepository persistence.IJobRepository
+	finishers     map[string]JobFinisher
+}
+
+func NewJobService(jobRepository persistence.IJobRepository) IJobService {
+	return &JobService{jobRepository: jobRepository, finishers: make(map[string]JobFinisher)}
+}
+
+// Enqueue stores a job for the workers, payload is encoded as json and handed to the handler of jobType.
+func (jobService *JobService) Enqueue(jobType string, payload interface{}, actor string) (domain.Job, error) {
+	if len(jobType) == 0 {
+		return domain.Job{}, errors.New("Job type should not be empty")
+	}
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return domain.Job{}, errors.New(fmt.Sprintf("Payload of %s job can not be encoded: %v", jobType, err))
+	}
+	return jobService.jobRepository.Add(domain.Job{Type: jobType, Payload: encodedPayload, MaxAttempts: defaultJobMaxAttempts, CreatedBy: actor})
+}
+
+func (jobService *JobService) GetById(id int64) (domain.Job, error) {
+	return jobService.jobRepository.GetById(id)
+}
+
+// Cancel stops a queued job at once, a running job stops when its worker notices, which the job status shows.
+func (jobService *JobService) Cancel(id int64) (domain.Job, error) {
+	job, err := jobService.jobRepository.RequestCancel(id)
+	if err != nil {
+		return domain.Job{}, err
+	}
+	// a queued job never reaches a worker once cancelled, so it is finished here
+	if finisher, found := jobService.finishers[job.Type]; found && job.Status == domain.JobStatusCancelled {
+		finisher(job)
+	}
+	return job, nil
+}
+
+// HandleFinished registers the finisher of a job type for the jobs cancelled before a worker claimed them,
+// the worker runs the finishers of the jobs it claimed.
+func (jobService *JobService) HandleFinished(jobType string, finisher JobFinisher) {
+	jobService.finishers[jobType] = finisher
+}
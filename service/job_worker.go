@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"sync"
+	"time"
+)
+
+// JobProgressReporter records how much of a job is done, total is zero while unknown.
+// It returns an error once the job is cancelled, the handler should stop then.
+type JobProgressReporter func(done int64, total int64) error
+
+// JobHandler runs one type of job and returns its result, which is stored as json.
+// ctx is cancelled when the job is cancelled or the worker stops.
+type JobHandler func(ctx context.Context, job domain.Job, reportProgress JobProgressReporter) (interface{}, error)
+
+// JobFinisher runs once a job has finished for good, whether it succeeded, failed on its last attempt or was
+// cancelled, e.g. to delete the input the job no longer needs.
+type JobFinisher func(job domain.Job)
+
+// PermanentJobError fails a job without retrying it, e.g. for a payload that can never succeed.
+type PermanentJobError struct {
+	Message string
+}
+
+func (permanentJobError PermanentJobError) Error() string {
+	return permanentJobError.Message
+}
+
+var errJobCancelled = errors.New("Job is cancelled")
+
+// JobWorker claims due jobs and runs them with their handlers. Any number of instances can run workers,
+// a job is claimed by one worker at a time and taken over by another if its worker stops renewing the lease.
+type JobWorker struct {
+	jobRepository persistence.IJobRepository
+	handlers      map[string]JobHandler
+	finishers     map[string]JobFinisher
+	concurrency   int
+	pollInterval  time.Duration
+	lease         time.Duration
+}
+
+func NewJobWorker(jobRepository persistence.IJobRepository, concurrency int, pollInterval time.Duration, lease time.Duration) *JobWorker {
+	return &JobWorker{
+		jobRepository: jobRepository,
+		handlers:      make(map[string]JobHandler),
+		finishers:     make(map[string]JobFinisher),
+		concurrency:   concurrency,
+		pollInterval:  pollInterval,
+		lease:         lease,
+	}
+}
+
+// Handle registers the handler of a job type, it should be called before Start.
+func (jobWorker *JobWorker) Handle(jobType string, handler JobHandler) {
+	jobWorker.handlers[jobType] = handler
+}
+
+// HandleFinished registers the finisher of a job type, it should be called before Start.
+func (jobWorker *JobWorker) HandleFinished(jobType string, finisher JobFinisher) {
+	jobWorker.finishers[jobType] = finisher
+}
+
+func (jobWorker *JobWorker) Start(ctx context.Context) {
+	for i := 0; i < jobWorker.concurrency; i++ {
+		go func() {
+			ticker := time.NewTicker(jobWorker.pollInterval)
+			defer ticker.Stop()
+
+			for {
+				// keep claiming while there is work, wait for the next tick once the queue is empty
+				for jobWorker.RunOnce(ctx) {
+					if ctx.Err() != nil {
+						return
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+}
+
+// RunOnce claims and runs a single job, it tells whether there was a job to run.
+func (jobWorker *JobWorker) RunOnce(ctx context.Context) bool {
+	abandonedJobs, err := jobWorker.jobRepository.FailAbandoned()
+	if err != nil {
+		return false
+	}
+	for _, abandonedJob := range abandonedJobs {
+		jobWorker.finished(abandonedJob)
+	}
+
+	job, claimed, err := jobWorker.jobRepository.ClaimNext(jobWorker.lease)
+	if err != nil || !claimed {
+		return false
+	}
+
+	handler, found := jobWorker.handlers[job.Type]
+	if !found {
+		jobWorker.jobRepository.Fail(job.Id, job.Attempts, fmt.Sprintf("No handler for job type %s", job.Type), nil)
+		return true
+	}
+	if job.CancelRequested {
+		jobWorker.jobRepository.MarkCancelled(job.Id, job.Attempts)
+		jobWorker.finished(job)
+		return true
+	}
+
+	log.Infof("Running job %d of type %s, attempt %d of %d", job.Id, job.Type, job.Attempts, job.MaxAttempts)
+	result, err := jobWorker.run(ctx, job, handler)
+	jobWorker.finish(job, result, err)
+	return true
+}
+
+// run calls the handler while renewing the lease in the background, which also picks up cancellation
+// for handlers that report progress rarely.
+func (jobWorker *JobWorker) run(ctx context.Context, job domain.Job, handler JobHandler) (result interface{}, err error) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var progressMutex sync.Mutex
+	var done, total int64
+	var cancelled bool
+	renewLease := func() error {
+		progressMutex.Lock()
+		defer progressMutex.Unlock()
+		if cancelled {
+			return errJobCancelled
+		}
+		cancelRequested, err := jobWorker.jobRepository.UpdateProgress(job.Id, job.Attempts, done, total, jobWorker.lease)
+		if err != nil {
+			return err
+		}
+		if cancelRequested {
+			cancelled = true
+			cancel()
+			return errJobCancelled
+		}
+		return nil
+	}
+
+	heartbeatStopped := make(chan struct{})
+	go func() {
+		defer close(heartbeatStopped)
+		ticker := time.NewTicker(jobWorker.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+				renewLease()
+			}
+		}
+	}()
+	defer func() {
+		cancel()
+		<-heartbeatStopped
+		if recovered := recover(); recovered != nil {
+			err = PermanentJobError{Message: fmt.Sprintf("Job panicked: %v", recovered)}
+		}
+		// a handler that finished its work before noticing the cancellation still succeeds
+		progressMutex.Lock()
+		if cancelled && err != nil {
+			err = errJobCancelled
+		}
+		progressMutex.Unlock()
+	}()
+
+	return handler(jobCtx, job, func(jobDone int64, jobTotal int64) error {
+		progressMutex.Lock()
+		done, total = jobDone, jobTotal
+		progressMutex.Unlock()
+		return renewLease()
+	})
+}
+
+func (jobWorker *JobWorker) finish(job domain.Job, result interface{}, err error) {
+	var permanentJobError PermanentJobError
+	switch {
+	case errors.Is(err, errJobCancelled):
+		log.Infof("Job %d cancelled", job.Id)
+		jobWorker.jobRepository.MarkCancelled(job.Id, job.Attempts)
+		jobWorker.finished(job)
+	case err == nil:
+		encodedResult, encodeErr := json.Marshal(result)
+		if encodeErr != nil {
+			jobWorker.jobRepository.Fail(job.Id, job.Attempts, fmt.Sprintf("Result can not be encoded: %v", encodeErr), nil)
+		} else {
+			log.Infof("Job %d succeeded", job.Id)
+			jobWorker.jobRepository.Complete(job.Id, job.Attempts, encodedResult)
+		}
+		jobWorker.finished(job)
+	case errors.As(err, &permanentJobError) || !job.CanRetry():
+		log.Errorf("Job %d failed: %v", job.Id, err)
+		jobWorker.jobRepository.Fail(job.Id, job.Attempts, err.Error(), nil)
+		jobWorker.finished(job)
+	default:
+		retryAt := time.Now().Add(domain.JobRetryDelay(job.Attempts))
+		log.Errorf("Job %d failed, retrying at %s: %v", job.Id, retryAt.Format(time.RFC3339), err)
+		jobWorker.jobRepository.Fail(job.Id, job.Attempts, err.Error(), &retryAt)
+	}
+}
+
+func (jobWorker *JobWorker) finished(job domain.Job) {
+	if finisher, found := jobWorker.finishers[job.Type]; found {
+		finisher(job)
+	}
+}
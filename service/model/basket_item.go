@@ -0,0 +1,6 @@
+package model
+
+type BasketItem struct {
+	ProductId int64
+	Quantity  int
+}
@@ -0,0 +1,8 @@
+package model
+
+type ChangeProductStatus struct {
+	ProductId int64
+	Status    string
+	Actor     string
+	Reason    string
+}
@@ -0,0 +1,10 @@
+package model
+
+type CreateAttributeDefinition struct {
+	Category   string
+	Name       string
+	Type       string
+	Required   bool
+	EnumValues []string
+	Unit       string
+}
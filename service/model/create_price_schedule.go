@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type CreatePriceSchedule struct {
+	ProductId     int64
+	Price         *float32
+	Discount      *float32
+	EffectiveFrom time.Time
+	EffectiveTo   *time.Time
+}
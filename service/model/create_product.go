@@ -1,8 +1,21 @@
 package model
 
+import "go-product-app/domain"
+
 type CreateProduct struct {
-	Name     string
-	Price    float32
-	Discount float32
-	Store    string
+	Name        string
+	Description string
+	Price       float32
+	Discount    float32
+	Store       string
+	Category    string
+	Status      string
+	Sku         string
+	Gtin        string
+	Options     []string
+	Attributes  map[string]interface{}
+	// Translations must include the store default locale, without any Name and Description are stored as its translation
+	Translations map[string]domain.LocalizedText
+	// Actor is who creates the product
+	Actor string
 }
@@ -0,0 +1,9 @@
+package model
+
+type CreateProductVariant struct {
+	ProductId     int64
+	Sku           string
+	PriceOverride *float32
+	Stock         int
+	Attributes    map[string]string
+}
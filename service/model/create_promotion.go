@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type CreatePromotion struct {
+	Name             string
+	Type             string
+	Store            string
+	Category         string
+	MinPrice         *float32
+	MaxPrice         *float32
+	Percent          float32
+	BuyQuantity      int
+	FreeQuantity     int
+	BundleProductIds []int64
+	Priority         int
+	Stackable        bool
+	ValidFrom        time.Time
+	ValidTo          *time.Time
+}
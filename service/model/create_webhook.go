@@ -0,0 +1,18 @@
+package model
+
+type CreateWebhook struct {
+	Url        string
+	EventTypes []string
+	Store      string
+	Secret     string
+	Actor      string
+}
+
+type UpdateWebhook struct {
+	Id         int64
+	Url        string
+	EventTypes []string
+	Store      string
+	Secret     string
+	Active     bool
+}
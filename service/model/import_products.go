@@ -0,0 +1,15 @@
+package model
+
+type ImportProducts struct {
+	Format   string
+	DryRun   bool
+	UpsertBy string
+	// Mapping maps product fields to the column names of the file, unmapped fields are read from the column of the same name
+	Mapping map[string]string
+	// DraftsOnly creates the new products as drafts and fails the rows with another status, for callers who can not publish
+	DraftsOnly bool
+	// Actor is who runs the import
+	Actor string
+	// Progress is called with the number of rows read every so often, an error from it stops the import
+	Progress func(rows int) error
+}
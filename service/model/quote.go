@@ -0,0 +1,27 @@
+package model
+
+import "go-product-app/domain/promotion"
+
+const (
+	QuoteLineStatusPriced   = "priced"
+	QuoteLineStatusNotFound = "not_found"
+)
+
+type QuoteLine struct {
+	ProductId       int64
+	Quantity        int
+	Status          string
+	ListTotal       float64
+	ProductDiscount float64
+	Evaluation      promotion.LineEvaluation
+}
+
+// Quote totals only cover priced lines. Subtotal is before any discount and
+// GrandTotal is what the customer pays after product and promotion discounts.
+type Quote struct {
+	Lines             []QuoteLine
+	Subtotal          float64
+	ProductDiscount   float64
+	PromotionDiscount float64
+	GrandTotal        float64
+}
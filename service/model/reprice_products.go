@@ -0,0 +1,11 @@
+package model
+
+type RepriceProducts struct {
+	Store     string
+	Category  string
+	MinPrice  *float32
+	MaxPrice  *float32
+	Operation string
+	Value     float32
+	Actor     string
+}
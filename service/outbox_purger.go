@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"github.com/labstack/gommon/log"
+	"go-product-app/persistence"
+	"time"
+)
+
+// OutboxPurger periodically deletes the outbox events that have been published for longer than the retention period.
+// The retention bounds how far back the product event feed can catch up after its listener reconnected.
+type OutboxPurger struct {
+	outboxRepository persistence.IOutboxRepository
+	retention        time.Duration
+	interval         time.Duration
+}
+
+func NewOutboxPurger(outboxRepository persistence.IOutboxRepository, retention time.Duration, interval time.Duration) *OutboxPurger {
+	return &OutboxPurger{outboxRepository: outboxRepository, retention: retention, interval: interval}
+}
+
+func (outboxPurger *OutboxPurger) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(outboxPurger.interval)
+		defer ticker.Stop()
+
+		for {
+			outboxPurger.RunOnce(time.Now())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (outboxPurger *OutboxPurger) RunOnce(now time.Time) {
+	purged, err := outboxPurger.outboxRepository.PurgePublished(now.Add(-outboxPurger.retention))
+	if err != nil {
+		log.Errorf("Error while purging published outbox events: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Infof("Published outbox events purged, %d rows removed", purged)
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"github.com/labstack/gommon/log"
+	"go-product-app/common/events"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"time"
+)
+
+// OutboxRelay periodically publishes the product events written to the outbox. It is safe to run on every
+// instance because the repository only lets one of them relay at a time.
+type OutboxRelay struct {
+	outboxRepository persistence.IOutboxRepository
+	publisher        events.Publisher
+	interval         time.Duration
+	batchSize        int
+}
+
+func NewOutboxRelay(outboxRepository persistence.IOutboxRepository, publisher events.Publisher, interval time.Duration, batchSize int) *OutboxRelay {
+	return &OutboxRelay{outboxRepository: outboxRepository, publisher: publisher, interval: interval, batchSize: batchSize}
+}
+
+func (outboxRelay *OutboxRelay) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(outboxRelay.interval)
+		defer ticker.Stop()
+
+		for {
+			outboxRelay.RunOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce relays batches until the due events are drained and returns how many events were published. Publishing
+// an event makes the next event of its product due, so it keeps going as long as a batch published anything.
+func (outboxRelay *OutboxRelay) RunOnce(ctx context.Context) int {
+	total, totalFailed := 0, 0
+	for ctx.Err() == nil {
+		published, failed, err := outboxRelay.outboxRepository.RelayPending(outboxRelay.batchSize, func(event domain.ProductEvent) error {
+			return outboxRelay.publisher.Publish(ctx, event)
+		})
+		if err != nil {
+			log.Errorf("Error while relaying outbox events: %v", err)
+			break
+		}
+		total, totalFailed = total+published, totalFailed+failed
+		if published == 0 {
+			break
+		}
+	}
+	if total > 0 || totalFailed > 0 {
+		log.Infof("Outbox relay published %d events, %d failed", total, totalFailed)
+	}
+	return total
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service/model"
+	"time"
+)
+
+type IPriceScheduleService interface {
+	Add(priceSchedule model.CreatePriceSchedule) error
+	GetPending() ([]domain.PriceSchedule, error)
+	GetPendingByProductId(productId int64) ([]domain.PriceSchedule, error)
+	Cancel(id int64) error
+}
+
+// PriceScheduleService keeps the search index of this instance in step with the schedules it adds and cancels, the
+// other instances and the windows opened or closed by the scheduler catch up through the product change listener.
+type PriceScheduleService struct {
+	priceScheduleRepository persistence.IPriceScheduleRepository
+	productService          IProductService
+}
+
+func NewPriceScheduleService(priceScheduleRepository persistence.IPriceScheduleRepository, productService IProductService) IPriceScheduleService {
+	return &PriceScheduleService{priceScheduleRepository: priceScheduleRepository, productService: productService}
+}
+
+func (priceScheduleService *PriceScheduleService) Add(priceSchedule model.CreatePriceSchedule) error {
+	validationErr := validatePriceSchedule(priceSchedule, time.Now())
+	if validationErr != nil {
+		return validationErr
+	}
+
+	_, err := priceScheduleService.productService.GetById(priceSchedule.ProductId)
+	if err != nil {
+		return err
+	}
+
+	err = priceScheduleService.priceScheduleRepository.Add(domain.PriceSchedule{
+		ProductId:     priceSchedule.ProductId,
+		Price:         priceSchedule.Price,
+		Discount:      priceSchedule.Discount,
+		EffectiveFrom: priceSchedule.EffectiveFrom,
+		EffectiveTo:   priceSchedule.EffectiveTo,
+	})
+	if err != nil {
+		return err
+	}
+
+	priceScheduleService.reindex(priceSchedule.ProductId)
+	return nil
+}
+
+func (priceScheduleService *PriceScheduleService) GetPending() ([]domain.PriceSchedule, error) {
+	return priceScheduleService.priceScheduleRepository.GetPending()
+}
+
+func (priceScheduleService *PriceScheduleService) GetPendingByProductId(productId int64) ([]domain.PriceSchedule, error) {
+	return priceScheduleService.priceScheduleRepository.GetPendingByProductId(productId)
+}
+
+func (priceScheduleService *PriceScheduleService) Cancel(id int64) error {
+	priceSchedule, err := priceScheduleService.priceScheduleRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+	err = priceScheduleService.priceScheduleRepository.Cancel(id)
+	if err != nil {
+		return err
+	}
+
+	priceScheduleService.reindex(priceSchedule.ProductId)
+	return nil
+}
+
+// reindex indexes the product with the price its schedules give it now. The schedule is written already, so a
+// failing refresh is only logged, the product change listener refreshes the index as well.
+func (priceScheduleService *PriceScheduleService) reindex(productId int64) {
+	err := priceScheduleService.productService.RefreshSearchIndex([]domain.ProductChange{{Operation: domain.ProductChangeUpdate, ProductId: productId}})
+	if err != nil {
+		log.Errorf("Error while reindexing product with id %d after a price schedule change: %v", productId, err)
+	}
+}
+
+func validatePriceSchedule(priceSchedule model.CreatePriceSchedule, now time.Time) error {
+	if priceSchedule.Price == nil && priceSchedule.Discount == nil {
+		return errors.New("Price or discount is required")
+	}
+	if priceSchedule.Price != nil && *priceSchedule.Price <= 0 {
+		return errors.New("Price should be greater than 0")
+	}
+	if priceSchedule.Discount != nil {
+		discountErr := validateDiscount(*priceSchedule.Discount)
+		if discountErr != nil {
+			return discountErr
+		}
+	}
+	if priceSchedule.EffectiveFrom.IsZero() {
+		return errors.New("Effective from is required")
+	}
+	if priceSchedule.EffectiveTo != nil {
+		if !priceSchedule.EffectiveTo.After(priceSchedule.EffectiveFrom) {
+			return errors.New("Effective to should be after effective from")
+		}
+		if !priceSchedule.EffectiveTo.After(now) {
+			return errors.New("Effective to should be in the future")
+		}
+	}
+	return nil
+}
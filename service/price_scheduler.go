@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"github.com/labstack/gommon/log"
+	"go-product-app/persistence"
+	"time"
+)
+
+// PriceScheduler periodically applies due price schedules. It is safe to run on every
+// instance because the repository only lets one of them apply schedules at a time.
+type PriceScheduler struct {
+	priceScheduleRepository persistence.IPriceScheduleRepository
+	interval                time.Duration
+}
+
+func NewPriceScheduler(priceScheduleRepository persistence.IPriceScheduleRepository, interval time.Duration) *PriceScheduler {
+	return &PriceScheduler{priceScheduleRepository: priceScheduleRepository, interval: interval}
+}
+
+func (priceScheduler *PriceScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(priceScheduler.interval)
+		defer ticker.Stop()
+
+		for {
+			priceScheduler.RunOnce(time.Now())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (priceScheduler *PriceScheduler) RunOnce(now time.Time) {
+	changed, err := priceScheduler.priceScheduleRepository.ApplyDue(now)
+	if err != nil {
+		log.Errorf("Error while applying price schedules: %v", err)
+		return
+	}
+	if changed > 0 {
+		log.Infof("Price schedules applied, %d rows changed", changed)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/service/model"
+)
+
+const maxBatchSize = 1000
+
+// batchItems tracks the items of a batch request that passed validation, by their index in the request.
+type batchItems struct {
+	results      []domain.BatchItemResult
+	validIndexes []int
+}
+
+func newBatchItems(size int, mode string) (*batchItems, error) {
+	if !domain.IsValidBatchMode(mode) {
+		return nil, errors.New(fmt.Sprintf("Batch mode %s is not valid", mode))
+	}
+	if size == 0 || size > maxBatchSize {
+		return nil, errors.New(fmt.Sprintf("Batch should have between 1 and %d items", maxBatchSize))
+	}
+	return &batchItems{results: make([]domain.BatchItemResult, size)}, nil
+}
+
+func (batchItems *batchItems) fail(index int, id int64, err error) {
+	batchItems.results[index] = domain.BatchItemResult{Index: index, Id: id, Status: domain.BatchItemFailed, Error: err.Error()}
+}
+
+func (batchItems *batchItems) accept(index int) {
+	batchItems.validIndexes = append(batchItems.validIndexes, index)
+}
+
+// rejected tells whether an atomic batch has to stop after validation, marking the valid items as not applied.
+func (batchItems *batchItems) rejected(mode string) bool {
+	if mode != domain.BatchModeAtomic || len(batchItems.validIndexes) == len(batchItems.results) {
+		return false
+	}
+	for _, index := range batchItems.validIndexes {
+		batchItems.results[index] = domain.BatchItemResult{Index: index, Status: domain.BatchItemNotApplied}
+	}
+	return true
+}
+
+// merge places the repository results, which follow the order of the valid items, back at their request index.
+func (batchItems *batchItems) merge(repositoryResults []domain.BatchItemResult) []int64 {
+	var succeededIds []int64
+	for i, result := range repositoryResults {
+		result.Index = batchItems.validIndexes[i]
+		batchItems.results[result.Index] = result
+		if result.Status == domain.BatchItemSucceeded {
+			succeededIds = append(succeededIds, result.Id)
+		}
+	}
+	return succeededIds
+}
+
+// AddBatch creates the products in one go, every item is validated like Add does.
+func (productService *ProductService) AddBatch(products []model.CreateProduct, mode string) ([]domain.BatchItemResult, error) {
+	items, err := newBatchItems(len(products), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	productEntities := make([]domain.Product, 0, len(products))
+	for i, product := range products {
+		productEntity, err := productService.toProductEntity(product)
+		if err != nil {
+			items.fail(i, 0, err)
+			continue
+		}
+		items.accept(i)
+		productEntities = append(productEntities, productEntity)
+	}
+	if items.rejected(mode) || len(productEntities) == 0 {
+		return items.results, nil
+	}
+
+	results, err := productService.productRepository.AddBatch(productEntities, mode == domain.BatchModeAtomic)
+	if err != nil {
+		return nil, err
+	}
+	productService.reindexAll(items.merge(results))
+	return items.results, nil
+}
+
+func (productService *ProductService) UpdatePriceBatch(updates []domain.ProductPriceUpdate, mode string, actor string) ([]domain.BatchItemResult, error) {
+	items, err := newBatchItems(len(updates), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	validUpdates := make([]domain.ProductPriceUpdate, 0, len(updates))
+	seen := make(map[int64]bool, len(updates))
+	for i, update := range updates {
+		if update.Price <= 0 {
+			items.fail(i, update.Id, errors.New("Price should be greater than 0"))
+			continue
+		}
+		if seen[update.Id] {
+			items.fail(i, update.Id, errors.New(fmt.Sprintf("Product with id %d is repeated in the batch", update.Id)))
+			continue
+		}
+		seen[update.Id] = true
+		items.accept(i)
+		validUpdates = append(validUpdates, update)
+	}
+	if items.rejected(mode) || len(validUpdates) == 0 {
+		return items.results, nil
+	}
+
+	results, err := productService.productRepository.UpdatePricesBatch(validUpdates, actor, mode == domain.BatchModeAtomic)
+	if err != nil {
+		return nil, err
+	}
+	productService.reindexAll(items.merge(results))
+	return items.results, nil
+}
+
+func (productService *ProductService) DeleteBatch(ids []int64, mode string, actor string) ([]domain.BatchItemResult, error) {
+	items, err := newBatchItems(len(ids), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	validIds := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for i, id := range ids {
+		if seen[id] {
+			items.fail(i, id, errors.New(fmt.Sprintf("Product with id %d is repeated in the batch", id)))
+			continue
+		}
+		seen[id] = true
+		items.accept(i)
+		validIds = append(validIds, id)
+	}
+	if items.rejected(mode) || len(validIds) == 0 {
+		return items.results, nil
+	}
+
+	results, err := productService.productRepository.DeleteBatch(validIds, actor, mode == domain.BatchModeAtomic)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range items.merge(results) {
+		productService.searchIndex.Remove(id)
+	}
+	return items.results, nil
+}
+
+// reindexAll loads the products with a single query instead of reindexing them one by one.
+func (productService *ProductService) reindexAll(ids []int64) {
+	if len(ids) == 0 {
+		return
+	}
+	products, err := productService.GetByIds(ids)
+	if err != nil {
+		log.Errorf("Error while reindexing %d products: %v", len(ids), err)
+		return
+	}
+	for _, product := range products {
+		productService.searchIndex.Index(product)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"github.com/labstack/gommon/log"
+	"go-product-app/common/events"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"sync"
+	"time"
+)
+
+// productEventFeedRetryDelay is how long the feed waits before reading the outbox again after an error.
+const productEventFeedRetryDelay = time.Second
+
+// ProductEventFeed hands the events published by the outbox relay, which runs on a single instance at a time, to the
+// publisher of this instance, e.g. the broadcaster of the product stream. It is told the ids of the published events
+// by the product change listener and reads them from the outbox in batches, so a busy relay costs one query per batch.
+//
+// After the listener reconnected the feed catches up with the events published after the last one it saw. An event
+// that was held back and published after a later one is not caught up, clients of the stream miss it until they reload.
+type ProductEventFeed struct {
+	outboxRepository persistence.IOutboxRepository
+	publisher        events.Publisher
+	batchSize        int
+	mutex            sync.Mutex
+	pending          []int64
+	resync           bool
+	lastId           int64
+	wake             chan struct{}
+}
+
+func NewProductEventFeed(outboxRepository persistence.IOutboxRepository, publisher events.Publisher, batchSize int) *ProductEventFeed {
+	return &ProductEventFeed{
+		outboxRepository: outboxRepository,
+		publisher:        publisher,
+		batchSize:        batchSize,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// Notify queues a published event, it is the PublishedEventHandler of the listener and does not block.
+func (productEventFeed *ProductEventFeed) Notify(eventId int64) {
+	productEventFeed.mutex.Lock()
+	productEventFeed.pending = append(productEventFeed.pending, eventId)
+	productEventFeed.mutex.Unlock()
+	productEventFeed.signal()
+}
+
+// HandleChange catches up after the listener reconnected, the other product changes are of no interest to the feed.
+func (productEventFeed *ProductEventFeed) HandleChange(change domain.ProductChange) {
+	if change.Operation != domain.ProductChangeResync {
+		return
+	}
+	productEventFeed.mutex.Lock()
+	productEventFeed.resync = true
+	productEventFeed.mutex.Unlock()
+	productEventFeed.signal()
+}
+
+func (productEventFeed *ProductEventFeed) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-productEventFeed.wake:
+			}
+			if err := productEventFeed.RunOnce(ctx); err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(productEventFeedRetryDelay):
+				}
+				productEventFeed.signal()
+			}
+		}
+	}()
+}
+
+// RunOnce publishes the queued events, the events are queued again when they can not be read. A catch up reads the
+// events published after the last one seen in batches until a short batch shows there are no more.
+func (productEventFeed *ProductEventFeed) RunOnce(ctx context.Context) error {
+	productEventFeed.mutex.Lock()
+	pending, resync, lastId := productEventFeed.pending, productEventFeed.resync, productEventFeed.lastId
+	productEventFeed.pending, productEventFeed.resync = nil, false
+	productEventFeed.mutex.Unlock()
+
+	published := make(map[int64]bool)
+	var err error
+	for resync && lastId > 0 {
+		var productEvents []domain.ProductEvent
+		productEvents, err = productEventFeed.outboxRepository.GetPublishedAfter(lastId, productEventFeed.batchSize)
+		if err != nil {
+			break
+		}
+		lastId = productEventFeed.publish(ctx, productEvents, published, lastId)
+		resync = len(productEvents) == productEventFeed.batchSize
+	}
+	if err == nil && len(pending) > 0 {
+		var productEvents []domain.ProductEvent
+		productEvents, err = productEventFeed.outboxRepository.GetPublished(pending)
+		if err == nil {
+			lastId = productEventFeed.publish(ctx, productEvents, published, lastId)
+		}
+	}
+
+	productEventFeed.mutex.Lock()
+	defer productEventFeed.mutex.Unlock()
+	productEventFeed.lastId = max(productEventFeed.lastId, lastId)
+	if err != nil {
+		log.Errorf("Error while reading published product events: %v", err)
+		productEventFeed.pending = append(pending, productEventFeed.pending...)
+		productEventFeed.resync = productEventFeed.resync || resync
+		return err
+	}
+	return nil
+}
+
+// publish hands the events that are not published yet to the publisher and returns the highest id seen.
+func (productEventFeed *ProductEventFeed) publish(ctx context.Context, productEvents []domain.ProductEvent, published map[int64]bool, lastId int64) int64 {
+	for _, event := range productEvents {
+		// the events caught up with may be notified as well
+		if published[event.Id] {
+			continue
+		}
+		published[event.Id] = true
+		if err := productEventFeed.publisher.Publish(ctx, event); err != nil {
+			log.Errorf("Error while publishing product event %s to this instance: %v", event.EventId, err)
+		}
+		lastId = max(lastId, event.Id)
+	}
+	return lastId
+}
+
+func (productEventFeed *ProductEventFeed) signal() {
+	select {
+	case productEventFeed.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// productExportWriter writes the selected fields of products in one export format.
+// Flush pushes what is buffered to the underlying writer, Close ends the file.
+type productExportWriter interface {
+	WriteProduct(product domain.Product) error
+	Flush() error
+	Close() error
+}
+
+func newProductExportWriter(format string, fields []string, writer io.Writer) (productExportWriter, error) {
+	switch format {
+	case domain.ExportFormatNdjson:
+		return &ndjsonExportWriter{writer: bufio.NewWriter(writer), fields: fields}, nil
+	case domain.ExportFormatXlsx:
+		return newXlsxExportWriter(fields, writer)
+	case domain.ExportFormatCsv:
+		csvWriter := csv.NewWriter(writer)
+		return &csvExportWriter{writer: csvWriter, fields: fields}, csvWriter.Write(fields)
+	}
+	return nil, errors.New(fmt.Sprintf("Export format %s is not supported", format))
+}
+
+// exportValue returns the value of a field, numbers and times keep their type for formats that have them.
+func exportValue(product domain.Product, field string) interface{} {
+	switch field {
+	case "id":
+		return product.Id
+	case "name":
+		return product.Name
+	case "description":
+		return product.Description
+	case "price":
+		return product.Price
+	case "discount":
+		return product.Discount
+	case "store":
+		return product.Store
+	case "category":
+		return product.Category
+	case "status":
+		return product.Status
+	case "sku":
+		return product.Sku
+	case "gtin":
+		return product.Gtin
+	case "options":
+		// joined the way the importer splits them
+		return strings.Join(product.Options, "|")
+	case "created_at":
+		return product.CreatedAt
+	case "updated_at":
+		return product.UpdatedAt
+	case "created_by":
+		return product.CreatedBy
+	case "updated_by":
+		return product.UpdatedBy
+	}
+	return nil
+}
+
+func exportString(value interface{}) string {
+	switch typedValue := value.(type) {
+	case string:
+		return typedValue
+	case int64:
+		return strconv.FormatInt(typedValue, 10)
+	case float32:
+		return strconv.FormatFloat(float64(typedValue), 'f', -1, 32)
+	case time.Time:
+		if typedValue.IsZero() {
+			return ""
+		}
+		return typedValue.UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+// csvFormulaPrefixes start the cells spreadsheets would run as formulas.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvCell keeps a spreadsheet from running a text cell as a formula by prefixing it with a quote, which spreadsheets
+// hide. The importer drops the quote again.
+func csvCell(value interface{}) string {
+	text := exportString(value)
+	if _, isString := value.(string); isString && len(text) > 0 && strings.ContainsRune(csvFormulaPrefixes, rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+	fields []string
+}
+
+func (csvExportWriter *csvExportWriter) WriteProduct(product domain.Product) error {
+	record := make([]string, 0, len(csvExportWriter.fields))
+	for _, field := range csvExportWriter.fields {
+		record = append(record, csvCell(exportValue(product, field)))
+	}
+	return csvExportWriter.writer.Write(record)
+}
+
+func (csvExportWriter *csvExportWriter) Flush() error {
+	csvExportWriter.writer.Flush()
+	return csvExportWriter.writer.Error()
+}
+
+func (csvExportWriter *csvExportWriter) Close() error {
+	return csvExportWriter.Flush()
+}
+
+type ndjsonExportWriter struct {
+	writer *bufio.Writer
+	fields []string
+}
+
+// WriteProduct writes the fields in their selected order, which a json encoded map would not keep.
+func (ndjsonExportWriter *ndjsonExportWriter) WriteProduct(product domain.Product) error {
+	ndjsonExportWriter.writer.WriteByte('{')
+	for i, field := range ndjsonExportWriter.fields {
+		if i > 0 {
+			ndjsonExportWriter.writer.WriteByte(',')
+		}
+		value := exportValue(product, field)
+		if timeValue, isTime := value.(time.Time); isTime {
+			value = exportString(timeValue)
+		}
+		encodedValue, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		ndjsonExportWriter.writer.WriteString(strconv.Quote(field) + ":")
+		ndjsonExportWriter.writer.Write(encodedValue)
+	}
+	_, err := ndjsonExportWriter.writer.WriteString("}\n")
+	return err
+}
+
+func (ndjsonExportWriter *ndjsonExportWriter) Flush() error {
+	return ndjsonExportWriter.writer.Flush()
+}
+
+func (ndjsonExportWriter *ndjsonExportWriter) Close() error {
+	return ndjsonExportWriter.Flush()
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Products" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxExportWriter streams a minimal SpreadsheetML workbook with a single sheet. The fixed parts of the
+// package are written first so the sheet, the only part growing with the export, can be written last.
+type xlsxExportWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	fields  []string
+}
+
+func newXlsxExportWriter(fields []string, writer io.Writer) (productExportWriter, error) {
+	archive := zip.NewWriter(writer)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRelationships},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRelationships},
+	} {
+		partWriter, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = io.WriteString(partWriter, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetWriter, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xlsxExportWriter := &xlsxExportWriter{archive: archive, sheet: bufio.NewWriter(sheetWriter), fields: fields}
+	xlsxExportWriter.sheet.WriteString(xlsxSheetStart)
+	header := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		header = append(header, field)
+	}
+	return xlsxExportWriter, xlsxExportWriter.writeRow(header)
+}
+
+func (xlsxExportWriter *xlsxExportWriter) WriteProduct(product domain.Product) error {
+	values := make([]interface{}, 0, len(xlsxExportWriter.fields))
+	for _, field := range xlsxExportWriter.fields {
+		values = append(values, exportValue(product, field))
+	}
+	return xlsxExportWriter.writeRow(values)
+}
+
+// writeRow writes numbers as numeric cells and everything else as inline strings, so no shared string table is needed.
+func (xlsxExportWriter *xlsxExportWriter) writeRow(values []interface{}) error {
+	sheet := xlsxExportWriter.sheet
+	sheet.WriteString("<row>")
+	for _, value := range values {
+		switch value.(type) {
+		case int64, float32:
+			sheet.WriteString("<c><v>" + exportString(value) + "</v></c>")
+		default:
+			sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(sheet, []byte(exportString(value))); err != nil {
+				return err
+			}
+			sheet.WriteString("</t></is></c>")
+		}
+	}
+	_, err := sheet.WriteString("</row>")
+	return err
+}
+
+func (xlsxExportWriter *xlsxExportWriter) Flush() error {
+	if err := xlsxExportWriter.sheet.Flush(); err != nil {
+		return err
+	}
+	return xlsxExportWriter.archive.Flush()
+}
+
+func (xlsxExportWriter *xlsxExportWriter) Close() error {
+	xlsxExportWriter.sheet.WriteString(xlsxSheetEnd)
+	if err := xlsxExportWriter.sheet.Flush(); err != nil {
+		return err
+	}
+	return xlsxExportWriter.archive.Close()
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// importRecordReader reads an import file one record at a time, a record maps column names to raw values.
+type importRecordReader interface {
+	Next() (map[string]string, error)
+	// Row is the 1 based position of the last record in the file, counting the csv header
+	Row() int
+}
+
+type csvRecordReader struct {
+	reader *csv.Reader
+	header []string
+	row    int
+}
+
+func newCsvRecordReader(reader io.Reader) (importRecordReader, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, errors.New("Csv file should start with a header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	header = append([]string{}, header...)
+	// spreadsheets often save utf-8 with a byte order mark
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+	return &csvRecordReader{reader: csvReader, header: header, row: 1}, nil
+}
+
+func (csvRecordReader *csvRecordReader) Next() (map[string]string, error) {
+	values, err := csvRecordReader.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	// a malformed record still takes a row, so the rows after it keep their position
+	csvRecordReader.row++
+	if err != nil {
+		return nil, err
+	}
+	record := make(map[string]string, len(csvRecordReader.header))
+	for i, column := range csvRecordReader.header {
+		if i < len(values) {
+			record[column] = csvCellValue(strings.TrimSpace(values[i]))
+		}
+	}
+	return record, nil
+}
+
+// csvCellValue drops the quote the exporter puts in front of a cell that would run as a formula.
+func csvCellValue(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
+
+func (csvRecordReader *csvRecordReader) Row() int {
+	return csvRecordReader.row
+}
+
+type ndjsonRecordReader struct {
+	decoder *json.Decoder
+	row     int
+}
+
+func newNdjsonRecordReader(reader io.Reader) importRecordReader {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	return &ndjsonRecordReader{decoder: decoder}
+}
+
+func (ndjsonRecordReader *ndjsonRecordReader) Next() (map[string]string, error) {
+	var object map[string]interface{}
+	err := ndjsonRecordReader.decoder.Decode(&object)
+	if err == io.EOF {
+		return nil, err
+	}
+	ndjsonRecordReader.row++
+	if err != nil {
+		return nil, err
+	}
+	record := make(map[string]string, len(object))
+	for key, value := range object {
+		record[key] = ndjsonValueString(value)
+	}
+	return record, nil
+}
+
+func (ndjsonRecordReader *ndjsonRecordReader) Row() int {
+	return ndjsonRecordReader.row
+}
+
+// ndjsonValueString flattens a json value the way the same cell would look in a csv file, arrays are joined with "|".
+func ndjsonValueString(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(typedValue)
+	case json.Number:
+		return typedValue.String()
+	case bool:
+		return strconv.FormatBool(typedValue)
+	case []interface{}:
+		parts := make([]string, 0, len(typedValue))
+		for _, item := range typedValue {
+			parts = append(parts, ndjsonValueString(item))
+		}
+		return strings.Join(parts, "|")
+	default:
+		encoded, _ := json.Marshal(typedValue)
+		return string(encoded)
+	}
+}
+
+func newImportRecordReader(format string, reader io.Reader) (importRecordReader, error) {
+	switch format {
+	case domain.ImportFormatCsv:
+		return newCsvRecordReader(reader)
+	case domain.ImportFormatNdjson:
+		return newNdjsonRecordReader(reader), nil
+	}
+	return nil, errors.New(fmt.Sprintf("Import format %s is not supported", format))
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"go-product-app/common/localization"
+	"go-product-app/common/storage"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service/model"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const importProgressInterval = 100
+
+type IProductImporter interface {
+	Import(reader io.Reader, options model.ImportProducts) (domain.ProductImportReport, error)
+}
+
+// ProductImporter reads import files record by record, so the size of a file does not matter.
+// Every row goes through the rules of ProductService and the failed rows are written to a csv error report.
+type ProductImporter struct {
+	productService       IProductService
+	productRepository    persistence.IProductRepository
+	reportStorage        storage.Storage
+	localizationSettings localization.Settings
+}
+
+func NewProductImporter(productService IProductService, productRepository persistence.IProductRepository, reportStorage storage.Storage,
+	localizationSettings localization.Settings) IProductImporter {
+	return &ProductImporter{
+		productService:       productService,
+		productRepository:    productRepository,
+		reportStorage:        reportStorage,
+		localizationSettings: localizationSettings,
+	}
+}
+
+func (productImporter *ProductImporter) Import(reader io.Reader, options model.ImportProducts) (domain.ProductImportReport, error) {
+	err := validateImportOptions(options)
+	if err != nil {
+		return domain.ProductImportReport{}, err
+	}
+	records, err := newImportRecordReader(options.Format, reader)
+	if err != nil {
+		return domain.ProductImportReport{}, err
+	}
+
+	errorReport, err := productImporter.newErrorReport()
+	if err != nil {
+		return domain.ProductImportReport{}, err
+	}
+	report := domain.ProductImportReport{DryRun: options.DryRun}
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			break
+		}
+		report.Rows++
+		if err != nil {
+			// a malformed csv line or json value, the reader can carry on with the next one only for csv
+			report.Failed++
+			if options.Format == domain.ImportFormatNdjson {
+				report.Truncated = true
+				errorReport.add(records.Row(), "", errors.New(fmt.Sprintf("%v, the lines after it are not imported", err)))
+				break
+			}
+			errorReport.add(records.Row(), "", err)
+			continue
+		}
+
+		created, err := productImporter.importRecord(record, options)
+		switch {
+		case err != nil:
+			report.Failed++
+			errorReport.add(records.Row(), importValue(record, options.Mapping, "sku"), err)
+		case created:
+			report.Created++
+		default:
+			report.Updated++
+		}
+
+		if options.Progress != nil && report.Rows%importProgressInterval == 0 {
+			if err = options.Progress(report.Rows); err != nil {
+				errorReport.close(report.Failed > 0)
+				return domain.ProductImportReport{}, err
+			}
+		}
+	}
+
+	report.ErrorReportUrl, err = errorReport.close(report.Failed > 0)
+	if err != nil {
+		return domain.ProductImportReport{}, err
+	}
+	log.Infof("Import of %d rows finished, %d created, %d updated, %d failed, dry run %t", report.Rows, report.Created, report.Updated, report.Failed, report.DryRun)
+	return report, nil
+}
+
+func validateImportOptions(options model.ImportProducts) error {
+	if options.Format != domain.ImportFormatCsv && options.Format != domain.ImportFormatNdjson {
+		return errors.New(fmt.Sprintf("Import format %s is not supported", options.Format))
+	}
+	if options.UpsertBy != domain.ImportUpsertBySku && options.UpsertBy != domain.ImportUpsertById {
+		return errors.New(fmt.Sprintf("Upsert by %s is not supported, use sku or id", options.UpsertBy))
+	}
+	for field := range options.Mapping {
+		if !slices.Contains(domain.ImportFields, field) {
+			return errors.New(fmt.Sprintf("Field %s can not be mapped, mappable fields are %s", field, strings.Join(domain.ImportFields, ", ")))
+		}
+	}
+	return nil
+}
+
+// importRecord creates the product of the record or updates the one it matches, it tells which one happened.
+func (productImporter *ProductImporter) importRecord(record map[string]string, options model.ImportProducts) (bool, error) {
+	existing, found, err := productImporter.findExisting(record, options)
+	if err != nil {
+		return false, err
+	}
+
+	product := model.CreateProduct{Actor: options.Actor}
+	if found {
+		product = model.CreateProduct{
+			Name:         existing.Name,
+			Description:  existing.Description,
+			Price:        existing.Price,
+			Discount:     existing.Discount,
+			Store:        existing.Store,
+			Category:     existing.Category,
+			Sku:          existing.Sku,
+			Gtin:         existing.Gtin,
+			Options:      existing.Options,
+			Attributes:   existing.Attributes,
+			Translations: maps.Clone(existing.Translations),
+			Actor:        options.Actor,
+		}
+	}
+	err = applyImportRecord(&product, record, options.Mapping, found)
+	if err != nil {
+		return false, err
+	}
+	// the name and description of the row are the text of the store default locale, its kept translation would
+	// otherwise still show the old text to localized reads
+	if found && len(product.Translations) > 0 {
+		defaultLocale := productImporter.localizationSettings.DefaultLocaleFor(product.Store)
+		product.Translations[defaultLocale] = domain.LocalizedText{Name: product.Name, Description: product.Description}
+	}
+	if !found && options.DraftsOnly {
+		if len(product.Status) > 0 && product.Status != domain.ProductStatusDraft {
+			return false, errors.New(fmt.Sprintf("Status %s can not be imported, only drafts are allowed", product.Status))
+		}
+		product.Status = domain.ProductStatusDraft
+	}
+
+	switch {
+	case options.DryRun:
+		return !found, productImporter.productService.Validate(product)
+	case found:
+		return false, productImporter.productService.Update(existing.Id, product)
+	default:
+		return true, productImporter.productService.Add(product)
+	}
+}
+
+func (productImporter *ProductImporter) findExisting(record map[string]string, options model.ImportProducts) (domain.Product, bool, error) {
+	if options.UpsertBy == domain.ImportUpsertById {
+		idValue := importValue(record, options.Mapping, "id")
+		if len(idValue) == 0 {
+			return domain.Product{}, false, nil
+		}
+		id, err := strconv.ParseInt(idValue, 10, 64)
+		if err != nil {
+			return domain.Product{}, false, errors.New(fmt.Sprintf("Id %s is not a number", idValue))
+		}
+		products, err := productImporter.productRepository.GetByIds([]int64{id})
+		if err != nil {
+			return domain.Product{}, false, err
+		}
+		if len(products) == 0 {
+			return domain.Product{}, false, errors.New(fmt.Sprintf("Product with id %d not found", id))
+		}
+		return products[0], true, nil
+	}
+
+	sku, store := importValue(record, options.Mapping, "sku"), importValue(record, options.Mapping, "store")
+	if len(sku) == 0 {
+		return domain.Product{}, false, nil
+	}
+	if len(store) == 0 {
+		return domain.Product{}, false, errors.New("Store is required to upsert by sku")
+	}
+	products, err := productImporter.productRepository.GetBySku(sku, store)
+	var notFoundError persistence.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return domain.Product{}, false, nil
+	}
+	if err != nil {
+		return domain.Product{}, false, err
+	}
+	return products[0], true, nil
+}
+
+// applyImportRecord overrides the fields of product with the non empty values of the record.
+// The store of an existing product is kept, and its status only changes through the status endpoint.
+func applyImportRecord(product *model.CreateProduct, record map[string]string, mapping map[string]string, existing bool) error {
+	for _, field := range domain.ImportFields {
+		value := importValue(record, mapping, field)
+		if len(value) == 0 {
+			continue
+		}
+		switch field {
+		case "name":
+			product.Name = value
+		case "description":
+			product.Description = value
+		case "price":
+			price, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				return errors.New(fmt.Sprintf("Price %s is not a number", value))
+			}
+			product.Price = float32(price)
+		case "discount":
+			discount, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				return errors.New(fmt.Sprintf("Discount %s is not a number", value))
+			}
+			product.Discount = float32(discount)
+		case "store":
+			if !existing {
+				product.Store = value
+			}
+		case "category":
+			product.Category = value
+		case "status":
+			if !existing {
+				product.Status = value
+			}
+		case "sku":
+			product.Sku = value
+		case "gtin":
+			product.Gtin = value
+		case "options":
+			product.Options = strings.Split(value, "|")
+		}
+	}
+	return nil
+}
+
+func importValue(record map[string]string, mapping map[string]string, field string) string {
+	column, mapped := mapping[field]
+	if !mapped {
+		column = field
+	}
+	return record[column]
+}
+
+// importErrorReport streams the failed rows into the report storage as they happen.
+type importErrorReport struct {
+	storage storage.Storage
+	key     string
+	writer  *io.PipeWriter
+	csv     *csv.Writer
+	saveErr chan error
+}
+
+func (productImporter *ProductImporter) newErrorReport() (*importErrorReport, error) {
+	token, err := newFileToken()
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("imports/%s/errors.csv", token)
+	pipeReader, pipeWriter := io.Pipe()
+	errorReport := &importErrorReport{
+		storage: productImporter.reportStorage,
+		key:     key,
+		writer:  pipeWriter,
+		csv:     csv.NewWriter(pipeWriter),
+		saveErr: make(chan error, 1),
+	}
+	go func() {
+		_, err := productImporter.reportStorage.Save(key, pipeReader)
+		pipeReader.CloseWithError(err)
+		errorReport.saveErr <- err
+	}()
+	errorReport.csv.Write([]string{"row", "sku", "error"})
+	return errorReport, nil
+}
+
+func (importErrorReport *importErrorReport) add(row int, sku string, err error) {
+	importErrorReport.csv.Write([]string{strconv.Itoa(row), sku, err.Error()})
+}
+
+// close finishes the report and returns its url, a report without failed rows is deleted.
+func (importErrorReport *importErrorReport) close(keep bool) (string, error) {
+	importErrorReport.csv.Flush()
+	importErrorReport.writer.CloseWithError(importErrorReport.csv.Error())
+	err := <-importErrorReport.saveErr
+	if err != nil {
+		log.Errorf("Error while saving import error report %s: %v", importErrorReport.key, err)
+		return "", err
+	}
+	if !keep {
+		return "", importErrorReport.storage.Delete(importErrorReport.key)
+	}
+	return importErrorReport.storage.URL(importErrorReport.key), nil
+}
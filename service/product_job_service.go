@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"go-product-app/common/storage"
+	"go-product-app/domain"
+	"go-product-app/service/model"
+	"io"
+)
+
+const (
+	JobTypeProductImport = "product_import"
+	JobTypeProductExport = "product_export"
+)
+
+// IProductJobService runs product imports and exports as background jobs.
+type IProductJobService interface {
+	StartImport(reader io.Reader, options model.ImportProducts) (domain.Job, error)
+	StartExport(query domain.ProductExportQuery, actor string) (domain.Job, error)
+	HandleImport(ctx context.Context, job domain.Job, reportProgress JobProgressReporter) (interface{}, error)
+	HandleExport(ctx context.Context, job domain.Job, reportProgress JobProgressReporter) (interface{}, error)
+	FinishImport(job domain.Job)
+}
+
+type ProductJobService struct {
+	jobService      IJobService
+	productService  IProductService
+	productImporter IProductImporter
+	uploadStorage   storage.Storage
+	fileStorage     storage.Storage
+}
+
+func NewProductJobService(jobService IJobService, productService IProductService, productImporter IProductImporter,
+	uploadStorage storage.Storage, fileStorage storage.Storage) IProductJobService {
+	return &ProductJobService{
+		jobService:      jobService,
+		productService:  productService,
+		productImporter: productImporter,
+		uploadStorage:   uploadStorage,
+		fileStorage:     fileStorage,
+	}
+}
+
+type productImportJobPayload struct {
+	SourceKey  string            `json:"source_key"`
+	Format     string            `json:"format"`
+	DryRun     bool              `json:"dry_run"`
+	UpsertBy   string            `json:"upsert_by"`
+	Mapping    map[string]string `json:"mapping"`
+	DraftsOnly bool              `json:"drafts_only"`
+}
+
+type productExportJobPayload struct {
+	Query domain.ProductExportQuery `json:"query"`
+}
+
+type productExportJobResult struct {
+	Url   string `json:"url"`
+	Bytes int64  `json:"bytes"`
+}
+
+// StartImport keeps the uploaded file until the import job has finished, see FinishImport.
+func (productJobService *ProductJobService) StartImport(reader io.Reader, options model.ImportProducts) (domain.Job, error) {
+	if err := validateImportOptions(options); err != nil {
+		return domain.Job{}, err
+	}
+	token, err := newFileToken()
+	if err != nil {
+		return domain.Job{}, err
+	}
+	sourceKey := fmt.Sprintf("imports/%s/source.%s", token, options.Format)
+	_, err = productJobService.uploadStorage.Save(sourceKey, reader)
+	if err != nil {
+		return domain.Job{}, err
+	}
+
+	job, err := productJobService.jobService.Enqueue(JobTypeProductImport, productImportJobPayload{
+		SourceKey:  sourceKey,
+		Format:     options.Format,
+		DryRun:     options.DryRun,
+		UpsertBy:   options.UpsertBy,
+		Mapping:    options.Mapping,
+		DraftsOnly: options.DraftsOnly,
+	}, options.Actor)
+	if err != nil {
+		productJobService.uploadStorage.Delete(sourceKey)
+		return domain.Job{}, err
+	}
+	return job, nil
+}
+
+func (productJobService *ProductJobService) StartExport(query domain.ProductExportQuery, actor string) (domain.Job, error) {
+	if !domain.IsValidExportFormat(query.Format) {
+		return domain.Job{}, errors.New(fmt.Sprintf("Export format %s is not supported", query.Format))
+	}
+	if err := domain.ValidateExportFields(query.Fields); err != nil {
+		return domain.Job{}, err
+	}
+	return productJobService.jobService.Enqueue(JobTypeProductExport, productExportJobPayload{Query: query}, actor)
+}
+
+// HandleImport imports the uploaded file, progress counts the rows read so far.
+func (productJobService *ProductJobService) HandleImport(ctx context.Context, job domain.Job, reportProgress JobProgressReporter) (interface{}, error) {
+	var payload productImportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, PermanentJobError{Message: fmt.Sprintf("Payload of import job is not valid: %v", err)}
+	}
+	source, err := productJobService.uploadStorage.Open(payload.SourceKey)
+	if err != nil {
+		return nil, PermanentJobError{Message: fmt.Sprintf("Uploaded file of import job can not be opened: %v", err)}
+	}
+	defer source.Close()
+
+	report, err := productJobService.productImporter.Import(source, model.ImportProducts{
+		Format:     payload.Format,
+		DryRun:     payload.DryRun,
+		UpsertBy:   payload.UpsertBy,
+		Mapping:    payload.Mapping,
+		DraftsOnly: payload.DraftsOnly,
+		Actor:      job.CreatedBy,
+		Progress: func(rows int) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return reportProgress(int64(rows), 0)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// FinishImport deletes the uploaded file once the import job will not run again, the attempts before the last
+// one leave it for the retry.
+func (productJobService *ProductJobService) FinishImport(job domain.Job) {
+	var payload productImportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil || len(payload.SourceKey) == 0 {
+		return
+	}
+	if err := productJobService.uploadStorage.Delete(payload.SourceKey); err != nil {
+		log.Errorf("Error while deleting uploaded file of import job %d: %v", job.Id, err)
+	}
+}
+
+// HandleExport writes the export file to the file storage, progress counts the bytes written so far.
+func (productJobService *ProductJobService) HandleExport(ctx context.Context, job domain.Job, reportProgress JobProgressReporter) (interface{}, error) {
+	var payload productExportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, PermanentJobError{Message: fmt.Sprintf("Payload of export job is not valid: %v", err)}
+	}
+
+	token, err := newFileToken()
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("exports/%s/products.%s", token, payload.Query.Format)
+	pipeReader, pipeWriter := io.Pipe()
+	saved := make(chan error, 1)
+	go func() {
+		_, err := productJobService.fileStorage.Save(key, pipeReader)
+		pipeReader.CloseWithError(err)
+		saved <- err
+	}()
+
+	writer := &jobProgressWriter{ctx: ctx, writer: pipeWriter, reportProgress: reportProgress}
+	err = productJobService.productService.Export(payload.Query, writer)
+	pipeWriter.CloseWithError(err)
+	saveErr := <-saved
+	if err != nil {
+		productJobService.fileStorage.Delete(key)
+		return nil, err
+	}
+	if saveErr != nil {
+		return nil, saveErr
+	}
+	return productExportJobResult{Url: productJobService.fileStorage.URL(key), Bytes: writer.written}, nil
+}
+
+// newFileToken names a directory in the file storage. The storage is served without authentication, the random
+// token is what keeps exports and error reports from being found by counting job ids.
+func newFileToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", errors.New(fmt.Sprintf("File token can not be generated: %v", err))
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// jobProgressWriter reports the bytes written on every Flush, which Export calls after each chunk,
+// and fails the writes that follow a cancellation.
+type jobProgressWriter struct {
+	ctx            context.Context
+	writer         io.Writer
+	reportProgress JobProgressReporter
+	written        int64
+	err            error
+}
+
+func (jobProgressWriter *jobProgressWriter) Write(p []byte) (int, error) {
+	if jobProgressWriter.err != nil {
+		return 0, jobProgressWriter.err
+	}
+	n, err := jobProgressWriter.writer.Write(p)
+	jobProgressWriter.written += int64(n)
+	return n, err
+}
+
+func (jobProgressWriter *jobProgressWriter) Flush() {
+	if jobProgressWriter.err == nil {
+		jobProgressWriter.err = jobProgressWriter.ctx.Err()
+	}
+	if jobProgressWriter.err == nil {
+		jobProgressWriter.err = jobProgressWriter.reportProgress(jobProgressWriter.written, 0)
+	}
+}
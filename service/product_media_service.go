@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"go-product-app/common/imaging"
+	"go-product-app/common/storage"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+const thumbnailSize = 256
+
+// Images are checked against these limits before they are decoded, a small file can declare a canvas
+// that takes gigabytes to decode.
+const (
+	maxImageSide   = 8000
+	maxImagePixels = 40_000_000
+)
+
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type IProductMediaService interface {
+	Upload(productId int64, reader io.Reader) (domain.ProductMedia, error)
+	GetByProductId(productId int64) ([]domain.ProductMedia, error)
+	GetByProductIds(productIds []int64) (map[int64][]domain.ProductMedia, error)
+	SetPrimary(productId int64, id int64) error
+	Reorder(productId int64, orderedIds []int64) error
+	Delete(productId int64, id int64) error
+}
+
+type ProductMediaService struct {
+	productMediaRepository persistence.IProductMediaRepository
+	productRepository      persistence.IProductRepository
+	storage                storage.Storage
+	maxUploadSize          int64
+}
+
+func NewProductMediaService(productMediaRepository persistence.IProductMediaRepository,
+	productRepository persistence.IProductRepository,
+	storage storage.Storage,
+	maxUploadSize int64) IProductMediaService {
+	return &ProductMediaService{
+		productMediaRepository: productMediaRepository,
+		productRepository:      productRepository,
+		storage:                storage,
+		maxUploadSize:          maxUploadSize,
+	}
+}
+
+func (productMediaService *ProductMediaService) Upload(productId int64, reader io.Reader) (domain.ProductMedia, error) {
+	_, err := productMediaService.productRepository.GetById(productId)
+	if err != nil {
+		return domain.ProductMedia{}, err
+	}
+
+	content, err := io.ReadAll(io.LimitReader(reader, productMediaService.maxUploadSize+1))
+	if err != nil {
+		return domain.ProductMedia{}, err
+	}
+	if int64(len(content)) > productMediaService.maxUploadSize {
+		return domain.ProductMedia{}, errors.New(fmt.Sprintf("File should be at most %d bytes", productMediaService.maxUploadSize))
+	}
+
+	contentType := http.DetectContentType(content)
+	extension, supported := mediaExtensions[contentType]
+	if !supported {
+		return domain.ProductMedia{}, errors.New(fmt.Sprintf("Unsupported content type %s, expected a jpeg, png or gif image", contentType))
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return domain.ProductMedia{}, errors.New(fmt.Sprintf("Image could not be decoded: %v", err))
+	}
+	if imageConfig.Width > maxImageSide || imageConfig.Height > maxImageSide || imageConfig.Width*imageConfig.Height > maxImagePixels {
+		return domain.ProductMedia{}, errors.New(fmt.Sprintf("Image should be at most %dx%d pixels and %d pixels in total, it is %dx%d",
+			maxImageSide, maxImageSide, maxImagePixels, imageConfig.Width, imageConfig.Height))
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return domain.ProductMedia{}, errors.New(fmt.Sprintf("Image could not be decoded: %v", err))
+	}
+
+	thumbnail, err := encodeThumbnail(img, contentType)
+	if err != nil {
+		return domain.ProductMedia{}, err
+	}
+
+	checksum := sha256.Sum256(content)
+	productMedia := domain.ProductMedia{
+		ProductId:   productId,
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		Checksum:    hex.EncodeToString(checksum[:]),
+	}
+	productMedia.Key = fmt.Sprintf("products/%d/%s%s", productId, productMedia.Checksum, extension)
+	productMedia.ThumbnailKey = fmt.Sprintf("products/%d/%s_thumb%s", productId, productMedia.Checksum, extension)
+
+	_, err = productMediaService.storage.Save(productMedia.Key, bytes.NewReader(content))
+	if err != nil {
+		return domain.ProductMedia{}, err
+	}
+	_, err = productMediaService.storage.Save(productMedia.ThumbnailKey, bytes.NewReader(thumbnail))
+	if err != nil {
+		productMediaService.deleteFiles(productMedia)
+		return domain.ProductMedia{}, err
+	}
+
+	id, err := productMediaService.productMediaRepository.Add(productMedia)
+	if err != nil {
+		var conflictError persistence.ConflictError
+		if !errors.As(err, &conflictError) {
+			// the files of a duplicate upload belong to the existing media
+			productMediaService.deleteFiles(productMedia)
+		}
+		return domain.ProductMedia{}, err
+	}
+
+	created, err := productMediaService.productMediaRepository.GetById(id)
+	if err != nil {
+		return domain.ProductMedia{}, err
+	}
+	return productMediaService.withUrls(created), nil
+}
+
+func (productMediaService *ProductMediaService) GetByProductId(productId int64) ([]domain.ProductMedia, error) {
+	productMediaList, err := productMediaService.productMediaRepository.GetByProductId(productId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range productMediaList {
+		productMediaList[i] = productMediaService.withUrls(productMediaList[i])
+	}
+	return productMediaList, nil
+}
+
+func (productMediaService *ProductMediaService) GetByProductIds(productIds []int64) (map[int64][]domain.ProductMedia, error) {
+	mediaByProductId := make(map[int64][]domain.ProductMedia)
+	if len(productIds) == 0 {
+		return mediaByProductId, nil
+	}
+
+	productMediaList, err := productMediaService.productMediaRepository.GetByProductIds(productIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, productMedia := range productMediaList {
+		mediaByProductId[productMedia.ProductId] = append(mediaByProductId[productMedia.ProductId], productMediaService.withUrls(productMedia))
+	}
+	return mediaByProductId, nil
+}
+
+func (productMediaService *ProductMediaService) SetPrimary(productId int64, id int64) error {
+	return productMediaService.productMediaRepository.SetPrimary(productId, id)
+}
+
+func (productMediaService *ProductMediaService) Reorder(productId int64, orderedIds []int64) error {
+	if len(orderedIds) == 0 {
+		return errors.New("Media ids are required")
+	}
+	seen := make(map[int64]bool, len(orderedIds))
+	for _, id := range orderedIds {
+		if seen[id] {
+			return errors.New(fmt.Sprintf("Media id %d is listed more than once", id))
+		}
+		seen[id] = true
+	}
+	return productMediaService.productMediaRepository.Reorder(productId, orderedIds)
+}
+
+func (productMediaService *ProductMediaService) Delete(productId int64, id int64) error {
+	productMedia, err := productMediaService.productMediaRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+	if productMedia.ProductId != productId {
+		return errors.New(fmt.Sprintf("Media with id %d not found for product %d", id, productId))
+	}
+
+	err = productMediaService.productMediaRepository.DeleteById(id)
+	if err != nil {
+		return err
+	}
+	productMediaService.deleteFiles(productMedia)
+	return nil
+}
+
+func (productMediaService *ProductMediaService) withUrls(productMedia domain.ProductMedia) domain.ProductMedia {
+	productMedia.Url = productMediaService.storage.URL(productMedia.Key)
+	productMedia.ThumbnailUrl = productMediaService.storage.URL(productMedia.ThumbnailKey)
+	return productMedia
+}
+
+func (productMediaService *ProductMediaService) deleteFiles(productMedia domain.ProductMedia) {
+	for _, key := range []string{productMedia.Key, productMedia.ThumbnailKey} {
+		err := productMediaService.storage.Delete(key)
+		if err != nil {
+			log.Errorf("Error while deleting media file %s: %v", key, err)
+		}
+	}
+}
+
+func encodeThumbnail(img image.Image, contentType string) ([]byte, error) {
+	thumbnail := imaging.Thumbnail(img, thumbnailSize)
+
+	var buffer bytes.Buffer
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buffer, thumbnail, &jpeg.Options{Quality: 85})
+	case "image/gif":
+		err = gif.Encode(&buffer, thumbnail, nil)
+	default:
+		err = png.Encode(&buffer, thumbnail)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
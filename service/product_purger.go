@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"github.com/labstack/gommon/log"
+	"go-product-app/common/storage"
+	"go-product-app/persistence"
+	"time"
+)
+
+// ProductPurger periodically hard deletes products that have been soft deleted for longer than the retention period,
+// together with their media files.
+type ProductPurger struct {
+	productRepository persistence.IProductRepository
+	mediaStorage      storage.Storage
+	retention         time.Duration
+	interval          time.Duration
+}
+
+func NewProductPurger(productRepository persistence.IProductRepository, mediaStorage storage.Storage, retention time.Duration, interval time.Duration) *ProductPurger {
+	return &ProductPurger{productRepository: productRepository, mediaStorage: mediaStorage, retention: retention, interval: interval}
+}
+
+func (productPurger *ProductPurger) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(productPurger.interval)
+		defer ticker.Stop()
+
+		for {
+			productPurger.RunOnce(time.Now())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (productPurger *ProductPurger) RunOnce(now time.Time) {
+	purged, mediaKeys, err := productPurger.productRepository.PurgeDeleted(now.Add(-productPurger.retention))
+	if err != nil {
+		log.Errorf("Error while purging deleted products: %v", err)
+		return
+	}
+	for _, key := range mediaKeys {
+		if err = productPurger.mediaStorage.Delete(key); err != nil {
+			log.Errorf("Error while deleting media file %s of a purged product: %v", key, err)
+		}
+	}
+	if purged > 0 {
+		log.Infof("Deleted products purged, %d rows removed", purged)
+	}
+}
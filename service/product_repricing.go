@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/service/model"
+)
+
+// PreviewRepricing plans the repricing against the current prices without writing anything.
+func (productService *ProductService) PreviewRepricing(reprice model.RepriceProducts) (domain.RepricingPreview, error) {
+	repricing, err := toRepricing(reprice)
+	if err != nil {
+		return domain.RepricingPreview{}, err
+	}
+	products, err := productService.productRepository.GetAllByFilter(repricing.Filter)
+	if err != nil {
+		return domain.RepricingPreview{}, err
+	}
+	changes, err := domain.PlanRepricing(products, repricing.Operation)
+	if err != nil {
+		return domain.RepricingPreview{}, err
+	}
+	return domain.RepricingPreview{Matched: len(products), Changes: changes}, nil
+}
+
+func (productService *ProductService) Reprice(reprice model.RepriceProducts) (domain.Repricing, error) {
+	repricing, err := toRepricing(reprice)
+	if err != nil {
+		return domain.Repricing{}, err
+	}
+	repricing, changes, err := productService.productRepository.Reprice(repricing)
+	if err != nil {
+		return domain.Repricing{}, err
+	}
+	productService.reindexAll(changedProductIds(changes))
+	return repricing, nil
+}
+
+func (productService *ProductService) GetRepricingById(id int64) (domain.Repricing, error) {
+	return productService.productRepository.GetRepricingById(id)
+}
+
+func (productService *ProductService) UndoRepricing(id int64, actor string) (domain.Repricing, error) {
+	repricing, reverted, err := productService.productRepository.UndoRepricing(id, actor)
+	if err != nil {
+		return domain.Repricing{}, err
+	}
+	productService.reindexAll(changedProductIds(reverted))
+	return repricing, nil
+}
+
+func (productService *ProductService) GetPriceHistory(productId int64) ([]domain.PriceChange, error) {
+	_, err := productService.productRepository.GetById(productId)
+	if err != nil {
+		return nil, err
+	}
+	return productService.productRepository.GetPriceHistory(productId)
+}
+
+// toRepricing validates the command. A filter is required so that a mistake can not reprice the whole catalog.
+func toRepricing(reprice model.RepriceProducts) (domain.Repricing, error) {
+	if len(reprice.Store) == 0 && len(reprice.Category) == 0 && reprice.MinPrice == nil && reprice.MaxPrice == nil {
+		return domain.Repricing{}, errors.New("Repricing should be filtered by store, category or price")
+	}
+	if reprice.MinPrice != nil && reprice.MaxPrice != nil && *reprice.MinPrice > *reprice.MaxPrice {
+		return domain.Repricing{}, errors.New("Min price should not be greater than max price")
+	}
+
+	switch reprice.Operation {
+	case domain.RepricingPercentChange:
+		if reprice.Value <= -100 {
+			return domain.Repricing{}, errors.New("Percent change should be greater than -100")
+		}
+	case domain.RepricingSetPrice:
+		if reprice.Value <= 0 {
+			return domain.Repricing{}, errors.New("Price should be greater than 0")
+		}
+	case domain.RepricingSetDiscount:
+		if err := validateDiscount(reprice.Value); err != nil {
+			return domain.Repricing{}, err
+		}
+	default:
+		if !domain.IsValidRepricingOperation(reprice.Operation) {
+			return domain.Repricing{}, errors.New(fmt.Sprintf("Repricing operation %s is not valid", reprice.Operation))
+		}
+	}
+
+	return domain.Repricing{
+		Filter: domain.ProductFilter{
+			Store:    reprice.Store,
+			Category: reprice.Category,
+			MinPrice: reprice.MinPrice,
+			MaxPrice: reprice.MaxPrice,
+		},
+		Operation: domain.RepricingOperation{Type: reprice.Operation, Value: reprice.Value},
+		CreatedBy: reprice.Actor,
+	}, nil
+}
+
+func changedProductIds(changes []domain.PriceChange) []int64 {
+	ids := make([]int64, 0, len(changes))
+	for _, change := range changes {
+		ids = append(ids, change.ProductId)
+	}
+	return ids
+}
@@ -2,66 +2,684 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"go-product-app/common/localization"
+	"go-product-app/common/search"
 	"go-product-app/domain"
 	"go-product-app/persistence"
 	"go-product-app/service/model"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	exportChunkSize    = 500
 )
 
 type IProductService interface {
 	Add(product model.CreateProduct) error
-	UpdatePrice(id int64, price float32) error
-	DeleteById(id int64) error
+	Update(id int64, product model.CreateProduct) error
+	Validate(product model.CreateProduct) error
+	AddBatch(products []model.CreateProduct, mode string) ([]domain.BatchItemResult, error)
+	UpdatePriceBatch(updates []domain.ProductPriceUpdate, mode string, actor string) ([]domain.BatchItemResult, error)
+	DeleteBatch(ids []int64, mode string, actor string) ([]domain.BatchItemResult, error)
+	UpdatePrice(id int64, price float32, actor string) error
+	UpdateTranslations(id int64, translations map[string]domain.LocalizedText, actor string) error
+	ChangeStatus(statusChange model.ChangeProductStatus) error
+	GetStatusHistory(productId int64) ([]domain.ProductStatusChange, error)
+	DeleteById(id int64, actor string) error
+	RestoreById(id int64, actor string) error
+	GetAllDeleted() ([]domain.Product, error)
 	GetById(id int64) (domain.Product, error)
+	GetBySku(sku string, store string) (domain.Product, error)
+	GetByIds(ids []int64) ([]domain.Product, error)
 	GetAll() ([]domain.Product, error)
 	GetAllByStore(store string) ([]domain.Product, error)
+	GetAllByFilter(filter domain.ProductFilter) ([]domain.Product, error)
+	Export(query domain.ProductExportQuery, writer io.Writer) error
+	Search(query domain.ProductSearchQuery) (domain.ProductSearchResult, error)
+	GetStats(query domain.ProductStatsQuery) ([]domain.ProductStatsGroup, error)
+	PreviewRepricing(reprice model.RepriceProducts) (domain.RepricingPreview, error)
+	Reprice(reprice model.RepriceProducts) (domain.Repricing, error)
+	GetRepricingById(id int64) (domain.Repricing, error)
+	UndoRepricing(id int64, actor string) (domain.Repricing, error)
+	GetPriceHistory(productId int64) ([]domain.PriceChange, error)
+	SearchIndex(query search.Query) search.Result
+	RebuildSearchIndex() error
+	RefreshSearchIndex(changes []domain.ProductChange) error
 }
 
 type ProductService struct {
-	productRepository persistence.IProductRepository
+	productRepository             persistence.IProductRepository
+	priceScheduleRepository       persistence.IPriceScheduleRepository
+	attributeDefinitionRepository persistence.IAttributeDefinitionRepository
+	localizationSettings          localization.Settings
+	searchIndex                   search.SearchIndex
 }
 
-func NewProductService(productRepository persistence.IProductRepository) IProductService {
-	return &ProductService{productRepository: productRepository}
+func NewProductService(productRepository persistence.IProductRepository,
+	priceScheduleRepository persistence.IPriceScheduleRepository,
+	attributeDefinitionRepository persistence.IAttributeDefinitionRepository,
+	localizationSettings localization.Settings,
+	searchIndex search.SearchIndex) IProductService {
+	return &ProductService{
+		productRepository:             productRepository,
+		priceScheduleRepository:       priceScheduleRepository,
+		attributeDefinitionRepository: attributeDefinitionRepository,
+		localizationSettings:          localizationSettings,
+		searchIndex:                   searchIndex,
+	}
 }
 
 func (productService *ProductService) Add(product model.CreateProduct) error {
+	productEntity, err := productService.toProductEntity(product)
+	if err != nil {
+		return err
+	}
+	id, err := productService.productRepository.Add(productEntity)
+	if err != nil {
+		return err
+	}
+
+	productService.reindex(id)
+	return nil
+}
+
+// Update replaces the product with the given one, validated like a new product. The store and status of the
+// product are kept, status changes go through ChangeStatus.
+func (productService *ProductService) Update(id int64, product model.CreateProduct) error {
+	existing, err := productService.productRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+	// the translations are checked against the default locale of the store the product stays in
+	product.Store, product.Status = existing.Store, ""
+	productEntity, err := productService.toProductEntity(product)
+	if err != nil {
+		return err
+	}
+	productEntity.Id = id
+	err = productService.productRepository.Update(productEntity)
+	if err != nil {
+		return err
+	}
+
+	productService.reindex(id)
+	return nil
+}
 
+// Validate applies the rules of Add without writing anything.
+func (productService *ProductService) Validate(product model.CreateProduct) error {
+	_, err := productService.toProductEntity(product)
+	return err
+}
+
+// toProductEntity validates a new product and resolves its name, status and translations.
+func (productService *ProductService) toProductEntity(product model.CreateProduct) (domain.Product, error) {
 	validationErr := validateProduct(product)
 	if validationErr != nil {
-		return validationErr
+		return domain.Product{}, validationErr
+	}
+
+	attributesErr := productService.validateAttributes(product)
+	if attributesErr != nil {
+		return domain.Product{}, attributesErr
+	}
+
+	translations, translationsErr := normalizeTranslations(product.Translations)
+	if translationsErr != nil {
+		return domain.Product{}, translationsErr
+	}
+
+	// the product row keeps the text of the store default locale, translations override it per locale
+	name, description := product.Name, product.Description
+	defaultLocale := productService.localizationSettings.DefaultLocaleFor(product.Store)
+	if len(translations) > 0 {
+		defaultText, found := translations[defaultLocale]
+		if !found {
+			return domain.Product{}, errors.New(fmt.Sprintf("Translation for store default locale %s is required", defaultLocale))
+		}
+		// a name or description sent along with the translations can not disagree with the store default locale
+		if (len(name) > 0 && name != defaultText.Name) || (len(description) > 0 && description != defaultText.Description) {
+			return domain.Product{}, errors.New(fmt.Sprintf("Name and description should match the translation for store default locale %s", defaultLocale))
+		}
+		name, description = defaultText.Name, defaultText.Description
+	}
+	if len(name) == 0 {
+		return domain.Product{}, errors.New("Name should not be empty")
+	}
+	// without translations the name and description are the text of the store default locale, so every product
+	// is stored with a translation for it
+	if len(translations) == 0 {
+		translations = map[string]domain.LocalizedText{defaultLocale: {Name: name, Description: description}}
+	}
+
+	status := product.Status
+	if len(status) == 0 {
+		status = domain.ProductStatusActive
+	}
+	if status != domain.ProductStatusDraft && status != domain.ProductStatusActive {
+		return domain.Product{}, errors.New("Status of a new product should be draft or active")
+	}
+	// the sku identifies the product in its store, without one the same product could be added twice
+	if len(strings.TrimSpace(product.Sku)) == 0 {
+		return domain.Product{}, errors.New("Sku is required")
+	}
+
+	return domain.Product{
+		Name:         name,
+		Description:  description,
+		Price:        product.Price,
+		Discount:     product.Discount,
+		Store:        product.Store,
+		Category:     product.Category,
+		Status:       status,
+		Sku:          product.Sku,
+		Gtin:         product.Gtin,
+		Options:      product.Options,
+		Attributes:   product.Attributes,
+		Translations: translations,
+		CreatedBy:    product.Actor,
+		UpdatedBy:    product.Actor,
+	}, nil
+}
+
+func (productService *ProductService) UpdatePrice(id int64, price float32, actor string) error {
+	err := productService.productRepository.UpdateProductPrice(id, price, actor)
+	if err != nil {
+		return err
+	}
+
+	productService.reindex(id)
+	return nil
+}
+
+// UpdateTranslations adds or replaces the given translations, locales that are not given are kept. A product stored
+// without a translation for its store default locale gets its name and description as that translation.
+func (productService *ProductService) UpdateTranslations(id int64, translations map[string]domain.LocalizedText, actor string) error {
+	if len(translations) == 0 {
+		return errors.New("Translations should not be empty")
+	}
+
+	normalizedTranslations, err := normalizeTranslations(translations)
+	if err != nil {
+		return err
+	}
+	product, err := productService.productRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+	defaultLocale := productService.localizationSettings.DefaultLocaleFor(product.Store)
+	_, given := normalizedTranslations[defaultLocale]
+	if _, stored := product.Translations[defaultLocale]; !given && !stored {
+		normalizedTranslations[defaultLocale] = domain.LocalizedText{Name: product.Name, Description: product.Description}
+	}
+	err = productService.productRepository.UpdateTranslations(id, normalizedTranslations, actor)
+	if err != nil {
+		return err
+	}
+
+	productService.reindex(id)
+	return nil
+}
+
+// ChangeStatus moves a product along its lifecycle, only the transitions allowed by the domain are accepted.
+func (productService *ProductService) ChangeStatus(statusChange model.ChangeProductStatus) error {
+	if !domain.IsValidProductStatus(statusChange.Status) {
+		return errors.New(fmt.Sprintf("Status %s is not valid", statusChange.Status))
+	}
+	if len(statusChange.Actor) == 0 {
+		return errors.New("Actor is required to change product status")
+	}
+
+	product, err := productService.productRepository.GetById(statusChange.ProductId)
+	if err != nil {
+		return err
+	}
+	if product.Status == statusChange.Status {
+		return errors.New(fmt.Sprintf("Product is already %s", product.Status))
+	}
+	if !domain.CanTransitionProductStatus(product.Status, statusChange.Status) {
+		return errors.New(fmt.Sprintf("Product can not move from %s to %s", product.Status, statusChange.Status))
 	}
 
-	productEntity := domain.Product{Name: product.Name, Price: product.Price, Discount: product.Discount, Store: product.Store}
-	err := productService.productRepository.Add(productEntity)
+	err = productService.productRepository.UpdateStatus(domain.ProductStatusChange{
+		ProductId:  statusChange.ProductId,
+		FromStatus: product.Status,
+		ToStatus:   statusChange.Status,
+		Actor:      statusChange.Actor,
+		Reason:     statusChange.Reason,
+		ChangedAt:  time.Now(),
+	})
 	if err != nil {
 		return err
 	}
 
+	productService.reindex(statusChange.ProductId)
+	return nil
+}
+
+func (productService *ProductService) GetStatusHistory(productId int64) ([]domain.ProductStatusChange, error) {
+	_, err := productService.productRepository.GetById(productId)
+	if err != nil {
+		return nil, err
+	}
+	return productService.productRepository.GetStatusHistory(productId)
+}
+
+func (productService *ProductService) DeleteById(id int64, actor string) error {
+	err := productService.productRepository.DeleteById(id, actor)
+	if err != nil {
+		return err
+	}
+
+	productService.searchIndex.Remove(id)
+	return nil
+}
+
+func (productService *ProductService) RestoreById(id int64, actor string) error {
+	err := productService.productRepository.RestoreById(id, actor)
+	if err != nil {
+		return err
+	}
+
+	productService.reindex(id)
+	return nil
+}
+
+// SearchIndex runs the search against the in-process index, no database query is made.
+func (productService *ProductService) SearchIndex(query search.Query) search.Result {
+	return productService.searchIndex.Search(query)
+}
+
+// RebuildSearchIndex loads every live product into the search index, it is meant to run on startup.
+// Writes made outside of this service, by the price scheduler or other instances, reach the index through RefreshSearchIndex.
+func (productService *ProductService) RebuildSearchIndex() error {
+	products, err := productService.GetAll()
+	if err != nil {
+		return err
+	}
+
+	productService.searchIndex.Rebuild(products)
+	log.Infof("Search index rebuilt with %d products", len(products))
+	return nil
+}
+
+// RefreshSearchIndex applies the product changes notified by the database, whichever instance made them. Changes of
+// the same product are coalesced and the changed products are read with one query. Changes made by this instance were
+// already indexed, indexing them again is harmless.
+func (productService *ProductService) RefreshSearchIndex(changes []domain.ProductChange) error {
+	operations := make(map[int64]string)
+	for _, change := range changes {
+		if change.Operation == domain.ProductChangeResync {
+			return productService.RebuildSearchIndex()
+		}
+		operations[change.ProductId] = change.Operation
+	}
+
+	changedIds := make([]int64, 0, len(operations))
+	for id, operation := range operations {
+		if operation == domain.ProductChangeDelete {
+			productService.searchIndex.Remove(id)
+			continue
+		}
+		changedIds = append(changedIds, id)
+	}
+	if len(changedIds) == 0 {
+		return nil
+	}
+
+	products, err := productService.GetByIds(changedIds)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		productService.searchIndex.Index(product)
+		delete(operations, product.Id)
+	}
+	// the products that were not found have been soft deleted since
+	for id, operation := range operations {
+		if operation != domain.ProductChangeDelete {
+			productService.searchIndex.Remove(id)
+		}
+	}
 	return nil
 }
 
-func (productService *ProductService) UpdatePrice(id int64, price float32) error {
-	return productService.productRepository.UpdateProductPrice(id, price)
+// reindex refreshes the product in the search index after a write. The write itself has succeeded,
+// so a failing read only leaves the index stale until the next rebuild and is logged, not returned.
+func (productService *ProductService) reindex(id int64) {
+	product, err := productService.GetById(id)
+	if err != nil {
+		log.Errorf("Error while reindexing product with id %d: %v", id, err)
+		return
+	}
+	productService.searchIndex.Index(product)
 }
 
-func (productService *ProductService) DeleteById(id int64) error {
-	return productService.productRepository.DeleteById(id)
+func (productService *ProductService) GetAllDeleted() ([]domain.Product, error) {
+	return productService.productRepository.GetAllDeleted()
 }
 
 func (productService *ProductService) GetById(id int64) (domain.Product, error) {
-	return productService.productRepository.GetById(id)
+	product, err := productService.productRepository.GetById(id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	products, err := productService.applyActivePriceSchedules([]domain.Product{product})
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return products[0], nil
+}
+
+// GetBySku looks a product up by its sku. Skus are only unique per store, so store is
+// required when the sku exists in more than one store.
+func (productService *ProductService) GetBySku(sku string, store string) (domain.Product, error) {
+	products, err := productService.productRepository.GetBySku(sku, store)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	if len(products) > 1 {
+		return domain.Product{}, errors.New(fmt.Sprintf("Sku %s exists in %d stores, store parameter is required", sku, len(products)))
+	}
+
+	products, err = productService.applyActivePriceSchedules(products)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return products[0], nil
+}
+
+// GetByIds returns the products that exist among ids in a single repository query; missing ids are simply absent.
+func (productService *ProductService) GetByIds(ids []int64) ([]domain.Product, error) {
+	products, err := productService.productRepository.GetByIds(ids)
+	if err != nil {
+		return nil, err
+	}
+	return productService.applyActivePriceSchedules(products)
 }
 
 func (productService *ProductService) GetAll() ([]domain.Product, error) {
-	return productService.productRepository.GetAll()
+	products, err := productService.productRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return productService.applyActivePriceSchedules(products)
 }
 
 func (productService *ProductService) GetAllByStore(store string) ([]domain.Product, error) {
-	return productService.productRepository.GetAllByStore(store)
+	products, err := productService.productRepository.GetAllByStore(store)
+	if err != nil {
+		return nil, err
+	}
+	return productService.applyActivePriceSchedules(products)
+}
+
+func (productService *ProductService) GetAllByFilter(filter domain.ProductFilter) ([]domain.Product, error) {
+	filter, err := productService.resolveAttributeTypes(filter)
+	if err != nil {
+		return nil, err
+	}
+	products, err := productService.productRepository.GetAllByFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return productService.applyActivePriceSchedules(products)
+}
+
+// Export writes the products matching the filter to writer while they are read from the database.
+// When writer can be flushed, like an http response, it is flushed after every chunk of products.
+func (productService *ProductService) Export(query domain.ProductExportQuery, writer io.Writer) error {
+	if !domain.IsValidExportFormat(query.Format) {
+		return errors.New(fmt.Sprintf("Export format %s is not supported", query.Format))
+	}
+	fields := query.Fields
+	if len(fields) == 0 {
+		fields = domain.ExportFields
+	}
+	if err := domain.ValidateExportFields(fields); err != nil {
+		return err
+	}
+	filter, err := productService.resolveAttributeTypes(query.Filter)
+	if err != nil {
+		return err
+	}
+
+	exportWriter, err := newProductExportWriter(query.Format, fields, writer)
+	if err != nil {
+		return err
+	}
+	flusher, canFlush := writer.(interface{ Flush() })
+	err = productService.productRepository.StreamByFilter(filter, exportChunkSize, func(products []domain.Product) error {
+		products, err := productService.applyActivePriceSchedules(products)
+		if err != nil {
+			return err
+		}
+		for _, product := range products {
+			if err = exportWriter.WriteProduct(product); err != nil {
+				return err
+			}
+		}
+		if err = exportWriter.Flush(); err != nil {
+			return err
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return exportWriter.Close()
+}
+
+// Search runs a free text search. Without a language the store default locale is used, the limit defaults to 20.
+func (productService *ProductService) Search(query domain.ProductSearchQuery) (domain.ProductSearchResult, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if len(query.Text) == 0 {
+		return domain.ProductSearchResult{}, errors.New("Search text should not be empty")
+	}
+	if len(query.Language) == 0 {
+		query.Language, _, _ = strings.Cut(productService.localizationSettings.DefaultLocaleFor(query.Filter.Store), "-")
+	}
+	if !domain.IsSupportedSearchLanguage(query.Language) {
+		return domain.ProductSearchResult{}, errors.New(fmt.Sprintf("Search language %s is not supported", query.Language))
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit < 0 || query.Limit > maxSearchLimit {
+		return domain.ProductSearchResult{}, errors.New(fmt.Sprintf("Limit should be between 1 and %d", maxSearchLimit))
+	}
+	if query.Offset < 0 {
+		return domain.ProductSearchResult{}, errors.New("Offset should not be negative")
+	}
+	filter, err := productService.resolveAttributeTypes(query.Filter)
+	if err != nil {
+		return domain.ProductSearchResult{}, err
+	}
+	query.Filter = filter
+
+	result, err := productService.productRepository.Search(query)
+	if err != nil {
+		return domain.ProductSearchResult{}, err
+	}
+
+	products := make([]domain.Product, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		products = append(products, hit.Product)
+	}
+	products, err = productService.applyActivePriceSchedules(products)
+	if err != nil {
+		return domain.ProductSearchResult{}, err
+	}
+	for i := range result.Hits {
+		result.Hits[i].Product = products[i]
+	}
+	result.Limit, result.Offset = query.Limit, query.Offset
+	return result, nil
+}
+
+// GetStats aggregates the stored prices, price schedules are not overlaid since the metrics are computed in SQL.
+func (productService *ProductService) GetStats(query domain.ProductStatsQuery) ([]domain.ProductStatsGroup, error) {
+	for i, dimension := range query.GroupBy {
+		if !domain.IsValidStatsDimension(dimension) {
+			return []domain.ProductStatsGroup{}, errors.New(fmt.Sprintf("Stats dimension %s is not valid", dimension))
+		}
+		if slices.Contains(query.GroupBy[:i], dimension) {
+			return []domain.ProductStatsGroup{}, errors.New(fmt.Sprintf("Stats dimension %s is repeated", dimension))
+		}
+	}
+	filter, err := productService.resolveAttributeTypes(query.Filter)
+	if err != nil {
+		return []domain.ProductStatsGroup{}, err
+	}
+	query.Filter = filter
+	return productService.productRepository.GetStats(query)
+}
+
+// resolveAttributeTypes sets the declared type of every attribute condition, so the value is compared as the JSON
+// type the products store. Without a category the definitions of all categories are used and have to agree on it.
+func (productService *ProductService) resolveAttributeTypes(filter domain.ProductFilter) (domain.ProductFilter, error) {
+	if len(filter.AttributeConditions) == 0 {
+		return filter, nil
+	}
+
+	var attributeDefinitions []domain.AttributeDefinition
+	var err error
+	if len(filter.Category) > 0 {
+		attributeDefinitions, err = productService.attributeDefinitionRepository.GetByCategory(filter.Category)
+	} else {
+		attributeDefinitions, err = productService.attributeDefinitionRepository.GetAll()
+	}
+	if err != nil {
+		return domain.ProductFilter{}, err
+	}
+
+	attributeConditions := make([]domain.AttributeCondition, 0, len(filter.AttributeConditions))
+	for _, attributeCondition := range filter.AttributeConditions {
+		attributeCondition.Type = ""
+		for _, attributeDefinition := range attributeDefinitions {
+			if attributeDefinition.Name != attributeCondition.Name {
+				continue
+			}
+			// enum values are stored as strings
+			attributeType := attributeDefinition.Type
+			if attributeType == domain.AttributeTypeEnum {
+				attributeType = domain.AttributeTypeString
+			}
+			if len(attributeCondition.Type) > 0 && attributeCondition.Type != attributeType {
+				return domain.ProductFilter{}, errors.New(fmt.Sprintf("Attribute %s has different types across categories, filter by category", attributeCondition.Name))
+			}
+			attributeCondition.Type = attributeType
+		}
+		if len(attributeCondition.Type) == 0 {
+			return domain.ProductFilter{}, errors.New(fmt.Sprintf("Attribute %s is not defined", attributeCondition.Name))
+		}
+		if _, err = attributeCondition.TypedValue(); err != nil {
+			return domain.ProductFilter{}, err
+		}
+		attributeConditions = append(attributeConditions, attributeCondition)
+	}
+	filter.AttributeConditions = attributeConditions
+	return filter, nil
+}
+
+// applyActivePriceSchedules overlays the price schedules of the products whose window is open right now,
+// so the effective price is always computed at read time regardless of when the scheduler last ran.
+func (productService *ProductService) applyActivePriceSchedules(products []domain.Product) ([]domain.Product, error) {
+	if len(products) == 0 {
+		return products, nil
+	}
+
+	productIds := make([]int64, 0, len(products))
+	for _, product := range products {
+		productIds = append(productIds, product.Id)
+	}
+
+	now := time.Now()
+	activeSchedules, err := productService.priceScheduleRepository.GetActiveByProductIds(productIds, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(activeSchedules) == 0 {
+		return products, nil
+	}
+
+	schedulesByProductId := make(map[int64][]domain.PriceSchedule)
+	for _, priceSchedule := range activeSchedules {
+		schedulesByProductId[priceSchedule.ProductId] = append(schedulesByProductId[priceSchedule.ProductId], priceSchedule)
+	}
+
+	effectiveProducts := make([]domain.Product, 0, len(products))
+	for _, product := range products {
+		effectiveProducts = append(effectiveProducts, domain.ApplyPriceSchedules(product, schedulesByProductId[product.Id], now))
+	}
+	return effectiveProducts, nil
+}
+
+func (productService *ProductService) validateAttributes(product model.CreateProduct) error {
+	if len(product.Category) == 0 && len(product.Attributes) == 0 {
+		return nil
+	}
+
+	attributeDefinitions, err := productService.attributeDefinitionRepository.GetByCategory(product.Category)
+	if err != nil {
+		return err
+	}
+	return validateProductAttributes(product.Attributes, product.Category, attributeDefinitions)
 }
 
 func validateProduct(product model.CreateProduct) error {
-	if product.Discount > 70 || product.Discount < 0 {
+	discountErr := validateDiscount(product.Discount)
+	if discountErr != nil {
+		return discountErr
+	}
+	if len(product.Sku) > 64 {
+		return errors.New("Sku should be at most 64 characters")
+	}
+	if len(product.Gtin) > 0 {
+		gtinErr := domain.ValidateGtin(product.Gtin)
+		if gtinErr != nil {
+			return gtinErr
+		}
+	}
+	return validateProductOptions(product.Options)
+}
+
+// normalizeTranslations keys translations by normalized locale so "tr_TR" and "tr-tr" can not both be stored.
+func normalizeTranslations(translations map[string]domain.LocalizedText) (map[string]domain.LocalizedText, error) {
+	if len(translations) == 0 {
+		return nil, nil
+	}
+
+	normalizedTranslations := make(map[string]domain.LocalizedText, len(translations))
+	for locale, text := range translations {
+		normalizedLocale := localization.Normalize(locale)
+		if len(normalizedLocale) == 0 || len(normalizedLocale) > 35 {
+			return nil, errors.New(fmt.Sprintf("Locale %s is not valid", locale))
+		}
+		if len(text.Name) == 0 {
+			return nil, errors.New(fmt.Sprintf("Translation name for locale %s should not be empty", locale))
+		}
+		if _, duplicate := normalizedTranslations[normalizedLocale]; duplicate {
+			return nil, errors.New(fmt.Sprintf("Locale %s is given more than once", normalizedLocale))
+		}
+		normalizedTranslations[normalizedLocale] = text
+	}
+	return normalizedTranslations, nil
+}
+
+func validateDiscount(discount float32) error {
+	if discount > 70 || discount < 0 {
 		return errors.New("Discount should be between 0 and 70")
 	}
 	return nil
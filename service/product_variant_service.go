@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service/model"
+	"strings"
+)
+
+type IProductVariantService interface {
+	Add(productVariant model.CreateProductVariant) error
+	GetByProductId(productId int64) ([]domain.ProductVariant, error)
+}
+
+type ProductVariantService struct {
+	productVariantRepository persistence.IProductVariantRepository
+	productRepository        persistence.IProductRepository
+}
+
+func NewProductVariantService(productVariantRepository persistence.IProductVariantRepository, productRepository persistence.IProductRepository) IProductVariantService {
+	return &ProductVariantService{productVariantRepository: productVariantRepository, productRepository: productRepository}
+}
+
+func (productVariantService *ProductVariantService) Add(productVariant model.CreateProductVariant) error {
+	parent, err := productVariantService.productRepository.GetById(productVariant.ProductId)
+	if err != nil {
+		return err
+	}
+
+	validationErr := validateProductVariant(productVariant, parent)
+	if validationErr != nil {
+		return validationErr
+	}
+
+	// skus are unique per store across products and variants, the database enforces it for concurrent writes
+	if len(productVariant.Sku) > 0 {
+		products, err := productVariantService.productRepository.GetBySku(productVariant.Sku, parent.Store)
+		var notFoundError persistence.NotFoundError
+		if err != nil && !errors.As(err, &notFoundError) {
+			return err
+		}
+		if len(products) > 0 {
+			return persistence.ConflictError{Message: fmt.Sprintf("Sku %s is used by product %d in store %s", productVariant.Sku, products[0].Id, parent.Store)}
+		}
+	}
+
+	return productVariantService.productVariantRepository.Add(domain.ProductVariant{
+		ProductId:     productVariant.ProductId,
+		Sku:           productVariant.Sku,
+		PriceOverride: productVariant.PriceOverride,
+		Stock:         productVariant.Stock,
+		Attributes:    productVariant.Attributes,
+	})
+}
+
+func (productVariantService *ProductVariantService) GetByProductId(productId int64) ([]domain.ProductVariant, error) {
+	_, err := productVariantService.productRepository.GetById(productId)
+	if err != nil {
+		return nil, err
+	}
+	return productVariantService.productVariantRepository.GetByProductId(productId)
+}
+
+func validateProductVariant(productVariant model.CreateProductVariant, parent domain.Product) error {
+	if len(parent.Options) == 0 {
+		return errors.New(fmt.Sprintf("Product with id %d has no options to create variants for", parent.Id))
+	}
+	if len(productVariant.Attributes) != len(parent.Options) {
+		return errors.New(fmt.Sprintf("Variant attributes should be exactly %s", strings.Join(parent.Options, ", ")))
+	}
+	for _, option := range parent.Options {
+		value, found := productVariant.Attributes[option]
+		if !found {
+			return errors.New(fmt.Sprintf("Variant attributes should be exactly %s", strings.Join(parent.Options, ", ")))
+		}
+		if len(strings.TrimSpace(value)) == 0 {
+			return errors.New(fmt.Sprintf("Variant attribute %s should not be empty", option))
+		}
+	}
+	if productVariant.PriceOverride != nil && *productVariant.PriceOverride <= 0 {
+		return errors.New("Price override should be greater than 0")
+	}
+	if productVariant.Stock < 0 {
+		return errors.New("Stock should not be negative")
+	}
+	if len(productVariant.Sku) > 64 {
+		return errors.New("Sku should be at most 64 characters")
+	}
+	return nil
+}
+
+func validateProductOptions(options []string) error {
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		if len(strings.TrimSpace(option)) == 0 {
+			return errors.New("Option names should not be empty")
+		}
+		if seen[option] {
+			return errors.New(fmt.Sprintf("Option %s is defined more than once", option))
+		}
+		seen[option] = true
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/domain/promotion"
+	"go-product-app/persistence"
+	"go-product-app/service/model"
+	"slices"
+	"time"
+)
+
+type IPromotionService interface {
+	Add(promotion model.CreatePromotion) error
+	GetAll() ([]domain.Promotion, error)
+	DeleteById(id int64) error
+	Evaluate(items []model.BasketItem, caller domain.Caller) (promotion.Evaluation, error)
+}
+
+type PromotionService struct {
+	promotionRepository persistence.IPromotionRepository
+	productService      IProductService
+}
+
+func NewPromotionService(promotionRepository persistence.IPromotionRepository, productService IProductService) IPromotionService {
+	return &PromotionService{promotionRepository: promotionRepository, productService: productService}
+}
+
+func (promotionService *PromotionService) Add(createPromotion model.CreatePromotion) error {
+	validationErr := validatePromotion(createPromotion)
+	if validationErr != nil {
+		return validationErr
+	}
+
+	return promotionService.promotionRepository.Add(domain.Promotion{
+		Name:             createPromotion.Name,
+		Type:             createPromotion.Type,
+		Store:            createPromotion.Store,
+		Category:         createPromotion.Category,
+		MinPrice:         createPromotion.MinPrice,
+		MaxPrice:         createPromotion.MaxPrice,
+		Percent:          createPromotion.Percent,
+		BuyQuantity:      createPromotion.BuyQuantity,
+		FreeQuantity:     createPromotion.FreeQuantity,
+		BundleProductIds: createPromotion.BundleProductIds,
+		Priority:         createPromotion.Priority,
+		Stackable:        createPromotion.Stackable,
+		ValidFrom:        createPromotion.ValidFrom,
+		ValidTo:          createPromotion.ValidTo,
+	})
+}
+
+func (promotionService *PromotionService) GetAll() ([]domain.Promotion, error) {
+	return promotionService.promotionRepository.GetAll()
+}
+
+func (promotionService *PromotionService) DeleteById(id int64) error {
+	return promotionService.promotionRepository.DeleteById(id)
+}
+
+// Evaluate applies the valid promotions to the basket, it fails when the caller can not see one of the products.
+func (promotionService *PromotionService) Evaluate(items []model.BasketItem, caller domain.Caller) (promotion.Evaluation, error) {
+	validationErr := validateBasketItems(items)
+	if validationErr != nil {
+		return promotion.Evaluation{}, validationErr
+	}
+
+	productsById, err := getProductsById(promotionService.productService, items, caller)
+	if err != nil {
+		return promotion.Evaluation{}, err
+	}
+
+	lines := make([]promotion.Line, 0, len(items))
+	for _, item := range items {
+		product, found := productsById[item.ProductId]
+		if !found {
+			return promotion.Evaluation{}, errors.New(fmt.Sprintf("Product with id %d not found", item.ProductId))
+		}
+		lines = append(lines, promotion.Line{Product: product, Quantity: item.Quantity})
+	}
+
+	now := time.Now()
+	validPromotions, err := promotionService.promotionRepository.GetValid(now)
+	if err != nil {
+		return promotion.Evaluation{}, err
+	}
+
+	return promotion.Evaluate(lines, validPromotions, now), nil
+}
+
+// getProductsById leaves out the products the caller can not see, so drafts can not be priced by viewers.
+func getProductsById(productService IProductService, items []model.BasketItem, caller domain.Caller) (map[int64]domain.Product, error) {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductId)
+	}
+
+	products, err := productService.GetByIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	productsById := make(map[int64]domain.Product, len(products))
+	for _, product := range products {
+		if caller.CanSeeStatus(product.Status) {
+			productsById[product.Id] = product
+		}
+	}
+	return productsById, nil
+}
+
+func validatePromotion(createPromotion model.CreatePromotion) error {
+	if len(createPromotion.Name) == 0 {
+		return errors.New("Promotion name is required")
+	}
+	switch createPromotion.Type {
+	case domain.PromotionTypePercentOff:
+		if createPromotion.Percent <= 0 || createPromotion.Percent > 100 {
+			return errors.New("Percent should be between 0 and 100")
+		}
+	case domain.PromotionTypeBuyXGetY:
+		if createPromotion.BuyQuantity < 1 || createPromotion.FreeQuantity < 1 {
+			return errors.New("Buy and free quantities should be at least 1")
+		}
+	case domain.PromotionTypeBundle:
+		if createPromotion.Percent <= 0 || createPromotion.Percent > 100 {
+			return errors.New("Percent should be between 0 and 100")
+		}
+		if len(createPromotion.BundleProductIds) < 2 {
+			return errors.New("Bundle should have at least 2 products")
+		}
+		for i, productId := range createPromotion.BundleProductIds {
+			if slices.Contains(createPromotion.BundleProductIds[:i], productId) {
+				return errors.New(fmt.Sprintf("Product %d is given more than once in the bundle", productId))
+			}
+		}
+	default:
+		return errors.New(fmt.Sprintf("Promotion type should be one of %s, %s, %s", domain.PromotionTypePercentOff, domain.PromotionTypeBuyXGetY, domain.PromotionTypeBundle))
+	}
+	if createPromotion.MinPrice != nil && createPromotion.MaxPrice != nil && *createPromotion.MinPrice > *createPromotion.MaxPrice {
+		return errors.New("Min price should not be greater than max price")
+	}
+	if createPromotion.ValidFrom.IsZero() {
+		return errors.New("Valid from is required")
+	}
+	if createPromotion.ValidTo != nil && !createPromotion.ValidTo.After(createPromotion.ValidFrom) {
+		return errors.New("Valid to should be after valid from")
+	}
+	return nil
+}
+
+func validateBasketItems(items []model.BasketItem) error {
+	if len(items) == 0 {
+		return errors.New("At least one item is required")
+	}
+	for _, item := range items {
+		if item.Quantity < 1 {
+			return errors.New(fmt.Sprintf("Quantity of product %d should be at least 1", item.ProductId))
+		}
+	}
+	return nil
+}
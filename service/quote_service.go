@@ -0,0 +1,82 @@
+package service
+
+import (
+	"go-product-app/domain"
+	"go-product-app/domain/pricing"
+	"go-product-app/domain/promotion"
+	"go-product-app/persistence"
+	"go-product-app/service/model"
+	"time"
+)
+
+type IQuoteService interface {
+	Quote(items []model.BasketItem, caller domain.Caller) (model.Quote, error)
+}
+
+type QuoteService struct {
+	productService      IProductService
+	promotionRepository persistence.IPromotionRepository
+}
+
+func NewQuoteService(productService IProductService, promotionRepository persistence.IPromotionRepository) IQuoteService {
+	return &QuoteService{productService: productService, promotionRepository: promotionRepository}
+}
+
+// Quote prices the basket, the products the caller can not see are reported as not found.
+func (quoteService *QuoteService) Quote(items []model.BasketItem, caller domain.Caller) (model.Quote, error) {
+	validationErr := validateBasketItems(items)
+	if validationErr != nil {
+		return model.Quote{}, validationErr
+	}
+
+	productsById, err := getProductsById(quoteService.productService, items, caller)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	now := time.Now()
+	validPromotions, err := quoteService.promotionRepository.GetValid(now)
+	if err != nil {
+		return model.Quote{}, err
+	}
+
+	lines := make([]promotion.Line, 0, len(items))
+	for _, item := range items {
+		if product, found := productsById[item.ProductId]; found {
+			lines = append(lines, promotion.Line{Product: product, Quantity: item.Quantity})
+		}
+	}
+	evaluation := promotion.Evaluate(lines, validPromotions, now)
+
+	quote := model.Quote{Lines: make([]model.QuoteLine, 0, len(items))}
+	evaluatedLines := evaluation.Lines
+	for _, item := range items {
+		if _, found := productsById[item.ProductId]; !found {
+			quote.Lines = append(quote.Lines, model.QuoteLine{ProductId: item.ProductId, Quantity: item.Quantity, Status: model.QuoteLineStatusNotFound})
+			continue
+		}
+
+		lineEvaluation := evaluatedLines[0]
+		evaluatedLines = evaluatedLines[1:]
+
+		quantity := float64(item.Quantity)
+		quoteLine := model.QuoteLine{
+			ProductId:       item.ProductId,
+			Quantity:        item.Quantity,
+			Status:          model.QuoteLineStatusPriced,
+			ListTotal:       pricing.Round(lineEvaluation.UnitPrice.ListPrice * quantity),
+			ProductDiscount: pricing.Round(lineEvaluation.UnitPrice.DiscountAmount * quantity),
+			Evaluation:      lineEvaluation,
+		}
+		quote.Lines = append(quote.Lines, quoteLine)
+		quote.Subtotal += quoteLine.ListTotal
+		quote.ProductDiscount += quoteLine.ProductDiscount
+		quote.PromotionDiscount += lineEvaluation.PromotionDiscount
+	}
+
+	quote.Subtotal = pricing.Round(quote.Subtotal)
+	quote.ProductDiscount = pricing.Round(quote.ProductDiscount)
+	quote.PromotionDiscount = pricing.Round(quote.PromotionDiscount)
+	quote.GrandTotal = pricing.Round(quote.Subtotal - quote.ProductDiscount - quote.PromotionDiscount)
+	return quote, nil
+}
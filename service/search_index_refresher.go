@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"sync"
+	"time"
+)
+
+// searchIndexRefresherRetryDelay is how long the refresher waits before applying the changes again after an error.
+const searchIndexRefresherRetryDelay = time.Second
+
+// SearchIndexRefresher applies the product changes notified by the product change listener to the search index of
+// this instance. The changes are queued and applied in batches, so a burst of writes costs one query per batch
+// instead of one per change and the listener never waits for the database.
+type SearchIndexRefresher struct {
+	productService IProductService
+	mutex          sync.Mutex
+	pending        []domain.ProductChange
+	wake           chan struct{}
+}
+
+func NewSearchIndexRefresher(productService IProductService) *SearchIndexRefresher {
+	return &SearchIndexRefresher{
+		productService: productService,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// HandleChange queues a product change, it is the ProductChangeHandler of the listener and does not block.
+func (searchIndexRefresher *SearchIndexRefresher) HandleChange(change domain.ProductChange) {
+	searchIndexRefresher.mutex.Lock()
+	searchIndexRefresher.pending = append(searchIndexRefresher.pending, change)
+	searchIndexRefresher.mutex.Unlock()
+	searchIndexRefresher.signal()
+}
+
+// Start applies the queued changes in the background. It should be called after the search index was rebuilt, the
+// changes queued meanwhile are applied then and can not be overwritten by an older read of the rebuild.
+func (searchIndexRefresher *SearchIndexRefresher) Start(ctx context.Context) {
+	searchIndexRefresher.signal()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-searchIndexRefresher.wake:
+			}
+			if err := searchIndexRefresher.RunOnce(); err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(searchIndexRefresherRetryDelay):
+				}
+				searchIndexRefresher.signal()
+			}
+		}
+	}()
+}
+
+// RunOnce applies the queued changes, they are queued again when they can not be applied.
+func (searchIndexRefresher *SearchIndexRefresher) RunOnce() error {
+	searchIndexRefresher.mutex.Lock()
+	pending := searchIndexRefresher.pending
+	searchIndexRefresher.pending = nil
+	searchIndexRefresher.mutex.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := searchIndexRefresher.productService.RefreshSearchIndex(pending); err != nil {
+		log.Errorf("Error while refreshing the search index with %d product changes: %v", len(pending), err)
+		searchIndexRefresher.mutex.Lock()
+		searchIndexRefresher.pending = append(pending, searchIndexRefresher.pending...)
+		searchIndexRefresher.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (searchIndexRefresher *SearchIndexRefresher) signal() {
+	select {
+	case searchIndexRefresher.wake <- struct{}{}:
+	default:
+	}
+}
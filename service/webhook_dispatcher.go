@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/labstack/gommon/log"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxWebhookResponseBody is how much of a response is read so the connection can be reused. The body is never
+// kept, the delivery log is visible to users and would otherwise show whatever the receiver answered.
+const maxWebhookResponseBody = 4096
+
+// WebhookDispatcher sends the queued webhook deliveries. Each request is signed with the secret of its webhook in
+// X-Webhook-Signature, failed deliveries are retried with exponential backoff until maxAttempts and a webhook
+// failing disableAfterFailures times in a row is deactivated.
+type WebhookDispatcher struct {
+	webhookRepository    persistence.IWebhookRepository
+	client               *http.Client
+	interval             time.Duration
+	batchSize            int
+	lease                time.Duration
+	maxAttempts          int
+	disableAfterFailures int
+}
+
+func NewWebhookDispatcher(webhookRepository persistence.IWebhookRepository, client *http.Client, interval time.Duration, batchSize int,
+	lease time.Duration, maxAttempts int, disableAfterFailures int) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookRepository:    webhookRepository,
+		client:               client,
+		interval:             interval,
+		batchSize:            batchSize,
+		lease:                lease,
+		maxAttempts:          maxAttempts,
+		disableAfterFailures: disableAfterFailures,
+	}
+}
+
+func (webhookDispatcher *WebhookDispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(webhookDispatcher.interval)
+		defer ticker.Stop()
+
+		for {
+			webhookDispatcher.RunOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce sends the due deliveries batch by batch, the deliveries of a batch in parallel,
+// and returns how many were delivered.
+func (webhookDispatcher *WebhookDispatcher) RunOnce(ctx context.Context) int {
+	delivered := 0
+	for ctx.Err() == nil {
+		deliveries, err := webhookDispatcher.webhookRepository.ClaimDueDeliveries(webhookDispatcher.batchSize, webhookDispatcher.lease)
+		if err != nil {
+			log.Errorf("Error while claiming webhook deliveries: %v", err)
+			break
+		}
+
+		var waitGroup sync.WaitGroup
+		results := make([]domain.WebhookDelivery, len(deliveries))
+		for i := range deliveries {
+			waitGroup.Add(1)
+			go func(i int) {
+				defer waitGroup.Done()
+				results[i] = webhookDispatcher.send(ctx, deliveries[i])
+			}(i)
+		}
+		waitGroup.Wait()
+
+		for _, result := range results {
+			// a delivery cut off by shutdown is sent again once its lease expires
+			if ctx.Err() != nil {
+				break
+			}
+			if err = webhookDispatcher.webhookRepository.RecordAttempt(result, webhookDispatcher.disableAfterFailures); err != nil {
+				log.Errorf("Error while recording webhook delivery %d: %v", result.Id, err)
+				continue
+			}
+			if result.Status == domain.WebhookDeliverySucceeded {
+				delivered++
+			}
+		}
+
+		if len(deliveries) < webhookDispatcher.batchSize {
+			break
+		}
+	}
+	return delivered
+}
+
+// send posts the delivery and returns it with the outcome of the attempt set.
+func (webhookDispatcher *WebhookDispatcher) send(ctx context.Context, delivery domain.WebhookDelivery) domain.WebhookDelivery {
+	statusCode, err := webhookDispatcher.post(ctx, delivery)
+
+	attempts := delivery.Attempts + 1
+	delivery.LastStatusCode = statusCode
+	delivery.NextAttempt = time.Now()
+	switch {
+	case err == nil:
+		delivery.Status, delivery.LastError = domain.WebhookDeliverySucceeded, ""
+	case attempts >= webhookDispatcher.maxAttempts:
+		delivery.Status, delivery.LastError = domain.WebhookDeliveryFailed, err.Error()
+		log.Errorf("Webhook delivery %d of event %s failed after %d attempts: %v", delivery.Id, delivery.EventId, attempts, err)
+	default:
+		delivery.Status, delivery.LastError = domain.WebhookDeliveryPending, err.Error()
+		delivery.NextAttempt = delivery.NextAttempt.Add(domain.WebhookRetryDelay(attempts))
+	}
+	return delivery
+}
+
+func (webhookDispatcher *WebhookDispatcher) post(ctx context.Context, delivery domain.WebhookDelivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, strings.NewReader(delivery.Body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Webhook-Id", strconv.FormatInt(delivery.WebhookId, 10))
+	request.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.Id, 10))
+	request.Header.Set("X-Webhook-Event-Id", delivery.EventId)
+	request.Header.Set("X-Webhook-Event", delivery.EventType)
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Webhook-Signature", "sha256="+domain.SignWebhookBody(delivery.Secret, timestamp, []byte(delivery.Body)))
+
+	response, err := webhookDispatcher.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, maxWebhookResponseBody))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, errors.New(fmt.Sprintf("Receiver responded with status %d", response.StatusCode))
+	}
+	return response.StatusCode, nil
+}
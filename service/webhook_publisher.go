@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-product-app/common/events"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"time"
+)
+
+// webhookEventBody is what receivers get, data is the payload of the event as written to the outbox.
+type webhookEventBody struct {
+	Id         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// WebhookPublisher queues a delivery of every relayed event for the webhooks subscribed to it,
+// the WebhookDispatcher sends them. Queueing is idempotent, so an event relayed again is delivered once.
+type WebhookPublisher struct {
+	webhookRepository persistence.IWebhookRepository
+}
+
+func NewWebhookPublisher(webhookRepository persistence.IWebhookRepository) events.Publisher {
+	return &WebhookPublisher{webhookRepository: webhookRepository}
+}
+
+func (webhookPublisher *WebhookPublisher) Publish(ctx context.Context, event domain.ProductEvent) error {
+	body, err := json.Marshal(webhookEventBody{Id: event.EventId, Type: event.Type, OccurredAt: event.OccurredAt, Data: event.Payload})
+	if err != nil {
+		return errors.New(fmt.Sprintf("Webhook body of event %s can not be encoded: %v", event.EventId, err))
+	}
+
+	_, err = webhookPublisher.webhookRepository.EnqueueDeliveries(event, event.Store, string(body))
+	return err
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service/model"
+	"net/url"
+	"slices"
+)
+
+const (
+	minWebhookSecretLength   = 16
+	defaultWebhookDeliveries = 50
+	maxWebhookDeliveries     = 200
+)
+
+type IWebhookService interface {
+	Add(webhook model.CreateWebhook) (domain.Webhook, error)
+	GetAll() ([]domain.Webhook, error)
+	GetById(id int64) (domain.Webhook, error)
+	Update(webhook model.UpdateWebhook) (domain.Webhook, error)
+	DeleteById(id int64) error
+	GetDeliveries(webhookId int64, status string, limit int) ([]domain.WebhookDelivery, error)
+	Redeliver(webhookId int64, deliveryId int64) (domain.WebhookDelivery, error)
+}
+
+type WebhookService struct {
+	webhookRepository persistence.IWebhookRepository
+}
+
+func NewWebhookService(webhookRepository persistence.IWebhookRepository) IWebhookService {
+	return &WebhookService{webhookRepository: webhookRepository}
+}
+
+// Add subscribes a receiver, a secret is generated when none is given. The secret is only returned by Add.
+func (webhookService *WebhookService) Add(webhook model.CreateWebhook) (domain.Webhook, error) {
+	validationErr := validateWebhook(webhook.Url, webhook.EventTypes, webhook.Secret)
+	if validationErr != nil {
+		return domain.Webhook{}, validationErr
+	}
+
+	secret := webhook.Secret
+	if len(secret) == 0 {
+		generatedSecret, err := generateWebhookSecret()
+		if err != nil {
+			return domain.Webhook{}, err
+		}
+		secret = generatedSecret
+	}
+
+	return webhookService.webhookRepository.Add(domain.Webhook{
+		Url:        webhook.Url,
+		EventTypes: webhook.EventTypes,
+		Store:      webhook.Store,
+		Secret:     secret,
+		CreatedBy:  webhook.Actor,
+	})
+}
+
+func (webhookService *WebhookService) GetAll() ([]domain.Webhook, error) {
+	return webhookService.webhookRepository.GetAll()
+}
+
+func (webhookService *WebhookService) GetById(id int64) (domain.Webhook, error) {
+	return webhookService.webhookRepository.GetById(id)
+}
+
+// Update keeps the secret when none is given. Activating a webhook that was deactivated
+// after failing sends its waiting deliveries again.
+func (webhookService *WebhookService) Update(webhook model.UpdateWebhook) (domain.Webhook, error) {
+	validationErr := validateWebhook(webhook.Url, webhook.EventTypes, webhook.Secret)
+	if validationErr != nil {
+		return domain.Webhook{}, validationErr
+	}
+
+	return webhookService.webhookRepository.Update(domain.Webhook{
+		Id:         webhook.Id,
+		Url:        webhook.Url,
+		EventTypes: webhook.EventTypes,
+		Store:      webhook.Store,
+		Secret:     webhook.Secret,
+		Active:     webhook.Active,
+	})
+}
+
+func (webhookService *WebhookService) DeleteById(id int64) error {
+	return webhookService.webhookRepository.DeleteById(id)
+}
+
+// GetDeliveries returns the latest deliveries of the webhook first, optionally only those with the given status.
+func (webhookService *WebhookService) GetDeliveries(webhookId int64, status string, limit int) ([]domain.WebhookDelivery, error) {
+	switch status {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliverySucceeded, domain.WebhookDeliveryFailed:
+	default:
+		return nil, errors.New(fmt.Sprintf("Delivery status should be one of %s, %s, %s",
+			domain.WebhookDeliveryPending, domain.WebhookDeliverySucceeded, domain.WebhookDeliveryFailed))
+	}
+	if limit <= 0 || limit > maxWebhookDeliveries {
+		limit = defaultWebhookDeliveries
+	}
+
+	_, err := webhookService.webhookRepository.GetById(webhookId)
+	if err != nil {
+		return nil, err
+	}
+	return webhookService.webhookRepository.GetDeliveries(webhookId, status, limit)
+}
+
+// Redeliver sends a delivery again with a fresh set of attempts, also one that already succeeded.
+func (webhookService *WebhookService) Redeliver(webhookId int64, deliveryId int64) (domain.WebhookDelivery, error) {
+	return webhookService.webhookRepository.Redeliver(webhookId, deliveryId)
+}
+
+func validateWebhook(webhookUrl string, eventTypes []string, secret string) error {
+	parsedUrl, err := url.Parse(webhookUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || len(parsedUrl.Host) == 0 {
+		return errors.New(fmt.Sprintf("Webhook url %s should be an absolute http or https url", webhookUrl))
+	}
+	if len(eventTypes) == 0 {
+		return errors.New("Webhook should subscribe to at least one event type")
+	}
+	for i, eventType := range eventTypes {
+		if !domain.IsValidProductEventType(eventType) {
+			return errors.New(fmt.Sprintf("Event type %s is not valid", eventType))
+		}
+		if slices.Contains(eventTypes[:i], eventType) {
+			return errors.New(fmt.Sprintf("Event type %s is given more than once", eventType))
+		}
+	}
+	if len(secret) > 0 && len(secret) < minWebhookSecretLength {
+		return errors.New(fmt.Sprintf("Webhook secret should be at least %d characters", minWebhookSecretLength))
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.New(fmt.Sprintf("Webhook secret can not be generated: %v", err))
+	}
+	return hex.EncodeToString(secret), nil
+}
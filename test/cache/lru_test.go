@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/cache"
+	"testing"
+	"time"
+)
+
+func Test_LRU_ShouldEvictLeastRecentlyUsed(t *testing.T) {
+	lru := cache.NewLRU[int, string](2, time.Minute)
+	lru.Set(1, "air")
+	lru.Set(2, "iron")
+	lru.Get(1)
+	lru.Set(3, "fax")
+
+	_, found := lru.Get(2)
+	assert.False(t, found)
+	value, found := lru.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, "air", value)
+	assert.Equal(t, cache.Stats{Hits: 2, Misses: 1, Evictions: 1, Entries: 2}, lru.Stats())
+}
+
+func Test_LRU_ShouldExpireEntries(t *testing.T) {
+	lru := cache.NewLRU[int, string](2, 20*time.Millisecond)
+	lru.Set(1, "air")
+	time.Sleep(30 * time.Millisecond)
+
+	_, found := lru.Get(1)
+	assert.False(t, found)
+	assert.Equal(t, 0, lru.Stats().Entries)
+
+	t.Run("SetStartsANewTtl", func(t *testing.T) {
+		lru.Set(1, "air")
+		time.Sleep(15 * time.Millisecond)
+		lru.Set(1, "iron")
+		time.Sleep(15 * time.Millisecond)
+		value, found := lru.Get(1)
+		assert.True(t, found)
+		assert.Equal(t, "iron", value)
+	})
+}
+
+func Test_LRU_ShouldNotKeepValuesLoadedBeforeARemoval(t *testing.T) {
+	lru := cache.NewLRU[int, string](2, time.Minute)
+	generation := lru.Generation()
+	lru.Remove(1)
+
+	assert.False(t, lru.SetIfGeneration(1, "stale", generation))
+	_, found := lru.Get(1)
+	assert.False(t, found)
+
+	generation = lru.Generation()
+	assert.True(t, lru.SetIfGeneration(1, "air", generation))
+	lru.Clear()
+	assert.False(t, lru.SetIfGeneration(2, "stale", generation))
+	assert.Equal(t, 0, lru.Stats().Entries)
+}
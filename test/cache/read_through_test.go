@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/cache"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_ReadThrough_ShouldLoadMissesOnce(t *testing.T) {
+	readThrough := cache.NewReadThrough[int, string](10, time.Minute)
+	var loads atomic.Int64
+	release := make(chan struct{})
+	load := func() (string, error) {
+		loads.Add(1)
+		<-release
+		return "air", nil
+	}
+
+	var waitGroup sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			value, err := readThrough.Get(1, load)
+			assert.Nil(t, err)
+			assert.Equal(t, "air", value)
+		}()
+	}
+	// let every caller miss before the load returns
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	waitGroup.Wait()
+
+	assert.Equal(t, int64(1), loads.Load())
+	value, _ := readThrough.Get(1, load)
+	assert.Equal(t, "air", value)
+	assert.Equal(t, cache.Stats{Hits: 1, Misses: 10, SharedLoads: 9, Entries: 1}, readThrough.Stats())
+}
+
+func Test_ReadThrough_ShouldNotCacheErrors(t *testing.T) {
+	readThrough := cache.NewReadThrough[int, string](10, time.Minute)
+
+	_, err := readThrough.Get(1, func() (string, error) { return "", errors.New("Product with id 1 not found") })
+	assert.Equal(t, "Product with id 1 not found", err.Error())
+
+	value, err := readThrough.Get(1, func() (string, error) { return "air", nil })
+	assert.Nil(t, err)
+	assert.Equal(t, "air", value)
+}
+
+func Test_ReadThrough_ShouldNotKeepValuesLoadedDuringAnInvalidation(t *testing.T) {
+	readThrough := cache.NewReadThrough[int, string](10, time.Minute)
+	loading, release := make(chan struct{}), make(chan struct{})
+	done := make(chan string)
+	go func() {
+		value, _ := readThrough.Get(1, func() (string, error) {
+			close(loading)
+			<-release
+			return "old price", nil
+		})
+		done <- value
+	}()
+	<-loading
+
+	readThrough.Invalidate(1)
+	t.Run("LoadsAgainInsteadOfWaiting", func(t *testing.T) {
+		value, _ := readThrough.Get(1, func() (string, error) { return "new price", nil })
+		assert.Equal(t, "new price", value)
+	})
+
+	close(release)
+	assert.Equal(t, "old price", <-done)
+	t.Run("KeepsTheValueLoadedAfter", func(t *testing.T) {
+		value, _ := readThrough.Get(1, func() (string, error) { return "unexpected load", nil })
+		assert.Equal(t, "new price", value)
+	})
+}
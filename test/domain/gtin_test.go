@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func Test_ValidateGtin(t *testing.T) {
+	testCases := []struct {
+		name          string
+		gtin          string
+		expectedError string
+	}{
+		{"valid ean-13", "4006381333931", ""},
+		{"valid ean-8", "96385074", ""},
+		{"valid upc-a", "036000291452", ""},
+		{"valid gtin-14", "10012345678902", ""},
+		{"wrong check digit", "4006381333932", "Gtin check digit should be 1"},
+		{"wrong length", "12345", "Gtin should be 8, 12, 13 or 14 digits long"},
+		{"not numeric", "40063813339A1", "Gtin should contain only digits"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := domain.ValidateGtin(testCase.gtin)
+			if len(testCase.expectedError) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
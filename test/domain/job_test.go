@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+	"time"
+)
+
+func Test_JobRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{4, 80 * time.Second},
+		{7, 10 * time.Minute},
+		{30, 10 * time.Minute},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expected.String(), func(t *testing.T) {
+			assert.Equal(t, testCase.expected, domain.JobRetryDelay(testCase.attempts))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func Test_Localize(t *testing.T) {
+	product := domain.Product{
+		Name:        "ütü",
+		Description: "buharlı",
+		Store:       "ABC TECH",
+		Translations: map[string]domain.LocalizedText{
+			"tr": {Name: "ütü", Description: "buharlı"},
+			"en": {Name: "iron", Description: "steam"},
+			"de": {Name: "bügeleisen"},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		preferred      []string
+		fallbacks      []string
+		expectedName   string
+		expectedLocale string
+	}{
+		{"exact locale", []string{"en"}, []string{"tr"}, "iron", "en"},
+		{"regional locale falls back to language", []string{"de-at"}, []string{"tr"}, "bügeleisen", "de"},
+		{"first available preferred locale", []string{"fr", "en"}, []string{"tr"}, "iron", "en"},
+		{"store default locale", []string{"fr"}, []string{"tr", "en"}, "ütü", "tr"},
+		{"no translation keeps product text", []string{"fr"}, []string{"it"}, "ütü", ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			localizedProduct, locale := product.Localize(testCase.preferred, testCase.fallbacks...)
+			assert.Equal(t, testCase.expectedName, localizedProduct.Name)
+			assert.Equal(t, testCase.expectedLocale, locale)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+	"time"
+)
+
+func Test_ApplyPriceSchedules_ShouldLayTimeBoxedSchedulesOverPermanentOnes(t *testing.T) {
+	now := time.Now()
+	price := func(value float32) *float32 { return &value }
+	until := now.Add(time.Hour)
+	product := domain.Product{Id: 1, Price: 3000, Discount: 22}
+
+	testCases := []struct {
+		name             string
+		priceSchedules   []domain.PriceSchedule
+		expectedPrice    float32
+		expectedDiscount float32
+	}{
+		{"permanent started after the time-boxed one", []domain.PriceSchedule{
+			{Id: 1, ProductId: 1, Discount: price(50), EffectiveFrom: now.Add(-2 * time.Hour), EffectiveTo: &until, Status: domain.PriceScheduleStatusPending},
+			{Id: 2, ProductId: 1, Discount: price(10), Price: price(2000), EffectiveFrom: now.Add(-time.Hour), Status: domain.PriceScheduleStatusPending},
+		}, 2000, 50},
+		{"latest permanent wins field by field", []domain.PriceSchedule{
+			{Id: 4, ProductId: 1, Price: price(2500), EffectiveFrom: now.Add(-time.Hour), Status: domain.PriceScheduleStatusPending},
+			{Id: 3, ProductId: 1, Price: price(2000), Discount: price(5), EffectiveFrom: now.Add(-2 * time.Hour), Status: domain.PriceScheduleStatusPending},
+		}, 2500, 5},
+		{"ties are broken by id", []domain.PriceSchedule{
+			{Id: 6, ProductId: 1, Price: price(2600), EffectiveFrom: now.Add(-time.Hour), Status: domain.PriceScheduleStatusPending},
+			{Id: 5, ProductId: 1, Price: price(2400), EffectiveFrom: now.Add(-time.Hour), Status: domain.PriceScheduleStatusPending},
+		}, 2600, 22},
+		{"inactive schedules are skipped", []domain.PriceSchedule{
+			{Id: 7, ProductId: 1, Price: price(1000), EffectiveFrom: now.Add(time.Hour), Status: domain.PriceScheduleStatusPending},
+			{Id: 8, ProductId: 1, Price: price(1000), EffectiveFrom: now.Add(-time.Hour), Status: domain.PriceScheduleStatusCancelled},
+			{Id: 9, ProductId: 2, Price: price(1000), EffectiveFrom: now.Add(-time.Hour), Status: domain.PriceScheduleStatusPending},
+		}, 3000, 22},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := domain.ApplyPriceSchedules(product, testCase.priceSchedules, now)
+			assert.Equal(t, testCase.expectedPrice, actual.Price)
+			assert.Equal(t, testCase.expectedDiscount, actual.Discount)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/domain/pricing"
+	"testing"
+)
+
+type fixedTaxAdjustment struct {
+	rate float64
+}
+
+func (adjustment fixedTaxAdjustment) Apply(breakdown pricing.Breakdown) pricing.Breakdown {
+	breakdown.FinalPrice = pricing.Round(breakdown.FinalPrice * (1 + adjustment.rate))
+	return breakdown
+}
+
+func Test_Calculate_ShouldRoundToCents(t *testing.T) {
+	testCases := []struct {
+		name            string
+		listPrice       float32
+		discountPercent float32
+		expected        pricing.Breakdown
+	}{
+		{"no discount", 2000.0, 0, pricing.Breakdown{ListPrice: 2000, DiscountPercent: 0, DiscountAmount: 0, FinalPrice: 2000}},
+		{"whole discount", 3000.0, 22.0, pricing.Breakdown{ListPrice: 3000, DiscountPercent: 22, DiscountAmount: 660, FinalPrice: 2340}},
+		{"rounds half up", 19.99, 15.0, pricing.Breakdown{ListPrice: 19.99, DiscountPercent: 15, DiscountAmount: 3, FinalPrice: 16.99}},
+		{"fractional discount", 9.99, 12.5, pricing.Breakdown{ListPrice: 9.99, DiscountPercent: 12.5, DiscountAmount: 1.25, FinalPrice: 8.74}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := pricing.DefaultCalculator.Calculate(testCase.listPrice, testCase.discountPercent)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func Test_Calculate_ShouldApplyAdjustmentsAfterDiscount(t *testing.T) {
+	t.Run("Calculate", func(t *testing.T) {
+		calculator := pricing.NewCalculator(fixedTaxAdjustment{rate: 0.2})
+		actual := calculator.Calculate(100.0, 10.0)
+		assert.Equal(t, 90.0, actual.ListPrice-actual.DiscountAmount)
+		assert.Equal(t, 108.0, actual.FinalPrice)
+	})
+}
+
+func Test_PriceBreakdown_ShouldUseProductPriceAndDiscount(t *testing.T) {
+	t.Run("PriceBreakdown", func(t *testing.T) {
+		product := domain.Product{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH"}
+		assert.Equal(t, 2340.0, product.PriceBreakdown().FinalPrice)
+	})
+}
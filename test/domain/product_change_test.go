@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func Test_ParseProductChange(t *testing.T) {
+	t.Run("ReadsTheNotification", func(t *testing.T) {
+		change, err := domain.ParseProductChange(`{"op" : "update", "id" : 7, "store" : "ABC TECH"}`)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.ProductChange{Operation: domain.ProductChangeUpdate, ProductId: 7, Store: "ABC TECH"}, change)
+	})
+
+	testCases := []struct {
+		name     string
+		payload  string
+		expected string
+	}{
+		{"NotJson", `7`, "Product change 7 can not be read: json: cannot unmarshal number into Go value of type domain.ProductChange"},
+		{"UnknownOperation", `{"op":"truncate","id":7}`, "Product change operation truncate is not valid"},
+		{"ResyncIsNotNotified", `{"op":"resync"}`, "Product change operation resync is not valid"},
+		{"NoProduct", `{"op":"insert"}`, `Product change {"op":"insert"} has no product id`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := domain.ParseProductChange(testCase.payload)
+			assert.Equal(t, testCase.expected, err.Error())
+		})
+	}
+}
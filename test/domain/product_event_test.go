@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+	"time"
+)
+
+func Test_OutboxRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{5, 16 * time.Second},
+		{9, 256 * time.Second},
+		{10, 5 * time.Minute},
+		{40, 5 * time.Minute},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expected.String(), func(t *testing.T) {
+			assert.Equal(t, testCase.expected, domain.OutboxRetryDelay(testCase.attempts))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func Test_DiscountBandOf(t *testing.T) {
+	testCases := []struct {
+		discount float32
+		expected string
+	}{
+		{0, "none"},
+		{5, "0-10"},
+		{10, "10-20"},
+		{29.5, "20-30"},
+		{50, "50+"},
+		{70, "50+"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expected, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, domain.DiscountBandOf(testCase.discount))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func Test_CanTransitionProductStatus(t *testing.T) {
+	testCases := []struct {
+		from     string
+		to       string
+		expected bool
+	}{
+		{domain.ProductStatusDraft, domain.ProductStatusActive, true},
+		{domain.ProductStatusDraft, domain.ProductStatusDiscontinued, false},
+		{domain.ProductStatusActive, domain.ProductStatusDiscontinued, true},
+		{domain.ProductStatusActive, domain.ProductStatusDraft, false},
+		{domain.ProductStatusDiscontinued, domain.ProductStatusActive, true},
+		{domain.ProductStatusDiscontinued, domain.ProductStatusArchived, true},
+		{domain.ProductStatusArchived, domain.ProductStatusActive, false},
+		{"unknown", domain.ProductStatusActive, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.from+" to "+testCase.to, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, domain.CanTransitionProductStatus(testCase.from, testCase.to))
+		})
+	}
+}
+
+func Test_CanSeeStatus(t *testing.T) {
+	t.Run("CanSeeStatus", func(t *testing.T) {
+		assert.False(t, domain.Caller{Role: domain.CallerRoleViewer}.CanSeeStatus(domain.ProductStatusDraft))
+		assert.True(t, domain.Caller{Role: domain.CallerRoleViewer}.CanSeeStatus(domain.ProductStatusDiscontinued))
+		assert.True(t, domain.Caller{Role: domain.CallerRoleEditor}.CanSeeStatus(domain.ProductStatusDraft))
+	})
+}
+
+func Test_CanEdit(t *testing.T) {
+	t.Run("CanEdit", func(t *testing.T) {
+		assert.True(t, domain.Caller{Role: domain.CallerRoleAdmin}.CanEdit())
+		assert.True(t, domain.Caller{Role: domain.CallerRoleEditor}.CanEdit())
+		assert.False(t, domain.Caller{Role: domain.CallerRoleViewer}.CanEdit())
+	})
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/domain/promotion"
+	"testing"
+	"time"
+)
+
+var promotionTestNow = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+func newPromotionTestLines() []promotion.Line {
+	return []promotion.Line{
+		{Product: domain.Product{Id: 1, Name: "air", Price: 3000.0, Discount: 0, Store: "ABC TECH", Category: "climate"}, Quantity: 1},
+		{Product: domain.Product{Id: 2, Name: "cable", Price: 100.0, Discount: 0, Store: "ABC TECH", Category: "accessories"}, Quantity: 3},
+		{Product: domain.Product{Id: 3, Name: "phone", Price: 2000.0, Discount: 0, Store: "x brand", Category: "phones"}, Quantity: 1},
+	}
+}
+
+func Test_Evaluate_ShouldApplyStoreWideAndBuyXGetY(t *testing.T) {
+	t.Run("Evaluate", func(t *testing.T) {
+		promotions := []domain.Promotion{
+			{Id: 1, Name: "store wide", Type: domain.PromotionTypePercentOff, Store: "ABC TECH", Percent: 10, Stackable: true, ValidFrom: promotionTestNow.Add(-time.Hour)},
+			{Id: 2, Name: "buy 2 get 1", Type: domain.PromotionTypeBuyXGetY, Category: "accessories", BuyQuantity: 2, FreeQuantity: 1, Priority: 10, Stackable: true, ValidFrom: promotionTestNow.Add(-time.Hour)},
+		}
+
+		evaluation := promotion.Evaluate(newPromotionTestLines(), promotions, promotionTestNow)
+
+		assert.Equal(t, 300.0, evaluation.Lines[0].PromotionDiscount)
+		assert.Equal(t, 120.0, evaluation.Lines[1].PromotionDiscount)
+		assert.Equal(t, int64(2), evaluation.Lines[1].Rules[0].PromotionId)
+		assert.Equal(t, 0.0, evaluation.Lines[2].PromotionDiscount)
+		assert.Equal(t, 5300.0, evaluation.Subtotal)
+		assert.Equal(t, 4880.0, evaluation.Total)
+	})
+}
+
+func Test_Evaluate_ShouldNotStackExclusivePromotion(t *testing.T) {
+	t.Run("Evaluate", func(t *testing.T) {
+		promotions := []domain.Promotion{
+			{Id: 1, Name: "store wide", Type: domain.PromotionTypePercentOff, Percent: 10, Stackable: true, ValidFrom: promotionTestNow.Add(-time.Hour)},
+			{Id: 2, Name: "climate sale", Type: domain.PromotionTypePercentOff, Category: "climate", Percent: 25, Priority: 5, ValidFrom: promotionTestNow.Add(-time.Hour)},
+		}
+
+		evaluation := promotion.Evaluate(newPromotionTestLines()[:1], promotions, promotionTestNow)
+
+		rules := evaluation.Lines[0].Rules
+		assert.Equal(t, 2, len(rules))
+		assert.True(t, rules[0].Applied)
+		assert.Equal(t, 750.0, rules[0].Discount)
+		assert.False(t, rules[1].Applied)
+		assert.Equal(t, `not combinable with "climate sale"`, rules[1].Reason)
+		assert.Equal(t, 2250.0, evaluation.Total)
+	})
+}
+
+func Test_Evaluate_ShouldIgnorePromotionsOutsideValidityWindow(t *testing.T) {
+	t.Run("Evaluate", func(t *testing.T) {
+		validTo := promotionTestNow.Add(-time.Minute)
+		minPrice := float32(2500.0)
+		promotions := []domain.Promotion{
+			{Id: 1, Name: "ended", Type: domain.PromotionTypePercentOff, Percent: 10, ValidFrom: promotionTestNow.Add(-time.Hour), ValidTo: &validTo},
+			{Id: 2, Name: "not started", Type: domain.PromotionTypePercentOff, Percent: 10, ValidFrom: promotionTestNow.Add(time.Hour)},
+			{Id: 3, Name: "expensive only", Type: domain.PromotionTypePercentOff, Percent: 10, MinPrice: &minPrice, ValidFrom: promotionTestNow.Add(-time.Hour)},
+		}
+
+		evaluation := promotion.Evaluate(newPromotionTestLines(), promotions, promotionTestNow)
+
+		assert.Equal(t, 300.0, evaluation.PromotionDiscount)
+		assert.Equal(t, int64(3), evaluation.Lines[0].Rules[0].PromotionId)
+		assert.Equal(t, 0, len(evaluation.Lines[2].Rules))
+	})
+}
+
+func Test_Evaluate_ShouldDiscountCompleteBundlesOnly(t *testing.T) {
+	t.Run("Evaluate", func(t *testing.T) {
+		promotions := []domain.Promotion{
+			{Id: 1, Name: "air kit", Type: domain.PromotionTypeBundle, Percent: 20, BundleProductIds: []int64{1, 2}, ValidFrom: promotionTestNow.Add(-time.Hour)},
+		}
+		lines := append(newPromotionTestLines(), promotion.Line{
+			Product: domain.Product{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH", Category: "climate"}, Quantity: 1,
+		})
+
+		evaluation := promotion.Evaluate(lines, promotions, promotionTestNow)
+
+		assert.Equal(t, 600.0, evaluation.Lines[0].PromotionDiscount)
+		assert.Equal(t, 40.0, evaluation.Lines[1].PromotionDiscount)
+		assert.Equal(t, 0, len(evaluation.Lines[2].Rules))
+		assert.Equal(t, 600.0, evaluation.Lines[3].PromotionDiscount)
+		assert.Equal(t, 1240.0, evaluation.PromotionDiscount)
+
+		evaluation = promotion.Evaluate(newPromotionTestLines()[1:], promotions, promotionTestNow)
+
+		assert.False(t, evaluation.Lines[0].Rules[0].Applied)
+		assert.Equal(t, "conditions met but no discount for this quantity", evaluation.Lines[0].Rules[0].Reason)
+		assert.Equal(t, 0.0, evaluation.PromotionDiscount)
+	})
+}
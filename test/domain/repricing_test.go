@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func Test_RepricingOperation_Apply(t *testing.T) {
+	testCases := []struct {
+		name             string
+		operation        domain.RepricingOperation
+		expectedPrice    float32
+		expectedDiscount float32
+	}{
+		{"percent increase", domain.RepricingOperation{Type: domain.RepricingPercentChange, Value: 5}, 2100, 10},
+		{"percent decrease", domain.RepricingOperation{Type: domain.RepricingPercentChange, Value: -12.5}, 1750, 10},
+		{"absolute change", domain.RepricingOperation{Type: domain.RepricingAbsoluteChange, Value: -0.5}, 1999.5, 10},
+		{"set price", domain.RepricingOperation{Type: domain.RepricingSetPrice, Value: 1500}, 1500, 10},
+		{"set discount", domain.RepricingOperation{Type: domain.RepricingSetDiscount, Value: 20}, 2000, 20},
+		{"round to 99", domain.RepricingOperation{Type: domain.RepricingRoundTo99}, 1999.99, 10},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			price, discount := testCase.operation.Apply(2000, 10)
+			assert.Equal(t, testCase.expectedPrice, price)
+			assert.Equal(t, testCase.expectedDiscount, discount)
+		})
+	}
+}
+
+func Test_RepricingRoundTo99_ShouldMoveToNearestPriceEndingIn99(t *testing.T) {
+	operation := domain.RepricingOperation{Type: domain.RepricingRoundTo99}
+	for price, expected := range map[float32]float32{1999.99: 1999.99, 2000.40: 1999.99, 2000.50: 2000.99, 14.2: 13.99} {
+		rounded, _ := operation.Apply(price, 0)
+		assert.Equal(t, expected, rounded)
+	}
+}
+
+func Test_PlanRepricing(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "air", Price: 3000, Discount: 10},
+		{Id: 2, Name: "iron", Price: 1500, Discount: 20},
+	}
+
+	t.Run("leaves unchanged products out", func(t *testing.T) {
+		changes, err := domain.PlanRepricing(products, domain.RepricingOperation{Type: domain.RepricingSetDiscount, Value: 20})
+		assert.Nil(t, err)
+		assert.Equal(t, []domain.PriceChange{
+			{ProductId: 1, ProductName: "air", OldPrice: 3000, NewPrice: 3000, OldDiscount: 10, NewDiscount: 20},
+		}, changes)
+	})
+
+	t.Run("fails when a price would not stay positive", func(t *testing.T) {
+		_, err := domain.PlanRepricing(products, domain.RepricingOperation{Type: domain.RepricingAbsoluteChange, Value: -1500})
+		assert.Equal(t, "Repricing would set the price of product 2 to 0.00", err.Error())
+	})
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+	"time"
+)
+
+func Test_WebhookRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{30, time.Hour},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expected.String(), func(t *testing.T) {
+			assert.Equal(t, testCase.expected, domain.WebhookRetryDelay(testCase.attempts))
+		})
+	}
+}
+
+func Test_SignWebhookBody(t *testing.T) {
+	body := []byte(`{"id":"e1"}`)
+
+	t.Run("SignsTimestampAndBody", func(t *testing.T) {
+		assert.Equal(t, "46fc0b60e09563a94dea2fa3b7b63d83458dd87b30fac860dcbabac0df9bdbde", domain.SignWebhookBody("secret", 1700000000, body))
+	})
+	t.Run("ChangesWithEveryPart", func(t *testing.T) {
+		signature := domain.SignWebhookBody("secret", 1700000000, body)
+		assert.NotEqual(t, signature, domain.SignWebhookBody("other", 1700000000, body))
+		assert.NotEqual(t, signature, domain.SignWebhookBody("secret", 1700000001, body))
+		assert.NotEqual(t, signature, domain.SignWebhookBody("secret", 1700000000, []byte(`{"id":"e2"}`)))
+	})
+}
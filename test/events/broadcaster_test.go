@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/events"
+	"go-product-app/domain"
+	"testing"
+)
+
+func publishEvents(broadcaster *events.Broadcaster, stores ...string) {
+	for i, store := range stores {
+		broadcaster.Publish(context.Background(), domain.ProductEvent{Id: int64(i + 1), Type: domain.ProductEventPriceChanged, ProductId: int64(i + 1), Store: store})
+	}
+}
+
+func receivedIds(subscription *events.Subscription) []int64 {
+	ids := make([]int64, 0)
+	for {
+		select {
+		case event, open := <-subscription.Events():
+			if !open {
+				return ids
+			}
+			ids = append(ids, event.Id)
+		default:
+			return ids
+		}
+	}
+}
+
+func eventIds(productEvents []domain.ProductEvent) []int64 {
+	ids := make([]int64, 0)
+	for _, event := range productEvents {
+		ids = append(ids, event.Id)
+	}
+	return ids
+}
+
+func Test_Broadcaster_ShouldFanOutEventsOfTheStore(t *testing.T) {
+	broadcaster := events.NewBroadcaster(10, 10)
+	all, _, _ := broadcaster.Subscribe("", 0)
+	abcTech, _, _ := broadcaster.Subscribe("ABC TECH", 0)
+
+	publishEvents(broadcaster, "ABC TECH", "x brand", "ABC TECH")
+
+	assert.Equal(t, []int64{1, 2, 3}, receivedIds(all))
+	assert.Equal(t, []int64{1, 3}, receivedIds(abcTech))
+}
+
+func Test_Broadcaster_ShouldResumeFromTheBacklog(t *testing.T) {
+	broadcaster := events.NewBroadcaster(3, 10)
+	publishEvents(broadcaster, "ABC TECH", "x brand", "ABC TECH", "ABC TECH", "x brand")
+
+	t.Run("ReplaysEventsAfterTheLastSeen", func(t *testing.T) {
+		_, missed, resumed := broadcaster.Subscribe("", 3)
+		assert.True(t, resumed)
+		assert.Equal(t, []int64{4, 5}, eventIds(missed))
+	})
+	t.Run("ReplaysOnlyEventsOfTheStore", func(t *testing.T) {
+		_, missed, resumed := broadcaster.Subscribe("ABC TECH", 3)
+		assert.True(t, resumed)
+		assert.Equal(t, []int64{4}, eventIds(missed))
+	})
+	t.Run("ReportsEventsOutOfTheBacklog", func(t *testing.T) {
+		_, missed, resumed := broadcaster.Subscribe("", 2)
+		assert.False(t, resumed)
+		assert.Empty(t, missed)
+	})
+	t.Run("StartsWithNewEventsWithoutLastSeen", func(t *testing.T) {
+		subscription, missed, resumed := broadcaster.Subscribe("", 0)
+		assert.True(t, resumed)
+		assert.Empty(t, missed)
+		assert.Empty(t, receivedIds(subscription))
+	})
+}
+
+func Test_Broadcaster_ShouldDropSubscribersThatFallBehind(t *testing.T) {
+	broadcaster := events.NewBroadcaster(10, 2)
+	slow, _, _ := broadcaster.Subscribe("", 0)
+	filtered, _, _ := broadcaster.Subscribe("x brand", 0)
+
+	publishEvents(broadcaster, "ABC TECH", "ABC TECH", "ABC TECH")
+
+	assert.Equal(t, []int64{1, 2}, receivedIds(slow))
+	_, open := <-slow.Events()
+	assert.False(t, open)
+	assert.True(t, slow.Dropped())
+
+	t.Run("KeepsSubscribersThatKeepUp", func(t *testing.T) {
+		broadcaster.Publish(context.Background(), domain.ProductEvent{Id: 4, Type: domain.ProductEventDeleted, ProductId: 4, Store: "x brand"})
+		assert.Equal(t, []int64{4}, receivedIds(filtered))
+		assert.False(t, filtered.Dropped())
+	})
+	t.Run("ResumesAfterReconnecting", func(t *testing.T) {
+		_, missed, resumed := broadcaster.Subscribe("", 2)
+		assert.True(t, resumed)
+		assert.Equal(t, []int64{3, 4}, eventIds(missed))
+	})
+}
+
+func Test_Broadcaster_ShouldCloseUnsubscribed(t *testing.T) {
+	broadcaster := events.NewBroadcaster(10, 2)
+	subscription, _, _ := broadcaster.Subscribe("", 0)
+
+	broadcaster.Unsubscribe(subscription)
+	broadcaster.Unsubscribe(subscription)
+	publishEvents(broadcaster, "ABC TECH")
+
+	_, open := <-subscription.Events()
+	assert.False(t, open)
+	assert.False(t, subscription.Dropped())
+}
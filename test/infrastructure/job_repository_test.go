@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"testing"
+	"time"
+)
+
+func TestJobRepository(t *testing.T) {
+	jobRepository := persistence.NewJobRepository(dbPool)
+	clearSetup(ctx, dbPool)
+
+	t.Run("claims due jobs once and records the result", func(t *testing.T) {
+		job, err := jobRepository.Add(domain.Job{Type: "export", Payload: json.RawMessage(`{"format":"csv"}`), MaxAttempts: 3, CreatedBy: "user-1"})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.JobStatusQueued, job.Status)
+
+		claimed, found, err := jobRepository.ClaimNext(time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, job.Id, claimed.Id)
+		assert.Equal(t, domain.JobStatusRunning, claimed.Status)
+		assert.Equal(t, 1, claimed.Attempts)
+
+		_, found, _ = jobRepository.ClaimNext(time.Minute)
+		assert.False(t, found)
+
+		cancelRequested, err := jobRepository.UpdateProgress(job.Id, claimed.Attempts, 4, 10, time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, cancelRequested)
+		assert.Nil(t, jobRepository.Complete(job.Id, claimed.Attempts, json.RawMessage(`{"url":"/files/a.csv"}`)))
+
+		job, _ = jobRepository.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusSucceeded, job.Status)
+		assert.Equal(t, int64(4), job.ProgressDone)
+		assert.JSONEq(t, `{"url":"/files/a.csv"}`, string(job.Result))
+		assert.NotNil(t, job.FinishedAt)
+	})
+
+	t.Run("requeues failed jobs after the retry time", func(t *testing.T) {
+		job, _ := jobRepository.Add(domain.Job{Type: "import", MaxAttempts: 3, CreatedBy: "user-1"})
+		claimed, _, _ := jobRepository.ClaimNext(time.Minute)
+
+		retryAt := time.Now().Add(time.Hour)
+		assert.Nil(t, jobRepository.Fail(job.Id, claimed.Attempts, "Storage is unavailable", &retryAt))
+		job, _ = jobRepository.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusQueued, job.Status)
+		assert.Equal(t, "Storage is unavailable", job.Error)
+
+		_, found, _ := jobRepository.ClaimNext(time.Minute)
+		assert.False(t, found)
+	})
+
+	t.Run("cancels queued jobs and flags running ones", func(t *testing.T) {
+		clearSetup(ctx, dbPool)
+		queuedJob, _ := jobRepository.Add(domain.Job{Type: "import", MaxAttempts: 3, CreatedBy: "user-1"})
+		queuedJob, err := jobRepository.RequestCancel(queuedJob.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.JobStatusCancelled, queuedJob.Status)
+
+		_, err = jobRepository.RequestCancel(queuedJob.Id)
+		assert.IsType(t, persistence.ConflictError{}, err)
+
+		runningJob, _ := jobRepository.Add(domain.Job{Type: "import", MaxAttempts: 3, CreatedBy: "user-1"})
+		claimed, _, _ := jobRepository.ClaimNext(time.Minute)
+		runningJob, _ = jobRepository.RequestCancel(runningJob.Id)
+		assert.Equal(t, domain.JobStatusRunning, runningJob.Status)
+		assert.True(t, runningJob.CancelRequested)
+
+		cancelRequested, _ := jobRepository.UpdateProgress(runningJob.Id, claimed.Attempts, 1, 1, time.Minute)
+		assert.True(t, cancelRequested)
+	})
+
+	t.Run("ignores updates from an attempt that was taken over", func(t *testing.T) {
+		clearSetup(ctx, dbPool)
+		job, _ := jobRepository.Add(domain.Job{Type: "export", MaxAttempts: 2, CreatedBy: "user-1"})
+		firstClaim, _, _ := jobRepository.ClaimNext(time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		secondClaim, found, _ := jobRepository.ClaimNext(time.Millisecond)
+		assert.True(t, found)
+		assert.Equal(t, 2, secondClaim.Attempts)
+
+		cancelRequested, _ := jobRepository.UpdateProgress(job.Id, firstClaim.Attempts, 1, 1, time.Minute)
+		assert.True(t, cancelRequested)
+		assert.Nil(t, jobRepository.Complete(job.Id, firstClaim.Attempts, json.RawMessage(`{}`)))
+		job, _ = jobRepository.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusRunning, job.Status)
+
+		time.Sleep(10 * time.Millisecond)
+		abandonedJobs, err := jobRepository.FailAbandoned()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(abandonedJobs))
+		assert.Equal(t, job.Id, abandonedJobs[0].Id)
+		_, found, _ = jobRepository.ClaimNext(time.Minute)
+		assert.False(t, found)
+		job, _ = jobRepository.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusFailed, job.Status)
+		assert.Equal(t, "Worker stopped renewing its lease on the last attempt", job.Error)
+	})
+
+	t.Run("returns not found for unknown jobs", func(t *testing.T) {
+		_, err := jobRepository.GetById(999)
+		assert.IsType(t, persistence.NotFoundError{}, err)
+	})
+
+	clearSetup(ctx, dbPool)
+}
@@ -0,0 +1,165 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"testing"
+	"time"
+)
+
+func relayAll(outboxRepository persistence.IOutboxRepository, publish func(event domain.ProductEvent) error) []domain.ProductEvent {
+	var events []domain.ProductEvent
+	for {
+		published, _, _ := outboxRepository.RelayPending(100, func(event domain.ProductEvent) error {
+			if err := publish(event); err != nil {
+				return err
+			}
+			events = append(events, event)
+			return nil
+		})
+		if published == 0 {
+			return events
+		}
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	outboxRepository := persistence.NewOutboxRepository(dbPool)
+	clearSetup(ctx, dbPool)
+
+	t.Run("writes an event with every product change", func(t *testing.T) {
+		id, err := productRepository.Add(domain.Product{Name: "kettle", Price: 500, Discount: 5, Store: "ABC TECH", Sku: "KET-1", CreatedBy: "editor"})
+		assert.Nil(t, err)
+		assert.Nil(t, productRepository.UpdateProductPrice(id, 450, "editor"))
+		assert.Nil(t, productRepository.UpdateProductPrice(id, 450, "editor"))
+		assert.Nil(t, productRepository.DeleteById(id, "editor"))
+
+		events := relayAll(outboxRepository, func(event domain.ProductEvent) error { return nil })
+		assert.Equal(t, 3, len(events))
+		assert.Equal(t, []string{domain.ProductEventCreated, domain.ProductEventPriceChanged, domain.ProductEventDeleted},
+			[]string{events[0].Type, events[1].Type, events[2].Type})
+		assert.NotEmpty(t, events[0].EventId)
+		assert.Equal(t, "ABC TECH", events[2].Store)
+
+		var created domain.ProductCreatedPayload
+		assert.Nil(t, json.Unmarshal(events[0].Payload, &created))
+		assert.Equal(t, domain.ProductCreatedPayload{ProductId: id, Name: "kettle", Price: 500, Discount: 5, Store: "ABC TECH",
+			Status: domain.ProductStatusActive, Sku: "KET-1", Actor: "editor"}, created)
+		var priceChanged domain.PriceChangedPayload
+		assert.Nil(t, json.Unmarshal(events[1].Payload, &priceChanged))
+		assert.Equal(t, domain.PriceChangedPayload{ProductId: id, OldPrice: 500, NewPrice: 450, OldDiscount: 5, NewDiscount: 5, Store: "ABC TECH",
+			Status: domain.ProductStatusActive, Actor: "editor"}, priceChanged)
+	})
+
+	t.Run("writes an updated event with every update and status change", func(t *testing.T) {
+		clearSetup(ctx, dbPool)
+		id, _ := productRepository.Add(domain.Product{Name: "fan", Price: 100, Store: "ABC TECH", Sku: "FAN-1", Status: domain.ProductStatusDraft})
+		relayAll(outboxRepository, func(event domain.ProductEvent) error { return nil })
+
+		assert.Nil(t, productRepository.Update(domain.Product{Id: id, Name: "desk fan", Price: 100, Category: "cooling", Sku: "FAN-1", UpdatedBy: "editor"}))
+		assert.Nil(t, productRepository.UpdateStatus(domain.ProductStatusChange{ProductId: id, FromStatus: domain.ProductStatusDraft,
+			ToStatus: domain.ProductStatusActive, Actor: "admin", ChangedAt: time.Now()}))
+
+		events := relayAll(outboxRepository, func(event domain.ProductEvent) error { return nil })
+		assert.Equal(t, 2, len(events))
+		var updated domain.ProductUpdatedPayload
+		assert.Nil(t, json.Unmarshal(events[0].Payload, &updated))
+		assert.Equal(t, domain.ProductUpdatedPayload{ProductId: id, Name: "desk fan", Price: 100, Store: "ABC TECH", Category: "cooling",
+			Status: domain.ProductStatusDraft, Sku: "FAN-1", Actor: "editor"}, updated)
+		assert.Equal(t, domain.ProductEventUpdated, events[1].Type)
+		assert.Equal(t, domain.ProductStatusActive, events[1].Status)
+	})
+
+	t.Run("writes an updated event when a deleted product is restored", func(t *testing.T) {
+		clearSetup(ctx, dbPool)
+		id, _ := productRepository.Add(domain.Product{Name: "fan", Price: 100, Store: "ABC TECH", Sku: "FAN-1"})
+		assert.Nil(t, productRepository.DeleteById(id, "editor"))
+		assert.Nil(t, productRepository.RestoreById(id, "admin"))
+
+		events := relayAll(outboxRepository, func(event domain.ProductEvent) error { return nil })
+		assert.Equal(t, []string{domain.ProductEventCreated, domain.ProductEventDeleted, domain.ProductEventUpdated},
+			[]string{events[0].Type, events[1].Type, events[2].Type})
+		var restored domain.ProductUpdatedPayload
+		assert.Nil(t, json.Unmarshal(events[2].Payload, &restored))
+		assert.Equal(t, "admin", restored.Actor)
+	})
+
+	t.Run("rolls the events back with a failed change", func(t *testing.T) {
+		_, err := productRepository.Add(domain.Product{Name: "kettle", Price: 500, Store: "ABC TECH", Sku: "KET-2"})
+		assert.Nil(t, err)
+		_, err = productRepository.Add(domain.Product{Name: "kettle", Price: 500, Store: "ABC TECH", Sku: "KET-2"})
+		assert.NotNil(t, err)
+
+		events := relayAll(outboxRepository, func(event domain.ProductEvent) error { return nil })
+		assert.Equal(t, 1, len(events))
+	})
+
+	t.Run("holds back the later events of a product that fails to publish", func(t *testing.T) {
+		clearSetup(ctx, dbPool)
+		first, _ := productRepository.Add(domain.Product{Name: "fan", Price: 100, Store: "ABC TECH"})
+		second, _ := productRepository.Add(domain.Product{Name: "lamp", Price: 100, Store: "ABC TECH"})
+		productRepository.UpdateProductPrice(first, 120, "editor")
+		productRepository.UpdateProductPrice(second, 120, "editor")
+
+		events := relayAll(outboxRepository, func(event domain.ProductEvent) error {
+			if event.ProductId == first {
+				return errors.New("Broker is unavailable")
+			}
+			return nil
+		})
+		assert.Equal(t, 2, len(events))
+		for _, event := range events {
+			assert.Equal(t, second, event.ProductId)
+		}
+
+		_, err := dbPool.Exec(ctx, `UPDATE outbox SET next_attempt_at = now()`)
+		assert.Nil(t, err)
+		events = relayAll(outboxRepository, func(event domain.ProductEvent) error { return nil })
+		assert.Equal(t, 2, len(events))
+		assert.Equal(t, domain.ProductEventCreated, events[0].Type)
+		assert.Equal(t, 1, events[0].Attempts)
+	})
+
+	t.Run("reads the published events for the stream of every instance", func(t *testing.T) {
+		clearSetup(ctx, dbPool)
+		draft, _ := productRepository.Add(domain.Product{Name: "fan", Price: 100, Store: "ABC TECH", Status: domain.ProductStatusDraft})
+		productRepository.UpdateProductPrice(draft, 120, "editor")
+		published := relayAll(outboxRepository, func(event domain.ProductEvent) error { return nil })
+		productRepository.DeleteById(draft, "editor")
+
+		events, err := outboxRepository.GetPublished([]int64{published[1].Id, published[0].Id, published[1].Id + 1})
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{published[1].Id, published[0].Id}, []int64{events[0].Id, events[1].Id})
+		assert.Equal(t, domain.ProductStatusDraft, events[0].Status)
+		assert.Equal(t, domain.ProductStatusDraft, events[1].Status)
+
+		events, err = outboxRepository.GetPublishedAfter(published[0].Id, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(events))
+		assert.Equal(t, domain.ProductEventPriceChanged, events[0].Type)
+	})
+
+	t.Run("purges only the events published before the cut off", func(t *testing.T) {
+		clearSetup(ctx, dbPool)
+		first, _ := productRepository.Add(domain.Product{Name: "fan", Price: 100, Store: "ABC TECH"})
+		relayAll(outboxRepository, func(event domain.ProductEvent) error { return nil })
+		productRepository.UpdateProductPrice(first, 120, "editor")
+
+		purged, err := outboxRepository.PurgePublished(time.Now().Add(-time.Hour))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), purged)
+
+		purged, err = outboxRepository.PurgePublished(time.Now().Add(time.Hour))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		events := relayAll(outboxRepository, func(event domain.ProductEvent) error { return nil })
+		assert.Equal(t, 1, len(events))
+		assert.Equal(t, domain.ProductEventPriceChanged, events[0].Type)
+	})
+
+	clearSetup(ctx, dbPool)
+}
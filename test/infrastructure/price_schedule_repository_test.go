@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"testing"
+	"time"
+)
+
+func timePointer(value time.Time) *time.Time {
+	return &value
+}
+
+func TestApplyDue(t *testing.T) {
+	setup(ctx, dbPool)
+
+	priceScheduleRepository := persistence.NewPriceScheduleRepository(dbPool)
+	price := float32(2500)
+	assert.Nil(t, priceScheduleRepository.Add(domain.PriceSchedule{ProductId: 1, Price: &price, EffectiveFrom: time.Now().Add(-time.Minute)}))
+
+	t.Run("skips the tick while another instance holds the lock", func(t *testing.T) {
+		connection, err := dbPool.Acquire(ctx)
+		assert.Nil(t, err)
+		_, err = connection.Exec(ctx, "SELECT pg_advisory_lock(726001)")
+		assert.Nil(t, err)
+
+		changed, err := priceScheduleRepository.ApplyDue(time.Now())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), changed)
+		product, _ := productRepository.GetById(1)
+		assert.Equal(t, float32(3000), product.Price)
+
+		_, err = connection.Exec(ctx, "SELECT pg_advisory_unlock(726001)")
+		assert.Nil(t, err)
+		connection.Release()
+	})
+
+	t.Run("applies due schedules once the lock is free", func(t *testing.T) {
+		changed, err := priceScheduleRepository.ApplyDue(time.Now())
+		assert.Nil(t, err)
+		assert.NotEqual(t, int64(0), changed)
+		product, _ := productRepository.GetById(1)
+		assert.Equal(t, float32(2500), product.Price)
+	})
+
+	t.Run("cancel does not touch an applied schedule", func(t *testing.T) {
+		err := priceScheduleRepository.Cancel(1)
+		assert.Equal(t, "Price schedule with id 1 is already applied", err.Error())
+	})
+
+	t.Run("touches the product once when a time-boxed window opens and once when it closes", func(t *testing.T) {
+		discount := float32(40)
+		assert.Nil(t, priceScheduleRepository.Add(domain.PriceSchedule{ProductId: 2, Discount: &discount,
+			EffectiveFrom: time.Now().Add(time.Minute), EffectiveTo: timePointer(time.Now().Add(time.Hour))}))
+		before, _ := productRepository.GetById(2)
+
+		changed, err := priceScheduleRepository.ApplyDue(time.Now().Add(2 * time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), changed)
+		opened, _ := productRepository.GetById(2)
+		assert.True(t, opened.UpdatedAt.After(before.UpdatedAt))
+
+		changed, err = priceScheduleRepository.ApplyDue(time.Now().Add(3 * time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), changed)
+
+		changed, err = priceScheduleRepository.ApplyDue(time.Now().Add(2 * time.Hour))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), changed)
+		closed, _ := productRepository.GetById(2)
+		assert.True(t, closed.UpdatedAt.After(opened.UpdatedAt))
+	})
+
+	clearSetup(ctx, dbPool)
+}
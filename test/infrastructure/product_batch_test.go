@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func TestAddBatch(t *testing.T) {
+	products := []domain.Product{
+		{Name: "laptop", Price: 50000.0, Store: "ABC TECH", Sku: "LP-1", CreatedBy: "user-1",
+			Translations: map[string]domain.LocalizedText{"tr": {Name: "dizüstü"}}},
+		{Name: "laptop copy", Price: 40000.0, Store: "ABC TECH", Sku: "LP-1"},
+		{Name: "tablet", Price: 20000.0, Store: "ABC TECH", Gtin: "4006381333931"},
+		{Name: "tablet copy", Price: 20000.0, Store: "ABC TECH", Gtin: "4006381333931"},
+	}
+
+	t.Run("atomic rolls back", func(t *testing.T) {
+		results, err := productRepository.AddBatch(products, true)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.BatchItemNotApplied, results[0].Status)
+		assert.Equal(t, "Product with sku LP-1 already exists in store ABC TECH", results[1].Error)
+		assert.Equal(t, "Product with gtin 4006381333931 already exists in store ABC TECH", results[3].Error)
+		actualProducts, _ := productRepository.GetAll()
+		assert.Equal(t, 0, len(actualProducts))
+	})
+
+	t.Run("best effort keeps valid items", func(t *testing.T) {
+		results, err := productRepository.AddBatch(products, false)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.BatchItemSucceeded, results[0].Status)
+		assert.Equal(t, domain.BatchItemFailed, results[1].Status)
+		assert.Equal(t, domain.BatchItemSucceeded, results[2].Status)
+
+		product, err := productRepository.GetById(results[0].Id)
+		assert.Nil(t, err)
+		assert.Equal(t, "dizüstü", product.Translations["tr"].Name)
+		assert.Equal(t, "user-1", product.CreatedBy)
+	})
+
+	clearSetup(ctx, dbPool)
+}
+
+func TestUpdatePricesAndDeleteBatch(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("UpdatePricesBatch", func(t *testing.T) {
+		results, err := productRepository.UpdatePricesBatch([]domain.ProductPriceUpdate{{Id: 1, Price: 2500.0}, {Id: 99, Price: 10.0}}, "user-1", false)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.BatchItemSucceeded, results[0].Status)
+		assert.Equal(t, "Product with id 99 not found", results[1].Error)
+		product, _ := productRepository.GetById(1)
+		assert.Equal(t, float32(2500.0), product.Price)
+	})
+
+	t.Run("DeleteBatch", func(t *testing.T) {
+		results, err := productRepository.DeleteBatch([]int64{2, 99}, "user-1", true)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.BatchItemNotApplied, results[0].Status)
+		_, err = productRepository.GetById(2)
+		assert.Nil(t, err)
+
+		_, err = productRepository.DeleteBatch([]int64{2, 3}, "user-1", true)
+		assert.Nil(t, err)
+		actualProducts, _ := productRepository.GetAll()
+		assert.Equal(t, 2, len(actualProducts))
+	})
+
+	clearSetup(ctx, dbPool)
+}
@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"testing"
+	"time"
+)
+
+func nextChange(t *testing.T, changes chan domain.ProductChange) domain.ProductChange {
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		t.Fatal("No product change notified")
+		return domain.ProductChange{}
+	}
+}
+
+func TestProductChangeListener(t *testing.T) {
+	clearSetup(ctx, dbPool)
+	listenerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes := make(chan domain.ProductChange, 10)
+	productChangeListener := persistence.NewProductChangeListener(dbPool, 100*time.Millisecond)
+	productChangeListener.Subscribe(func(change domain.ProductChange) {
+		changes <- change
+	})
+	productChangeListener.Start(listenerCtx)
+	// the listener connects in the background
+	time.Sleep(500 * time.Millisecond)
+
+	t.Run("notifies every write to a product", func(t *testing.T) {
+		id, err := productRepository.Add(domain.Product{Name: "kettle", Price: 500, Store: "ABC TECH"})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.ProductChange{Operation: domain.ProductChangeInsert, ProductId: id, Store: "ABC TECH"}, nextChange(t, changes))
+
+		assert.Nil(t, productRepository.UpdateProductPrice(id, 450, "editor"))
+		assert.Equal(t, domain.ProductChange{Operation: domain.ProductChangeUpdate, ProductId: id, Store: "ABC TECH"}, nextChange(t, changes))
+
+		assert.Nil(t, productRepository.DeleteById(id, "editor"))
+		assert.Equal(t, domain.ProductChange{Operation: domain.ProductChangeDelete, ProductId: id, Store: "ABC TECH"}, nextChange(t, changes))
+	})
+
+	t.Run("does not notify rolled back writes", func(t *testing.T) {
+		_, err := productRepository.Add(domain.Product{Name: "kettle", Price: 500, Store: "ABC TECH", Sku: "KET-9"})
+		assert.Nil(t, err)
+		nextChange(t, changes)
+		_, err = productRepository.Add(domain.Product{Name: "kettle", Price: 500, Store: "ABC TECH", Sku: "KET-9"})
+		assert.NotNil(t, err)
+
+		select {
+		case change := <-changes:
+			t.Errorf("Unexpected product change %v", change)
+		case <-time.After(300 * time.Millisecond):
+		}
+	})
+
+	t.Run("resyncs after reconnecting", func(t *testing.T) {
+		_, err := dbPool.Exec(ctx, `SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE query = 'LISTEN `+persistence.ProductChangesChannel+`'`)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.ProductChangeResync, nextChange(t, changes).Operation)
+	})
+
+	clearSetup(ctx, dbPool)
+}
@@ -0,0 +1,41 @@
+package infrastructure
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func TestStreamByFilter(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("reads every product in chunks", func(t *testing.T) {
+		var chunkSizes []int
+		var ids []int64
+		err := productRepository.StreamByFilter(domain.ProductFilter{}, 3, func(products []domain.Product) error {
+			chunkSizes = append(chunkSizes, len(products))
+			for _, product := range products {
+				ids = append(ids, product.Id)
+			}
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []int{3, 1}, chunkSizes)
+		assert.Equal(t, []int64{1, 2, 3, 4}, ids)
+	})
+
+	t.Run("honours the filter and stops on error", func(t *testing.T) {
+		handleErr := errors.New("client went away")
+		calls := 0
+		err := productRepository.StreamByFilter(domain.ProductFilter{Store: "ABC TECH"}, 1, func(products []domain.Product) error {
+			calls++
+			assert.Equal(t, "ABC TECH", products[0].Store)
+			return handleErr
+		})
+		assert.Equal(t, handleErr, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	clearSetup(ctx, dbPool)
+}
@@ -10,6 +10,7 @@ import (
 	"go-product-app/persistence"
 	"os"
 	"testing"
+	"time"
 )
 
 //go test -v - all test will run under current directory
@@ -46,9 +47,26 @@ func clearSetup(ctx context.Context, dbPool *pgxpool.Pool) {
 	TruncateTestData(ctx, dbPool)
 }
 
+// withoutTimestamps clears the timestamps the database sets, so products can be compared as a whole.
+func withoutTimestamps(products []domain.Product) []domain.Product {
+	for i := range products {
+		products[i].CreatedAt = time.Time{}
+		products[i].UpdatedAt = time.Time{}
+	}
+	return products
+}
+
+func productIds(products []domain.Product) []int64 {
+	ids := make([]int64, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.Id)
+	}
+	return ids
+}
+
 func TestAdd(t *testing.T) {
 	expectedProducts := []domain.Product{
-		{Id: 1, Name: "laptop", Price: 50000.0, Discount: 10.0, Store: "ABC TECH"},
+		{Id: 1, Name: "laptop", Price: 50000.0, Discount: 10.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
 	}
 
 	product := domain.Product{
@@ -62,7 +80,123 @@ func TestAdd(t *testing.T) {
 		productRepository.Add(product)
 		actualProducts, _ := productRepository.GetAll()
 		assert.Equal(t, 1, len(actualProducts))
-		assert.Equal(t, expectedProducts, actualProducts)
+		assert.Equal(t, expectedProducts, withoutTimestamps(actualProducts))
+	})
+
+	clearSetup(ctx, dbPool)
+}
+
+func TestAddDuplicateSku(t *testing.T) {
+	product := domain.Product{Name: "laptop", Price: 50000.0, Discount: 10.0, Store: "ABC TECH", Sku: "LP-1"}
+
+	t.Run("AddDuplicateSku", func(t *testing.T) {
+		_, err := productRepository.Add(product)
+		assert.Nil(t, err)
+
+		_, err = productRepository.Add(product)
+		assert.IsType(t, persistence.ConflictError{}, err)
+		assert.Equal(t, "Product with sku LP-1 already exists in store ABC TECH", err.Error())
+
+		product.Store = "x brand"
+		_, err = productRepository.Add(product)
+		assert.Nil(t, err)
+	})
+
+	clearSetup(ctx, dbPool)
+}
+
+func TestGetBySku(t *testing.T) {
+	t.Run("GetBySku", func(t *testing.T) {
+		productRepository.Add(domain.Product{Name: "laptop", Price: 50000.0, Store: "ABC TECH", Sku: "LP-1", Gtin: "4006381333931"})
+		actualProducts, err := productRepository.GetBySku("LP-1", "ABC TECH")
+		assert.Nil(t, err)
+		assert.Equal(t, []domain.Product{
+			{Id: 1, Name: "laptop", Price: 50000.0, Store: "ABC TECH", Status: domain.ProductStatusActive, Sku: "LP-1", Gtin: "4006381333931"},
+		}, withoutTimestamps(actualProducts))
+
+		_, err = productRepository.GetBySku("LP-1", "x brand")
+		assert.IsType(t, persistence.NotFoundError{}, err)
+	})
+
+	clearSetup(ctx, dbPool)
+}
+
+func TestTranslations(t *testing.T) {
+	t.Run("Translations", func(t *testing.T) {
+		productRepository.Add(domain.Product{Name: "ütü", Description: "buharlı", Price: 1500.0, Store: "ABC TECH", Translations: map[string]domain.LocalizedText{
+			"tr": {Name: "ütü", Description: "buharlı"},
+		}})
+		err := productRepository.UpdateTranslations(1, map[string]domain.LocalizedText{
+			"tr": {Name: "buharlı ütü", Description: "buharlı"},
+			"en": {Name: "iron"},
+		}, "user-1")
+		assert.Nil(t, err)
+
+		actualProduct, _ := productRepository.GetById(1)
+		assert.Equal(t, domain.Product{Id: 1, Name: "ütü", Description: "buharlı", Price: 1500.0, Store: "ABC TECH", Status: domain.ProductStatusActive, Translations: map[string]domain.LocalizedText{
+			"tr": {Name: "buharlı ütü", Description: "buharlı"},
+			"en": {Name: "iron"},
+		}, UpdatedBy: "user-1"}, withoutTimestamps([]domain.Product{actualProduct})[0])
+	})
+
+	clearSetup(ctx, dbPool)
+}
+
+func TestUpdateStatus(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		changedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		err := productRepository.UpdateStatus(domain.ProductStatusChange{ProductId: 1, FromStatus: domain.ProductStatusActive, ToStatus: domain.ProductStatusDiscontinued, Actor: "user-1", Reason: "end of season", ChangedAt: changedAt})
+		assert.Nil(t, err)
+
+		err = productRepository.UpdateStatus(domain.ProductStatusChange{ProductId: 1, FromStatus: domain.ProductStatusActive, ToStatus: domain.ProductStatusArchived, Actor: "user-2", ChangedAt: changedAt})
+		assert.IsType(t, persistence.ConflictError{}, err)
+
+		activeProducts, _ := productRepository.GetAllByFilter(domain.ProductFilter{Statuses: []string{domain.ProductStatusActive}})
+		assert.Equal(t, 3, len(activeProducts))
+
+		history, _ := productRepository.GetStatusHistory(1)
+		assert.Equal(t, 1, len(history))
+		assert.Equal(t, "user-1", history[0].Actor)
+		assert.Equal(t, "end of season", history[0].Reason)
+		assert.True(t, changedAt.Equal(history[0].ChangedAt))
+	})
+
+	clearSetup(ctx, dbPool)
+}
+
+func TestSoftDelete(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		err := productRepository.DeleteById(1, "user-1")
+		assert.Nil(t, err)
+
+		_, err = productRepository.GetById(1)
+		assert.NotNil(t, err)
+		actualProducts, _ := productRepository.GetAll()
+		assert.Equal(t, 3, len(actualProducts))
+
+		deletedProducts, _ := productRepository.GetAllDeleted()
+		assert.Equal(t, 1, len(deletedProducts))
+		assert.NotNil(t, deletedProducts[0].DeletedAt)
+
+		purged, _, _ := productRepository.PurgeDeleted(deletedProducts[0].DeletedAt.Add(-time.Minute))
+		assert.Equal(t, int64(0), purged)
+
+		err = productRepository.RestoreById(1, "user-1")
+		assert.Nil(t, err)
+		err = productRepository.RestoreById(1, "user-1")
+		assert.IsType(t, persistence.NotFoundError{}, err)
+		actualProducts, _ = productRepository.GetAll()
+		assert.Equal(t, 4, len(actualProducts))
+
+		productRepository.DeleteById(2, "user-1")
+		purged, _, _ = productRepository.PurgeDeleted(time.Now().Add(time.Minute))
+		assert.Equal(t, int64(1), purged)
+		deletedProducts, _ = productRepository.GetAllDeleted()
+		assert.Equal(t, 0, len(deletedProducts))
 	})
 
 	clearSetup(ctx, dbPool)
@@ -72,12 +206,12 @@ func TestGetById(t *testing.T) {
 	setup(ctx, dbPool)
 
 	expectedProduct := domain.Product{
-		Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH",
+		Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH", Status: domain.ProductStatusActive,
 	}
 
 	t.Run("GetById", func(t *testing.T) {
 		actualProduct, _ := productRepository.GetById(1)
-		assert.Equal(t, expectedProduct, actualProduct)
+		assert.Equal(t, expectedProduct, withoutTimestamps([]domain.Product{actualProduct})[0])
 	})
 
 	clearSetup(ctx, dbPool)
@@ -95,20 +229,36 @@ func TestGeyByIdNotFound(t *testing.T) {
 	clearSetup(ctx, dbPool)
 }
 
+func TestGetByIds(t *testing.T) {
+	setup(ctx, dbPool)
+
+	expectedProducts := []domain.Product{
+		{Id: 2, Name: "iron", Price: 1500.0, Discount: 10.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 4, Name: "phone", Price: 2000.0, Discount: 0.0, Store: "x brand", Status: domain.ProductStatusActive},
+	}
+
+	t.Run("GetByIds", func(t *testing.T) {
+		actualProducts, _ := productRepository.GetByIds([]int64{2, 4, 100})
+		assert.Equal(t, expectedProducts, withoutTimestamps(actualProducts))
+	})
+
+	clearSetup(ctx, dbPool)
+}
+
 func TestGetAll(t *testing.T) {
 	setup(ctx, dbPool)
 
 	expectedProducts := []domain.Product{
-		{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH"},
-		{Id: 2, Name: "iron", Price: 1500.0, Discount: 10.0, Store: "ABC TECH"},
-		{Id: 3, Name: "fax", Price: 10000.0, Discount: 15.0, Store: "ABC TECH"},
-		{Id: 4, Name: "phone", Price: 2000.0, Discount: 0.0, Store: "x brand"},
+		{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 2, Name: "iron", Price: 1500.0, Discount: 10.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 3, Name: "fax", Price: 10000.0, Discount: 15.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 4, Name: "phone", Price: 2000.0, Discount: 0.0, Store: "x brand", Status: domain.ProductStatusActive},
 	}
 
 	t.Run("GetAll", func(t *testing.T) {
 		actualProducts, _ := productRepository.GetAll()
 		assert.Equal(t, 4, len(actualProducts))
-		assert.Equal(t, expectedProducts, actualProducts)
+		assert.Equal(t, expectedProducts, withoutTimestamps(actualProducts))
 
 	})
 	clearSetup(ctx, dbPool)
@@ -119,14 +269,14 @@ func TestGetAllByStore(t *testing.T) {
 	setup(ctx, dbPool)
 
 	expectedProducts := []domain.Product{
-		{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH"},
-		{Id: 2, Name: "iron", Price: 1500.0, Discount: 10.0, Store: "ABC TECH"},
-		{Id: 3, Name: "fax", Price: 10000.0, Discount: 15.0, Store: "ABC TECH"},
+		{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 2, Name: "iron", Price: 1500.0, Discount: 10.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 3, Name: "fax", Price: 10000.0, Discount: 15.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
 	}
 
 	t.Run("GetAllByStore", func(t *testing.T) {
 		actualProducts, _ := productRepository.GetAllByStore("ABC TECH")
-		assert.Equal(t, expectedProducts, actualProducts)
+		assert.Equal(t, expectedProducts, withoutTimestamps(actualProducts))
 	})
 
 	clearSetup(ctx, dbPool)
@@ -136,7 +286,7 @@ func TestDeleteById(t *testing.T) {
 	setup(ctx, dbPool)
 
 	t.Run("DeleteById", func(t *testing.T) {
-		productRepository.DeleteById(1)
+		productRepository.DeleteById(1, "user-1")
 		products, _ := productRepository.GetAll()
 		assert.Equal(t, 3, len(products))
 	})
@@ -148,7 +298,7 @@ func TestDeleteByIdNotFound(t *testing.T) {
 	setup(ctx, dbPool)
 
 	t.Run("DeleteByIdNotFound", func(t *testing.T) {
-		err := productRepository.DeleteById(100)
+		err := productRepository.DeleteById(100, "user-1")
 		assert.NotNil(t, err)
 		assert.Equal(t, fmt.Sprintf("Product with id %d not found", 100), err.Error())
 	})
@@ -161,11 +311,71 @@ func TestUpdateProductPrice(t *testing.T) {
 
 	t.Run("UpdateProductPrice", func(t *testing.T) {
 		product, _ := productRepository.GetById(1)
-		productRepository.UpdateProductPrice(product.Id, 4000.0)
+		productRepository.UpdateProductPrice(product.Id, 4000.0, "user-1")
 		updatedProduct, _ := productRepository.GetById(product.Id)
 		assert.Equal(t, float32(4000.0), updatedProduct.Price)
 	})
 
+	t.Run("UpdateProductPrice tracks the change", func(t *testing.T) {
+		product, _ := productRepository.GetById(2)
+		since := time.Now()
+		productRepository.UpdateProductPrice(product.Id, 1600.0, "user-2")
+		updatedProduct, _ := productRepository.GetById(product.Id)
+		assert.Equal(t, "", updatedProduct.CreatedBy)
+		assert.Equal(t, "user-2", updatedProduct.UpdatedBy)
+		assert.Equal(t, product.CreatedAt, updatedProduct.CreatedAt)
+		assert.True(t, updatedProduct.UpdatedAt.After(product.UpdatedAt))
+
+		updatedProducts, _ := productRepository.GetAllByFilter(domain.ProductFilter{UpdatedSince: &since})
+		assert.Equal(t, 1, len(updatedProducts))
+		assert.Equal(t, int64(2), updatedProducts[0].Id)
+	})
+
+	t.Run("UpdatedSince lists removed products and counts variant writes", func(t *testing.T) {
+		since := time.Now()
+		productRepository.DeleteById(3, "user-2")
+		productRepository.UpdateStatus(domain.ProductStatusChange{ProductId: 4, FromStatus: domain.ProductStatusActive, ToStatus: domain.ProductStatusDiscontinued, Actor: "user-2", ChangedAt: time.Now()})
+		assert.Nil(t, persistence.NewProductVariantRepository(dbPool).Add(domain.ProductVariant{ProductId: 1, Sku: "AIR-1-RED", Stock: 1, Attributes: map[string]string{"color": "red"}}))
+
+		filter := domain.ProductFilter{Statuses: []string{domain.ProductStatusActive}, UpdatedSince: &since}
+		updatedProducts, _ := productRepository.GetAllByFilter(filter)
+		assert.Equal(t, []int64{1}, productIds(updatedProducts))
+
+		filter.IncludeRemoved = true
+		updatedProducts, _ = productRepository.GetAllByFilter(filter)
+		assert.Equal(t, []int64{1, 3, 4}, productIds(updatedProducts))
+		assert.NotNil(t, updatedProducts[1].DeletedAt)
+		assert.Equal(t, domain.ProductStatusDiscontinued, updatedProducts[2].Status)
+	})
+
+	clearSetup(ctx, dbPool)
+}
+
+func TestUpdate(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("Update", func(t *testing.T) {
+		err := productRepository.Update(domain.Product{Id: 1, Name: "air conditioner", Price: 2800.0, Discount: 5.0, Sku: "AIR-1", UpdatedBy: "user-2",
+			Translations: map[string]domain.LocalizedText{"tr": {Name: "klima"}}})
+		assert.Nil(t, err)
+
+		product, _ := productRepository.GetById(1)
+		assert.Equal(t, "air conditioner", product.Name)
+		assert.Equal(t, float32(2800.0), product.Price)
+		assert.Equal(t, "ABC TECH", product.Store)
+		assert.Equal(t, domain.ProductStatusActive, product.Status)
+		assert.Equal(t, "klima", product.Translations["tr"].Name)
+		assert.Equal(t, "user-2", product.UpdatedBy)
+	})
+
+	t.Run("Update conflict and not found", func(t *testing.T) {
+		err := productRepository.Update(domain.Product{Id: 2, Name: "iron", Price: 1500.0, Sku: "AIR-1"})
+		assert.IsType(t, persistence.ConflictError{}, err)
+
+		err = productRepository.Update(domain.Product{Id: 99, Name: "ghost", Price: 1.0})
+		assert.IsType(t, persistence.NotFoundError{}, err)
+	})
+
 	clearSetup(ctx, dbPool)
 }
 
@@ -173,10 +383,32 @@ func TestUpdateProductPriceNotFound(t *testing.T) {
 	setup(ctx, dbPool)
 
 	t.Run("UpdateProductPriceNotFound", func(t *testing.T) {
-		err := productRepository.UpdateProductPrice(100, 4000.0)
+		err := productRepository.UpdateProductPrice(100, 4000.0, "user-1")
 		assert.NotNil(t, err)
 		assert.Equal(t, fmt.Sprintf("Product with id %d not found", 100), err.Error())
 	})
 
 	clearSetup(ctx, dbPool)
 }
+
+func TestGetAllByAttributeFilter(t *testing.T) {
+	productRepository.Add(domain.Product{Name: "small heater", Price: 500.0, Store: "ABC TECH", Category: "heaters", Attributes: map[string]interface{}{"wattage": 400, "plug": "EU"}})
+	productRepository.Add(domain.Product{Name: "big heater", Price: 900.0, Store: "ABC TECH", Category: "heaters", Attributes: map[string]interface{}{"wattage": 2000, "plug": "UK"}})
+	productRepository.Add(domain.Product{Name: "unrated heater", Price: 300.0, Store: "ABC TECH", Category: "heaters", Attributes: map[string]interface{}{"wattage": "high", "plug": "US"}})
+
+	t.Run("GetAllByAttributeFilter", func(t *testing.T) {
+		products, _ := productRepository.GetAllByFilter(domain.ProductFilter{AttributeConditions: []domain.AttributeCondition{
+			{Name: "wattage", Operator: domain.FilterOperatorGte, Value: "500", Type: domain.AttributeTypeNumber},
+		}})
+		assert.Equal(t, 1, len(products))
+		assert.Equal(t, "big heater", products[0].Name)
+
+		products, _ = productRepository.GetAllByFilter(domain.ProductFilter{AttributeConditions: []domain.AttributeCondition{
+			{Name: "plug", Operator: domain.FilterOperatorEq, Value: "EU", Type: domain.AttributeTypeString},
+		}})
+		assert.Equal(t, 1, len(products))
+		assert.Equal(t, "small heater", products[0].Name)
+	})
+
+	clearSetup(ctx, dbPool)
+}
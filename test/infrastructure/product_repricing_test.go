@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"testing"
+)
+
+func TestReprice(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("changes the matching products and records their history", func(t *testing.T) {
+		repricing, changes, err := productRepository.Reprice(domain.Repricing{
+			Filter:    domain.ProductFilter{Store: "ABC TECH"},
+			Operation: domain.RepricingOperation{Type: domain.RepricingPercentChange, Value: 10},
+			CreatedBy: "finance",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.RepricingStatusApplied, repricing.Status)
+		assert.Equal(t, 3, repricing.Affected)
+		assert.Equal(t, "ABC TECH", repricing.Filter.Store)
+		assert.Equal(t, []int64{1, 2, 3}, []int64{changes[0].ProductId, changes[1].ProductId, changes[2].ProductId})
+
+		product, _ := productRepository.GetById(1)
+		assert.Equal(t, float32(3300), product.Price)
+		assert.Equal(t, "finance", product.UpdatedBy)
+
+		history, err := productRepository.GetPriceHistory(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(history))
+		assert.Equal(t, float32(3000), history[0].OldPrice)
+		assert.Equal(t, float32(3300), history[0].NewPrice)
+		assert.Equal(t, repricing.Id, history[0].RepricingId)
+		assert.Equal(t, "air", history[0].ProductName)
+	})
+
+	t.Run("writes nothing when a price would not stay positive", func(t *testing.T) {
+		_, _, err := productRepository.Reprice(domain.Repricing{
+			Filter:    domain.ProductFilter{Store: "ABC TECH"},
+			Operation: domain.RepricingOperation{Type: domain.RepricingAbsoluteChange, Value: -2000},
+		})
+		assert.NotNil(t, err)
+		product, _ := productRepository.GetById(1)
+		assert.Equal(t, float32(3300), product.Price)
+	})
+
+	t.Run("undo skips products changed since", func(t *testing.T) {
+		assert.Nil(t, productRepository.UpdateProductPrice(2, 1700, "editor"))
+
+		repricing, reverted, err := productRepository.UndoRepricing(1, "finance")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.RepricingStatusUndone, repricing.Status)
+		assert.Equal(t, 2, repricing.Reverted)
+		assert.Equal(t, 1, repricing.Skipped)
+		assert.Equal(t, 2, len(reverted))
+		assert.NotNil(t, repricing.UndoneAt)
+
+		air, _ := productRepository.GetById(1)
+		assert.Equal(t, float32(3000), air.Price)
+		iron, _ := productRepository.GetById(2)
+		assert.Equal(t, float32(1700), iron.Price)
+
+		history, _ := productRepository.GetPriceHistory(1)
+		assert.Equal(t, 2, len(history))
+		assert.Equal(t, domain.PriceChangeReasonUndo, history[1].Reason)
+		history, _ = productRepository.GetPriceHistory(2)
+		assert.Equal(t, 2, len(history))
+		assert.Equal(t, domain.PriceChangeReasonUpdate, history[1].Reason)
+		assert.Equal(t, float32(1700), history[1].NewPrice)
+
+		_, _, err = productRepository.UndoRepricing(1, "finance")
+		assert.IsType(t, persistence.ConflictError{}, err)
+		_, err = productRepository.GetRepricingById(7)
+		assert.IsType(t, persistence.NotFoundError{}, err)
+	})
+
+	clearSetup(ctx, dbPool)
+}
@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	productRepository.Add(domain.Product{Name: "steam iron", Description: "ceramic soleplate", Price: 1500.0, Store: "ABC TECH"})
+	productRepository.Add(domain.Product{Name: "ironing board", Description: "fits a steam iron", Price: 700.0, Store: "ABC TECH"})
+	productRepository.Add(domain.Product{Name: "travel iron", Price: 900.0, Store: "x brand"})
+	productRepository.Add(domain.Product{Name: "fax", Price: 10000.0, Store: "ABC TECH"})
+
+	t.Run("name matches rank first", func(t *testing.T) {
+		result, err := productRepository.Search(domain.ProductSearchQuery{Text: "steam", Language: domain.SearchLanguageEnglish, Limit: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), result.Total)
+		assert.Equal(t, int64(1), result.Hits[0].Product.Id)
+		assert.Contains(t, result.Hits[0].Snippet, "<b>steam</b>")
+	})
+
+	t.Run("snippet escapes the product text", func(t *testing.T) {
+		productRepository.Add(domain.Product{Name: "steam cleaner", Description: `<img src=x onerror="alert(1)"> & more`, Price: 300.0, Store: "x brand", Sku: "CLEAN-1"})
+
+		result, err := productRepository.Search(domain.ProductSearchQuery{Text: "cleaner", Language: domain.SearchLanguageEnglish, Limit: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result.Hits))
+		assert.NotContains(t, result.Hits[0].Snippet, "<img")
+		assert.Contains(t, result.Hits[0].Snippet, "&lt;img")
+		assert.Contains(t, result.Hits[0].Snippet, "<b>cleaner</b>")
+		productRepository.DeleteById(result.Hits[0].Product.Id, "")
+	})
+
+	t.Run("store filter and pagination", func(t *testing.T) {
+		result, err := productRepository.Search(domain.ProductSearchQuery{Text: "iron", Language: domain.SearchLanguageEnglish, Filter: domain.ProductFilter{Store: "ABC TECH"}, Limit: 1, Offset: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), result.Total)
+		assert.Equal(t, 1, len(result.Hits))
+	})
+
+	t.Run("typos match by trigram", func(t *testing.T) {
+		result, err := productRepository.Search(domain.ProductSearchQuery{Text: "trvel", Language: domain.SearchLanguageEnglish, Limit: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result.Hits))
+		assert.Equal(t, "travel iron", result.Hits[0].Product.Name)
+	})
+
+	t.Run("translations are searched", func(t *testing.T) {
+		productRepository.Add(domain.Product{Name: "buharlı ütü", Description: "seramik taban", Price: 1200.0, Store: "ABC TECH",
+			Translations: map[string]domain.LocalizedText{
+				"tr": {Name: "buharlı ütü", Description: "seramik taban"},
+				"en": {Name: "vertical steamer", Description: "ceramic plate"},
+			}})
+
+		result, err := productRepository.Search(domain.ProductSearchQuery{Text: "steamer", Language: domain.SearchLanguageEnglish, Limit: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result.Hits))
+		assert.Equal(t, "buharlı ütü", result.Hits[0].Product.Name)
+		assert.Contains(t, result.Hits[0].Snippet, "<b>steamer</b>")
+	})
+
+	clearSetup(ctx, dbPool)
+}
@@ -0,0 +1,41 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func TestGetStats(t *testing.T) {
+	setup(ctx, dbPool)
+
+	t.Run("per store", func(t *testing.T) {
+		groups, err := productRepository.GetStats(domain.ProductStatsQuery{GroupBy: []string{domain.StatsDimensionStore}})
+		assert.Nil(t, err)
+		assert.Equal(t, []domain.ProductStatsGroup{
+			{Keys: map[string]string{"store": "ABC TECH"}, Count: 3, AveragePrice: 4833.33, MinPrice: 1500.0, MaxPrice: 10000.0, AverageDiscount: 15.67},
+			{Keys: map[string]string{"store": "x brand"}, Count: 1, AveragePrice: 2000.0, MinPrice: 2000.0, MaxPrice: 2000.0, AverageDiscount: 0},
+		}, groups)
+	})
+
+	t.Run("per discount band with filter", func(t *testing.T) {
+		groups, err := productRepository.GetStats(domain.ProductStatsQuery{
+			GroupBy: []string{domain.StatsDimensionDiscountBand},
+			Filter:  domain.ProductFilter{Store: "ABC TECH"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(groups))
+		assert.Equal(t, map[string]string{"discount_band": "10-20"}, groups[0].Keys)
+		assert.Equal(t, int64(2), groups[0].Count)
+		assert.Equal(t, map[string]string{"discount_band": "20-30"}, groups[1].Keys)
+	})
+
+	t.Run("without dimensions", func(t *testing.T) {
+		groups, err := productRepository.GetStats(domain.ProductStatsQuery{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(groups))
+		assert.Equal(t, int64(4), groups[0].Count)
+	})
+
+	clearSetup(ctx, dbPool)
+}
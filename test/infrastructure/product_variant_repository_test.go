@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"testing"
+)
+
+func TestAddAndGetVariants(t *testing.T) {
+	productVariantRepository := persistence.NewProductVariantRepository(dbPool)
+	productRepository.Add(domain.Product{Name: "t-shirt", Price: 200.0, Store: "fashion", Options: []string{"size", "colour"}})
+
+	t.Run("AddVariant", func(t *testing.T) {
+		err := productVariantRepository.Add(domain.ProductVariant{ProductId: 1, Sku: "TS-M-RED", Stock: 5, Attributes: map[string]string{"size": "M", "colour": "red"}})
+		assert.Nil(t, err)
+
+		err = productVariantRepository.Add(domain.ProductVariant{ProductId: 1, Attributes: map[string]string{"size": "M", "colour": "red"}})
+		assert.IsType(t, persistence.ConflictError{}, err)
+
+		productVariants, _ := productVariantRepository.GetByProductId(1)
+		assert.Equal(t, []domain.ProductVariant{
+			{Id: 1, ProductId: 1, Sku: "TS-M-RED", Stock: 5, Attributes: map[string]string{"size": "M", "colour": "red"}},
+		}, productVariants)
+	})
+
+	t.Run("KeepsSkusUniqueInTheStore", func(t *testing.T) {
+		productRepository.Add(domain.Product{Name: "polo", Price: 300.0, Store: "fashion", Sku: "POLO-1", Options: []string{"size", "colour"}})
+
+		err := productVariantRepository.Add(domain.ProductVariant{ProductId: 2, Sku: "TS-M-RED", Attributes: map[string]string{"size": "M", "colour": "red"}})
+		assert.IsType(t, persistence.ConflictError{}, err)
+		err = productVariantRepository.Add(domain.ProductVariant{ProductId: 1, Sku: "POLO-1", Attributes: map[string]string{"size": "L", "colour": "red"}})
+		assert.IsType(t, persistence.ConflictError{}, err)
+
+		_, err = productRepository.Add(domain.Product{Name: "t-shirt red", Price: 200.0, Store: "fashion", Sku: "TS-M-RED"})
+		assert.Equal(t, "Sku TS-M-RED is used by a variant in store fashion", err.Error())
+		_, err = productRepository.Add(domain.Product{Name: "t-shirt red", Price: 200.0, Store: "ABC TECH", Sku: "TS-M-RED"})
+		assert.Nil(t, err)
+	})
+
+	t.Run("GetAllByVariantFilter", func(t *testing.T) {
+		products, _ := productRepository.GetAllByFilter(domain.ProductFilter{VariantAttributes: map[string]string{"size": "M"}})
+		assert.Equal(t, 1, len(products))
+		assert.Equal(t, []string{"size", "colour"}, products[0].Options)
+
+		products, _ = productRepository.GetAllByFilter(domain.ProductFilter{VariantAttributes: map[string]string{"size": "S"}})
+		assert.Equal(t, 0, len(products))
+	})
+
+	clearSetup(ctx, dbPool)
+}
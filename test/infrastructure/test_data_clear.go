@@ -7,7 +7,7 @@ import (
 )
 
 func TruncateTestData(ctx context.Context, dbPool *pgxpool.Pool) {
-	_, truncateResultErr := dbPool.Exec(ctx, "TRUNCATE products RESTART IDENTITY")
+	_, truncateResultErr := dbPool.Exec(ctx, "TRUNCATE products, price_schedules, promotions, product_variants, attribute_definitions, product_media, product_translations, product_status_history, jobs, repricings, price_history, outbox, webhooks, webhook_deliveries RESTART IDENTITY")
 	if truncateResultErr != nil {
 		log.Error(truncateResultErr)
 	} else {
@@ -0,0 +1,96 @@
+package infrastructure
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"testing"
+	"time"
+)
+
+func TestWebhooks(t *testing.T) {
+	webhookRepository := persistence.NewWebhookRepository(dbPool)
+	clearSetup(ctx, dbPool)
+
+	event := domain.ProductEvent{EventId: "8a1c4c1e-62a8-4c36-9f0e-1b0f5f7c2d11", Type: domain.ProductEventPriceChanged, ProductId: 1}
+
+	t.Run("queues an event once for every matching active webhook", func(t *testing.T) {
+		all, err := webhookRepository.Add(domain.Webhook{Url: "https://example.com/all", EventTypes: []string{domain.ProductEventPriceChanged}, Secret: "s1", CreatedBy: "admin"})
+		assert.Nil(t, err)
+		assert.True(t, all.Active)
+		webhookRepository.Add(domain.Webhook{Url: "https://example.com/brand", EventTypes: []string{domain.ProductEventPriceChanged}, Store: "x brand", Secret: "s2"})
+		webhookRepository.Add(domain.Webhook{Url: "https://example.com/created", EventTypes: []string{domain.ProductEventCreated}, Secret: "s3"})
+
+		queued, err := webhookRepository.EnqueueDeliveries(event, "ABC TECH", `{"id":"e"}`)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), queued)
+		queued, _ = webhookRepository.EnqueueDeliveries(event, "ABC TECH", `{"id":"e"}`)
+		assert.Equal(t, int64(0), queued)
+
+		deliveries, err := webhookRepository.GetDeliveries(all.Id, "", 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(deliveries))
+		assert.Equal(t, event.EventId, deliveries[0].EventId)
+		assert.Equal(t, domain.WebhookDeliveryPending, deliveries[0].Status)
+	})
+
+	t.Run("claims a due delivery for one dispatcher at a time", func(t *testing.T) {
+		claimed, err := webhookRepository.ClaimDueDeliveries(10, time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(claimed))
+		assert.Equal(t, "https://example.com/all", claimed[0].Url)
+		assert.Equal(t, "s1", claimed[0].Secret)
+
+		claimed, _ = webhookRepository.ClaimDueDeliveries(10, time.Minute)
+		assert.Equal(t, 0, len(claimed))
+	})
+
+	t.Run("deactivates a webhook after consecutive failures", func(t *testing.T) {
+		delivery := domain.WebhookDelivery{Id: 1, WebhookId: 1, Status: domain.WebhookDeliveryPending, NextAttempt: time.Now().Add(time.Minute), LastStatusCode: 500, LastError: "Receiver responded with status 500"}
+		assert.Nil(t, webhookRepository.RecordAttempt(delivery, 2))
+		webhook, _ := webhookRepository.GetById(1)
+		assert.True(t, webhook.Active)
+		assert.Equal(t, 1, webhook.ConsecutiveFailures)
+
+		assert.Nil(t, webhookRepository.RecordAttempt(delivery, 2))
+		webhook, _ = webhookRepository.GetById(1)
+		assert.False(t, webhook.Active)
+		assert.NotNil(t, webhook.DisabledAt)
+
+		deliveries, _ := webhookRepository.GetDeliveries(1, domain.WebhookDeliveryPending, 10)
+		assert.Equal(t, 2, deliveries[0].Attempts)
+		assert.Equal(t, 500, deliveries[0].LastStatusCode)
+
+		redelivered, err := webhookRepository.Redeliver(1, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, redelivered.Attempts)
+		claimed, _ := webhookRepository.ClaimDueDeliveries(10, time.Minute)
+		assert.Equal(t, 0, len(claimed))
+	})
+
+	t.Run("sends waiting deliveries once activated again", func(t *testing.T) {
+		webhook, err := webhookRepository.Update(domain.Webhook{Id: 1, Url: "https://example.com/all", EventTypes: []string{domain.ProductEventPriceChanged}, Active: true})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, webhook.ConsecutiveFailures)
+		assert.Equal(t, "s1", webhook.Secret)
+
+		claimed, _ := webhookRepository.ClaimDueDeliveries(10, time.Minute)
+		assert.Equal(t, 1, len(claimed))
+		assert.Nil(t, webhookRepository.RecordAttempt(domain.WebhookDelivery{Id: claimed[0].Id, WebhookId: 1, Status: domain.WebhookDeliverySucceeded, NextAttempt: time.Now(), LastStatusCode: 200}, 2))
+
+		deliveries, _ := webhookRepository.GetDeliveries(1, domain.WebhookDeliverySucceeded, 10)
+		assert.Equal(t, 1, len(deliveries))
+		assert.NotNil(t, deliveries[0].DeliveredAt)
+	})
+
+	t.Run("returns not found for unknown webhooks and deliveries", func(t *testing.T) {
+		_, err := webhookRepository.GetById(99)
+		assert.Equal(t, "Webhook with id 99 not found", err.Error())
+		_, err = webhookRepository.Redeliver(2, 1)
+		assert.Equal(t, "Delivery with id 1 of webhook 2 not found", err.Error())
+		assert.Nil(t, webhookRepository.DeleteById(1))
+		assert.Equal(t, "Webhook with id 1 not found", webhookRepository.DeleteById(1).Error())
+	})
+
+	clearSetup(ctx, dbPool)
+}
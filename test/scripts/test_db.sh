@@ -9,15 +9,395 @@ sleep 3
 echo "productapp database created"
 
 docker exec -it postgres-db psql -U postgres -d productapp -c "
+create extension if not exists pg_trgm;
 create table if not exists products
 (
   id bigserial not null primary key,
   name varchar(255) not null,
+  description text not null default '',
   price double precision not null,
   discount double precision,
-  store varchar(255) not null
+  store varchar(255) not null,
+  category varchar(255) not null default '',
+  status varchar(20) not null default 'active' check (status in ('draft', 'active', 'discontinued', 'archived')),
+  sku varchar(64),
+  gtin varchar(14),
+  options text[],
+  attributes jsonb not null default '{}',
+  deleted_at timestamptz,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  created_by varchar(255) not null default '',
+  updated_by varchar(255) not null default '',
+  search_vector_english tsvector generated always as (
+    setweight(to_tsvector('english', name), 'A') || setweight(to_tsvector('english', description), 'B') || setweight(to_tsvector('english', category), 'C')
+  ) stored,
+  search_vector_turkish tsvector generated always as (
+    setweight(to_tsvector('turkish', name), 'A') || setweight(to_tsvector('turkish', description), 'B') || setweight(to_tsvector('turkish', category), 'C')
+  ) stored
 );
+create unique index if not exists products_store_sku_key on products (store, sku) where deleted_at is null;
+create unique index if not exists products_store_gtin_key on products (store, gtin) where deleted_at is null;
+create index if not exists products_deleted_at_idx on products (deleted_at) where deleted_at is not null;
+create index if not exists products_attributes_idx on products using gin (attributes);
+create index if not exists products_status_idx on products (status);
+create index if not exists products_updated_at_idx on products (updated_at);
+create index if not exists products_search_english_idx on products using gin (search_vector_english);
+create index if not exists products_search_turkish_idx on products using gin (search_vector_turkish);
+create index if not exists products_name_trgm_idx on products using gin (name gin_trgm_ops);
 "
 sleep 3
 echo "products table created"
 
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists price_schedules
+(
+  id bigserial not null primary key,
+  product_id bigint not null references products (id) on delete cascade,
+  price double precision,
+  discount double precision,
+  effective_from timestamptz not null,
+  effective_to timestamptz,
+  status varchar(20) not null default 'pending'
+);
+create index if not exists price_schedules_status_effective_from_idx on price_schedules (status, effective_from);
+"
+sleep 3
+echo "price_schedules table created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists promotions
+(
+  id bigserial not null primary key,
+  name varchar(255) not null,
+  type varchar(20) not null,
+  store varchar(255) not null default '',
+  category varchar(255) not null default '',
+  min_price double precision,
+  max_price double precision,
+  percent double precision not null default 0,
+  buy_quantity integer not null default 0,
+  free_quantity integer not null default 0,
+  priority integer not null default 0,
+  stackable boolean not null default false,
+  valid_from timestamptz not null,
+  valid_to timestamptz
+);
+"
+sleep 3
+echo "promotions table created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists product_variants
+(
+  id bigserial not null primary key,
+  product_id bigint not null references products (id) on delete cascade,
+  sku varchar(64),
+  price_override double precision,
+  stock integer not null default 0,
+  attributes jsonb not null default '{}'
+);
+create unique index if not exists product_variants_product_attributes_key on product_variants (product_id, attributes);
+create index if not exists product_variants_attributes_idx on product_variants using gin (attributes jsonb_path_ops);
+"
+sleep 3
+echo "product_variants table created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists attribute_definitions
+(
+  id bigserial not null primary key,
+  category varchar(255) not null,
+  name varchar(63) not null,
+  type varchar(20) not null,
+  required boolean not null default false,
+  enum_values text[],
+  unit varchar(20) not null default '',
+  unique (category, name)
+);
+"
+sleep 3
+echo "attribute_definitions table created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists product_media
+(
+  id bigserial not null primary key,
+  product_id bigint not null references products (id) on delete cascade,
+  key varchar(255) not null,
+  thumbnail_key varchar(255) not null,
+  content_type varchar(100) not null,
+  size bigint not null,
+  width integer not null,
+  height integer not null,
+  checksum char(64) not null,
+  position integer not null default 0,
+  is_primary boolean not null default false,
+  unique (product_id, checksum)
+);
+create unique index if not exists product_media_primary_key on product_media (product_id) where is_primary;
+"
+sleep 3
+echo "product_media table created"
+
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists product_translations
+(
+  product_id bigint not null references products (id) on delete cascade,
+  locale varchar(35) not null,
+  name varchar(255) not null,
+  description text not null default '',
+  primary key (product_id, locale)
+);
+"
+sleep 3
+echo "product_translations table created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists product_status_history
+(
+  id bigserial not null primary key,
+  product_id bigint not null references products (id) on delete cascade,
+  from_status varchar(20) not null,
+  to_status varchar(20) not null,
+  actor varchar(255) not null,
+  reason text not null default '',
+  changed_at timestamptz not null default now()
+);
+create index if not exists product_status_history_product_idx on product_status_history (product_id, changed_at);
+"
+sleep 3
+echo "product_status_history table created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists jobs
+(
+  id bigserial not null primary key,
+  type varchar(64) not null,
+  status varchar(20) not null default 'queued' check (status in ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+  payload jsonb not null default '{}',
+  result jsonb,
+  error text not null default '',
+  progress_done bigint not null default 0,
+  progress_total bigint not null default 0,
+  attempts int not null default 0,
+  max_attempts int not null default 3,
+  run_after timestamptz not null default now(),
+  lease_expires_at timestamptz,
+  cancel_requested boolean not null default false,
+  created_by varchar(255) not null default '',
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  started_at timestamptz,
+  finished_at timestamptz
+);
+create index if not exists jobs_queued_idx on jobs (run_after, id) where status = 'queued';
+create index if not exists jobs_running_idx on jobs (lease_expires_at) where status = 'running';
+"
+sleep 3
+echo "jobs table created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists repricings
+(
+  id bigserial not null primary key,
+  filter jsonb not null,
+  operation jsonb not null,
+  status varchar(20) not null check (status in ('applied', 'undone')),
+  affected int not null default 0,
+  reverted int not null default 0,
+  skipped int not null default 0,
+  created_by varchar(255) not null default '',
+  created_at timestamptz not null default now(),
+  undone_by varchar(255) not null default '',
+  undone_at timestamptz
+);
+create table if not exists price_history
+(
+  id bigserial not null primary key,
+  product_id bigint not null references products (id) on delete cascade,
+  repricing_id bigint references repricings (id),
+  reason varchar(20) not null,
+  old_price double precision not null,
+  new_price double precision not null,
+  old_discount double precision not null,
+  new_discount double precision not null,
+  actor varchar(255) not null default '',
+  changed_at timestamptz not null default now()
+);
+create index if not exists price_history_product_idx on price_history (product_id, changed_at);
+create index if not exists price_history_repricing_idx on price_history (repricing_id);
+"
+sleep 3
+echo "repricings and price_history tables created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists outbox
+(
+  id bigserial not null primary key,
+  event_id uuid not null default gen_random_uuid() unique,
+  aggregate_id bigint not null,
+  event_type varchar(64) not null,
+  payload jsonb not null,
+  occurred_at timestamptz not null default now(),
+  attempts int not null default 0,
+  next_attempt_at timestamptz not null default now(),
+  last_error text not null default '',
+  published_at timestamptz
+);
+create index if not exists outbox_pending_idx on outbox (aggregate_id, id) where published_at is null;
+"
+sleep 3
+echo "outbox table created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create table if not exists webhooks
+(
+  id bigserial not null primary key,
+  url varchar(2048) not null,
+  event_types text[] not null,
+  store varchar(255) not null default '',
+  secret varchar(255) not null,
+  active boolean not null default true,
+  consecutive_failures int not null default 0,
+  disabled_at timestamptz,
+  created_by varchar(255) not null default '',
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+create table if not exists webhook_deliveries
+(
+  id bigserial not null primary key,
+  webhook_id bigint not null references webhooks (id) on delete cascade,
+  event_id uuid not null,
+  event_type varchar(64) not null,
+  product_id bigint not null,
+  body text not null,
+  status varchar(20) not null default 'pending',
+  attempts int not null default 0,
+  next_attempt_at timestamptz not null default now(),
+  last_status_code int not null default 0,
+  last_error text not null default '',
+  created_at timestamptz not null default now(),
+  delivered_at timestamptz,
+  unique (webhook_id, event_id)
+);
+create index if not exists webhook_deliveries_due_idx on webhook_deliveries (next_attempt_at) where status = 'pending';
+"
+sleep 3
+echo "webhooks and webhook_deliveries tables created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create or replace function notify_product_change() returns trigger as \$\$
+declare
+  changed products;
+  operation text;
+begin
+  if TG_OP = 'DELETE' then
+    changed := OLD;
+    operation := 'delete';
+  else
+    changed := NEW;
+    operation := case when TG_OP = 'INSERT' then 'insert' when NEW.deleted_at is not null then 'delete' else 'update' end;
+  end if;
+  perform pg_notify('product_changes', json_build_object('op', operation, 'id', changed.id, 'store', changed.store)::text);
+  return null;
+end;
+\$\$ language plpgsql;
+drop trigger if exists products_notify_change on products;
+create trigger products_notify_change after insert or update or delete on products for each row execute function notify_product_change();
+"
+sleep 3
+echo "products_notify_change trigger created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create or replace function notify_product_event_published() returns trigger as \$\$
+begin
+  perform pg_notify('product_events', NEW.id::text);
+  return null;
+end;
+\$\$ language plpgsql;
+drop trigger if exists outbox_notify_published on outbox;
+create trigger outbox_notify_published after update of published_at on outbox for each row
+  when (OLD.published_at is null and NEW.published_at is not null) execute function notify_product_event_published();
+"
+sleep 3
+echo "outbox_notify_published trigger created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create or replace function touch_product() returns trigger as \$\$
+begin
+  if TG_OP = 'DELETE' then
+    update products set updated_at = now() where id = OLD.product_id;
+  else
+    update products set updated_at = now() where id = NEW.product_id;
+  end if;
+  return null;
+end;
+\$\$ language plpgsql;
+drop trigger if exists product_variants_touch_product on product_variants;
+create trigger product_variants_touch_product after insert or update or delete on product_variants for each row execute function touch_product();
+drop trigger if exists product_media_touch_product on product_media;
+create trigger product_media_touch_product after insert or update or delete on product_media for each row execute function touch_product();
+"
+sleep 3
+echo "product_variants_touch_product and product_media_touch_product triggers created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+drop index if exists product_variants_product_sku_key;
+create or replace function check_store_sku() returns trigger as \$\$
+declare
+  sku_store varchar;
+begin
+  if NEW.sku is null then
+    return NEW;
+  end if;
+  if TG_TABLE_NAME = 'products' then
+    if NEW.deleted_at is not null then
+      return NEW;
+    end if;
+    sku_store := NEW.store;
+  else
+    select store into sku_store from products where id = NEW.product_id;
+  end if;
+  -- serializes the writers of a sku in a store, the check below then sees the rows committed before
+  perform pg_advisory_xact_lock(hashtext(sku_store || '/' || NEW.sku));
+  -- products among themselves are covered by products_store_sku_key
+  if (TG_TABLE_NAME = 'product_variants' and exists (
+        select 1 from products where store = sku_store and sku = NEW.sku and deleted_at is null))
+    or exists (
+        select 1 from product_variants join products on products.id = product_variants.product_id
+        where products.store = sku_store and product_variants.sku = NEW.sku and products.deleted_at is null
+          and (TG_TABLE_NAME = 'products' or product_variants.id <> NEW.id)) then
+    raise exception 'Sku % already exists in store %', NEW.sku, sku_store
+      using errcode = 'unique_violation', constraint = 'store_sku_key';
+  end if;
+  return NEW;
+end;
+\$\$ language plpgsql;
+drop trigger if exists products_check_store_sku on products;
+create trigger products_check_store_sku before insert or update of sku, store, deleted_at on products for each row execute function check_store_sku();
+drop trigger if exists product_variants_check_store_sku on product_variants;
+create trigger product_variants_check_store_sku before insert or update of sku, product_id on product_variants for each row execute function check_store_sku();
+"
+sleep 3
+echo "products_check_store_sku and product_variants_check_store_sku triggers created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+create index if not exists outbox_published_idx on outbox (published_at) where published_at is not null;
+"
+sleep 3
+echo "outbox_published_idx index created"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+alter table price_schedules add column if not exists opened_at timestamptz;
+"
+sleep 3
+echo "price_schedules opened_at column added"
+
+docker exec -it postgres-db psql -U postgres -d productapp -c "
+alter table promotions add column if not exists bundle_product_ids bigint[] not null default '{}';
+"
+sleep 3
+echo "promotions bundle_product_ids column added"
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/search"
+	"go-product-app/domain"
+	"testing"
+)
+
+func newTestIndex() search.SearchIndex {
+	searchIndex := search.NewMemoryIndex(search.DefaultPriceBuckets)
+	searchIndex.Rebuild([]domain.Product{
+		{Id: 1, Name: "Steam Iron", Description: "ceramic soleplate", Price: 1500.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 2, Name: "travel iron", Price: 90.0, Store: "x brand", Status: domain.ProductStatusActive},
+		{Id: 3, Name: "ironing board", Description: "fits a steam iron", Price: 700.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 4, Name: "fax", Price: 10000.0, Store: "ABC TECH", Status: domain.ProductStatusDraft},
+	})
+	return searchIndex
+}
+
+func hitIds(result search.Result) []int64 {
+	ids := make([]int64, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.Product.Id)
+	}
+	return ids
+}
+
+func Test_Tokenize(t *testing.T) {
+	t.Run("Tokenize", func(t *testing.T) {
+		assert.Equal(t, []string{"steam", "iron", "2000w", "ütü"}, search.Tokenize("Steam-Iron, 2000W (ütü)"))
+	})
+}
+
+func Test_Search(t *testing.T) {
+	testCases := []struct {
+		name        string
+		query       search.Query
+		expectedIds []int64
+	}{
+		{"name matches rank above description matches", search.Query{Text: "steam"}, []int64{1, 3}},
+		{"prefix matches", search.Query{Text: "iro"}, []int64{1, 2, 3}},
+		{"every word has to match", search.Query{Text: "iron trav"}, []int64{2}},
+		{"no match", search.Query{Text: "kettle"}, []int64{}},
+		{"store filter", search.Query{Text: "iron", Store: "x brand"}, []int64{2}},
+		{"price bucket filter", search.Query{Text: "iron", PriceBucket: "500-1000"}, []int64{3}},
+		{"status filter", search.Query{Statuses: []string{domain.ProductStatusDraft}}, []int64{4}},
+		{"pagination", search.Query{Text: "iron", Limit: 1, Offset: 1}, []int64{2}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedIds, hitIds(newTestIndex().Search(testCase.query)))
+		})
+	}
+}
+
+func Test_Search_ShouldCountFacetsIgnoringTheirOwnFilter(t *testing.T) {
+	t.Run("Search", func(t *testing.T) {
+		result := newTestIndex().Search(search.Query{Text: "iron", Store: "ABC TECH"})
+		assert.Equal(t, 2, result.Total)
+		assert.Equal(t, []search.FacetCount{{Value: "ABC TECH", Count: 2}, {Value: "x brand", Count: 1}}, result.StoreFacets)
+		assert.Equal(t, []search.FacetCount{{Value: "500-1000", Count: 1}, {Value: "1000-5000", Count: 1}}, result.PriceFacets)
+	})
+}
+
+func Test_Index_ShouldReplaceAndRemoveProducts(t *testing.T) {
+	t.Run("Index", func(t *testing.T) {
+		searchIndex := newTestIndex()
+		searchIndex.Index(domain.Product{Id: 2, Name: "travel kettle", Price: 90.0, Store: "x brand", Status: domain.ProductStatusActive})
+		assert.Equal(t, []int64{1, 3}, hitIds(searchIndex.Search(search.Query{Text: "iron"})))
+		assert.Equal(t, []int64{2}, hitIds(searchIndex.Search(search.Query{Text: "kettle"})))
+
+		searchIndex.Remove(2)
+		assert.Equal(t, []int64{}, hitIds(searchIndex.Search(search.Query{Text: "travel"})))
+		assert.Equal(t, 3, searchIndex.Search(search.Query{}).Total)
+	})
+}
+
+func Test_Rebuild_ShouldReplaceTheIndexAndKeepPrefixLookups(t *testing.T) {
+	t.Run("Rebuild", func(t *testing.T) {
+		searchIndex := newTestIndex()
+		searchIndex.Rebuild([]domain.Product{
+			{Id: 5, Name: "kettle", Price: 300.0, Store: "x brand", Status: domain.ProductStatusActive},
+			{Id: 6, Name: "iron stand", Price: 50.0, Store: "x brand", Status: domain.ProductStatusActive},
+		})
+		assert.Equal(t, []int64{6}, hitIds(searchIndex.Search(search.Query{Text: "iro"})))
+
+		searchIndex.Index(domain.Product{Id: 7, Name: "ironing mat", Price: 80.0, Store: "x brand", Status: domain.ProductStatusActive})
+		assert.Equal(t, []int64{6, 7}, hitIds(searchIndex.Search(search.Query{Text: "iron"})))
+		assert.Equal(t, []int64{5}, hitIds(searchIndex.Search(search.Query{Text: "ket"})))
+	})
+}
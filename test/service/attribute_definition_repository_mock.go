@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+)
+
+type AttributeDefinitionRepositoryMock struct {
+	attributeDefinitions []domain.AttributeDefinition
+}
+
+func NewAttributeDefinitionRepositoryMock(initialAttributeDefinitions []domain.AttributeDefinition) persistence.IAttributeDefinitionRepository {
+	return &AttributeDefinitionRepositoryMock{attributeDefinitions: initialAttributeDefinitions}
+}
+
+func (attributeDefinitionRepository *AttributeDefinitionRepositoryMock) Add(attributeDefinition domain.AttributeDefinition) error {
+	for _, existing := range attributeDefinitionRepository.attributeDefinitions {
+		if existing.Category == attributeDefinition.Category && existing.Name == attributeDefinition.Name {
+			return persistence.ConflictError{Message: fmt.Sprintf("Attribute %s is already defined for category %s", attributeDefinition.Name, attributeDefinition.Category)}
+		}
+	}
+	attributeDefinition.Id = int64(len(attributeDefinitionRepository.attributeDefinitions) + 1)
+	attributeDefinitionRepository.attributeDefinitions = append(attributeDefinitionRepository.attributeDefinitions, attributeDefinition)
+	return nil
+}
+
+func (attributeDefinitionRepository *AttributeDefinitionRepositoryMock) GetAll() ([]domain.AttributeDefinition, error) {
+	return attributeDefinitionRepository.attributeDefinitions, nil
+}
+
+func (attributeDefinitionRepository *AttributeDefinitionRepositoryMock) GetByCategory(category string) ([]domain.AttributeDefinition, error) {
+	var attributeDefinitions []domain.AttributeDefinition
+	for _, attributeDefinition := range attributeDefinitionRepository.attributeDefinitions {
+		if attributeDefinition.Category == category {
+			attributeDefinitions = append(attributeDefinitions, attributeDefinition)
+		}
+	}
+
+	return attributeDefinitions, nil
+}
+
+func (attributeDefinitionRepository *AttributeDefinitionRepositoryMock) DeleteById(id int64) error {
+	for i, attributeDefinition := range attributeDefinitionRepository.attributeDefinitions {
+		if attributeDefinition.Id == id {
+			attributeDefinitionRepository.attributeDefinitions = append(attributeDefinitionRepository.attributeDefinitions[:i], attributeDefinitionRepository.attributeDefinitions[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Attribute definition with id %d not found", id))
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"testing"
+	"time"
+)
+
+func newCachedProducts() (persistence.IProductRepository, *persistence.CachingProductRepository) {
+	productRepository := NewProductRepositoryMock([]domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH", Options: []string{"white"}, Attributes: map[string]interface{}{"watts": 2000.0}},
+		{Id: 2, Name: "iron", Price: 1500.0, Store: "ABC TECH"},
+		{Id: 3, Name: "phone", Price: 2000.0, Store: "x brand"},
+	})
+	return productRepository, persistence.NewCachingProductRepository(productRepository, 10, 10, time.Minute)
+}
+
+func Test_CachingProductRepository_ShouldServeReadsFromMemory(t *testing.T) {
+	productRepository, productCache := newCachedProducts()
+
+	product, err := productCache.GetById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "air", product.Name)
+	products, err := productCache.GetAllByStore("ABC TECH")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(products))
+
+	// written behind the back of the cache, like another instance would
+	productRepository.UpdateProductPrice(1, 2500.0, "user-1")
+	product, _ = productCache.GetById(1)
+	assert.Equal(t, float32(3000.0), product.Price)
+	products, _ = productCache.GetAllByStore("ABC TECH")
+	assert.Equal(t, float32(3000.0), products[0].Price)
+
+	stats := productCache.Stats()
+	assert.Equal(t, int64(1), stats["products_by_id"].Hits)
+	assert.Equal(t, int64(1), stats["products_by_id"].Misses)
+	assert.Equal(t, int64(1), stats["products_by_store"].Hits)
+
+	t.Run("InvalidatesOnNotifiedChanges", func(t *testing.T) {
+		productCache.Invalidate(domain.ProductChange{Operation: domain.ProductChangeUpdate, ProductId: 1, Store: "ABC TECH"})
+		product, _ = productCache.GetById(1)
+		assert.Equal(t, float32(2500.0), product.Price)
+		products, _ = productCache.GetAllByStore("ABC TECH")
+		assert.Equal(t, float32(2500.0), products[0].Price)
+	})
+	t.Run("KeepsCachedProductsFromCallers", func(t *testing.T) {
+		product, _ = productCache.GetById(1)
+		product.Options[0] = "black"
+		product.Attributes["watts"] = 1.0
+
+		product, _ = productCache.GetById(1)
+		assert.Equal(t, []string{"white"}, product.Options)
+		assert.Equal(t, 2000.0, product.Attributes["watts"])
+	})
+	t.Run("DoesNotCacheMissingProducts", func(t *testing.T) {
+		_, err = productCache.GetById(9)
+		assert.NotNil(t, err)
+		productRepository.Add(domain.Product{Name: "fax", Price: 10000.0, Store: "ABC TECH"})
+		product, err = productCache.GetById(4)
+		assert.Nil(t, err)
+		assert.Equal(t, "fax", product.Name)
+	})
+}
+
+func Test_CachingProductRepository_ShouldInvalidateOnWrites(t *testing.T) {
+	testCases := []struct {
+		name  string
+		write func(productCache persistence.IProductRepository) error
+	}{
+		{"UpdateProductPrice", func(productCache persistence.IProductRepository) error {
+			return productCache.UpdateProductPrice(1, 2500.0, "user-1")
+		}},
+		{"UpdatePricesBatch", func(productCache persistence.IProductRepository) error {
+			_, err := productCache.UpdatePricesBatch([]domain.ProductPriceUpdate{{Id: 1, Price: 2500.0}}, "user-1", true)
+			return err
+		}},
+		{"Reprice", func(productCache persistence.IProductRepository) error {
+			_, _, err := productCache.Reprice(domain.Repricing{
+				Filter:    domain.ProductFilter{Store: "ABC TECH"},
+				Operation: domain.RepricingOperation{Type: domain.RepricingSetPrice, Value: 2500.0},
+			})
+			return err
+		}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, productCache := newCachedProducts()
+			productCache.GetById(1)
+			productCache.GetAllByStore("ABC TECH")
+
+			assert.Nil(t, testCase.write(productCache))
+
+			product, _ := productCache.GetById(1)
+			assert.Equal(t, float32(2500.0), product.Price)
+			products, _ := productCache.GetAllByStore("ABC TECH")
+			assert.Equal(t, float32(2500.0), products[0].Price)
+		})
+	}
+
+	t.Run("DeleteById", func(t *testing.T) {
+		_, productCache := newCachedProducts()
+		productCache.GetById(1)
+		productCache.GetAllByStore("ABC TECH")
+
+		assert.Nil(t, productCache.DeleteById(1, "user-1"))
+		_, err := productCache.GetById(1)
+		assert.NotNil(t, err)
+		products, _ := productCache.GetAllByStore("ABC TECH")
+		assert.Equal(t, 1, len(products))
+	})
+	t.Run("Add", func(t *testing.T) {
+		_, productCache := newCachedProducts()
+		productCache.GetAllByStore("x brand")
+
+		_, err := productCache.Add(domain.Product{Name: "tablet", Price: 4000.0, Store: "x brand"})
+		assert.Nil(t, err)
+		products, _ := productCache.GetAllByStore("x brand")
+		assert.Equal(t, 2, len(products))
+	})
+}
+
+func Test_CachingProductRepository_ShouldReadItsOwnWritesThroughTheService(t *testing.T) {
+	_, productCache := newCachedProducts()
+	productService := newProductTestServiceWith(productCache, NewPriceScheduleRepositoryMock(nil), nil)
+
+	product, _ := productService.GetById(2)
+	assert.Equal(t, float32(1500.0), product.Price)
+	assert.Nil(t, productService.UpdatePrice(2, 1200.0, "user-1"))
+
+	product, _ = productService.GetById(2)
+	assert.Equal(t, float32(1200.0), product.Price)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"sync"
+	"time"
+)
+
+type JobRepositoryMock struct {
+	mutex          sync.Mutex
+	jobs           []domain.Job
+	leaseExpiresAt map[int64]time.Time
+}
+
+func NewJobRepositoryMock() *JobRepositoryMock {
+	return &JobRepositoryMock{leaseExpiresAt: make(map[int64]time.Time)}
+}
+
+func (jobRepository *JobRepositoryMock) Add(job domain.Job) (domain.Job, error) {
+	jobRepository.mutex.Lock()
+	defer jobRepository.mutex.Unlock()
+
+	job.Id = int64(len(jobRepository.jobs) + 1)
+	job.Status = domain.JobStatusQueued
+	job.CreatedAt, job.UpdatedAt, job.RunAfter = time.Now(), time.Now(), time.Now()
+	jobRepository.jobs = append(jobRepository.jobs, job)
+	return job, nil
+}
+
+func (jobRepository *JobRepositoryMock) GetById(id int64) (domain.Job, error) {
+	jobRepository.mutex.Lock()
+	defer jobRepository.mutex.Unlock()
+
+	job := jobRepository.find(id)
+	if job == nil {
+		return domain.Job{}, persistence.NotFoundError{Message: fmt.Sprintf("Job with id %d not found", id)}
+	}
+	return *job, nil
+}
+
+func (jobRepository *JobRepositoryMock) FailAbandoned() ([]domain.Job, error) {
+	jobRepository.mutex.Lock()
+	defer jobRepository.mutex.Unlock()
+
+	var jobs []domain.Job
+	now := time.Now()
+	for i := range jobRepository.jobs {
+		job := &jobRepository.jobs[i]
+		if job.Status == domain.JobStatusRunning && jobRepository.leaseExpiresAt[job.Id].Before(now) && job.Attempts >= job.MaxAttempts {
+			job.Status, job.Error = domain.JobStatusFailed, "Worker stopped renewing its lease on the last attempt"
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (jobRepository *JobRepositoryMock) ClaimNext(lease time.Duration) (domain.Job, bool, error) {
+	jobRepository.mutex.Lock()
+	defer jobRepository.mutex.Unlock()
+
+	now := time.Now()
+	for i := range jobRepository.jobs {
+		job := &jobRepository.jobs[i]
+		due := job.Status == domain.JobStatusQueued && !job.RunAfter.After(now)
+		abandoned := job.Status == domain.JobStatusRunning && jobRepository.leaseExpiresAt[job.Id].Before(now) && job.Attempts < job.MaxAttempts
+		if due || abandoned {
+			job.Status = domain.JobStatusRunning
+			job.Attempts++
+			jobRepository.leaseExpiresAt[job.Id] = now.Add(lease)
+			return *job, true, nil
+		}
+	}
+	return domain.Job{}, false, nil
+}
+
+func (jobRepository *JobRepositoryMock) UpdateProgress(id int64, attempt int, done int64, total int64, lease time.Duration) (bool, error) {
+	jobRepository.mutex.Lock()
+	defer jobRepository.mutex.Unlock()
+
+	job := jobRepository.find(id)
+	if job == nil || job.Status != domain.JobStatusRunning || job.Attempts != attempt {
+		return true, nil
+	}
+	job.ProgressDone, job.ProgressTotal = done, total
+	jobRepository.leaseExpiresAt[id] = time.Now().Add(lease)
+	return job.CancelRequested, nil
+}
+
+func (jobRepository *JobRepositoryMock) Complete(id int64, attempt int, result json.RawMessage) error {
+	return jobRepository.finish(id, attempt, func(job *domain.Job) {
+		job.Status, job.Result = domain.JobStatusSucceeded, result
+	})
+}
+
+func (jobRepository *JobRepositoryMock) Fail(id int64, attempt int, message string, retryAt *time.Time) error {
+	if retryAt != nil {
+		jobRepository.mutex.Lock()
+		defer jobRepository.mutex.Unlock()
+		job := jobRepository.find(id)
+		if job == nil || job.Status != domain.JobStatusRunning || job.Attempts != attempt {
+			return nil
+		}
+		job.Status, job.Error, job.RunAfter = domain.JobStatusQueued, message, *retryAt
+		return nil
+	}
+	return jobRepository.finish(id, attempt, func(job *domain.Job) {
+		job.Status, job.Error = domain.JobStatusFailed, message
+	})
+}
+
+func (jobRepository *JobRepositoryMock) MarkCancelled(id int64, attempt int) error {
+	return jobRepository.finish(id, attempt, func(job *domain.Job) {
+		job.Status, job.Error = domain.JobStatusCancelled, "Cancelled"
+	})
+}
+
+func (jobRepository *JobRepositoryMock) RequestCancel(id int64) (domain.Job, error) {
+	jobRepository.mutex.Lock()
+	defer jobRepository.mutex.Unlock()
+
+	job := jobRepository.find(id)
+	if job == nil {
+		return domain.Job{}, persistence.NotFoundError{Message: fmt.Sprintf("Job with id %d not found", id)}
+	}
+	if job.IsFinished() {
+		return domain.Job{}, persistence.ConflictError{Message: fmt.Sprintf("Job with id %d is already %s", id, job.Status)}
+	}
+	job.CancelRequested = true
+	if job.Status == domain.JobStatusQueued {
+		job.Status, job.Error = domain.JobStatusCancelled, "Cancelled"
+	}
+	return *job, nil
+}
+
+func (jobRepository *JobRepositoryMock) finish(id int64, attempt int, apply func(job *domain.Job)) error {
+	jobRepository.mutex.Lock()
+	defer jobRepository.mutex.Unlock()
+
+	job := jobRepository.find(id)
+	if job != nil && job.Status == domain.JobStatusRunning && job.Attempts == attempt {
+		apply(job)
+		finishedAt := time.Now()
+		job.FinishedAt = &finishedAt
+	}
+	return nil
+}
+
+func (jobRepository *JobRepositoryMock) find(id int64) *domain.Job {
+	for i := range jobRepository.jobs {
+		if jobRepository.jobs[i].Id == id {
+			return &jobRepository.jobs[i]
+		}
+	}
+	return nil
+}
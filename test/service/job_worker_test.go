@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"testing"
+	"time"
+)
+
+func Test_JobWorker_ShouldStoreResultAndProgress(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		jobRepository := NewJobRepositoryMock()
+		jobService := service.NewJobService(jobRepository)
+		jobWorker := service.NewJobWorker(jobRepository, 1, time.Second, time.Minute)
+		jobWorker.Handle("count", func(ctx context.Context, job domain.Job, reportProgress service.JobProgressReporter) (interface{}, error) {
+			assert.Nil(t, reportProgress(5, 10))
+			return map[string]int{"counted": 10}, nil
+		})
+
+		job, err := jobService.Enqueue("count", map[string]int{"to": 10}, "user-1")
+		assert.Nil(t, err)
+		assert.Equal(t, `{"to":10}`, string(job.Payload))
+
+		assert.True(t, jobWorker.RunOnce(context.Background()))
+		assert.False(t, jobWorker.RunOnce(context.Background()))
+		job, _ = jobService.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusSucceeded, job.Status)
+		assert.Equal(t, `{"counted":10}`, string(job.Result))
+		assert.Equal(t, int64(5), job.ProgressDone)
+		assert.NotNil(t, job.FinishedAt)
+	})
+}
+
+func Test_JobWorker_ShouldRetryWithBackoff_UntilAttemptsRunOut(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		jobRepository := NewJobRepositoryMock()
+		jobService := service.NewJobService(jobRepository)
+		jobWorker := service.NewJobWorker(jobRepository, 1, time.Second, time.Minute)
+		calls := 0
+		jobWorker.Handle("flaky", func(ctx context.Context, job domain.Job, reportProgress service.JobProgressReporter) (interface{}, error) {
+			calls++
+			return nil, errors.New("Storage is unavailable")
+		})
+		job, _ := jobService.Enqueue("flaky", nil, "user-1")
+
+		before := time.Now()
+		assert.True(t, jobWorker.RunOnce(context.Background()))
+		job, _ = jobService.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusQueued, job.Status)
+		assert.Equal(t, "Storage is unavailable", job.Error)
+		assert.True(t, !job.RunAfter.Before(before.Add(domain.JobRetryDelay(1))))
+		assert.False(t, jobWorker.RunOnce(context.Background()))
+
+		for attempt := 2; attempt <= 3; attempt++ {
+			jobRepository.jobs[0].RunAfter = time.Now()
+			assert.True(t, jobWorker.RunOnce(context.Background()))
+		}
+		job, _ = jobService.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusFailed, job.Status)
+		assert.Equal(t, 3, calls)
+	})
+}
+
+func Test_JobWorker_ShouldNotRetry_WhenErrorIsPermanentOrHandlerIsMissing(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		jobRepository := NewJobRepositoryMock()
+		jobService := service.NewJobService(jobRepository)
+		jobWorker := service.NewJobWorker(jobRepository, 1, time.Second, time.Minute)
+		jobWorker.Handle("broken", func(ctx context.Context, job domain.Job, reportProgress service.JobProgressReporter) (interface{}, error) {
+			return nil, service.PermanentJobError{Message: "Payload is not valid"}
+		})
+		brokenJob, _ := jobService.Enqueue("broken", nil, "user-1")
+		unknownJob, _ := jobService.Enqueue("unknown", nil, "user-1")
+
+		jobWorker.RunOnce(context.Background())
+		jobWorker.RunOnce(context.Background())
+
+		brokenJob, _ = jobService.GetById(brokenJob.Id)
+		assert.Equal(t, domain.JobStatusFailed, brokenJob.Status)
+		assert.Equal(t, "Payload is not valid", brokenJob.Error)
+		unknownJob, _ = jobService.GetById(unknownJob.Id)
+		assert.Equal(t, domain.JobStatusFailed, unknownJob.Status)
+		assert.Equal(t, "No handler for job type unknown", unknownJob.Error)
+	})
+}
+
+func Test_JobWorker_ShouldStopJob_WhenCancelled(t *testing.T) {
+	t.Run("Cancel", func(t *testing.T) {
+		jobRepository := NewJobRepositoryMock()
+		jobService := service.NewJobService(jobRepository)
+		jobWorker := service.NewJobWorker(jobRepository, 1, time.Second, time.Minute)
+		jobWorker.Handle("long", func(ctx context.Context, job domain.Job, reportProgress service.JobProgressReporter) (interface{}, error) {
+			_, err := jobService.Cancel(job.Id)
+			assert.Nil(t, err)
+			err = reportProgress(1, 0)
+			assert.NotNil(t, err)
+			assert.NotNil(t, ctx.Err())
+			return nil, err
+		})
+
+		queuedJob, _ := jobService.Enqueue("long", nil, "user-1")
+		queuedJob, err := jobService.Cancel(queuedJob.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.JobStatusCancelled, queuedJob.Status)
+		_, err = jobService.Cancel(queuedJob.Id)
+		assert.Equal(t, "Job with id 1 is already cancelled", err.Error())
+
+		runningJob, _ := jobService.Enqueue("long", nil, "user-1")
+		assert.True(t, jobWorker.RunOnce(context.Background()))
+		runningJob, _ = jobService.GetById(runningJob.Id)
+		assert.Equal(t, domain.JobStatusCancelled, runningJob.Status)
+		assert.Equal(t, 1, runningJob.Attempts)
+	})
+}
+
+func Test_JobWorker_ShouldIgnoreStaleAttempt_WhenAnotherWorkerTookOver(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		jobRepository := NewJobRepositoryMock()
+		jobService := service.NewJobService(jobRepository)
+		firstWorker := service.NewJobWorker(jobRepository, 1, time.Second, time.Minute)
+		secondWorker := service.NewJobWorker(jobRepository, 1, time.Second, time.Minute)
+		handler := func(ctx context.Context, job domain.Job, reportProgress service.JobProgressReporter) (interface{}, error) {
+			if job.Attempts == 1 {
+				// the first worker stalls past its lease and the second one runs the job meanwhile
+				jobRepository.leaseExpiresAt[job.Id] = time.Now().Add(-time.Second)
+				assert.True(t, secondWorker.RunOnce(context.Background()))
+				assert.NotNil(t, reportProgress(1, 1))
+				return "first", nil
+			}
+			return "second", nil
+		}
+		firstWorker.Handle("slow", handler)
+		secondWorker.Handle("slow", handler)
+		job, _ := jobService.Enqueue("slow", nil, "user-1")
+
+		assert.True(t, firstWorker.RunOnce(context.Background()))
+		job, _ = jobService.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusSucceeded, job.Status)
+		assert.Equal(t, 2, job.Attempts)
+		assert.Equal(t, `"second"`, string(job.Result))
+	})
+}
+
+func Test_JobWorker_ShouldFailAbandonedJob_WhenAttemptsRunOut(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		jobRepository := NewJobRepositoryMock()
+		jobService := service.NewJobService(jobRepository)
+		jobWorker := service.NewJobWorker(jobRepository, 1, time.Second, time.Minute)
+		jobWorker.Handle("crashing", func(ctx context.Context, job domain.Job, reportProgress service.JobProgressReporter) (interface{}, error) {
+			return nil, nil
+		})
+		job, _ := jobService.Enqueue("crashing", nil, "user-1")
+		jobRepository.jobs[0].Status, jobRepository.jobs[0].Attempts = domain.JobStatusRunning, job.MaxAttempts
+		jobRepository.leaseExpiresAt[job.Id] = time.Now().Add(-time.Second)
+
+		assert.False(t, jobWorker.RunOnce(context.Background()))
+		job, _ = jobService.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusFailed, job.Status)
+		assert.Equal(t, "Worker stopped renewing its lease on the last attempt", job.Error)
+	})
+}
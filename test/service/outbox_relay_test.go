@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"testing"
+	"time"
+)
+
+type PublisherMock struct {
+	published     []string
+	failProductId int64
+}
+
+func (publisher *PublisherMock) Publish(ctx context.Context, event domain.ProductEvent) error {
+	if event.ProductId == publisher.failProductId {
+		return errors.New("Broker is unavailable")
+	}
+	publisher.published = append(publisher.published, event.EventId)
+	return nil
+}
+
+func outboxEvents() []domain.ProductEvent {
+	return []domain.ProductEvent{
+		{Id: 1, EventId: "e1", Type: domain.ProductEventCreated, ProductId: 1},
+		{Id: 2, EventId: "e2", Type: domain.ProductEventCreated, ProductId: 2},
+		{Id: 3, EventId: "e3", Type: domain.ProductEventPriceChanged, ProductId: 1},
+		{Id: 4, EventId: "e4", Type: domain.ProductEventPriceChanged, ProductId: 2},
+		{Id: 5, EventId: "e5", Type: domain.ProductEventDeleted, ProductId: 1},
+	}
+}
+
+func Test_OutboxRelay_ShouldPublishEveryEventInOrderPerProduct(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		publisher := &PublisherMock{}
+		outboxRelay := service.NewOutboxRelay(NewOutboxRepositoryMock(outboxEvents()), publisher, time.Second, 2)
+
+		assert.Equal(t, 5, outboxRelay.RunOnce(context.Background()))
+		assert.Equal(t, []string{"e1", "e2", "e3", "e4", "e5"}, publisher.published)
+		assert.Equal(t, 0, outboxRelay.RunOnce(context.Background()))
+	})
+}
+
+func Test_OutboxRelay_ShouldHoldBackLaterEventsOfAFailingProduct(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		publisher := &PublisherMock{failProductId: 2}
+		outboxRelay := service.NewOutboxRelay(NewOutboxRepositoryMock(outboxEvents()), publisher, time.Second, 10)
+
+		assert.Equal(t, 3, outboxRelay.RunOnce(context.Background()))
+		assert.Equal(t, []string{"e1", "e3", "e5"}, publisher.published)
+
+		publisher.failProductId = 0
+		assert.Equal(t, 0, outboxRelay.RunOnce(context.Background()))
+	})
+}
+
+func Test_OutboxPurger_ShouldPurgeOnlyPublishedEventsAfterRetention(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		outboxRepositoryMock := NewOutboxRepositoryMock(outboxEvents())
+		service.NewOutboxRelay(outboxRepositoryMock, &PublisherMock{failProductId: 2}, time.Second, 10).RunOnce(context.Background())
+		outboxPurger := service.NewOutboxPurger(outboxRepositoryMock, 24*time.Hour, time.Hour)
+
+		outboxPurger.RunOnce(time.Now().Add(time.Hour))
+		published, _ := outboxRepositoryMock.GetPublishedAfter(0, 10)
+		assert.Equal(t, 3, len(published))
+
+		outboxPurger.RunOnce(time.Now().Add(25 * time.Hour))
+		published, _ = outboxRepositoryMock.GetPublishedAfter(0, 10)
+		assert.Equal(t, 0, len(published))
+		assert.Equal(t, []string{"e2", "e4"}, []string{outboxRepositoryMock.(*OutboxRepositoryMock).events[0].EventId,
+			outboxRepositoryMock.(*OutboxRepositoryMock).events[1].EventId})
+	})
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"time"
+)
+
+type OutboxRepositoryMock struct {
+	events      []domain.ProductEvent
+	publishedAt map[int64]time.Time
+	retryAt     map[int64]time.Time
+}
+
+func NewOutboxRepositoryMock(events []domain.ProductEvent) persistence.IOutboxRepository {
+	return &OutboxRepositoryMock{events: events, publishedAt: make(map[int64]time.Time), retryAt: make(map[int64]time.Time)}
+}
+
+// RelayPending keeps the ordering of the repository, only the oldest unpublished event of a product is due.
+func (outboxRepository *OutboxRepositoryMock) RelayPending(limit int, publish func(event domain.ProductEvent) error) (int, int, error) {
+	published, failed := 0, 0
+	heldBack := make(map[int64]bool)
+	for i, event := range outboxRepository.events {
+		if _, done := outboxRepository.publishedAt[event.Id]; done {
+			continue
+		}
+		if heldBack[event.ProductId] || published+failed == limit {
+			heldBack[event.ProductId] = true
+			continue
+		}
+		heldBack[event.ProductId] = true
+		if outboxRepository.retryAt[event.Id].After(time.Now()) {
+			continue
+		}
+
+		outboxRepository.events[i].Attempts++
+		if err := publish(event); err != nil {
+			outboxRepository.retryAt[event.Id] = time.Now().Add(domain.OutboxRetryDelay(event.Attempts + 1))
+			failed++
+			continue
+		}
+		outboxRepository.publishedAt[event.Id] = time.Now()
+		published++
+	}
+	return published, failed, nil
+}
+
+func (outboxRepository *OutboxRepositoryMock) GetPublished(ids []int64) ([]domain.ProductEvent, error) {
+	events := make([]domain.ProductEvent, 0)
+	for _, id := range ids {
+		for _, event := range outboxRepository.events {
+			if _, published := outboxRepository.publishedAt[event.Id]; published && event.Id == id {
+				events = append(events, event)
+			}
+		}
+	}
+	return events, nil
+}
+
+func (outboxRepository *OutboxRepositoryMock) GetPublishedAfter(afterId int64, limit int) ([]domain.ProductEvent, error) {
+	events := make([]domain.ProductEvent, 0)
+	for _, event := range outboxRepository.events {
+		if _, published := outboxRepository.publishedAt[event.Id]; published && event.Id > afterId && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (outboxRepository *OutboxRepositoryMock) PurgePublished(publishedBefore time.Time) (int64, error) {
+	kept := make([]domain.ProductEvent, 0, len(outboxRepository.events))
+	for _, event := range outboxRepository.events {
+		if publishedAt, published := outboxRepository.publishedAt[event.Id]; published && publishedAt.Before(publishedBefore) {
+			delete(outboxRepository.publishedAt, event.Id)
+			continue
+		}
+		kept = append(kept, event)
+	}
+	purged := int64(len(outboxRepository.events) - len(kept))
+	outboxRepository.events = kept
+	return purged, nil
+}
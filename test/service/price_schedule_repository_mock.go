@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"slices"
+	"time"
+)
+
+type PriceScheduleRepositoryMock struct {
+	priceSchedules []domain.PriceSchedule
+}
+
+func NewPriceScheduleRepositoryMock(initialPriceSchedules []domain.PriceSchedule) persistence.IPriceScheduleRepository {
+	return &PriceScheduleRepositoryMock{priceSchedules: initialPriceSchedules}
+}
+
+func (priceScheduleRepository *PriceScheduleRepositoryMock) Add(priceSchedule domain.PriceSchedule) error {
+	priceSchedule.Id = int64(len(priceScheduleRepository.priceSchedules) + 1)
+	priceSchedule.Status = domain.PriceScheduleStatusPending
+	priceScheduleRepository.priceSchedules = append(priceScheduleRepository.priceSchedules, priceSchedule)
+	return nil
+}
+
+func (priceScheduleRepository *PriceScheduleRepositoryMock) GetById(id int64) (domain.PriceSchedule, error) {
+	for _, priceSchedule := range priceScheduleRepository.priceSchedules {
+		if priceSchedule.Id == id {
+			return priceSchedule, nil
+		}
+	}
+
+	return domain.PriceSchedule{}, errors.New(fmt.Sprintf("Price schedule with id %d not found", id))
+}
+
+func (priceScheduleRepository *PriceScheduleRepositoryMock) GetPending() ([]domain.PriceSchedule, error) {
+	var priceSchedules []domain.PriceSchedule
+	for _, priceSchedule := range priceScheduleRepository.priceSchedules {
+		if priceSchedule.Status == domain.PriceScheduleStatusPending {
+			priceSchedules = append(priceSchedules, priceSchedule)
+		}
+	}
+
+	return priceSchedules, nil
+}
+
+func (priceScheduleRepository *PriceScheduleRepositoryMock) GetPendingByProductId(productId int64) ([]domain.PriceSchedule, error) {
+	var priceSchedules []domain.PriceSchedule
+	for _, priceSchedule := range priceScheduleRepository.priceSchedules {
+		if priceSchedule.Status == domain.PriceScheduleStatusPending && priceSchedule.ProductId == productId {
+			priceSchedules = append(priceSchedules, priceSchedule)
+		}
+	}
+
+	return priceSchedules, nil
+}
+
+func (priceScheduleRepository *PriceScheduleRepositoryMock) GetActiveByProductIds(productIds []int64, now time.Time) ([]domain.PriceSchedule, error) {
+	var priceSchedules []domain.PriceSchedule
+	for _, priceSchedule := range priceScheduleRepository.priceSchedules {
+		if slices.Contains(productIds, priceSchedule.ProductId) && priceSchedule.IsActiveAt(now) {
+			priceSchedules = append(priceSchedules, priceSchedule)
+		}
+	}
+
+	return priceSchedules, nil
+}
+
+func (priceScheduleRepository *PriceScheduleRepositoryMock) Cancel(id int64) error {
+	for i, priceSchedule := range priceScheduleRepository.priceSchedules {
+		if priceSchedule.Id == id {
+			if priceSchedule.Status != domain.PriceScheduleStatusPending {
+				return errors.New(fmt.Sprintf("Price schedule with id %d is already %s", id, priceSchedule.Status))
+			}
+			priceScheduleRepository.priceSchedules[i].Status = domain.PriceScheduleStatusCancelled
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Price schedule with id %d not found", id))
+}
+
+func (priceScheduleRepository *PriceScheduleRepositoryMock) ApplyDue(now time.Time) (int64, error) {
+	var changed int64
+	for i, priceSchedule := range priceScheduleRepository.priceSchedules {
+		if priceSchedule.Status != domain.PriceScheduleStatusPending {
+			continue
+		}
+		if priceSchedule.EffectiveTo == nil && !now.Before(priceSchedule.EffectiveFrom) {
+			priceScheduleRepository.priceSchedules[i].Status = domain.PriceScheduleStatusApplied
+			changed++
+		}
+		if priceSchedule.EffectiveTo != nil && !now.Before(*priceSchedule.EffectiveTo) {
+			priceScheduleRepository.priceSchedules[i].Status = domain.PriceScheduleStatusExpired
+			changed++
+		}
+	}
+
+	return changed, nil
+}
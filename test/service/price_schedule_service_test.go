@@ -0,0 +1,144 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/search"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"testing"
+	"time"
+)
+
+func float32Pointer(value float32) *float32 {
+	return &value
+}
+
+func timePointer(value time.Time) *time.Time {
+	return &value
+}
+
+func newPriceScheduleTestProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH"},
+		{Id: 2, Name: "iron", Price: 1500.0, Discount: 10.0, Store: "ABC TECH"},
+	}
+}
+
+func newPriceScheduleTestServices(priceSchedules []domain.PriceSchedule) (service.IPriceScheduleService, service.IProductService) {
+	priceScheduleRepositoryMock := NewPriceScheduleRepositoryMock(priceSchedules)
+	productService := newProductTestServiceWith(NewProductRepositoryMock(newPriceScheduleTestProducts()), priceScheduleRepositoryMock, nil)
+	return service.NewPriceScheduleService(priceScheduleRepositoryMock, productService), productService
+}
+
+func Test_GetById_ShouldApplyActivePriceSchedule(t *testing.T) {
+	t.Run("GetById", func(t *testing.T) {
+		now := time.Now()
+		productService := newProductTestService(newPriceScheduleTestProducts(), []domain.PriceSchedule{
+			{Id: 1, ProductId: 1, Discount: float32Pointer(50.0), EffectiveFrom: now.Add(-time.Hour), EffectiveTo: timePointer(now.Add(time.Hour)), Status: domain.PriceScheduleStatusPending},
+			{Id: 2, ProductId: 1, Price: float32Pointer(1000.0), EffectiveFrom: now.Add(time.Hour), Status: domain.PriceScheduleStatusPending},
+			{Id: 3, ProductId: 2, Price: float32Pointer(1000.0), EffectiveFrom: now.Add(-time.Hour), Status: domain.PriceScheduleStatusCancelled},
+		}, nil)
+
+		product, err := productService.GetById(1)
+		assert.Nil(t, err)
+		assert.Equal(t, float32(3000.0), product.Price)
+		assert.Equal(t, float32(50.0), product.Discount)
+
+		product, err = productService.GetById(2)
+		assert.Nil(t, err)
+		assert.Equal(t, float32(1500.0), product.Price)
+	})
+}
+
+func Test_GetAll_ShouldIgnoreExpiredPriceSchedule(t *testing.T) {
+	t.Run("GetAll", func(t *testing.T) {
+		now := time.Now()
+		productService := newProductTestService(newPriceScheduleTestProducts(), []domain.PriceSchedule{
+			{Id: 1, ProductId: 2, Discount: float32Pointer(50.0), EffectiveFrom: now.Add(-2 * time.Hour), EffectiveTo: timePointer(now.Add(-time.Hour)), Status: domain.PriceScheduleStatusPending},
+		}, nil)
+
+		products, err := productService.GetAll()
+		assert.Nil(t, err)
+		assert.Equal(t, float32(10.0), products[1].Discount)
+	})
+}
+
+func Test_AddPriceSchedule_ShouldAddSchedule_WhenScheduleIsValid(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		now := time.Now()
+		priceScheduleService, _ := newPriceScheduleTestServices(nil)
+
+		err := priceScheduleService.Add(model.CreatePriceSchedule{
+			ProductId:     1,
+			Discount:      float32Pointer(30.0),
+			EffectiveFrom: now.Add(24 * time.Hour),
+			EffectiveTo:   timePointer(now.Add(72 * time.Hour)),
+		})
+		assert.Nil(t, err)
+
+		pending, _ := priceScheduleService.GetPendingByProductId(1)
+		assert.Equal(t, 1, len(pending))
+		assert.Equal(t, float32(30.0), *pending[0].Discount)
+	})
+}
+
+func Test_AddPriceSchedule_ShouldReturnError_WhenScheduleIsInvalid(t *testing.T) {
+	now := time.Now()
+	priceScheduleService, _ := newPriceScheduleTestServices(nil)
+
+	testCases := []struct {
+		name          string
+		priceSchedule model.CreatePriceSchedule
+		expectedError string
+	}{
+		{"no change", model.CreatePriceSchedule{ProductId: 1, EffectiveFrom: now}, "Price or discount is required"},
+		{"discount out of range", model.CreatePriceSchedule{ProductId: 1, Discount: float32Pointer(80.0), EffectiveFrom: now}, "Discount should be between 0 and 70"},
+		{"window reversed", model.CreatePriceSchedule{ProductId: 1, Price: float32Pointer(10.0), EffectiveFrom: now, EffectiveTo: timePointer(now.Add(-time.Hour))}, "Effective to should be after effective from"},
+		{"unknown product", model.CreatePriceSchedule{ProductId: 100, Price: float32Pointer(10.0), EffectiveFrom: now}, "Product with id 100 not found"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := priceScheduleService.Add(testCase.priceSchedule)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
+
+func Test_CancelPriceSchedule_ShouldRemoveScheduleFromPending(t *testing.T) {
+	t.Run("Cancel", func(t *testing.T) {
+		priceScheduleService, _ := newPriceScheduleTestServices([]domain.PriceSchedule{
+			{Id: 1, ProductId: 1, Price: float32Pointer(1000.0), EffectiveFrom: time.Now().Add(time.Hour), Status: domain.PriceScheduleStatusPending},
+		})
+
+		err := priceScheduleService.Cancel(1)
+		assert.Nil(t, err)
+		pending, _ := priceScheduleService.GetPending()
+		assert.Equal(t, 0, len(pending))
+
+		err = priceScheduleService.Cancel(1)
+		assert.Equal(t, "Price schedule with id 1 is already cancelled", err.Error())
+	})
+}
+
+func Test_PriceSchedule_ShouldReindexTheScheduledPrice(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		priceScheduleService, productService := newPriceScheduleTestServices(nil)
+		assert.Nil(t, productService.RebuildSearchIndex())
+
+		err := priceScheduleService.Add(model.CreatePriceSchedule{
+			ProductId:     2,
+			Price:         float32Pointer(1000.0),
+			EffectiveFrom: time.Now().Add(-time.Minute),
+			EffectiveTo:   timePointer(time.Now().Add(time.Hour)),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, float32(1000.0), productService.SearchIndex(search.Query{Text: "iron"}).Hits[0].Product.Price)
+
+		pending, _ := priceScheduleService.GetPendingByProductId(2)
+		assert.Nil(t, priceScheduleService.Cancel(pending[0].Id))
+		assert.Equal(t, float32(1500.0), productService.SearchIndex(search.Query{Text: "iron"}).Hits[0].Product.Price)
+	})
+}
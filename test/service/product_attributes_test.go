@@ -0,0 +1,109 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"testing"
+)
+
+func newAttributeTestDefinitions() []domain.AttributeDefinition {
+	return []domain.AttributeDefinition{
+		{Id: 1, Category: "heaters", Name: "wattage", Type: domain.AttributeTypeNumber, Required: true, Unit: "W"},
+		{Id: 2, Category: "heaters", Name: "warranty_months", Type: domain.AttributeTypeNumber},
+		{Id: 3, Category: "heaters", Name: "plug", Type: domain.AttributeTypeEnum, EnumValues: []string{"EU", "UK"}},
+	}
+}
+
+func Test_Add_ShouldAddProduct_WhenAttributesMatchDefinitions(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		productService := newProductTestService(nil, nil, newAttributeTestDefinitions())
+		err := productService.Add(model.CreateProduct{
+			Name: "heater", Price: 900.0, Store: "ABC TECH", Category: "heaters", Sku: "HEAT-1",
+			Attributes: map[string]interface{}{"wattage": 2000.0, "plug": "EU"},
+		})
+		assert.Nil(t, err)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, 2000.0, product.Attributes["wattage"])
+	})
+}
+
+func Test_Add_ShouldReturnError_WhenAttributesDoNotMatchDefinitions(t *testing.T) {
+	productService := newProductTestService(nil, nil, newAttributeTestDefinitions())
+
+	testCases := []struct {
+		name          string
+		attributes    map[string]interface{}
+		expectedError string
+	}{
+		{"missing required", map[string]interface{}{"plug": "EU"}, "Attribute wattage is required for category heaters"},
+		{"wrong type", map[string]interface{}{"wattage": "a lot"}, "Attribute wattage should be a number"},
+		{"not in enum", map[string]interface{}{"wattage": 500.0, "plug": "US"}, "Attribute plug should be one of EU, UK"},
+		{"undefined", map[string]interface{}{"wattage": 500.0, "colour": "red"}, "Attribute colour is not defined for category heaters"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := productService.Add(model.CreateProduct{Name: "heater", Price: 900.0, Store: "ABC TECH", Category: "heaters", Attributes: testCase.attributes})
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
+
+func Test_AddAttributeDefinition_ShouldReturnError_WhenDefinitionIsInvalid(t *testing.T) {
+	attributeDefinitionService := service.NewAttributeDefinitionService(NewAttributeDefinitionRepositoryMock(nil))
+
+	testCases := []struct {
+		name                string
+		attributeDefinition model.CreateAttributeDefinition
+		expectedError       string
+	}{
+		{"bad name", model.CreateAttributeDefinition{Category: "heaters", Name: "Watt Age", Type: domain.AttributeTypeNumber}, "Attribute name should be lowercase letters, digits or underscores"},
+		{"enum without values", model.CreateAttributeDefinition{Category: "heaters", Name: "plug", Type: domain.AttributeTypeEnum}, "Enum attributes need at least one enum value"},
+		{"unknown type", model.CreateAttributeDefinition{Category: "heaters", Name: "plug", Type: "date"}, "Attribute type should be one of string, number, boolean, enum"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := attributeDefinitionService.Add(testCase.attributeDefinition)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
+
+func Test_GetAllByFilter_ShouldReturnError_WhenAttributeConditionDoesNotMatchDefinitions(t *testing.T) {
+	attributeDefinitions := []domain.AttributeDefinition{
+		{Id: 1, Category: "heaters", Name: "wattage", Type: domain.AttributeTypeNumber},
+		{Id: 2, Category: "heaters", Name: "plug", Type: domain.AttributeTypeEnum, EnumValues: []string{"EU", "UK"}},
+		{Id: 3, Category: "lamps", Name: "plug", Type: domain.AttributeTypeBoolean},
+	}
+	productService := newProductTestService(nil, nil, attributeDefinitions)
+
+	testCases := []struct {
+		name          string
+		filter        domain.ProductFilter
+		expectedError string
+	}{
+		{"undefined", domain.ProductFilter{AttributeConditions: []domain.AttributeCondition{{Name: "colour", Operator: domain.FilterOperatorEq, Value: "red"}}}, "Attribute colour is not defined"},
+		{"not a number", domain.ProductFilter{AttributeConditions: []domain.AttributeCondition{{Name: "wattage", Operator: domain.FilterOperatorEq, Value: "high"}}}, "Attribute wattage should be compared with a number"},
+		{"types disagree", domain.ProductFilter{AttributeConditions: []domain.AttributeCondition{{Name: "plug", Operator: domain.FilterOperatorEq, Value: "EU"}}}, "Attribute plug has different types across categories, filter by category"},
+		{"boolean", domain.ProductFilter{Category: "lamps", AttributeConditions: []domain.AttributeCondition{{Name: "plug", Operator: domain.FilterOperatorEq, Value: "EU"}}}, "Attribute plug should be compared with true or false"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := productService.GetAllByFilter(testCase.filter)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+
+	t.Run("defined", func(t *testing.T) {
+		_, err := productService.GetAllByFilter(domain.ProductFilter{Category: "heaters", AttributeConditions: []domain.AttributeCondition{{Name: "plug", Operator: domain.FilterOperatorEq, Value: "1"}}})
+		assert.Nil(t, err)
+	})
+}
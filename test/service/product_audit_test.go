@@ -0,0 +1,69 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service/model"
+	"testing"
+	"time"
+)
+
+func Test_Add_ShouldRecordActor(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		productService := newProductTestService(nil, nil, nil)
+		err := productService.Add(model.CreateProduct{Name: "tv", Price: 5000.0, Store: "ABC TECH", Sku: "TV-1", Actor: "user-1"})
+		assert.Nil(t, err)
+
+		err = productService.UpdatePrice(1, 4500.0, "user-2")
+		assert.Nil(t, err)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, "user-1", product.CreatedBy)
+		assert.Equal(t, "user-2", product.UpdatedBy)
+	})
+}
+
+func Test_GetAllByFilter_ShouldReturnProductsUpdatedSince(t *testing.T) {
+	t.Run("GetAllByFilter", func(t *testing.T) {
+		since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		productService := newProductTestService([]domain.Product{
+			{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH", UpdatedAt: since.Add(-time.Hour)},
+			{Id: 2, Name: "iron", Price: 1500.0, Store: "ABC TECH", UpdatedAt: since},
+			{Id: 3, Name: "fax", Price: 10000.0, Store: "ABC TECH", UpdatedAt: since.Add(time.Hour)},
+		}, nil, nil)
+
+		products, _ := productService.GetAllByFilter(domain.ProductFilter{UpdatedSince: &since})
+		assert.Equal(t, 2, len(products))
+		assert.Equal(t, int64(2), products[0].Id)
+		assert.Equal(t, int64(3), products[1].Id)
+	})
+}
+
+func Test_GetAllByFilter_ShouldIncludeRemovedProducts_WhenPullingIncrementally(t *testing.T) {
+	since := time.Now().Add(-time.Minute)
+	productService := newProductTestService([]domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH", Status: domain.ProductStatusActive, UpdatedAt: since.Add(-time.Hour)},
+		{Id: 2, Name: "iron", Price: 1500.0, Store: "ABC TECH", Status: domain.ProductStatusActive, UpdatedAt: since.Add(-time.Hour)},
+		{Id: 3, Name: "fax", Price: 10000.0, Store: "ABC TECH", Status: domain.ProductStatusDiscontinued, UpdatedAt: since.Add(time.Second)},
+	}, nil, nil)
+	assert.Nil(t, productService.DeleteById(2, "user-1"))
+	filter := domain.ProductFilter{Statuses: []string{domain.ProductStatusActive}, UpdatedSince: &since}
+
+	t.Run("WithoutRemoved", func(t *testing.T) {
+		products, _ := productService.GetAllByFilter(filter)
+		assert.Empty(t, products)
+	})
+	t.Run("WithRemoved", func(t *testing.T) {
+		filter.IncludeRemoved = true
+		products, _ := productService.GetAllByFilter(filter)
+		assert.Equal(t, 2, len(products))
+		assert.Equal(t, int64(3), products[0].Id)
+		assert.Equal(t, int64(2), products[1].Id)
+		assert.NotNil(t, products[1].DeletedAt)
+	})
+	t.Run("OnlyWithUpdatedSince", func(t *testing.T) {
+		products, _ := productService.GetAllByFilter(domain.ProductFilter{Statuses: []string{domain.ProductStatusActive}, IncludeRemoved: true})
+		assert.Equal(t, 1, len(products))
+		assert.Equal(t, int64(1), products[0].Id)
+	})
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/search"
+	"go-product-app/domain"
+	"go-product-app/service/model"
+	"testing"
+)
+
+func newBatchTestProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH", Sku: "AIR-1"},
+		{Id: 2, Name: "iron", Price: 1500.0, Store: "ABC TECH"},
+	}
+}
+
+func Test_AddBatch_ShouldApplyValidItems_WhenModeIsBestEffort(t *testing.T) {
+	t.Run("AddBatch", func(t *testing.T) {
+		productService := newProductTestService(newBatchTestProducts(), nil, nil)
+		results, err := productService.AddBatch([]model.CreateProduct{
+			{Name: "fax", Price: 10000.0, Store: "x brand", Sku: "FAX-1"},
+			{Name: "", Price: 100.0, Store: "x brand"},
+			{Name: "air v2", Price: 3500.0, Store: "ABC TECH", Sku: "AIR-1"},
+		}, domain.BatchModeBestEffort)
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.BatchItemResult{Index: 0, Id: 3, Status: domain.BatchItemSucceeded}, results[0])
+		assert.Equal(t, domain.BatchItemResult{Index: 1, Status: domain.BatchItemFailed, Error: "Name should not be empty"}, results[1])
+		assert.Equal(t, domain.BatchItemFailed, results[2].Status)
+		assert.Equal(t, "Product with sku AIR-1 already exists in store ABC TECH", results[2].Error)
+
+		products, _ := productService.GetAll()
+		assert.Equal(t, 3, len(products))
+		assert.Equal(t, 1, productService.SearchIndex(search.Query{Text: "fax"}).Total)
+	})
+}
+
+func Test_AddBatch_ShouldApplyNothing_WhenAtomicBatchHasFailedItem(t *testing.T) {
+	testCases := []struct {
+		name     string
+		products []model.CreateProduct
+	}{
+		{"invalid item", []model.CreateProduct{{Name: "fax", Price: 10000.0, Store: "x brand", Sku: "FAX-1"}, {Name: "bad", Discount: 90.0, Store: "x brand", Sku: "BAD-1"}}},
+		{"conflicting item", []model.CreateProduct{{Name: "fax", Price: 10000.0, Store: "x brand", Sku: "FAX-1"}, {Name: "air v2", Store: "ABC TECH", Sku: "AIR-1"}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			productService := newProductTestService(newBatchTestProducts(), nil, nil)
+			results, err := productService.AddBatch(testCase.products, domain.BatchModeAtomic)
+
+			assert.Nil(t, err)
+			assert.Equal(t, domain.BatchItemNotApplied, results[0].Status)
+			assert.Equal(t, domain.BatchItemFailed, results[1].Status)
+			products, _ := productService.GetAll()
+			assert.Equal(t, 2, len(products))
+		})
+	}
+}
+
+func Test_UpdatePriceBatch_ShouldReportEveryItem(t *testing.T) {
+	t.Run("UpdatePriceBatch", func(t *testing.T) {
+		productService := newProductTestService(newBatchTestProducts(), nil, nil)
+		results, err := productService.UpdatePriceBatch([]domain.ProductPriceUpdate{
+			{Id: 1, Price: 2800.0},
+			{Id: 1, Price: 2700.0},
+			{Id: 2, Price: 0},
+			{Id: 9, Price: 100.0},
+		}, domain.BatchModeBestEffort, "user-1")
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.BatchItemSucceeded, results[0].Status)
+		assert.Equal(t, "Product with id 1 is repeated in the batch", results[1].Error)
+		assert.Equal(t, "Price should be greater than 0", results[2].Error)
+		assert.Equal(t, "Product with id 9 not found", results[3].Error)
+		product, _ := productService.GetById(1)
+		assert.Equal(t, float32(2800.0), product.Price)
+		assert.Equal(t, "user-1", product.UpdatedBy)
+	})
+}
+
+func Test_DeleteBatch_ShouldRollBack_WhenAtomicBatchHasMissingProduct(t *testing.T) {
+	t.Run("DeleteBatch", func(t *testing.T) {
+		productService := newProductTestService(newBatchTestProducts(), nil, nil)
+		results, err := productService.DeleteBatch([]int64{1, 9}, domain.BatchModeAtomic, "user-1")
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.BatchItemNotApplied, results[0].Status)
+		assert.Equal(t, domain.BatchItemFailed, results[1].Status)
+		_, err = productService.GetById(1)
+		assert.Nil(t, err)
+
+		results, err = productService.DeleteBatch([]int64{1, 2}, domain.BatchModeAtomic, "user-1")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.BatchItemSucceeded, results[1].Status)
+		products, _ := productService.GetAll()
+		assert.Equal(t, 0, len(products))
+	})
+}
+
+func Test_Batch_ShouldReturnError_WhenBatchIsInvalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ids           []int64
+		mode          string
+		expectedError string
+	}{
+		{"unknown mode", []int64{1}, "partial", "Batch mode partial is not valid"},
+		{"empty batch", nil, domain.BatchModeAtomic, "Batch should have between 1 and 1000 items"},
+		{"too many items", make([]int64, 1001), domain.BatchModeAtomic, "Batch should have between 1 and 1000 items"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := newProductTestService(newBatchTestProducts(), nil, nil).DeleteBatch(testCase.ids, testCase.mode, "user-1")
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
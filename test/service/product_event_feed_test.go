@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"testing"
+	"time"
+)
+
+func Test_ProductEventFeed_ShouldPublishNotifiedEvents(t *testing.T) {
+	outboxRepository := NewOutboxRepositoryMock(outboxEvents())
+	// the relay runs on another instance, it only publishes the first two events for now
+	outboxRepository.RelayPending(2, func(event domain.ProductEvent) error { return nil })
+	publisher := &PublisherMock{}
+	productEventFeed := service.NewProductEventFeed(outboxRepository, publisher, 100)
+
+	t.Run("Notify", func(t *testing.T) {
+		productEventFeed.Notify(2)
+		productEventFeed.Notify(1)
+		assert.Nil(t, productEventFeed.RunOnce(context.Background()))
+		assert.Equal(t, []string{"e2", "e1"}, publisher.published)
+	})
+	t.Run("SkipsUnpublishedEvents", func(t *testing.T) {
+		productEventFeed.Notify(5)
+		assert.Nil(t, productEventFeed.RunOnce(context.Background()))
+		assert.Equal(t, []string{"e2", "e1"}, publisher.published)
+	})
+	t.Run("CatchesUpAfterResync", func(t *testing.T) {
+		service.NewOutboxRelay(outboxRepository, &PublisherMock{}, time.Second, 10).RunOnce(context.Background())
+		// the notifications of the events published meanwhile are lost with the connection
+		productEventFeed.HandleChange(domain.ProductChange{Operation: domain.ProductChangeUpdate, ProductId: 1})
+		assert.Nil(t, productEventFeed.RunOnce(context.Background()))
+		assert.Equal(t, 2, len(publisher.published))
+
+		productEventFeed.HandleChange(domain.ProductChange{Operation: domain.ProductChangeResync})
+		productEventFeed.Notify(4)
+		assert.Nil(t, productEventFeed.RunOnce(context.Background()))
+		assert.Equal(t, []string{"e2", "e1", "e3", "e4", "e5"}, publisher.published)
+	})
+}
+
+func Test_ProductEventFeed_ShouldCatchUpInBatches(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		outboxRepository := NewOutboxRepositoryMock(outboxEvents())
+		outboxRepository.RelayPending(1, func(event domain.ProductEvent) error { return nil })
+		publisher := &PublisherMock{}
+		productEventFeed := service.NewProductEventFeed(outboxRepository, publisher, 2)
+		productEventFeed.Notify(1)
+		assert.Nil(t, productEventFeed.RunOnce(context.Background()))
+
+		service.NewOutboxRelay(outboxRepository, &PublisherMock{}, time.Second, 10).RunOnce(context.Background())
+		productEventFeed.HandleChange(domain.ProductChange{Operation: domain.ProductChangeResync})
+		assert.Nil(t, productEventFeed.RunOnce(context.Background()))
+		assert.Equal(t, []string{"e1", "e2", "e3", "e4", "e5"}, publisher.published)
+	})
+}
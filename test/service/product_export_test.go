@@ -0,0 +1,102 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"io"
+	"testing"
+	"time"
+)
+
+func newExportTestProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH", Status: domain.ProductStatusActive, Options: []string{"color", "size"}},
+		{Id: 2, Name: `iron "pro"`, Price: 1500.5, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 3, Name: "fax", Price: 10000.0, Store: "x brand", Status: domain.ProductStatusActive},
+	}
+}
+
+func newExportTestPriceSchedules() []domain.PriceSchedule {
+	return []domain.PriceSchedule{
+		{Id: 1, ProductId: 1, Price: float32Pointer(2500.0), EffectiveFrom: time.Now().Add(-time.Hour), EffectiveTo: timePointer(time.Now().Add(time.Hour)), Status: domain.PriceScheduleStatusPending},
+	}
+}
+
+func Test_Export_ShouldWriteSelectedFields(t *testing.T) {
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{domain.ExportFormatCsv, "id,name,price,options\n1,air,2500,color|size\n2,\"iron \"\"pro\"\"\",1500.5,\n"},
+		{domain.ExportFormatNdjson, `{"id":1,"name":"air","price":2500,"options":"color|size"}` + "\n" + `{"id":2,"name":"iron \"pro\"","price":1500.5,"options":""}` + "\n"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.format, func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := newProductTestService(newExportTestProducts(), newExportTestPriceSchedules(), nil).Export(domain.ProductExportQuery{
+				Format: testCase.format,
+				Fields: []string{"id", "name", "price", "options"},
+				Filter: domain.ProductFilter{Store: "ABC TECH"},
+			}, &buffer)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.expected, buffer.String())
+		})
+	}
+}
+
+func Test_Export_ShouldNotWriteFormulasToCsv(t *testing.T) {
+	t.Run("Export", func(t *testing.T) {
+		productService := newProductTestService([]domain.Product{
+			{Id: 1, Name: "=HYPERLINK(\"http://evil\")", Description: "-10% off", Price: 10.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+			{Id: 2, Name: "@SUM(A1)", Description: "a+b", Price: 20.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		}, nil, nil)
+
+		var buffer bytes.Buffer
+		err := productService.Export(domain.ProductExportQuery{Format: domain.ExportFormatCsv, Fields: []string{"id", "name", "description"}}, &buffer)
+		assert.Nil(t, err)
+		assert.Equal(t, "id,name,description\n1,\"'=HYPERLINK(\"\"http://evil\"\")\",'-10% off\n2,'@SUM(A1),a+b\n", buffer.String())
+	})
+}
+
+func Test_Export_ShouldWriteWorkbook(t *testing.T) {
+	t.Run("Export", func(t *testing.T) {
+		var buffer bytes.Buffer
+		err := newProductTestService(newExportTestProducts(), newExportTestPriceSchedules(), nil).Export(domain.ProductExportQuery{Format: domain.ExportFormatXlsx, Fields: []string{"name", "price"}}, &buffer)
+		assert.Nil(t, err)
+
+		archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		assert.Nil(t, err)
+		assert.Equal(t, "[Content_Types].xml", archive.File[0].Name)
+		sheetFile, err := archive.Open("xl/worksheets/sheet1.xml")
+		assert.Nil(t, err)
+		sheet, _ := io.ReadAll(sheetFile)
+		assert.Contains(t, string(sheet), `<row><c t="inlineStr"><is><t xml:space="preserve">iron &#34;pro&#34;</t></is></c><c><v>1500.5</v></c></row>`)
+		assert.Contains(t, string(sheet), `</sheetData></worksheet>`)
+	})
+}
+
+func Test_Export_ShouldReturnError_WhenQueryIsInvalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         domain.ProductExportQuery
+		expectedError string
+	}{
+		{"unknown format", domain.ProductExportQuery{Format: "pdf"}, "Export format pdf is not supported"},
+		{"unknown field", domain.ProductExportQuery{Format: domain.ExportFormatCsv, Fields: []string{"cost"}},
+			"Field cost can not be exported, exportable fields are id, name, description, price, discount, store, category, status, sku, gtin, options, created_at, updated_at, created_by, updated_by"},
+		{"repeated field", domain.ProductExportQuery{Format: domain.ExportFormatCsv, Fields: []string{"id", "id"}}, "Field id is selected more than once"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := newProductTestService(newExportTestProducts(), newExportTestPriceSchedules(), nil).Export(testCase.query, &buffer)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+			assert.Equal(t, 0, buffer.Len())
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/storage"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newImportTestServices(root string) (service.IProductImporter, service.IProductService) {
+	return newImportTestServicesWith(root, []domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Store: "x brand", Sku: "AIR-1", Status: domain.ProductStatusActive},
+	})
+}
+
+func newImportTestServicesWith(root string, products []domain.Product) (service.IProductImporter, service.IProductService) {
+	productRepository := NewProductRepositoryMock(products)
+	productService := newProductTestServiceWith(productRepository, NewPriceScheduleRepositoryMock(nil), nil)
+	return service.NewProductImporter(productService, productRepository, storage.NewLocalStorage(root, "/imports"), localizationSettings), productService
+}
+
+const importCsv = `Title,Unit Price,store,sku
+air conditioner,2800,x brand,AIR-1
+fax,10000,x brand,FAX-1
+iron,cheap,x brand,IRON-1
+,500,x brand,
+`
+
+func Test_Import_ShouldUpsertBySkuAndReportFailedRows(t *testing.T) {
+	t.Run("Import", func(t *testing.T) {
+		root := t.TempDir()
+		productImporter, productService := newImportTestServices(root)
+
+		report, err := productImporter.Import(strings.NewReader(importCsv), model.ImportProducts{
+			Format:   domain.ImportFormatCsv,
+			UpsertBy: domain.ImportUpsertBySku,
+			Mapping:  map[string]string{"name": "Title", "price": "Unit Price"},
+			Actor:    "user-1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 4, report.Rows)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 2, report.Failed)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, "air conditioner", product.Name)
+		assert.Equal(t, float32(2800), product.Price)
+		assert.Equal(t, "user-1", product.UpdatedBy)
+		products, _ := productService.GetAll()
+		assert.Equal(t, 2, len(products))
+
+		assert.True(t, strings.HasPrefix(report.ErrorReportUrl, "/imports/imports/"))
+		errorReport, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(report.ErrorReportUrl, "/imports/"))))
+		assert.Nil(t, err)
+		assert.Equal(t, "row,sku,error\n4,IRON-1,Price cheap is not a number\n5,,Name should not be empty\n", string(errorReport))
+	})
+}
+
+func Test_Import_ShouldReportMalformedRowsAtTheirPosition(t *testing.T) {
+	t.Run("Import", func(t *testing.T) {
+		root := t.TempDir()
+		productImporter, _ := newImportTestServices(root)
+		readErrorReport := func(report domain.ProductImportReport) string {
+			errorReport, _ := os.ReadFile(filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(report.ErrorReportUrl, "/imports/"))))
+			return string(errorReport)
+		}
+
+		csv := "name,price,store,sku\nkettle,300,x brand,KET-1\nir\"on,500,x brand,IRON-1\ntoaster,400,x brand,TOAST-1\n"
+		report, err := productImporter.Import(strings.NewReader(csv), model.ImportProducts{Format: domain.ImportFormatCsv, UpsertBy: domain.ImportUpsertBySku})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, report.Rows)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 1, report.Failed)
+		assert.False(t, report.Truncated)
+		assert.True(t, strings.HasPrefix(readErrorReport(report), "row,sku,error\n3,,"))
+
+		ndjson := `{"name": "mixer", "price": 700, "store": "x brand", "sku": "MIX-1"}
+{"name": "blender", "price":
+{"name": "grill", "price": 900, "store": "x brand", "sku": "GRILL-1"}
+`
+		report, err = productImporter.Import(strings.NewReader(ndjson), model.ImportProducts{Format: domain.ImportFormatNdjson, UpsertBy: domain.ImportUpsertBySku})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, report.Rows)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Failed)
+		assert.True(t, report.Truncated)
+		assert.True(t, strings.HasPrefix(readErrorReport(report), "row,sku,error\n2,,"))
+		assert.Contains(t, readErrorReport(report), "the lines after it are not imported")
+	})
+}
+
+func Test_Import_ShouldNotWrite_WhenDryRun(t *testing.T) {
+	t.Run("Import", func(t *testing.T) {
+		productImporter, productService := newImportTestServices(t.TempDir())
+
+		ndjson := `{"id": 1, "price": 2500}
+{"name": "fax", "price": 10000, "store": "x brand", "sku": "FAX-1", "options": ["color"]}
+`
+		report, err := productImporter.Import(strings.NewReader(ndjson), model.ImportProducts{
+			Format:   domain.ImportFormatNdjson,
+			DryRun:   true,
+			UpsertBy: domain.ImportUpsertById,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.ProductImportReport{DryRun: true, Rows: 2, Created: 1, Updated: 1}, report)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, float32(3000), product.Price)
+		products, _ := productService.GetAll()
+		assert.Equal(t, 1, len(products))
+	})
+}
+
+func Test_Import_ShouldReplaceStoreDefaultLocaleTranslation_WhenRowChangesTheText(t *testing.T) {
+	t.Run("Import", func(t *testing.T) {
+		productImporter, productService := newImportTestServicesWith(t.TempDir(), []domain.Product{
+			{Id: 1, Name: "klima", Description: "sessiz", Price: 3000.0, Store: "ABC TECH", Sku: "AIR-1", Status: domain.ProductStatusActive,
+				Translations: map[string]domain.LocalizedText{"tr": {Name: "klima", Description: "sessiz"}, "en": {Name: "air conditioner"}}},
+		})
+
+		report, err := productImporter.Import(strings.NewReader(`{"sku": "AIR-1", "store": "ABC TECH", "name": "inverter klima"}`), model.ImportProducts{
+			Format:   domain.ImportFormatNdjson,
+			UpsertBy: domain.ImportUpsertBySku,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, report.Updated)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, "inverter klima", product.Name)
+		assert.Equal(t, map[string]domain.LocalizedText{
+			"tr": {Name: "inverter klima", Description: "sessiz"},
+			"en": {Name: "air conditioner"},
+		}, product.Translations)
+		localized, _ := product.Localize([]string{"tr"})
+		assert.Equal(t, "inverter klima", localized.Name)
+	})
+}
+
+func Test_Import_ShouldCreateDrafts_WhenDraftsOnly(t *testing.T) {
+	t.Run("Import", func(t *testing.T) {
+		productImporter, productService := newImportTestServices(t.TempDir())
+
+		ndjson := `{"sku": "AIR-1", "store": "x brand", "price": 2500}
+{"name": "fax", "price": 10000, "store": "x brand", "sku": "FAX-1"}
+{"name": "tv", "price": 5000, "store": "x brand", "sku": "TV-1", "status": "active"}
+`
+		report, err := productImporter.Import(strings.NewReader(ndjson), model.ImportProducts{
+			Format:     domain.ImportFormatNdjson,
+			UpsertBy:   domain.ImportUpsertBySku,
+			DraftsOnly: true,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 1, report.Failed)
+
+		products, _ := productService.GetAll()
+		assert.Equal(t, 2, len(products))
+		assert.Equal(t, domain.ProductStatusActive, products[0].Status)
+		assert.Equal(t, domain.ProductStatusDraft, products[1].Status)
+	})
+}
+
+func Test_Import_ShouldReturnError_WhenOptionsAreInvalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		options       model.ImportProducts
+		expectedError string
+	}{
+		{"unknown format", model.ImportProducts{Format: "xml", UpsertBy: domain.ImportUpsertBySku}, "Import format xml is not supported"},
+		{"unknown upsert key", model.ImportProducts{Format: domain.ImportFormatCsv, UpsertBy: "gtin"}, "Upsert by gtin is not supported, use sku or id"},
+		{"unknown field", model.ImportProducts{Format: domain.ImportFormatCsv, UpsertBy: domain.ImportUpsertBySku, Mapping: map[string]string{"colour": "Color"}},
+			"Field colour can not be mapped, mappable fields are id, name, description, price, discount, store, category, status, sku, gtin, options"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			productImporter, _ := newImportTestServices(t.TempDir())
+			_, err := productImporter.Import(strings.NewReader(importCsv), testCase.options)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
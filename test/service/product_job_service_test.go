@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/storage"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newProductJobTestServices(uploadRoot string, fileRoot string) (*JobRepositoryMock, service.IJobService, *service.JobWorker, service.IProductJobService, service.IProductService) {
+	productRepository := NewProductRepositoryMock([]domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Store: "x brand", Sku: "AIR-1", Status: domain.ProductStatusActive},
+	})
+	productService := newProductTestServiceWith(productRepository, NewPriceScheduleRepositoryMock(nil), nil)
+	fileStorage := storage.NewLocalStorage(fileRoot, "/files")
+	productImporter := service.NewProductImporter(productService, productRepository, fileStorage, localizationSettings)
+
+	jobRepository := NewJobRepositoryMock()
+	jobService := service.NewJobService(jobRepository)
+	productJobService := service.NewProductJobService(jobService, productService, productImporter,
+		storage.NewLocalStorage(uploadRoot, ""), fileStorage)
+	jobService.HandleFinished(service.JobTypeProductImport, productJobService.FinishImport)
+	jobWorker := service.NewJobWorker(jobRepository, 1, time.Second, time.Minute)
+	jobWorker.Handle(service.JobTypeProductImport, productJobService.HandleImport)
+	jobWorker.Handle(service.JobTypeProductExport, productJobService.HandleExport)
+	jobWorker.HandleFinished(service.JobTypeProductImport, productJobService.FinishImport)
+	return jobRepository, jobService, jobWorker, productJobService, productService
+}
+
+func Test_ProductJobService_ShouldImportUploadedFileInBackground(t *testing.T) {
+	t.Run("StartImport", func(t *testing.T) {
+		uploadRoot := t.TempDir()
+		jobRepository, _, jobWorker, productJobService, productService := newProductJobTestServices(uploadRoot, t.TempDir())
+
+		_, err := productJobService.StartImport(strings.NewReader(""), model.ImportProducts{Format: "xml", UpsertBy: domain.ImportUpsertBySku})
+		assert.Equal(t, "Import format xml is not supported", err.Error())
+
+		job, err := productJobService.StartImport(strings.NewReader("name,price,store,sku\nfax,10000,x brand,FAX-1\n"),
+			model.ImportProducts{Format: domain.ImportFormatCsv, UpsertBy: domain.ImportUpsertBySku, Actor: "user-1"})
+		assert.Nil(t, err)
+		assert.Equal(t, service.JobTypeProductImport, job.Type)
+		assert.Equal(t, "user-1", job.CreatedBy)
+
+		assert.True(t, jobWorker.RunOnce(context.Background()))
+		job, _ = jobRepository.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusSucceeded, job.Status)
+		var report domain.ProductImportReport
+		assert.Nil(t, json.Unmarshal(job.Result, &report))
+		assert.Equal(t, 1, report.Created)
+
+		products, _ := productService.GetAll()
+		assert.Equal(t, 2, len(products))
+		uploads, _ := filepath.Glob(filepath.Join(uploadRoot, "imports", "*", "source.csv"))
+		assert.Empty(t, uploads)
+	})
+}
+
+func Test_ProductJobService_ShouldDeleteUploadedFile_WhenImportFinishesForGood(t *testing.T) {
+	t.Run("FinishImport", func(t *testing.T) {
+		uploadRoot := t.TempDir()
+		jobRepository, jobService, jobWorker, productJobService, _ := newProductJobTestServices(uploadRoot, t.TempDir())
+		uploads := func() []string {
+			files, _ := filepath.Glob(filepath.Join(uploadRoot, "imports", "*", "source.csv"))
+			return files
+		}
+
+		job, _ := productJobService.StartImport(strings.NewReader(""), model.ImportProducts{Format: domain.ImportFormatCsv, UpsertBy: domain.ImportUpsertBySku})
+		assert.Regexp(t, `imports/[0-9a-f]{32}/source\.csv$`, filepath.ToSlash(uploads()[0]))
+		assert.True(t, jobWorker.RunOnce(context.Background()))
+		job, _ = jobRepository.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusQueued, job.Status)
+		assert.Equal(t, 1, len(uploads()))
+
+		jobRepository.jobs[0].MaxAttempts, jobRepository.jobs[0].RunAfter = 2, time.Now()
+		assert.True(t, jobWorker.RunOnce(context.Background()))
+		job, _ = jobRepository.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusFailed, job.Status)
+		assert.Empty(t, uploads())
+
+		job, _ = productJobService.StartImport(strings.NewReader("name,price,store,sku\n"), model.ImportProducts{Format: domain.ImportFormatCsv, UpsertBy: domain.ImportUpsertBySku})
+		assert.Equal(t, 1, len(uploads()))
+		job, err := jobService.Cancel(job.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.JobStatusCancelled, job.Status)
+		assert.Empty(t, uploads())
+	})
+}
+
+func Test_ProductJobService_ShouldWriteExportFile(t *testing.T) {
+	t.Run("StartExport", func(t *testing.T) {
+		fileRoot := t.TempDir()
+		jobRepository, _, jobWorker, productJobService, _ := newProductJobTestServices(t.TempDir(), fileRoot)
+
+		_, err := productJobService.StartExport(domain.ProductExportQuery{Format: "pdf"}, "user-1")
+		assert.Equal(t, "Export format pdf is not supported", err.Error())
+
+		job, err := productJobService.StartExport(domain.ProductExportQuery{Format: domain.ExportFormatCsv, Fields: []string{"id", "name"}}, "user-1")
+		assert.Nil(t, err)
+
+		assert.True(t, jobWorker.RunOnce(context.Background()))
+		job, _ = jobRepository.GetById(job.Id)
+		assert.Equal(t, domain.JobStatusSucceeded, job.Status)
+		var result struct {
+			Url   string `json:"url"`
+			Bytes int64  `json:"bytes"`
+		}
+		assert.Nil(t, json.Unmarshal(job.Result, &result))
+		assert.Regexp(t, `^/files/exports/[0-9a-f]{32}/products\.csv$`, result.Url)
+		assert.Equal(t, int64(14), result.Bytes)
+		assert.Equal(t, int64(14), job.ProgressDone)
+
+		content, err := os.ReadFile(filepath.Join(fileRoot, filepath.FromSlash(strings.TrimPrefix(result.Url, "/files/"))))
+		assert.Nil(t, err)
+		assert.Equal(t, "id,name\n1,air\n", string(content))
+	})
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"sort"
+)
+
+type ProductMediaRepositoryMock struct {
+	productMediaList []domain.ProductMedia
+	nextId           int64
+}
+
+func NewProductMediaRepositoryMock() persistence.IProductMediaRepository {
+	return &ProductMediaRepositoryMock{nextId: 1}
+}
+
+func (productMediaRepository *ProductMediaRepositoryMock) Add(productMedia domain.ProductMedia) (int64, error) {
+	existing, _ := productMediaRepository.GetByProductId(productMedia.ProductId)
+	for _, other := range existing {
+		if other.Checksum == productMedia.Checksum {
+			return 0, persistence.ConflictError{Message: fmt.Sprintf("The same file is already attached to product %d", productMedia.ProductId)}
+		}
+	}
+	productMedia.Id = productMediaRepository.nextId
+	productMedia.Position = len(existing)
+	productMedia.IsPrimary = len(existing) == 0
+	productMediaRepository.nextId++
+	productMediaRepository.productMediaList = append(productMediaRepository.productMediaList, productMedia)
+	return productMedia.Id, nil
+}
+
+func (productMediaRepository *ProductMediaRepositoryMock) GetById(id int64) (domain.ProductMedia, error) {
+	for _, productMedia := range productMediaRepository.productMediaList {
+		if productMedia.Id == id {
+			return productMedia, nil
+		}
+	}
+
+	return domain.ProductMedia{}, errors.New(fmt.Sprintf("Media with id %d not found", id))
+}
+
+func (productMediaRepository *ProductMediaRepositoryMock) GetByProductId(productId int64) ([]domain.ProductMedia, error) {
+	return productMediaRepository.GetByProductIds([]int64{productId})
+}
+
+func (productMediaRepository *ProductMediaRepositoryMock) GetByProductIds(productIds []int64) ([]domain.ProductMedia, error) {
+	var productMediaList []domain.ProductMedia
+	for _, productMedia := range productMediaRepository.productMediaList {
+		for _, productId := range productIds {
+			if productMedia.ProductId == productId {
+				productMediaList = append(productMediaList, productMedia)
+			}
+		}
+	}
+	sort.SliceStable(productMediaList, func(i, j int) bool {
+		return productMediaList[i].Position < productMediaList[j].Position
+	})
+
+	return productMediaList, nil
+}
+
+func (productMediaRepository *ProductMediaRepositoryMock) SetPrimary(productId int64, id int64) error {
+	if _, err := productMediaRepository.GetById(id); err != nil {
+		return errors.New(fmt.Sprintf("Media with id %d not found for product %d", id, productId))
+	}
+	for i, productMedia := range productMediaRepository.productMediaList {
+		if productMedia.ProductId == productId {
+			productMediaRepository.productMediaList[i].IsPrimary = productMedia.Id == id
+		}
+	}
+	return nil
+}
+
+func (productMediaRepository *ProductMediaRepositoryMock) Reorder(productId int64, orderedIds []int64) error {
+	for position, id := range orderedIds {
+		for i, productMedia := range productMediaRepository.productMediaList {
+			if productMedia.Id == id && productMedia.ProductId == productId {
+				productMediaRepository.productMediaList[i].Position = position
+			}
+		}
+	}
+	return nil
+}
+
+func (productMediaRepository *ProductMediaRepositoryMock) DeleteById(id int64) error {
+	for i, productMedia := range productMediaRepository.productMediaList {
+		if productMedia.Id == id {
+			productMediaRepository.productMediaList = append(productMediaRepository.productMediaList[:i], productMediaRepository.productMediaList[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Media with id %d not found", id))
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/storage"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newPngImage(t *testing.T, width int, height int, fill color.Color) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+	var buffer bytes.Buffer
+	assert.Nil(t, png.Encode(&buffer, img))
+	return buffer.Bytes()
+}
+
+// newImageBombHeader is only the header of an image declaring a huge canvas, which is all a decoder needs to allocate it.
+func newImageBombHeader(format string, width uint32, height uint32) []byte {
+	var buffer bytes.Buffer
+	if format == "gif" {
+		buffer.WriteString("GIF89a")
+		binary.Write(&buffer, binary.LittleEndian, uint16(width))
+		binary.Write(&buffer, binary.LittleEndian, uint16(height))
+		buffer.Write([]byte{0, 0, 0})
+		return buffer.Bytes()
+	}
+	buffer.WriteString("\x89PNG\r\n\x1a\n")
+	chunk := []byte("IHDR")
+	chunk = binary.BigEndian.AppendUint32(chunk, width)
+	chunk = binary.BigEndian.AppendUint32(chunk, height)
+	chunk = append(chunk, 8, 6, 0, 0, 0)
+	binary.Write(&buffer, binary.BigEndian, uint32(len(chunk)-4))
+	buffer.Write(chunk)
+	binary.Write(&buffer, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buffer.Bytes()
+}
+
+func newProductMediaTestService(root string) service.IProductMediaService {
+	products := []domain.Product{{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH"}}
+	return service.NewProductMediaService(NewProductMediaRepositoryMock(), NewProductRepositoryMock(products), storage.NewLocalStorage(root, "/media"), 1<<20)
+}
+
+func Test_UploadMedia_ShouldStoreImageAndThumbnail(t *testing.T) {
+	t.Run("Upload", func(t *testing.T) {
+		root := t.TempDir()
+		productMediaService := newProductMediaTestService(root)
+
+		productMedia, err := productMediaService.Upload(1, bytes.NewReader(newPngImage(t, 1024, 512, color.RGBA{R: 200, A: 255})))
+		assert.Nil(t, err)
+		assert.Equal(t, "image/png", productMedia.ContentType)
+		assert.Equal(t, 1024, productMedia.Width)
+		assert.Equal(t, 512, productMedia.Height)
+		assert.True(t, productMedia.IsPrimary)
+		assert.Equal(t, "/media/"+productMedia.Key, productMedia.Url)
+
+		thumbnailFile, err := os.Open(filepath.Join(root, filepath.FromSlash(productMedia.ThumbnailKey)))
+		assert.Nil(t, err)
+		defer thumbnailFile.Close()
+		thumbnail, err := png.DecodeConfig(thumbnailFile)
+		assert.Nil(t, err)
+		assert.Equal(t, 256, thumbnail.Width)
+		assert.Equal(t, 128, thumbnail.Height)
+	})
+}
+
+func Test_UploadMedia_ShouldRejectDuplicatesAndNonImages(t *testing.T) {
+	productMediaService := newProductMediaTestService(t.TempDir())
+	content := newPngImage(t, 10, 10, color.White)
+
+	t.Run("duplicate", func(t *testing.T) {
+		_, err := productMediaService.Upload(1, bytes.NewReader(content))
+		assert.Nil(t, err)
+		_, err = productMediaService.Upload(1, bytes.NewReader(content))
+		var conflictError persistence.ConflictError
+		assert.True(t, errors.As(err, &conflictError))
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		_, err := productMediaService.Upload(1, bytes.NewReader([]byte("hello world")))
+		assert.Equal(t, "Unsupported content type text/plain; charset=utf-8, expected a jpeg, png or gif image", err.Error())
+	})
+
+	t.Run("huge png canvas", func(t *testing.T) {
+		_, err := productMediaService.Upload(1, bytes.NewReader(newImageBombHeader("png", 50000, 50000)))
+		assert.Equal(t, "Image should be at most 8000x8000 pixels and 40000000 pixels in total, it is 50000x50000", err.Error())
+	})
+
+	t.Run("huge gif canvas", func(t *testing.T) {
+		_, err := productMediaService.Upload(1, bytes.NewReader(newImageBombHeader("gif", 7000, 7000)))
+		assert.Equal(t, "Image should be at most 8000x8000 pixels and 40000000 pixels in total, it is 7000x7000", err.Error())
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		_, err := productMediaService.Upload(1, io.LimitReader(neverEndingReader{}, 2<<20))
+		assert.Equal(t, "File should be at most 1048576 bytes", err.Error())
+	})
+}
+
+func Test_DeleteMedia_ShouldRemoveFilesAndKeepOrder(t *testing.T) {
+	t.Run("Delete", func(t *testing.T) {
+		root := t.TempDir()
+		productMediaService := newProductMediaTestService(root)
+		first, _ := productMediaService.Upload(1, bytes.NewReader(newPngImage(t, 10, 10, color.White)))
+		second, _ := productMediaService.Upload(1, bytes.NewReader(newPngImage(t, 10, 10, color.Black)))
+
+		assert.Nil(t, productMediaService.Reorder(1, []int64{second.Id, first.Id}))
+		assert.Nil(t, productMediaService.SetPrimary(1, second.Id))
+		assert.Nil(t, productMediaService.Delete(1, first.Id))
+
+		_, err := os.Stat(filepath.Join(root, filepath.FromSlash(first.Key)))
+		assert.True(t, os.IsNotExist(err))
+
+		productMediaList, _ := productMediaService.GetByProductId(1)
+		assert.Equal(t, 1, len(productMediaList))
+		assert.True(t, productMediaList[0].IsPrimary)
+	})
+}
+
+type neverEndingReader struct{}
+
+func (neverEndingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
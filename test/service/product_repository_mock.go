@@ -5,20 +5,34 @@ import (
 	"fmt"
 	"go-product-app/domain"
 	"go-product-app/persistence"
+	"slices"
+	"strings"
+	"time"
 )
 
 type ProductRepositoryMock struct {
-	products []domain.Product
+	products        []domain.Product
+	deletedProducts []domain.Product
+	statusChanges   []domain.ProductStatusChange
+	repricings      []domain.Repricing
+	priceChanges    []domain.PriceChange
+	// mediaKeys are the storage keys of the media files of each product
+	mediaKeys map[int64][]string
 }
 
 func NewProductRepositoryMock(initialProducts []domain.Product) persistence.IProductRepository {
 	return &ProductRepositoryMock{products: initialProducts}
 }
 
-func (productRepository *ProductRepositoryMock) Add(product domain.Product) error {
-	product.Id = int64(len(productRepository.products) + 1)
+func (productRepository *ProductRepositoryMock) Add(product domain.Product) (int64, error) {
+	for _, existing := range productRepository.products {
+		if len(product.Sku) > 0 && existing.Store == product.Store && existing.Sku == product.Sku {
+			return 0, persistence.ConflictError{Message: fmt.Sprintf("Product with sku %s already exists in store %s", product.Sku, product.Store)}
+		}
+	}
+	product.Id = int64(len(productRepository.products) + len(productRepository.deletedProducts) + 1)
 	productRepository.products = append(productRepository.products, product)
-	return nil
+	return product.Id, nil
 }
 
 func (productRepository *ProductRepositoryMock) GetById(id int64) (domain.Product, error) {
@@ -31,6 +45,34 @@ func (productRepository *ProductRepositoryMock) GetById(id int64) (domain.Produc
 	return domain.Product{}, errors.New(fmt.Sprintf("Product with id %d not found", id))
 }
 
+func (productRepository *ProductRepositoryMock) GetBySku(sku string, store string) ([]domain.Product, error) {
+	var products []domain.Product
+	for _, product := range productRepository.products {
+		if product.Sku == sku && (len(store) == 0 || product.Store == store) {
+			products = append(products, product)
+		}
+	}
+	if len(products) == 0 {
+		return []domain.Product{}, persistence.NotFoundError{Message: fmt.Sprintf("Product with sku %s not found", sku)}
+	}
+
+	return products, nil
+}
+
+func (productRepository *ProductRepositoryMock) GetByIds(ids []int64) ([]domain.Product, error) {
+	var products []domain.Product
+	for _, product := range productRepository.products {
+		for _, id := range ids {
+			if product.Id == id {
+				products = append(products, product)
+				break
+			}
+		}
+	}
+
+	return products, nil
+}
+
 func (productRepository *ProductRepositoryMock) GetAll() ([]domain.Product, error) {
 	return productRepository.products, nil
 }
@@ -46,9 +88,47 @@ func (productRepository *ProductRepositoryMock) GetAllByStore(store string) ([]d
 	return products, nil
 }
 
-func (productRepository *ProductRepositoryMock) DeleteById(id int64) error {
+// GetAllByFilter only filters by store, category, price, status and update time, the mock keeps no variants so a variant filter matches nothing.
+func (productRepository *ProductRepositoryMock) GetAllByFilter(filter domain.ProductFilter) ([]domain.Product, error) {
+	var products []domain.Product
+	if len(filter.VariantAttributes) > 0 {
+		return products, nil
+	}
+	includeRemoved := filter.IncludeRemoved && filter.UpdatedSince != nil
+	candidates := productRepository.products
+	if includeRemoved {
+		candidates = append(slices.Clone(candidates), productRepository.deletedProducts...)
+	}
+	for _, product := range candidates {
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, product.Status) && !includeRemoved {
+			continue
+		}
+		if filter.UpdatedSince != nil && product.UpdatedAt.Before(*filter.UpdatedSince) {
+			continue
+		}
+		if len(filter.Category) > 0 && product.Category != filter.Category {
+			continue
+		}
+		if (filter.MinPrice != nil && product.Price < *filter.MinPrice) || (filter.MaxPrice != nil && product.Price > *filter.MaxPrice) {
+			continue
+		}
+		if len(filter.Store) == 0 || product.Store == filter.Store {
+			products = append(products, product)
+		}
+	}
+
+	return products, nil
+}
+
+// DeleteById moves the product aside like a soft delete, so it disappears from every read but can be restored.
+func (productRepository *ProductRepositoryMock) DeleteById(id int64, actor string) error {
 	for i, product := range productRepository.products {
 		if product.Id == id {
+			deletedAt := time.Now()
+			product.DeletedAt = &deletedAt
+			product.UpdatedAt = deletedAt
+			product.UpdatedBy = actor
+			productRepository.deletedProducts = append(productRepository.deletedProducts, product)
 			productRepository.products = append(productRepository.products[:i], productRepository.products[i+1:]...)
 			return nil
 		}
@@ -57,13 +137,336 @@ func (productRepository *ProductRepositoryMock) DeleteById(id int64) error {
 	return errors.New(fmt.Sprintf("Product with id %d not found", id))
 }
 
-func (productRepository *ProductRepositoryMock) UpdateProductPrice(id int64, price float32) error {
+func (productRepository *ProductRepositoryMock) UpdateProductPrice(id int64, price float32, actor string) error {
 	for i, product := range productRepository.products {
 		if product.Id == id {
 			productRepository.products[i].Price = price
+			productRepository.products[i].UpdatedBy = actor
+			productRepository.recordPriceChange(product, productRepository.products[i], domain.PriceChangeReasonUpdate)
 			return nil
 		}
 	}
 
 	return errors.New(fmt.Sprintf("Product with id %d not found", id))
 }
+
+func (productRepository *ProductRepositoryMock) UpdateTranslations(id int64, translations map[string]domain.LocalizedText, actor string) error {
+	for i, product := range productRepository.products {
+		if product.Id == id {
+			if product.Translations == nil {
+				productRepository.products[i].Translations = make(map[string]domain.LocalizedText)
+			}
+			for locale, text := range translations {
+				productRepository.products[i].Translations[locale] = text
+			}
+			productRepository.products[i].UpdatedBy = actor
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Product with id %d not found", id))
+}
+
+func (productRepository *ProductRepositoryMock) UpdateStatus(statusChange domain.ProductStatusChange) error {
+	for i, product := range productRepository.products {
+		if product.Id == statusChange.ProductId {
+			if product.Status != statusChange.FromStatus {
+				return persistence.ConflictError{Message: fmt.Sprintf("Product with id %d is no longer %s", product.Id, statusChange.FromStatus)}
+			}
+			productRepository.products[i].Status = statusChange.ToStatus
+			productRepository.products[i].UpdatedBy = statusChange.Actor
+			statusChange.Id = int64(len(productRepository.statusChanges) + 1)
+			productRepository.statusChanges = append(productRepository.statusChanges, statusChange)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Product with id %d not found", statusChange.ProductId))
+}
+
+func (productRepository *ProductRepositoryMock) GetStatusHistory(productId int64) ([]domain.ProductStatusChange, error) {
+	statusChanges := make([]domain.ProductStatusChange, 0)
+	for _, statusChange := range productRepository.statusChanges {
+		if statusChange.ProductId == productId {
+			statusChanges = append(statusChanges, statusChange)
+		}
+	}
+	return statusChanges, nil
+}
+
+func (productRepository *ProductRepositoryMock) GetAllDeleted() ([]domain.Product, error) {
+	return productRepository.deletedProducts, nil
+}
+
+func (productRepository *ProductRepositoryMock) RestoreById(id int64, actor string) error {
+	for i, product := range productRepository.deletedProducts {
+		if product.Id == id {
+			for _, existing := range productRepository.products {
+				if len(product.Sku) > 0 && existing.Store == product.Store && existing.Sku == product.Sku {
+					return persistence.ConflictError{Message: fmt.Sprintf("Product with id %d can not be restored, its sku is in use", id)}
+				}
+			}
+			product.DeletedAt = nil
+			product.UpdatedBy = actor
+			productRepository.products = append(productRepository.products, product)
+			productRepository.deletedProducts = append(productRepository.deletedProducts[:i], productRepository.deletedProducts[i+1:]...)
+			return nil
+		}
+	}
+
+	return persistence.NotFoundError{Message: fmt.Sprintf("Deleted product with id %d not found", id)}
+}
+
+func (productRepository *ProductRepositoryMock) PurgeDeleted(deletedBefore time.Time) (int64, []string, error) {
+	var kept []domain.Product
+	mediaKeys := make([]string, 0)
+	for _, product := range productRepository.deletedProducts {
+		if !product.DeletedAt.Before(deletedBefore) {
+			kept = append(kept, product)
+			continue
+		}
+		mediaKeys = append(mediaKeys, productRepository.mediaKeys[product.Id]...)
+	}
+	purged := int64(len(productRepository.deletedProducts) - len(kept))
+	productRepository.deletedProducts = kept
+	return purged, mediaKeys, nil
+}
+
+// Search matches products whose name contains the text, ignoring case, and ranks them all the same.
+func (productRepository *ProductRepositoryMock) Search(query domain.ProductSearchQuery) (domain.ProductSearchResult, error) {
+	result := domain.ProductSearchResult{Hits: make([]domain.ProductSearchHit, 0)}
+	matches, _ := productRepository.GetAllByFilter(query.Filter)
+	for _, product := range matches {
+		if !strings.Contains(strings.ToLower(product.Name), strings.ToLower(query.Text)) {
+			continue
+		}
+		if result.Total >= int64(query.Offset) && len(result.Hits) < query.Limit {
+			result.Hits = append(result.Hits, domain.ProductSearchHit{Product: product, Rank: 1, Snippet: product.Name})
+		}
+		result.Total++
+	}
+	return result, nil
+}
+
+// GetStats aggregates the filtered products in memory, ordering the groups by their keys like the SQL does.
+func (productRepository *ProductRepositoryMock) GetStats(query domain.ProductStatsQuery) ([]domain.ProductStatsGroup, error) {
+	matches, _ := productRepository.GetAllByFilter(query.Filter)
+	groupsByKey := make(map[string]*domain.ProductStatsGroup)
+	var groupKeys []string
+	for _, product := range matches {
+		keys := make(map[string]string, len(query.GroupBy))
+		keyParts := make([]string, 0, len(query.GroupBy))
+		for _, dimension := range query.GroupBy {
+			switch dimension {
+			case domain.StatsDimensionStore:
+				keys[dimension] = product.Store
+			case domain.StatsDimensionCategory:
+				keys[dimension] = product.Category
+			case domain.StatsDimensionDiscountBand:
+				keys[dimension] = domain.DiscountBandOf(product.Discount)
+			default:
+				return nil, errors.New(fmt.Sprintf("Unknown stats dimension %s", dimension))
+			}
+			keyParts = append(keyParts, keys[dimension])
+		}
+		groupKey := strings.Join(keyParts, "|")
+		group, exists := groupsByKey[groupKey]
+		if !exists {
+			group = &domain.ProductStatsGroup{Keys: keys, MinPrice: float64(product.Price), MaxPrice: float64(product.Price)}
+			groupsByKey[groupKey] = group
+			groupKeys = append(groupKeys, groupKey)
+		}
+		group.AveragePrice = (group.AveragePrice*float64(group.Count) + float64(product.Price)) / float64(group.Count+1)
+		group.AverageDiscount = (group.AverageDiscount*float64(group.Count) + float64(product.Discount)) / float64(group.Count+1)
+		group.MinPrice = min(group.MinPrice, float64(product.Price))
+		group.MaxPrice = max(group.MaxPrice, float64(product.Price))
+		group.Count++
+	}
+
+	slices.Sort(groupKeys)
+	groups := make([]domain.ProductStatsGroup, 0, len(groupKeys))
+	for _, groupKey := range groupKeys {
+		groups = append(groups, *groupsByKey[groupKey])
+	}
+	if len(query.GroupBy) == 0 && len(groups) == 0 {
+		groups = append(groups, domain.ProductStatsGroup{Keys: map[string]string{}})
+	}
+	return groups, nil
+}
+
+func (productRepository *ProductRepositoryMock) AddBatch(products []domain.Product, atomic bool) ([]domain.BatchItemResult, error) {
+	return productRepository.runBatch(len(products), atomic, func(i int) (int64, error) {
+		return productRepository.Add(products[i])
+	})
+}
+
+func (productRepository *ProductRepositoryMock) UpdatePricesBatch(updates []domain.ProductPriceUpdate, actor string, atomic bool) ([]domain.BatchItemResult, error) {
+	return productRepository.runBatch(len(updates), atomic, func(i int) (int64, error) {
+		return updates[i].Id, productRepository.UpdateProductPrice(updates[i].Id, updates[i].Price, actor)
+	})
+}
+
+func (productRepository *ProductRepositoryMock) DeleteBatch(ids []int64, actor string, atomic bool) ([]domain.BatchItemResult, error) {
+	return productRepository.runBatch(len(ids), atomic, func(i int) (int64, error) {
+		return ids[i], productRepository.DeleteById(ids[i], actor)
+	})
+}
+
+// runBatch applies the items one by one, an atomic batch with a failed item restores the products it started with.
+func (productRepository *ProductRepositoryMock) runBatch(size int, atomic bool, apply func(i int) (int64, error)) ([]domain.BatchItemResult, error) {
+	products, deletedProducts := slices.Clone(productRepository.products), slices.Clone(productRepository.deletedProducts)
+	results := make([]domain.BatchItemResult, size)
+	failed := false
+	for i := range results {
+		id, err := apply(i)
+		results[i] = domain.BatchItemResult{Index: i, Id: id, Status: domain.BatchItemSucceeded}
+		if err != nil {
+			results[i] = domain.BatchItemResult{Index: i, Id: id, Status: domain.BatchItemFailed, Error: err.Error()}
+			failed = true
+		}
+	}
+
+	if atomic && failed {
+		productRepository.products, productRepository.deletedProducts = products, deletedProducts
+		for i := range results {
+			if results[i].Status == domain.BatchItemSucceeded {
+				results[i].Status = domain.BatchItemNotApplied
+			}
+		}
+	}
+	return results, nil
+}
+
+func (productRepository *ProductRepositoryMock) Update(product domain.Product) error {
+	index := slices.IndexFunc(productRepository.products, func(existing domain.Product) bool { return existing.Id == product.Id })
+	if index < 0 {
+		return persistence.NotFoundError{Message: fmt.Sprintf("Product with id %d not found", product.Id)}
+	}
+	existing := productRepository.products[index]
+	for _, other := range productRepository.products {
+		if other.Id != product.Id && len(product.Sku) > 0 && other.Store == existing.Store && other.Sku == product.Sku {
+			return persistence.ConflictError{Message: fmt.Sprintf("Product with sku %s already exists in store %s", product.Sku, existing.Store)}
+		}
+	}
+
+	product.Store, product.Status = existing.Store, existing.Status
+	product.CreatedAt, product.CreatedBy = existing.CreatedAt, existing.CreatedBy
+	productRepository.products[index] = product
+	productRepository.recordPriceChange(existing, product, domain.PriceChangeReasonUpdate)
+	return nil
+}
+
+func (productRepository *ProductRepositoryMock) StreamByFilter(filter domain.ProductFilter, chunkSize int, handle func(products []domain.Product) error) error {
+	products, _ := productRepository.GetAllByFilter(filter)
+	for start := 0; start < len(products); start += chunkSize {
+		if err := handle(products[start:min(start+chunkSize, len(products))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (productRepository *ProductRepositoryMock) Reprice(repricing domain.Repricing) (domain.Repricing, []domain.PriceChange, error) {
+	products, _ := productRepository.GetAllByFilter(repricing.Filter)
+	changes, err := domain.PlanRepricing(products, repricing.Operation)
+	if err != nil {
+		return domain.Repricing{}, nil, err
+	}
+
+	repricing.Id = int64(len(productRepository.repricings) + 1)
+	repricing.Status, repricing.Affected, repricing.CreatedAt = domain.RepricingStatusApplied, len(changes), time.Now()
+	productRepository.repricings = append(productRepository.repricings, repricing)
+	for i := range changes {
+		changes[i].RepricingId, changes[i].Reason, changes[i].Actor = repricing.Id, domain.PriceChangeReasonRepricing, repricing.CreatedBy
+		productRepository.applyPriceChange(changes[i])
+	}
+	return repricing, changes, nil
+}
+
+func (productRepository *ProductRepositoryMock) GetRepricingById(id int64) (domain.Repricing, error) {
+	if id < 1 || int(id) > len(productRepository.repricings) {
+		return domain.Repricing{}, persistence.NotFoundError{Message: fmt.Sprintf("Repricing with id %d not found", id)}
+	}
+	return productRepository.repricings[id-1], nil
+}
+
+func (productRepository *ProductRepositoryMock) UndoRepricing(id int64, actor string) (domain.Repricing, []domain.PriceChange, error) {
+	repricing, err := productRepository.GetRepricingById(id)
+	if err != nil {
+		return domain.Repricing{}, nil, err
+	}
+	if repricing.Status == domain.RepricingStatusUndone {
+		return domain.Repricing{}, nil, persistence.ConflictError{Message: fmt.Sprintf("Repricing with id %d is already undone", id)}
+	}
+
+	var reverted []domain.PriceChange
+	for _, change := range slices.Clone(productRepository.priceChanges) {
+		if change.RepricingId != id || change.Reason != domain.PriceChangeReasonRepricing {
+			continue
+		}
+		current, err := productRepository.GetById(change.ProductId)
+		if err != nil || current.Price != change.NewPrice || current.Discount != change.NewDiscount {
+			continue
+		}
+		undo := domain.PriceChange{
+			ProductId:   change.ProductId,
+			ProductName: change.ProductName,
+			RepricingId: id,
+			Reason:      domain.PriceChangeReasonUndo,
+			OldPrice:    change.NewPrice,
+			NewPrice:    change.OldPrice,
+			OldDiscount: change.NewDiscount,
+			NewDiscount: change.OldDiscount,
+			Actor:       actor,
+		}
+		productRepository.applyPriceChange(undo)
+		reverted = append(reverted, undo)
+	}
+
+	undoneAt := time.Now()
+	repricing.Status, repricing.UndoneBy, repricing.UndoneAt = domain.RepricingStatusUndone, actor, &undoneAt
+	repricing.Reverted, repricing.Skipped = len(reverted), repricing.Affected-len(reverted)
+	productRepository.repricings[id-1] = repricing
+	return repricing, reverted, nil
+}
+
+func (productRepository *ProductRepositoryMock) GetPriceHistory(productId int64) ([]domain.PriceChange, error) {
+	changes := make([]domain.PriceChange, 0)
+	for _, change := range productRepository.priceChanges {
+		if change.ProductId == productId {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (productRepository *ProductRepositoryMock) applyPriceChange(change domain.PriceChange) {
+	for i := range productRepository.products {
+		if productRepository.products[i].Id == change.ProductId {
+			productRepository.products[i].Price, productRepository.products[i].Discount = change.NewPrice, change.NewDiscount
+			productRepository.products[i].UpdatedBy = change.Actor
+		}
+	}
+	change.Id = int64(len(productRepository.priceChanges) + 1)
+	change.ChangedAt = time.Now()
+	productRepository.priceChanges = append(productRepository.priceChanges, change)
+}
+
+// recordPriceChange adds a history entry when the write changed the price or discount, like the price_history CTE.
+func (productRepository *ProductRepositoryMock) recordPriceChange(before domain.Product, after domain.Product, reason string) {
+	if before.Price == after.Price && before.Discount == after.Discount {
+		return
+	}
+	productRepository.priceChanges = append(productRepository.priceChanges, domain.PriceChange{
+		Id:          int64(len(productRepository.priceChanges) + 1),
+		ProductId:   after.Id,
+		ProductName: after.Name,
+		Reason:      reason,
+		OldPrice:    before.Price,
+		NewPrice:    after.Price,
+		OldDiscount: before.Discount,
+		NewDiscount: after.Discount,
+		Actor:       after.UpdatedBy,
+		ChangedAt:   time.Now(),
+	})
+}
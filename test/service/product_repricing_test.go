@@ -0,0 +1,98 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service/model"
+	"testing"
+)
+
+func newRepricingTestProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH", Category: "cooling", Status: domain.ProductStatusActive},
+		{Id: 2, Name: "iron", Price: 1500.0, Discount: 10.0, Store: "ABC TECH", Category: "home", Status: domain.ProductStatusActive},
+		{Id: 3, Name: "phone", Price: 2000.0, Discount: 0.0, Store: "x brand", Category: "mobile", Status: domain.ProductStatusActive},
+	}
+}
+
+func Test_PreviewRepricing_ShouldShowChangesWithoutWriting(t *testing.T) {
+	t.Run("PreviewRepricing", func(t *testing.T) {
+		productService := newProductTestService(newRepricingTestProducts(), nil, nil)
+
+		preview, err := productService.PreviewRepricing(model.RepriceProducts{Store: "ABC TECH", Operation: domain.RepricingPercentChange, Value: 5})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, preview.Matched)
+		assert.Equal(t, []domain.PriceChange{
+			{ProductId: 1, ProductName: "air", OldPrice: 3000, NewPrice: 3150, OldDiscount: 22, NewDiscount: 22},
+			{ProductId: 2, ProductName: "iron", OldPrice: 1500, NewPrice: 1575, OldDiscount: 10, NewDiscount: 10},
+		}, preview.Changes)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, float32(3000), product.Price)
+	})
+}
+
+func Test_Reprice_ShouldValidateTheCommand(t *testing.T) {
+	testCases := []struct {
+		reprice       model.RepriceProducts
+		expectedError string
+	}{
+		{model.RepriceProducts{Operation: domain.RepricingPercentChange, Value: 5}, "Repricing should be filtered by store, category or price"},
+		{model.RepriceProducts{Store: "ABC TECH", Operation: "multiply", Value: 2}, "Repricing operation multiply is not valid"},
+		{model.RepriceProducts{Store: "ABC TECH", Operation: domain.RepricingPercentChange, Value: -100}, "Percent change should be greater than -100"},
+		{model.RepriceProducts{Store: "ABC TECH", Operation: domain.RepricingSetPrice, Value: 0}, "Price should be greater than 0"},
+		{model.RepriceProducts{Store: "ABC TECH", Operation: domain.RepricingSetDiscount, Value: 80}, "Discount should be between 0 and 70"},
+		{model.RepriceProducts{Store: "ABC TECH", Operation: domain.RepricingAbsoluteChange, Value: -2000}, "Repricing would set the price of product 2 to -500.00"},
+	}
+
+	productService := newProductTestService(newRepricingTestProducts(), nil, nil)
+	for _, testCase := range testCases {
+		t.Run(testCase.expectedError, func(t *testing.T) {
+			_, err := productService.Reprice(testCase.reprice)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
+
+func Test_Reprice_ShouldRecordHistoryAndBeUndoable(t *testing.T) {
+	t.Run("Reprice", func(t *testing.T) {
+		productService := newProductTestService(newRepricingTestProducts(), nil, nil)
+		maxPrice := float32(2000)
+
+		repricing, err := productService.Reprice(model.RepriceProducts{MaxPrice: &maxPrice, Operation: domain.RepricingSetDiscount, Value: 10, Actor: "finance"})
+		assert.Nil(t, err)
+		assert.Equal(t, domain.RepricingStatusApplied, repricing.Status)
+		assert.Equal(t, 1, repricing.Affected)
+
+		product, _ := productService.GetById(3)
+		assert.Equal(t, float32(10), product.Discount)
+		assert.Equal(t, "finance", product.UpdatedBy)
+		history, _ := productService.GetPriceHistory(3)
+		assert.Equal(t, 1, len(history))
+		assert.Equal(t, domain.PriceChangeReasonRepricing, history[0].Reason)
+		assert.Equal(t, repricing.Id, history[0].RepricingId)
+
+		repricing, err = productService.Reprice(model.RepriceProducts{Store: "ABC TECH", Operation: domain.RepricingPercentChange, Value: 10, Actor: "finance"})
+		assert.Nil(t, err)
+		assert.Nil(t, productService.UpdatePrice(2, 1700, "editor"))
+
+		repricing, err = productService.UndoRepricing(repricing.Id, "finance")
+		assert.Nil(t, err)
+		assert.Equal(t, domain.RepricingStatusUndone, repricing.Status)
+		assert.Equal(t, 1, repricing.Reverted)
+		assert.Equal(t, 1, repricing.Skipped)
+
+		air, _ := productService.GetById(1)
+		assert.Equal(t, float32(3000), air.Price)
+		iron, _ := productService.GetById(2)
+		assert.Equal(t, float32(1700), iron.Price)
+		history, _ = productService.GetPriceHistory(2)
+		assert.Equal(t, []string{domain.PriceChangeReasonRepricing, domain.PriceChangeReasonUpdate}, []string{history[0].Reason, history[1].Reason})
+		assert.Equal(t, "editor", history[1].Actor)
+
+		_, err = productService.UndoRepricing(repricing.Id, "finance")
+		assert.Equal(t, "Repricing with id 2 is already undone", err.Error())
+		_, err = productService.GetRepricingById(9)
+		assert.Equal(t, "Repricing with id 9 not found", err.Error())
+	})
+}
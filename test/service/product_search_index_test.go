@@ -0,0 +1,95 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/search"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"testing"
+)
+
+func Test_SearchIndex_ShouldFollowProductWrites(t *testing.T) {
+	t.Run("SearchIndex", func(t *testing.T) {
+		productService := newProductTestService([]domain.Product{
+			{Id: 1, Name: "steam iron", Price: 1500.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		}, nil, nil)
+
+		err := productService.RebuildSearchIndex()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, productService.SearchIndex(search.Query{Text: "iron"}).Total)
+
+		err = productService.Add(model.CreateProduct{Name: "travel iron", Price: 900.0, Store: "x brand", Sku: "IRON-2"})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, productService.SearchIndex(search.Query{Text: "iron"}).Total)
+
+		err = productService.UpdatePrice(2, 400.0, "user-1")
+		assert.Nil(t, err)
+		result := productService.SearchIndex(search.Query{Text: "travel"})
+		assert.Equal(t, float32(400.0), result.Hits[0].Product.Price)
+
+		err = productService.DeleteById(1, "user-1")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, productService.SearchIndex(search.Query{Text: "steam"}).Total)
+
+		err = productService.RestoreById(1, "user-1")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, productService.SearchIndex(search.Query{Text: "steam"}).Total)
+	})
+}
+
+func Test_SearchIndex_ShouldFollowProductChangesOfOtherInstances(t *testing.T) {
+	productRepository := NewProductRepositoryMock([]domain.Product{
+		{Id: 1, Name: "steam iron", Price: 1500.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 2, Name: "travel iron", Price: 900.0, Store: "x brand", Status: domain.ProductStatusActive},
+	})
+	productService := newProductTestServiceWith(productRepository, NewPriceScheduleRepositoryMock(nil), nil)
+	assert.Nil(t, productService.RebuildSearchIndex())
+
+	t.Run("Update", func(t *testing.T) {
+		assert.Nil(t, productRepository.UpdateProductPrice(2, 400.0, "user-1"))
+		assert.Equal(t, float32(900.0), productService.SearchIndex(search.Query{Text: "travel"}).Hits[0].Product.Price)
+
+		assert.Nil(t, productService.RefreshSearchIndex([]domain.ProductChange{{Operation: domain.ProductChangeUpdate, ProductId: 2, Store: "x brand"}}))
+		assert.Equal(t, float32(400.0), productService.SearchIndex(search.Query{Text: "travel"}).Hits[0].Product.Price)
+	})
+	t.Run("Delete", func(t *testing.T) {
+		assert.Nil(t, productRepository.DeleteById(1, "user-1"))
+
+		assert.Nil(t, productService.RefreshSearchIndex([]domain.ProductChange{{Operation: domain.ProductChangeDelete, ProductId: 1, Store: "ABC TECH"}}))
+		assert.Equal(t, 0, productService.SearchIndex(search.Query{Text: "steam"}).Total)
+	})
+	t.Run("Resync", func(t *testing.T) {
+		assert.Nil(t, productRepository.RestoreById(1, "user-1"))
+
+		assert.Nil(t, productService.RefreshSearchIndex([]domain.ProductChange{{Operation: domain.ProductChangeResync}}))
+		assert.Equal(t, 2, productService.SearchIndex(search.Query{Text: "iron"}).Total)
+	})
+}
+
+func Test_SearchIndexRefresher_ShouldApplyQueuedChangesInOneBatch(t *testing.T) {
+	productRepository := NewProductRepositoryMock([]domain.Product{
+		{Id: 1, Name: "steam iron", Price: 1500.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 2, Name: "travel iron", Price: 900.0, Store: "x brand", Status: domain.ProductStatusActive},
+		{Id: 3, Name: "iron board", Price: 300.0, Store: "x brand", Status: domain.ProductStatusActive},
+	})
+	productService := newProductTestServiceWith(productRepository, NewPriceScheduleRepositoryMock(nil), nil)
+	assert.Nil(t, productService.RebuildSearchIndex())
+	searchIndexRefresher := service.NewSearchIndexRefresher(productService)
+
+	assert.Nil(t, productRepository.UpdateProductPrice(2, 400.0, "user-1"))
+	assert.Nil(t, productRepository.UpdateProductPrice(2, 450.0, "user-1"))
+	assert.Nil(t, productRepository.DeleteById(1, "user-1"))
+	assert.Nil(t, productRepository.DeleteById(3, "user-1"))
+	searchIndexRefresher.HandleChange(domain.ProductChange{Operation: domain.ProductChangeUpdate, ProductId: 2})
+	searchIndexRefresher.HandleChange(domain.ProductChange{Operation: domain.ProductChangeUpdate, ProductId: 2})
+	searchIndexRefresher.HandleChange(domain.ProductChange{Operation: domain.ProductChangeDelete, ProductId: 1})
+	// a soft delete is notified as an update, the product is not found any more
+	searchIndexRefresher.HandleChange(domain.ProductChange{Operation: domain.ProductChangeUpdate, ProductId: 3})
+	assert.Equal(t, 3, productService.SearchIndex(search.Query{Text: "iron"}).Total)
+
+	assert.Nil(t, searchIndexRefresher.RunOnce())
+	result := productService.SearchIndex(search.Query{Text: "iron"})
+	assert.Equal(t, 1, result.Total)
+	assert.Equal(t, float32(450.0), result.Hits[0].Product.Price)
+}
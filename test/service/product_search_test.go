@@ -0,0 +1,57 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func newSearchTestProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "steam iron", Price: 1500.0, Store: "ABC TECH"},
+		{Id: 2, Name: "travel iron", Price: 900.0, Store: "x brand"},
+		{Id: 3, Name: "fax", Price: 10000.0, Store: "ABC TECH"},
+		{Id: 4, Name: "iron board", Price: 700.0, Store: "ABC TECH"},
+	}
+}
+
+func Test_Search_ShouldPaginateMatches(t *testing.T) {
+	t.Run("Search", func(t *testing.T) {
+		result, err := newProductTestService(newSearchTestProducts(), nil, nil).Search(domain.ProductSearchQuery{Text: " iron ", Filter: domain.ProductFilter{Store: "ABC TECH"}, Limit: 1, Offset: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), result.Total)
+		assert.Equal(t, 1, len(result.Hits))
+		assert.Equal(t, int64(4), result.Hits[0].Product.Id)
+		assert.Equal(t, 1, result.Limit)
+	})
+}
+
+func Test_Search_ShouldUseDefaults(t *testing.T) {
+	t.Run("Search", func(t *testing.T) {
+		result, err := newProductTestService(newSearchTestProducts(), nil, nil).Search(domain.ProductSearchQuery{Text: "iron"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), result.Total)
+		assert.Equal(t, 20, result.Limit)
+	})
+}
+
+func Test_Search_ShouldReturnError_WhenQueryIsInvalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         domain.ProductSearchQuery
+		expectedError string
+	}{
+		{"empty text", domain.ProductSearchQuery{Text: "  "}, "Search text should not be empty"},
+		{"unsupported language", domain.ProductSearchQuery{Text: "iron", Language: "de"}, "Search language de is not supported"},
+		{"limit too large", domain.ProductSearchQuery{Text: "iron", Limit: 500}, "Limit should be between 1 and 100"},
+		{"negative offset", domain.ProductSearchQuery{Text: "iron", Offset: -1}, "Offset should not be negative"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := newProductTestService(newSearchTestProducts(), nil, nil).Search(testCase.query)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"github.com/stretchr/testify/assert"
+	"go-product-app/common/localization"
+	"go-product-app/common/search"
 	"go-product-app/domain"
+	"go-product-app/persistence"
 	"go-product-app/service"
 	"go-product-app/service/model"
 	"os"
@@ -11,6 +14,8 @@ import (
 
 var productService service.IProductService
 
+var localizationSettings = localization.Settings{DefaultLocale: "en", StoreDefaultLocales: map[string]string{"ABC TECH": "tr"}}
+
 func TestMain(m *testing.M) {
 	initialProducts := []domain.Product{
 		{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH"},
@@ -18,13 +23,25 @@ func TestMain(m *testing.M) {
 		{Id: 3, Name: "fax", Price: 10000.0, Discount: 15.0, Store: "ABC TECH"},
 		{Id: 4, Name: "phone", Price: 2000.0, Discount: 0.0, Store: "x brand"},
 	}
-	productRepositoryMock := NewProductRepositoryMock(initialProducts)
-	productService = service.NewProductService(productRepositoryMock)
+	productService = newProductTestService(initialProducts, nil, nil)
 
 	exitCode := m.Run()
 	os.Exit(exitCode)
 }
 
+// newProductTestService builds a product service over repository mocks holding the given rows, nil leaves a mock empty.
+func newProductTestService(products []domain.Product, priceSchedules []domain.PriceSchedule, attributeDefinitions []domain.AttributeDefinition) service.IProductService {
+	return newProductTestServiceWith(NewProductRepositoryMock(products), NewPriceScheduleRepositoryMock(priceSchedules), attributeDefinitions)
+}
+
+// newProductTestServiceWith builds a product service over the given repositories, for tests that share them with
+// another service or look into them.
+func newProductTestServiceWith(productRepository persistence.IProductRepository, priceScheduleRepository persistence.IPriceScheduleRepository,
+	attributeDefinitions []domain.AttributeDefinition) service.IProductService {
+	return service.NewProductService(productRepository, priceScheduleRepository, NewAttributeDefinitionRepositoryMock(attributeDefinitions),
+		localizationSettings, search.NewMemoryIndex(search.DefaultPriceBuckets))
+}
+
 func Test_GetAll_ShouldReturnAllProducts(t *testing.T) {
 	t.Run("GetAll", func(t *testing.T) {
 		products, _ := productService.GetAll()
@@ -34,17 +51,27 @@ func Test_GetAll_ShouldReturnAllProducts(t *testing.T) {
 
 func Test_Add_ShouldAddProduct_WhenProductIsValid(t *testing.T) {
 	t.Run("Add", func(t *testing.T) {
-		product := model.CreateProduct{Name: "tv", Price: 5000.0, Discount: 10.0, Store: "ABC TECH"}
+		product := model.CreateProduct{Name: "tv", Price: 5000.0, Discount: 10.0, Store: "ABC TECH", Sku: "TV-1"}
 		err := productService.Add(product)
 		allProducts, _ := productService.GetAll()
 		assert.Nil(t, err)
 		assert.Equal(t, 5, len(allProducts))
 		assert.Equal(t, domain.Product{
-			Id: 5, Name: "tv", Price: 5000.0, Discount: 10.0, Store: "ABC TECH",
+			Id: 5, Name: "tv", Price: 5000.0, Discount: 10.0, Store: "ABC TECH", Status: domain.ProductStatusActive, Sku: "TV-1",
+			Translations: map[string]domain.LocalizedText{"tr": {Name: "tv"}},
 		}, allProducts[4])
 	})
 }
 
+func Test_Add_ShouldReturnError_WhenSkuIsMissing(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		product := model.CreateProduct{Name: "tv", Price: 5000.0, Discount: 10.0, Store: "ABC TECH", Sku: " "}
+		err := productService.Add(product)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Sku is required", err.Error())
+	})
+}
+
 func Test_Add_ShouldReturnError_WhenDiscountIsInvalid(t *testing.T) {
 	t.Run("Add", func(t *testing.T) {
 		product := model.CreateProduct{Name: "tv", Price: 5000.0, Discount: 80.0, Store: "AVV"}
@@ -56,7 +83,7 @@ func Test_Add_ShouldReturnError_WhenDiscountIsInvalid(t *testing.T) {
 
 func Test_UpdatePrice_ShouldUpdatePrice_WhenProductExists(t *testing.T) {
 	t.Run("UpdatePrice", func(t *testing.T) {
-		err := productService.UpdatePrice(1, 4000.0)
+		err := productService.UpdatePrice(1, 4000.0, "user-1")
 		product, _ := productService.GetById(1)
 		assert.Equal(t, float32(4000.0), product.Price)
 		assert.Nil(t, err)
@@ -65,7 +92,7 @@ func Test_UpdatePrice_ShouldUpdatePrice_WhenProductExists(t *testing.T) {
 
 func Test_UpdatePrice_ShouldReturnError_WhenProductDoesNotExist(t *testing.T) {
 	t.Run("UpdatePrice", func(t *testing.T) {
-		err := productService.UpdatePrice(100, 4000.0)
+		err := productService.UpdatePrice(100, 4000.0, "user-1")
 		assert.NotNil(t, err)
 		assert.Equal(t, "Product with id 100 not found", err.Error())
 	})
@@ -87,7 +114,7 @@ func Test_GetAllByStore(t *testing.T) {
 
 func Test_DeleteById_ShouldDeleteProduct_WhenProductExists(t *testing.T) {
 	t.Run("DeleteById", func(t *testing.T) {
-		err := productService.DeleteById(1)
+		err := productService.DeleteById(1, "user-1")
 		products, _ := productService.GetAll()
 		assert.Nil(t, err)
 		assert.Equal(t, 3, len(products))
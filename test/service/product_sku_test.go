@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service/model"
+	"testing"
+)
+
+func newSkuTestProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "phone", Price: 2000.0, Store: "ABC TECH", Sku: "PH-1"},
+		{Id: 2, Name: "phone", Price: 2100.0, Store: "x brand", Sku: "PH-1"},
+		{Id: 3, Name: "fax", Price: 10000.0, Store: "ABC TECH", Sku: "FX-1"},
+	}
+}
+
+func Test_Add_ShouldReturnConflictError_WhenSkuExistsInStore(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		err := newProductTestService(newSkuTestProducts(), nil, nil).Add(model.CreateProduct{Name: "phone", Price: 2000.0, Store: "ABC TECH", Sku: "PH-1"})
+		var conflictError persistence.ConflictError
+		assert.True(t, errors.As(err, &conflictError))
+		assert.Equal(t, "Product with sku PH-1 already exists in store ABC TECH", err.Error())
+	})
+}
+
+func Test_Add_ShouldReturnError_WhenGtinIsInvalid(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		err := newProductTestService(newSkuTestProducts(), nil, nil).Add(model.CreateProduct{Name: "tv", Price: 5000.0, Store: "ABC TECH", Sku: "TV-1", Gtin: "4006381333932"})
+		assert.NotNil(t, err)
+		assert.Equal(t, "Gtin check digit should be 1", err.Error())
+	})
+}
+
+func Test_GetBySku(t *testing.T) {
+	productService := newProductTestService(newSkuTestProducts(), nil, nil)
+
+	t.Run("unique sku", func(t *testing.T) {
+		product, err := productService.GetBySku("FX-1", "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), product.Id)
+	})
+
+	t.Run("sku in several stores", func(t *testing.T) {
+		_, err := productService.GetBySku("PH-1", "")
+		assert.Equal(t, "Sku PH-1 exists in 2 stores, store parameter is required", err.Error())
+
+		product, err := productService.GetBySku("PH-1", "x brand")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), product.Id)
+	})
+
+	t.Run("unknown sku", func(t *testing.T) {
+		_, err := productService.GetBySku("NOPE", "")
+		var notFoundError persistence.NotFoundError
+		assert.True(t, errors.As(err, &notFoundError))
+	})
+}
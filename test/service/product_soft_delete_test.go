@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/common/storage"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_DeleteById_ShouldKeepProductRestorable(t *testing.T) {
+	t.Run("DeleteById", func(t *testing.T) {
+		productService := newProductTestService([]domain.Product{
+			{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH"},
+			{Id: 2, Name: "iron", Price: 1500.0, Store: "ABC TECH"},
+		}, nil, nil)
+
+		err := productService.DeleteById(1, "user-1")
+		assert.Nil(t, err)
+		_, err = productService.GetById(1)
+		assert.NotNil(t, err)
+
+		deletedProducts, _ := productService.GetAllDeleted()
+		assert.Equal(t, 1, len(deletedProducts))
+		assert.NotNil(t, deletedProducts[0].DeletedAt)
+
+		err = productService.RestoreById(1, "user-1")
+		assert.Nil(t, err)
+		product, _ := productService.GetById(1)
+		assert.Equal(t, "air", product.Name)
+		assert.Nil(t, product.DeletedAt)
+
+		err = productService.RestoreById(1, "user-1")
+		var notFoundError persistence.NotFoundError
+		assert.True(t, errors.As(err, &notFoundError))
+	})
+}
+
+func Test_RestoreById_ShouldReturnConflictError_WhenSkuIsTaken(t *testing.T) {
+	t.Run("RestoreById", func(t *testing.T) {
+		productService := newProductTestService([]domain.Product{
+			{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH", Sku: "AIR-1"},
+		}, nil, nil)
+
+		productService.DeleteById(1, "user-1")
+		err := productService.Add(model.CreateProduct{Name: "air 2", Price: 3200.0, Store: "ABC TECH", Sku: "AIR-1"})
+		assert.Nil(t, err)
+
+		err = productService.RestoreById(1, "user-1")
+		var conflictError persistence.ConflictError
+		assert.True(t, errors.As(err, &conflictError))
+	})
+}
+
+func Test_ProductPurger_ShouldPurgeOnlyAfterRetention(t *testing.T) {
+	t.Run("RunOnce", func(t *testing.T) {
+		productRepositoryMock := NewProductRepositoryMock([]domain.Product{{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH"}})
+		mediaKeys := []string{"products/1/a.png", "products/1/a_thumb.png"}
+		productRepositoryMock.(*ProductRepositoryMock).mediaKeys = map[int64][]string{1: mediaKeys}
+		mediaStorage := storage.NewLocalStorage(t.TempDir(), "/media")
+		for _, key := range mediaKeys {
+			mediaStorage.Save(key, strings.NewReader("image"))
+		}
+		productRepositoryMock.DeleteById(1, "user-1")
+		productPurger := service.NewProductPurger(productRepositoryMock, mediaStorage, 24*time.Hour, time.Hour)
+
+		productPurger.RunOnce(time.Now().Add(time.Hour))
+		deletedProducts, _ := productRepositoryMock.GetAllDeleted()
+		assert.Equal(t, 1, len(deletedProducts))
+		file, err := mediaStorage.Open(mediaKeys[0])
+		assert.Nil(t, err)
+		file.Close()
+
+		productPurger.RunOnce(time.Now().Add(25 * time.Hour))
+		deletedProducts, _ = productRepositoryMock.GetAllDeleted()
+		assert.Equal(t, 0, len(deletedProducts))
+		for _, key := range mediaKeys {
+			_, err = mediaStorage.Open(key)
+			assert.True(t, errors.Is(err, os.ErrNotExist))
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"testing"
+)
+
+func newStatsTestProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH", Category: "climate", Status: domain.ProductStatusActive},
+		{Id: 2, Name: "iron", Price: 1500.0, Discount: 10.0, Store: "ABC TECH", Category: "home", Status: domain.ProductStatusActive},
+		{Id: 3, Name: "fax", Price: 10000.0, Discount: 15.0, Store: "ABC TECH", Category: "office", Status: domain.ProductStatusDraft},
+		{Id: 4, Name: "phone", Price: 2000.0, Store: "x brand", Category: "home", Status: domain.ProductStatusActive},
+	}
+}
+
+func Test_GetStats_ShouldGroupByDimensions(t *testing.T) {
+	t.Run("GetStats", func(t *testing.T) {
+		groups, err := newProductTestService(newStatsTestProducts(), nil, nil).GetStats(domain.ProductStatsQuery{
+			GroupBy: []string{domain.StatsDimensionStore, domain.StatsDimensionDiscountBand},
+			Filter:  domain.ProductFilter{Statuses: []string{domain.ProductStatusActive}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(groups))
+		assert.Equal(t, map[string]string{"store": "ABC TECH", "discount_band": "10-20"}, groups[0].Keys)
+		assert.Equal(t, map[string]string{"store": "x brand", "discount_band": "none"}, groups[2].Keys)
+		assert.Equal(t, 2000.0, groups[2].MaxPrice)
+	})
+}
+
+func Test_GetStats_ShouldReturnError_WhenDimensionIsInvalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		groupBy       []string
+		expectedError string
+	}{
+		{"unknown dimension", []string{"color"}, "Stats dimension color is not valid"},
+		{"repeated dimension", []string{"store", "store"}, "Stats dimension store is repeated"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := newProductTestService(newStatsTestProducts(), nil, nil).GetStats(domain.ProductStatsQuery{GroupBy: testCase.groupBy})
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service/model"
+	"testing"
+)
+
+func newStatusTestProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH", Status: domain.ProductStatusDraft},
+		{Id: 2, Name: "iron", Price: 1500.0, Store: "ABC TECH", Status: domain.ProductStatusActive},
+		{Id: 3, Name: "fax", Price: 10000.0, Store: "ABC TECH", Status: domain.ProductStatusArchived},
+	}
+}
+
+func Test_ChangeStatus_ShouldRecordHistory_WhenTransitionIsAllowed(t *testing.T) {
+	t.Run("ChangeStatus", func(t *testing.T) {
+		productService := newProductTestService(newStatusTestProducts(), nil, nil)
+		err := productService.ChangeStatus(model.ChangeProductStatus{ProductId: 1, Status: domain.ProductStatusActive, Actor: "user-1", Reason: "launch"})
+		assert.Nil(t, err)
+		err = productService.ChangeStatus(model.ChangeProductStatus{ProductId: 1, Status: domain.ProductStatusDiscontinued, Actor: "user-2"})
+		assert.Nil(t, err)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, domain.ProductStatusDiscontinued, product.Status)
+
+		history, _ := productService.GetStatusHistory(1)
+		assert.Equal(t, 2, len(history))
+		assert.Equal(t, domain.ProductStatusDraft, history[0].FromStatus)
+		assert.Equal(t, domain.ProductStatusActive, history[0].ToStatus)
+		assert.Equal(t, "user-1", history[0].Actor)
+		assert.Equal(t, "launch", history[0].Reason)
+		assert.Equal(t, "user-2", history[1].Actor)
+	})
+}
+
+func Test_ChangeStatus_ShouldReturnError_WhenTransitionIsNotAllowed(t *testing.T) {
+	testCases := []struct {
+		name          string
+		statusChange  model.ChangeProductStatus
+		expectedError string
+	}{
+		{"unknown status", model.ChangeProductStatus{ProductId: 2, Status: "deleted", Actor: "user-1"}, "Status deleted is not valid"},
+		{"missing actor", model.ChangeProductStatus{ProductId: 2, Status: domain.ProductStatusArchived}, "Actor is required to change product status"},
+		{"same status", model.ChangeProductStatus{ProductId: 2, Status: domain.ProductStatusActive, Actor: "user-1"}, "Product is already active"},
+		{"back to draft", model.ChangeProductStatus{ProductId: 2, Status: domain.ProductStatusDraft, Actor: "user-1"}, "Product can not move from active to draft"},
+		{"archived is final", model.ChangeProductStatus{ProductId: 3, Status: domain.ProductStatusActive, Actor: "user-1"}, "Product can not move from archived to active"},
+		{"missing product", model.ChangeProductStatus{ProductId: 9, Status: domain.ProductStatusActive, Actor: "user-1"}, "Product with id 9 not found"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := newProductTestService(newStatusTestProducts(), nil, nil).ChangeStatus(testCase.statusChange)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
+
+func Test_Add_ShouldReturnError_WhenStatusIsNotInitial(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		err := newProductTestService(newStatusTestProducts(), nil, nil).Add(model.CreateProduct{Name: "tv", Price: 5000.0, Store: "ABC TECH", Status: domain.ProductStatusArchived})
+		assert.NotNil(t, err)
+		assert.Equal(t, "Status of a new product should be draft or active", err.Error())
+	})
+}
+
+func Test_GetAllByFilter_ShouldReturnOnlyGivenStatuses(t *testing.T) {
+	t.Run("GetAllByFilter", func(t *testing.T) {
+		products, _ := newProductTestService(newStatusTestProducts(), nil, nil).GetAllByFilter(domain.ProductFilter{Statuses: []string{domain.ProductStatusActive}})
+		assert.Equal(t, 1, len(products))
+		assert.Equal(t, int64(2), products[0].Id)
+	})
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service/model"
+	"testing"
+)
+
+func newTranslationTestProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "ütü", Price: 1500.0, Store: "ABC TECH"},
+	}
+}
+
+func Test_Add_ShouldTakeNameFromStoreDefaultLocale_WhenOnlyTranslationsAreGiven(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		productService := newProductTestService(newTranslationTestProducts(), nil, nil)
+		err := productService.Add(model.CreateProduct{Price: 5000.0, Store: "ABC TECH", Sku: "TV-1", Translations: map[string]domain.LocalizedText{
+			"TR":    {Name: "televizyon", Description: "akıllı"},
+			"en_US": {Name: "tv", Description: "smart"},
+		}})
+		assert.Nil(t, err)
+
+		product, _ := productService.GetById(2)
+		assert.Equal(t, "televizyon", product.Name)
+		assert.Equal(t, "akıllı", product.Description)
+		assert.Equal(t, map[string]domain.LocalizedText{
+			"tr":    {Name: "televizyon", Description: "akıllı"},
+			"en-us": {Name: "tv", Description: "smart"},
+		}, product.Translations)
+	})
+}
+
+func Test_Add_ShouldReturnError_WhenTranslationsAreInvalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		product       model.CreateProduct
+		expectedError string
+	}{
+		{"store default locale missing",
+			model.CreateProduct{Name: "tv", Price: 5000.0, Store: "ABC TECH", Translations: map[string]domain.LocalizedText{"en": {Name: "tv"}}},
+			"Translation for store default locale tr is required"},
+		{"global default locale missing",
+			model.CreateProduct{Name: "tv", Price: 5000.0, Store: "x brand", Translations: map[string]domain.LocalizedText{"tr": {Name: "televizyon"}}},
+			"Translation for store default locale en is required"},
+		{"empty translation name",
+			model.CreateProduct{Name: "tv", Price: 5000.0, Store: "ABC TECH", Translations: map[string]domain.LocalizedText{"tr": {Description: "akıllı"}}},
+			"Translation name for locale tr should not be empty"},
+		{"duplicate locale",
+			model.CreateProduct{Name: "tv", Price: 5000.0, Store: "ABC TECH", Translations: map[string]domain.LocalizedText{"tr": {Name: "televizyon"}, "TR": {Name: "tv"}}},
+			"Locale tr is given more than once"},
+		{"name differs from store default locale translation",
+			model.CreateProduct{Name: "tv", Price: 5000.0, Store: "ABC TECH", Translations: map[string]domain.LocalizedText{"tr": {Name: "televizyon"}}},
+			"Name and description should match the translation for store default locale tr"},
+		{"description differs from store default locale translation",
+			model.CreateProduct{Name: "televizyon", Description: "smart", Price: 5000.0, Store: "ABC TECH", Translations: map[string]domain.LocalizedText{"tr": {Name: "televizyon", Description: "akıllı"}}},
+			"Name and description should match the translation for store default locale tr"},
+		{"no name",
+			model.CreateProduct{Price: 5000.0, Store: "ABC TECH"},
+			"Name should not be empty"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := newProductTestService(newTranslationTestProducts(), nil, nil).Add(testCase.product)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
+
+func Test_UpdateTranslations_ShouldMergeTranslations(t *testing.T) {
+	t.Run("UpdateTranslations", func(t *testing.T) {
+		productService := newProductTestService(newTranslationTestProducts(), nil, nil)
+		err := productService.UpdateTranslations(1, map[string]domain.LocalizedText{"en": {Name: "iron"}}, "user-1")
+		assert.Nil(t, err)
+		err = productService.UpdateTranslations(1, map[string]domain.LocalizedText{"de": {Name: "bügeleisen"}}, "user-1")
+		assert.Nil(t, err)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, map[string]domain.LocalizedText{"tr": {Name: "ütü"}, "en": {Name: "iron"}, "de": {Name: "bügeleisen"}}, product.Translations)
+	})
+}
+
+func Test_Update_ShouldStoreNameAsStoreDefaultLocaleTranslation_WhenNoTranslationsAreGiven(t *testing.T) {
+	t.Run("Update", func(t *testing.T) {
+		productService := newProductTestService(newTranslationTestProducts(), nil, nil)
+		err := productService.Update(1, model.CreateProduct{Name: "ütü", Description: "buharlı", Price: 1500.0, Store: "x brand", Sku: "UTU-1"})
+		assert.Nil(t, err)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, map[string]domain.LocalizedText{"tr": {Name: "ütü", Description: "buharlı"}}, product.Translations)
+
+		err = productService.Update(1, model.CreateProduct{Name: "ütü", Price: 1500.0, Sku: "UTU-1", Translations: map[string]domain.LocalizedText{"en": {Name: "iron"}}})
+		assert.Equal(t, "Translation for store default locale tr is required", err.Error())
+	})
+}
+
+func Test_UpdateTranslations_ShouldReturnError_WhenProductDoesNotExist(t *testing.T) {
+	t.Run("UpdateTranslations", func(t *testing.T) {
+		err := newProductTestService(newTranslationTestProducts(), nil, nil).UpdateTranslations(9, map[string]domain.LocalizedText{"en": {Name: "iron"}}, "user-1")
+		assert.NotNil(t, err)
+		assert.Equal(t, "Product with id 9 not found", err.Error())
+	})
+}
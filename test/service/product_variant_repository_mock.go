@@ -0,0 +1,38 @@
+package service
+
+import (
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"reflect"
+)
+
+type ProductVariantRepositoryMock struct {
+	productVariants []domain.ProductVariant
+}
+
+func NewProductVariantRepositoryMock(initialProductVariants []domain.ProductVariant) persistence.IProductVariantRepository {
+	return &ProductVariantRepositoryMock{productVariants: initialProductVariants}
+}
+
+func (productVariantRepository *ProductVariantRepositoryMock) Add(productVariant domain.ProductVariant) error {
+	for _, existing := range productVariantRepository.productVariants {
+		if existing.ProductId == productVariant.ProductId && reflect.DeepEqual(existing.Attributes, productVariant.Attributes) {
+			return persistence.ConflictError{Message: fmt.Sprintf("Variant with the same attributes already exists for product %d", productVariant.ProductId)}
+		}
+	}
+	productVariant.Id = int64(len(productVariantRepository.productVariants) + 1)
+	productVariantRepository.productVariants = append(productVariantRepository.productVariants, productVariant)
+	return nil
+}
+
+func (productVariantRepository *ProductVariantRepositoryMock) GetByProductId(productId int64) ([]domain.ProductVariant, error) {
+	var productVariants []domain.ProductVariant
+	for _, productVariant := range productVariantRepository.productVariants {
+		if productVariant.ProductId == productId {
+			productVariants = append(productVariants, productVariant)
+		}
+	}
+
+	return productVariants, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"testing"
+)
+
+func newProductVariantTestService() service.IProductVariantService {
+	products := []domain.Product{
+		{Id: 1, Name: "t-shirt", Price: 200.0, Store: "fashion", Sku: "TS", Options: []string{"size", "colour"}},
+		{Id: 2, Name: "iron", Price: 1500.0, Store: "ABC TECH"},
+	}
+	return service.NewProductVariantService(NewProductVariantRepositoryMock(nil), NewProductRepositoryMock(products))
+}
+
+func Test_AddProductVariant_ShouldAddVariant_WhenAttributesMatchOptions(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		productVariantService := newProductVariantTestService()
+
+		err := productVariantService.Add(model.CreateProductVariant{ProductId: 1, Sku: "TS-M-RED", Stock: 5, Attributes: map[string]string{"size": "M", "colour": "red"}})
+		assert.Nil(t, err)
+		err = productVariantService.Add(model.CreateProductVariant{ProductId: 1, PriceOverride: float32Pointer(220.0), Attributes: map[string]string{"size": "XL", "colour": "red"}})
+		assert.Nil(t, err)
+
+		productVariants, _ := productVariantService.GetByProductId(1)
+		assert.Equal(t, 2, len(productVariants))
+		assert.Equal(t, float32(220.0), productVariants[1].EffectivePrice(domain.Product{Price: 200.0}))
+	})
+}
+
+func Test_AddProductVariant_ShouldReturnConflict_WhenAttributesAlreadyExist(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		productVariantService := newProductVariantTestService()
+		attributes := map[string]string{"size": "M", "colour": "red"}
+
+		productVariantService.Add(model.CreateProductVariant{ProductId: 1, Attributes: attributes})
+		err := productVariantService.Add(model.CreateProductVariant{ProductId: 1, Attributes: attributes})
+
+		var conflictError persistence.ConflictError
+		assert.True(t, errors.As(err, &conflictError))
+	})
+}
+
+func Test_AddProductVariant_ShouldReturnConflict_WhenSkuIsUsedByProductOfStore(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		productVariantService := newProductVariantTestService()
+
+		err := productVariantService.Add(model.CreateProductVariant{ProductId: 1, Sku: "TS", Attributes: map[string]string{"size": "M", "colour": "red"}})
+
+		var conflictError persistence.ConflictError
+		assert.True(t, errors.As(err, &conflictError))
+		assert.Equal(t, "Sku TS is used by product 1 in store fashion", err.Error())
+	})
+}
+
+func Test_AddProductVariant_ShouldReturnError_WhenVariantIsInvalid(t *testing.T) {
+	productVariantService := newProductVariantTestService()
+
+	testCases := []struct {
+		name           string
+		productVariant model.CreateProductVariant
+		expectedError  string
+	}{
+		{"no options", model.CreateProductVariant{ProductId: 2, Attributes: map[string]string{"size": "M"}}, "Product with id 2 has no options to create variants for"},
+		{"missing axis", model.CreateProductVariant{ProductId: 1, Attributes: map[string]string{"size": "M"}}, "Variant attributes should be exactly size, colour"},
+		{"unknown axis", model.CreateProductVariant{ProductId: 1, Attributes: map[string]string{"size": "M", "fit": "slim"}}, "Variant attributes should be exactly size, colour"},
+		{"negative stock", model.CreateProductVariant{ProductId: 1, Stock: -1, Attributes: map[string]string{"size": "M", "colour": "red"}}, "Stock should not be negative"},
+		{"unknown product", model.CreateProductVariant{ProductId: 100}, "Product with id 100 not found"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := productVariantService.Add(testCase.productVariant)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"time"
+)
+
+type PromotionRepositoryMock struct {
+	promotions []domain.Promotion
+}
+
+func NewPromotionRepositoryMock(initialPromotions []domain.Promotion) persistence.IPromotionRepository {
+	return &PromotionRepositoryMock{promotions: initialPromotions}
+}
+
+func (promotionRepository *PromotionRepositoryMock) Add(promotion domain.Promotion) error {
+	promotion.Id = int64(len(promotionRepository.promotions) + 1)
+	promotionRepository.promotions = append(promotionRepository.promotions, promotion)
+	return nil
+}
+
+func (promotionRepository *PromotionRepositoryMock) GetById(id int64) (domain.Promotion, error) {
+	for _, promotion := range promotionRepository.promotions {
+		if promotion.Id == id {
+			return promotion, nil
+		}
+	}
+
+	return domain.Promotion{}, errors.New(fmt.Sprintf("Promotion with id %d not found", id))
+}
+
+func (promotionRepository *PromotionRepositoryMock) GetAll() ([]domain.Promotion, error) {
+	return promotionRepository.promotions, nil
+}
+
+func (promotionRepository *PromotionRepositoryMock) GetValid(now time.Time) ([]domain.Promotion, error) {
+	var promotions []domain.Promotion
+	for _, promotion := range promotionRepository.promotions {
+		if promotion.IsValidAt(now) {
+			promotions = append(promotions, promotion)
+		}
+	}
+
+	return promotions, nil
+}
+
+func (promotionRepository *PromotionRepositoryMock) DeleteById(id int64) error {
+	for i, promotion := range promotionRepository.promotions {
+		if promotion.Id == id {
+			promotionRepository.promotions = append(promotionRepository.promotions[:i], promotionRepository.promotions[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("Promotion with id %d not found", id))
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"testing"
+	"time"
+)
+
+func newPromotionTestService(promotions []domain.Promotion) service.IPromotionService {
+	products := []domain.Product{
+		{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH", Category: "climate"},
+		{Id: 2, Name: "phone", Price: 2000.0, Discount: 0.0, Store: "x brand", Category: "phones"},
+		{Id: 3, Name: "tablet", Price: 1000.0, Discount: 0.0, Store: "x brand", Category: "phones", Status: domain.ProductStatusDraft},
+	}
+	productService := newProductTestService(products, nil, nil)
+	return service.NewPromotionService(NewPromotionRepositoryMock(promotions), productService)
+}
+
+func Test_AddPromotion_ShouldReturnError_WhenPromotionIsInvalid(t *testing.T) {
+	promotionService := newPromotionTestService(nil)
+	now := time.Now()
+
+	testCases := []struct {
+		name          string
+		promotion     model.CreatePromotion
+		expectedError string
+	}{
+		{"missing name", model.CreatePromotion{Type: domain.PromotionTypePercentOff, Percent: 10, ValidFrom: now}, "Promotion name is required"},
+		{"unknown type", model.CreatePromotion{Name: "sale", Type: "gift", ValidFrom: now}, "Promotion type should be one of percent_off, buy_x_get_y, bundle"},
+		{"percent out of range", model.CreatePromotion{Name: "sale", Type: domain.PromotionTypePercentOff, Percent: 120, ValidFrom: now}, "Percent should be between 0 and 100"},
+		{"missing free quantity", model.CreatePromotion{Name: "bogo", Type: domain.PromotionTypeBuyXGetY, BuyQuantity: 1, ValidFrom: now}, "Buy and free quantities should be at least 1"},
+		{"bundle of one product", model.CreatePromotion{Name: "kit", Type: domain.PromotionTypeBundle, Percent: 15, BundleProductIds: []int64{1}, ValidFrom: now}, "Bundle should have at least 2 products"},
+		{"bundle repeats a product", model.CreatePromotion{Name: "kit", Type: domain.PromotionTypeBundle, Percent: 15, BundleProductIds: []int64{1, 2, 1}, ValidFrom: now}, "Product 1 is given more than once in the bundle"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := promotionService.Add(testCase.promotion)
+			assert.NotNil(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+		})
+	}
+}
+
+func Test_EvaluatePromotions_ShouldApplyValidPromotionsToEffectivePrice(t *testing.T) {
+	t.Run("Evaluate", func(t *testing.T) {
+		promotionService := newPromotionTestService(nil)
+		err := promotionService.Add(model.CreatePromotion{
+			Name: "climate sale", Type: domain.PromotionTypePercentOff, Category: "climate", Percent: 10, ValidFrom: time.Now().Add(-time.Minute),
+		})
+		assert.Nil(t, err)
+
+		evaluation, err := promotionService.Evaluate([]model.BasketItem{{ProductId: 1, Quantity: 2}, {ProductId: 2, Quantity: 1}}, domain.Caller{Role: domain.CallerRoleViewer})
+		assert.Nil(t, err)
+		assert.Equal(t, 6680.0, evaluation.Subtotal)
+		assert.Equal(t, 468.0, evaluation.PromotionDiscount)
+		assert.True(t, evaluation.Lines[0].Rules[0].Applied)
+	})
+}
+
+func Test_EvaluatePromotions_ShouldReturnError_WhenProductDoesNotExist(t *testing.T) {
+	t.Run("Evaluate", func(t *testing.T) {
+		_, err := newPromotionTestService(nil).Evaluate([]model.BasketItem{{ProductId: 100, Quantity: 1}}, domain.Caller{Role: domain.CallerRoleViewer})
+		assert.NotNil(t, err)
+		assert.Equal(t, "Product with id 100 not found", err.Error())
+	})
+}
+
+func Test_EvaluatePromotions_ShouldHideDrafts_WhenCallerIsViewer(t *testing.T) {
+	promotionService := newPromotionTestService(nil)
+
+	t.Run("Viewer", func(t *testing.T) {
+		_, err := promotionService.Evaluate([]model.BasketItem{{ProductId: 3, Quantity: 1}}, domain.Caller{Role: domain.CallerRoleViewer})
+		assert.Equal(t, "Product with id 3 not found", err.Error())
+	})
+	t.Run("Admin", func(t *testing.T) {
+		evaluation, err := promotionService.Evaluate([]model.BasketItem{{ProductId: 3, Quantity: 1}}, domain.Caller{Role: domain.CallerRoleAdmin})
+		assert.Nil(t, err)
+		assert.Equal(t, 1000.0, evaluation.Subtotal)
+	})
+}
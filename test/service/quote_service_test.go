@@ -0,0 +1,72 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"testing"
+	"time"
+)
+
+func Test_Quote_ShouldPriceKnownLinesAndReportUnknownOnes(t *testing.T) {
+	t.Run("Quote", func(t *testing.T) {
+		products := []domain.Product{
+			{Id: 1, Name: "air", Price: 3000.0, Discount: 22.0, Store: "ABC TECH", Category: "climate"},
+			{Id: 2, Name: "cable", Price: 100.0, Discount: 0.0, Store: "ABC TECH", Category: "accessories"},
+		}
+		promotions := []domain.Promotion{
+			{Id: 1, Name: "buy 2 get 1", Type: domain.PromotionTypeBuyXGetY, Category: "accessories", BuyQuantity: 2, FreeQuantity: 1, ValidFrom: time.Now().Add(-time.Hour)},
+		}
+		productService := newProductTestService(products, nil, nil)
+		quoteService := service.NewQuoteService(productService, NewPromotionRepositoryMock(promotions))
+
+		quote, err := quoteService.Quote([]model.BasketItem{
+			{ProductId: 1, Quantity: 2},
+			{ProductId: 100, Quantity: 1},
+			{ProductId: 2, Quantity: 3},
+		}, domain.Caller{Role: domain.CallerRoleViewer})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(quote.Lines))
+		assert.Equal(t, model.QuoteLineStatusPriced, quote.Lines[0].Status)
+		assert.Equal(t, 6000.0, quote.Lines[0].ListTotal)
+		assert.Equal(t, 1320.0, quote.Lines[0].ProductDiscount)
+		assert.Equal(t, model.QuoteLineStatusNotFound, quote.Lines[1].Status)
+		assert.Equal(t, 100.0, quote.Lines[2].Evaluation.PromotionDiscount)
+		assert.Equal(t, 6300.0, quote.Subtotal)
+		assert.Equal(t, 1320.0, quote.ProductDiscount)
+		assert.Equal(t, 100.0, quote.PromotionDiscount)
+		assert.Equal(t, 4880.0, quote.GrandTotal)
+	})
+}
+
+func Test_Quote_ShouldReturnError_WhenQuantityIsInvalid(t *testing.T) {
+	t.Run("Quote", func(t *testing.T) {
+		productService := newProductTestService(nil, nil, nil)
+		quoteService := service.NewQuoteService(productService, NewPromotionRepositoryMock(nil))
+
+		_, err := quoteService.Quote([]model.BasketItem{{ProductId: 1, Quantity: 0}}, domain.Caller{Role: domain.CallerRoleViewer})
+		assert.NotNil(t, err)
+		assert.Equal(t, "Quantity of product 1 should be at least 1", err.Error())
+	})
+}
+
+func Test_Quote_ShouldNotPriceDrafts_WhenCallerIsViewer(t *testing.T) {
+	products := []domain.Product{{Id: 1, Name: "air", Price: 3000.0, Store: "ABC TECH", Status: domain.ProductStatusDraft}}
+	productService := newProductTestService(products, nil, nil)
+	quoteService := service.NewQuoteService(productService, NewPromotionRepositoryMock(nil))
+
+	t.Run("Viewer", func(t *testing.T) {
+		quote, err := quoteService.Quote([]model.BasketItem{{ProductId: 1, Quantity: 1}}, domain.Caller{Role: domain.CallerRoleViewer})
+		assert.Nil(t, err)
+		assert.Equal(t, model.QuoteLineStatusNotFound, quote.Lines[0].Status)
+		assert.Equal(t, 0.0, quote.GrandTotal)
+	})
+	t.Run("Editor", func(t *testing.T) {
+		quote, err := quoteService.Quote([]model.BasketItem{{ProductId: 1, Quantity: 1}}, domain.Caller{Role: domain.CallerRoleEditor})
+		assert.Nil(t, err)
+		assert.Equal(t, model.QuoteLineStatusPriced, quote.Lines[0].Status)
+		assert.Equal(t, 3000.0, quote.GrandTotal)
+	})
+}
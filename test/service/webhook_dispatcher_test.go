@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go-product-app/domain"
+	"go-product-app/service"
+	"go-product-app/service/model"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const webhookTestSecret = "0123456789abcdef"
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// WebhookReceiver answers with the queued status codes and 200 once they are used up.
+type WebhookReceiver struct {
+	mutex       sync.Mutex
+	statusCodes []int
+	received    []receivedWebhook
+}
+
+func (webhookReceiver *WebhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	webhookReceiver.mutex.Lock()
+	defer webhookReceiver.mutex.Unlock()
+	webhookReceiver.received = append(webhookReceiver.received, receivedWebhook{header: r.Header.Clone(), body: body})
+	statusCode := http.StatusOK
+	if len(webhookReceiver.statusCodes) > 0 {
+		statusCode, webhookReceiver.statusCodes = webhookReceiver.statusCodes[0], webhookReceiver.statusCodes[1:]
+	}
+	w.WriteHeader(statusCode)
+	w.Write([]byte("receiver says " + http.StatusText(statusCode)))
+}
+
+func (webhookReceiver *WebhookReceiver) count() int {
+	webhookReceiver.mutex.Lock()
+	defer webhookReceiver.mutex.Unlock()
+	return len(webhookReceiver.received)
+}
+
+func webhookTestEvent(eventId string, eventType string, store string) domain.ProductEvent {
+	payload, _ := json.Marshal(domain.PriceChangedPayload{ProductId: 1, OldPrice: 3000, NewPrice: 2500, Store: store, Actor: "editor"})
+	return domain.ProductEvent{EventId: eventId, Type: eventType, ProductId: 1, Store: store, Payload: payload, OccurredAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+}
+
+func newWebhookTestSetup(t *testing.T, receiver *WebhookReceiver, maxAttempts int, disableAfterFailures int) (*WebhookRepositoryMock, service.IWebhookService, *service.WebhookDispatcher, string) {
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	webhookRepository := NewWebhookRepositoryMock()
+	webhookService := service.NewWebhookService(webhookRepository)
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepository, server.Client(), time.Second, 10, time.Minute, maxAttempts, disableAfterFailures)
+	return webhookRepository, webhookService, webhookDispatcher, server.URL
+}
+
+func Test_WebhookDispatcher_ShouldDeliverSignedEvents(t *testing.T) {
+	receiver := &WebhookReceiver{}
+	webhookRepository, webhookService, webhookDispatcher, url := newWebhookTestSetup(t, receiver, 3, 5)
+	webhook, err := webhookService.Add(model.CreateWebhook{Url: url + "/hooks", EventTypes: []string{domain.ProductEventPriceChanged}, Secret: webhookTestSecret})
+	assert.Nil(t, err)
+
+	assert.Nil(t, service.NewWebhookPublisher(webhookRepository).Publish(context.Background(), webhookTestEvent("e1", domain.ProductEventPriceChanged, "ABC TECH")))
+	assert.Equal(t, 1, webhookDispatcher.RunOnce(context.Background()))
+	assert.Equal(t, 1, receiver.count())
+
+	t.Run("SignsTheBody", func(t *testing.T) {
+		received := receiver.received[0]
+		timestamp, err := strconv.ParseInt(received.header.Get("X-Webhook-Timestamp"), 10, 64)
+		assert.Nil(t, err)
+		assert.Equal(t, "sha256="+domain.SignWebhookBody(webhookTestSecret, timestamp, received.body), received.header.Get("X-Webhook-Signature"))
+		assert.Equal(t, domain.ProductEventPriceChanged, received.header.Get("X-Webhook-Event"))
+		assert.Equal(t, "e1", received.header.Get("X-Webhook-Event-Id"))
+		assert.Equal(t, strconv.FormatInt(webhook.Id, 10), received.header.Get("X-Webhook-Id"))
+	})
+	t.Run("SendsTheEvent", func(t *testing.T) {
+		var body struct {
+			Id         string                     `json:"id"`
+			Type       string                     `json:"type"`
+			OccurredAt time.Time                  `json:"occurred_at"`
+			Data       domain.PriceChangedPayload `json:"data"`
+		}
+		assert.Nil(t, json.Unmarshal(receiver.received[0].body, &body))
+		assert.Equal(t, "e1", body.Id)
+		assert.Equal(t, domain.ProductEventPriceChanged, body.Type)
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), body.OccurredAt)
+		assert.Equal(t, float32(2500), body.Data.NewPrice)
+	})
+	t.Run("LogsTheDelivery", func(t *testing.T) {
+		deliveries, err := webhookService.GetDeliveries(webhook.Id, domain.WebhookDeliverySucceeded, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(deliveries))
+		assert.Equal(t, 1, deliveries[0].Attempts)
+		assert.Equal(t, http.StatusOK, deliveries[0].LastStatusCode)
+		assert.NotNil(t, deliveries[0].DeliveredAt)
+	})
+	t.Run("DeliversAnEventOnce", func(t *testing.T) {
+		assert.Nil(t, service.NewWebhookPublisher(webhookRepository).Publish(context.Background(), webhookTestEvent("e1", domain.ProductEventPriceChanged, "ABC TECH")))
+		assert.Equal(t, 0, webhookDispatcher.RunOnce(context.Background()))
+		assert.Equal(t, 1, receiver.count())
+	})
+}
+
+func Test_WebhookDispatcher_ShouldOnlyDeliverSubscribedEvents(t *testing.T) {
+	receiver := &WebhookReceiver{}
+	webhookRepository, webhookService, webhookDispatcher, url := newWebhookTestSetup(t, receiver, 3, 5)
+	_, err := webhookService.Add(model.CreateWebhook{Url: url, EventTypes: []string{domain.ProductEventCreated}})
+	assert.Nil(t, err)
+	_, err = webhookService.Add(model.CreateWebhook{Url: url, EventTypes: []string{domain.ProductEventPriceChanged}, Store: "x brand"})
+	assert.Nil(t, err)
+
+	webhookPublisher := service.NewWebhookPublisher(webhookRepository)
+	assert.Nil(t, webhookPublisher.Publish(context.Background(), webhookTestEvent("e1", domain.ProductEventPriceChanged, "ABC TECH")))
+	assert.Equal(t, 0, webhookDispatcher.RunOnce(context.Background()))
+
+	assert.Nil(t, webhookPublisher.Publish(context.Background(), webhookTestEvent("e2", domain.ProductEventPriceChanged, "x brand")))
+	assert.Nil(t, webhookPublisher.Publish(context.Background(), webhookTestEvent("e3", domain.ProductEventCreated, "ABC TECH")))
+	assert.Equal(t, 2, webhookDispatcher.RunOnce(context.Background()))
+	assert.Equal(t, 2, receiver.count())
+}
+
+func Test_WebhookDispatcher_ShouldRetryFailedDeliveries(t *testing.T) {
+	receiver := &WebhookReceiver{statusCodes: []int{http.StatusInternalServerError, http.StatusServiceUnavailable}}
+	webhookRepository, webhookService, webhookDispatcher, url := newWebhookTestSetup(t, receiver, 3, 5)
+	webhook, _ := webhookService.Add(model.CreateWebhook{Url: url, EventTypes: []string{domain.ProductEventPriceChanged}})
+	assert.Nil(t, service.NewWebhookPublisher(webhookRepository).Publish(context.Background(), webhookTestEvent("e1", domain.ProductEventPriceChanged, "ABC TECH")))
+
+	assert.Equal(t, 0, webhookDispatcher.RunOnce(context.Background()))
+	deliveries, _ := webhookService.GetDeliveries(webhook.Id, "", 0)
+	assert.Equal(t, domain.WebhookDeliveryPending, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, deliveries[0].LastStatusCode)
+	assert.Equal(t, "Receiver responded with status 500", deliveries[0].LastError)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), deliveries[0].NextAttempt, 5*time.Second)
+
+	t.Run("WaitsForTheBackoff", func(t *testing.T) {
+		assert.Equal(t, 0, webhookDispatcher.RunOnce(context.Background()))
+		assert.Equal(t, 1, receiver.count())
+	})
+	t.Run("DeliversOnceTheReceiverRecovers", func(t *testing.T) {
+		webhookRepository.makeDue()
+		assert.Equal(t, 0, webhookDispatcher.RunOnce(context.Background()))
+		webhookRepository.makeDue()
+		assert.Equal(t, 1, webhookDispatcher.RunOnce(context.Background()))
+
+		deliveries, _ = webhookService.GetDeliveries(webhook.Id, "", 0)
+		assert.Equal(t, domain.WebhookDeliverySucceeded, deliveries[0].Status)
+		assert.Equal(t, 3, deliveries[0].Attempts)
+		assert.Empty(t, deliveries[0].LastError)
+	})
+}
+
+func Test_WebhookDispatcher_ShouldFailDeliveryAfterMaxAttempts(t *testing.T) {
+	receiver := &WebhookReceiver{statusCodes: []int{http.StatusBadGateway, http.StatusBadGateway}}
+	webhookRepository, webhookService, webhookDispatcher, url := newWebhookTestSetup(t, receiver, 2, 5)
+	webhook, _ := webhookService.Add(model.CreateWebhook{Url: url, EventTypes: []string{domain.ProductEventPriceChanged}})
+	assert.Nil(t, service.NewWebhookPublisher(webhookRepository).Publish(context.Background(), webhookTestEvent("e1", domain.ProductEventPriceChanged, "ABC TECH")))
+
+	webhookDispatcher.RunOnce(context.Background())
+	webhookRepository.makeDue()
+	webhookDispatcher.RunOnce(context.Background())
+	webhookRepository.makeDue()
+	assert.Equal(t, 0, webhookDispatcher.RunOnce(context.Background()))
+	assert.Equal(t, 2, receiver.count())
+
+	deliveries, _ := webhookService.GetDeliveries(webhook.Id, domain.WebhookDeliveryFailed, 0)
+	assert.Equal(t, 1, len(deliveries))
+
+	t.Run("RedeliversOnRequest", func(t *testing.T) {
+		delivery, err := webhookService.Redeliver(webhook.Id, deliveries[0].Id)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, 0, delivery.Attempts)
+
+		assert.Equal(t, 1, webhookDispatcher.RunOnce(context.Background()))
+		assert.Equal(t, 3, receiver.count())
+	})
+	t.Run("RedeliversOnlyDeliveriesOfTheWebhook", func(t *testing.T) {
+		_, err := webhookService.Redeliver(webhook.Id+1, deliveries[0].Id)
+		assert.Equal(t, "Delivery with id 1 of webhook 2 not found", err.Error())
+	})
+}
+
+func Test_WebhookDispatcher_ShouldDeactivateWebhookAfterConsecutiveFailures(t *testing.T) {
+	receiver := &WebhookReceiver{statusCodes: []int{http.StatusInternalServerError, http.StatusInternalServerError}}
+	webhookRepository, webhookService, webhookDispatcher, url := newWebhookTestSetup(t, receiver, 5, 2)
+	webhook, _ := webhookService.Add(model.CreateWebhook{Url: url, EventTypes: []string{domain.ProductEventPriceChanged}})
+	webhookPublisher := service.NewWebhookPublisher(webhookRepository)
+	assert.Nil(t, webhookPublisher.Publish(context.Background(), webhookTestEvent("e1", domain.ProductEventPriceChanged, "ABC TECH")))
+	assert.Nil(t, webhookPublisher.Publish(context.Background(), webhookTestEvent("e2", domain.ProductEventPriceChanged, "ABC TECH")))
+
+	assert.Equal(t, 0, webhookDispatcher.RunOnce(context.Background()))
+	webhook, _ = webhookService.GetById(webhook.Id)
+	assert.False(t, webhook.Active)
+	assert.Equal(t, 2, webhook.ConsecutiveFailures)
+	assert.NotNil(t, webhook.DisabledAt)
+
+	t.Run("StopsSending", func(t *testing.T) {
+		webhookRepository.makeDue()
+		assert.Nil(t, webhookPublisher.Publish(context.Background(), webhookTestEvent("e3", domain.ProductEventPriceChanged, "ABC TECH")))
+		assert.Equal(t, 0, webhookDispatcher.RunOnce(context.Background()))
+		assert.Equal(t, 2, receiver.count())
+	})
+	t.Run("SendsWaitingDeliveriesOnceActivated", func(t *testing.T) {
+		webhook, err := webhookService.Update(model.UpdateWebhook{Id: webhook.Id, Url: webhook.Url, EventTypes: webhook.EventTypes, Active: true})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, webhook.ConsecutiveFailures)
+		assert.Nil(t, webhook.DisabledAt)
+
+		assert.Equal(t, 2, webhookDispatcher.RunOnce(context.Background()))
+		assert.Equal(t, 4, receiver.count())
+	})
+}
+
+func Test_WebhookService_ShouldValidateWebhook(t *testing.T) {
+	webhookService := service.NewWebhookService(NewWebhookRepositoryMock())
+	priceChanged := []string{domain.ProductEventPriceChanged}
+
+	testCases := []struct {
+		name     string
+		webhook  model.CreateWebhook
+		expected string
+	}{
+		{"RelativeUrl", model.CreateWebhook{Url: "/hooks", EventTypes: priceChanged}, "Webhook url /hooks should be an absolute http or https url"},
+		{"OtherScheme", model.CreateWebhook{Url: "ftp://example.com", EventTypes: priceChanged}, "Webhook url ftp://example.com should be an absolute http or https url"},
+		{"NoEventTypes", model.CreateWebhook{Url: "https://example.com"}, "Webhook should subscribe to at least one event type"},
+		{"UnknownEventType", model.CreateWebhook{Url: "https://example.com", EventTypes: []string{"product.renamed"}}, "Event type product.renamed is not valid"},
+		{"RepeatedEventType", model.CreateWebhook{Url: "https://example.com", EventTypes: []string{domain.ProductEventDeleted, domain.ProductEventDeleted}}, "Event type product.deleted is given more than once"},
+		{"ShortSecret", model.CreateWebhook{Url: "https://example.com", EventTypes: priceChanged, Secret: "short"}, "Webhook secret should be at least 16 characters"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := webhookService.Add(testCase.webhook)
+			assert.Equal(t, testCase.expected, err.Error())
+		})
+	}
+
+	t.Run("GeneratesSecret", func(t *testing.T) {
+		webhook, err := webhookService.Add(model.CreateWebhook{Url: "https://example.com/hooks", EventTypes: priceChanged})
+		assert.Nil(t, err)
+		assert.Equal(t, 64, len(webhook.Secret))
+		assert.False(t, strings.ContainsAny(webhook.Secret, "ghijklmnopqrstuvwxyz"))
+	})
+	t.Run("RejectsUnknownDeliveryStatus", func(t *testing.T) {
+		_, err := webhookService.GetDeliveries(1, "sent", 0)
+		assert.Equal(t, "Delivery status should be one of pending, succeeded, failed", err.Error())
+	})
+}
+
+func Test_WebhookClient_ShouldOnlyReachPublicAddresses(t *testing.T) {
+	receiver := &WebhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	client := service.NewWebhookClient(time.Second)
+
+	t.Run("RefusesLoopback", func(t *testing.T) {
+		_, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+		assert.ErrorContains(t, err, "Webhook receiver address 127.0.0.1 is not public")
+		assert.Equal(t, 0, receiver.count())
+	})
+	t.Run("RefusesMetadataService", func(t *testing.T) {
+		_, err := client.Post("http://169.254.169.254/latest/meta-data", "application/json", strings.NewReader("{}"))
+		assert.ErrorContains(t, err, "Webhook receiver address 169.254.169.254 is not public")
+	})
+	t.Run("RefusesPrivateNetworks", func(t *testing.T) {
+		for _, host := range []string{"10.0.0.1", "172.16.0.1", "192.168.1.1", "100.64.0.1", "[::1]", "[fd00::1]", "[::ffff:10.0.0.1]", "0.0.0.0"} {
+			_, err := client.Post("http://"+host+"/hooks", "application/json", strings.NewReader("{}"))
+			assert.ErrorContains(t, err, "is not public", host)
+		}
+	})
+	t.Run("DoesNotFollowRedirects", func(t *testing.T) {
+		assert.Equal(t, http.ErrUseLastResponse, client.CheckRedirect(httptest.NewRequest(http.MethodGet, "http://example.com", nil), nil))
+	})
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"fmt"
+	"go-product-app/domain"
+	"go-product-app/persistence"
+	"slices"
+	"sync"
+	"time"
+)
+
+type WebhookRepositoryMock struct {
+	mutex      sync.Mutex
+	webhooks   []domain.Webhook
+	deliveries []domain.WebhookDelivery
+}
+
+func NewWebhookRepositoryMock() *WebhookRepositoryMock {
+	return &WebhookRepositoryMock{}
+}
+
+func (webhookRepository *WebhookRepositoryMock) Add(webhook domain.Webhook) (domain.Webhook, error) {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	webhook.Id = int64(len(webhookRepository.webhooks) + 1)
+	webhook.Active = true
+	webhook.CreatedAt, webhook.UpdatedAt = time.Now(), time.Now()
+	webhookRepository.webhooks = append(webhookRepository.webhooks, webhook)
+	return webhook, nil
+}
+
+func (webhookRepository *WebhookRepositoryMock) GetAll() ([]domain.Webhook, error) {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	return slices.Clone(webhookRepository.webhooks), nil
+}
+
+func (webhookRepository *WebhookRepositoryMock) GetById(id int64) (domain.Webhook, error) {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	webhook := webhookRepository.find(id)
+	if webhook == nil {
+		return domain.Webhook{}, persistence.NotFoundError{Message: fmt.Sprintf("Webhook with id %d not found", id)}
+	}
+	return *webhook, nil
+}
+
+func (webhookRepository *WebhookRepositoryMock) Update(webhook domain.Webhook) (domain.Webhook, error) {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	existing := webhookRepository.find(webhook.Id)
+	if existing == nil {
+		return domain.Webhook{}, persistence.NotFoundError{Message: fmt.Sprintf("Webhook with id %d not found", webhook.Id)}
+	}
+	existing.Url, existing.EventTypes, existing.Store, existing.Active = webhook.Url, webhook.EventTypes, webhook.Store, webhook.Active
+	if len(webhook.Secret) > 0 {
+		existing.Secret = webhook.Secret
+	}
+	if webhook.Active {
+		existing.ConsecutiveFailures, existing.DisabledAt = 0, nil
+	}
+	existing.UpdatedAt = time.Now()
+	return *existing, nil
+}
+
+func (webhookRepository *WebhookRepositoryMock) DeleteById(id int64) error {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	for i, webhook := range webhookRepository.webhooks {
+		if webhook.Id == id {
+			webhookRepository.webhooks = slices.Delete(webhookRepository.webhooks, i, i+1)
+			return nil
+		}
+	}
+	return persistence.NotFoundError{Message: fmt.Sprintf("Webhook with id %d not found", id)}
+}
+
+func (webhookRepository *WebhookRepositoryMock) EnqueueDeliveries(event domain.ProductEvent, store string, body string) (int64, error) {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	var queued int64
+	for _, webhook := range webhookRepository.webhooks {
+		if !webhook.Active || !slices.Contains(webhook.EventTypes, event.Type) || (len(webhook.Store) > 0 && webhook.Store != store) {
+			continue
+		}
+		if slices.ContainsFunc(webhookRepository.deliveries, func(delivery domain.WebhookDelivery) bool {
+			return delivery.WebhookId == webhook.Id && delivery.EventId == event.EventId
+		}) {
+			continue
+		}
+		webhookRepository.deliveries = append(webhookRepository.deliveries, domain.WebhookDelivery{
+			Id:          int64(len(webhookRepository.deliveries) + 1),
+			WebhookId:   webhook.Id,
+			EventId:     event.EventId,
+			EventType:   event.Type,
+			ProductId:   event.ProductId,
+			Body:        body,
+			Status:      domain.WebhookDeliveryPending,
+			NextAttempt: time.Now(),
+			CreatedAt:   time.Now(),
+		})
+		queued++
+	}
+	return queued, nil
+}
+
+func (webhookRepository *WebhookRepositoryMock) ClaimDueDeliveries(limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	claimed := make([]domain.WebhookDelivery, 0)
+	for i := range webhookRepository.deliveries {
+		delivery := &webhookRepository.deliveries[i]
+		webhook := webhookRepository.find(delivery.WebhookId)
+		if len(claimed) == limit || webhook == nil || !webhook.Active ||
+			delivery.Status != domain.WebhookDeliveryPending || delivery.NextAttempt.After(time.Now()) {
+			continue
+		}
+		delivery.NextAttempt = time.Now().Add(lease)
+		claimedDelivery := *delivery
+		claimedDelivery.Url, claimedDelivery.Secret = webhook.Url, webhook.Secret
+		claimed = append(claimed, claimedDelivery)
+	}
+	return claimed, nil
+}
+
+func (webhookRepository *WebhookRepositoryMock) RecordAttempt(delivery domain.WebhookDelivery, disableAfter int) error {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	stored := webhookRepository.findDelivery(delivery.Id)
+	stored.Status, stored.NextAttempt, stored.LastStatusCode, stored.LastError = delivery.Status, delivery.NextAttempt, delivery.LastStatusCode, delivery.LastError
+	stored.Attempts++
+
+	webhook := webhookRepository.find(delivery.WebhookId)
+	if delivery.Status == domain.WebhookDeliverySucceeded {
+		now := time.Now()
+		stored.DeliveredAt = &now
+		webhook.ConsecutiveFailures = 0
+		return nil
+	}
+	webhook.ConsecutiveFailures++
+	if webhook.Active && webhook.ConsecutiveFailures >= disableAfter {
+		now := time.Now()
+		webhook.Active, webhook.DisabledAt = false, &now
+	}
+	return nil
+}
+
+func (webhookRepository *WebhookRepositoryMock) GetDeliveries(webhookId int64, status string, limit int) ([]domain.WebhookDelivery, error) {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for i := len(webhookRepository.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		delivery := webhookRepository.deliveries[i]
+		if delivery.WebhookId == webhookId && (len(status) == 0 || delivery.Status == status) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (webhookRepository *WebhookRepositoryMock) Redeliver(webhookId int64, deliveryId int64) (domain.WebhookDelivery, error) {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	delivery := webhookRepository.findDelivery(deliveryId)
+	if delivery == nil || delivery.WebhookId != webhookId {
+		return domain.WebhookDelivery{}, persistence.NotFoundError{Message: fmt.Sprintf("Delivery with id %d of webhook %d not found", deliveryId, webhookId)}
+	}
+	delivery.Status, delivery.Attempts, delivery.NextAttempt = domain.WebhookDeliveryPending, 0, time.Now()
+	return *delivery, nil
+}
+
+// makeDue lets the tests skip the retry delay of the pending deliveries.
+func (webhookRepository *WebhookRepositoryMock) makeDue() {
+	webhookRepository.mutex.Lock()
+	defer webhookRepository.mutex.Unlock()
+
+	for i := range webhookRepository.deliveries {
+		webhookRepository.deliveries[i].NextAttempt = time.Now()
+	}
+}
+
+func (webhookRepository *WebhookRepositoryMock) find(id int64) *domain.Webhook {
+	for i := range webhookRepository.webhooks {
+		if webhookRepository.webhooks[i].Id == id {
+			return &webhookRepository.webhooks[i]
+		}
+	}
+	return nil
+}
+
+func (webhookRepository *WebhookRepositoryMock) findDelivery(id int64) *domain.WebhookDelivery {
+	for i := range webhookRepository.deliveries {
+		if webhookRepository.deliveries[i].Id == id {
+			return &webhookRepository.deliveries[i]
+		}
+	}
+	return nil
+}